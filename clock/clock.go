@@ -0,0 +1,53 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock gives the current time. It is injected wherever the time matters so that
+// tests can control it.
+type Clock interface {
+	Now() time.Time
+}
+
+// Real is the wall clock, expressed in a given location.
+type Real struct {
+	Location *time.Location
+}
+
+func New(location *time.Location) Real {
+	return Real{Location: location}
+}
+
+func (c Real) Now() time.Time {
+	return time.Now().In(c.Location)
+}
+
+// Mock is a manually driven clock for tests. It is safe for concurrent use.
+type Mock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewMock(now time.Time) *Mock {
+	return &Mock{now: now}
+}
+
+func (c *Mock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *Mock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+func (c *Mock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
@@ -47,6 +47,7 @@ func RunCreateMatch(ctx context.Context, db database.Database, opt CreateMatchOp
 		Score2:          nil,
 		CreatorID:       opt.UserID,
 		CourtID:         opt.CourtID,
+		Visibility:      models.VisibilityPublic,
 		CreatedAt:       now,
 		UpdatedAt:       now,
 	}
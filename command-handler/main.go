@@ -1,14 +1,23 @@
 package main
 
 import (
+	"PLIC/clock"
 	"PLIC/database"
+	"PLIC/domain"
+	"PLIC/mailer"
+	"PLIC/matchmaking"
 	"PLIC/models"
+	"PLIC/notifier"
+	"PLIC/outbox"
+	"PLIC/rating"
 	"context"
 	"flag"
 	"fmt"
 	"os"
 	"time"
 
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/caarlos0/env/v10"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
 	"github.com/joho/godotenv"
@@ -18,7 +27,14 @@ import (
 )
 
 type App struct {
-	db database.Database
+	db         database.Database
+	mailer     mailer.MailSender
+	ratings    rating.Engines
+	clock      clock.Clock
+	matcher    matchmaking.Matcher
+	dispatcher outbox.Dispatcher
+	// startingSoon is how long before its date the players of a match are reminded.
+	startingSoon time.Duration
 }
 
 func main() {
@@ -51,8 +67,70 @@ func main() {
 	dbStd.SetMaxIdleConns(10)
 	dbStd.SetMaxOpenConns(20)
 
+	parisLocation, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		log.Fatal().Err(err).Msg("échec chargement fuseau Europe/Paris")
+	}
+
+	var mailerConfig models.MailerConfig
+	if err := env.Parse(&mailerConfig); err != nil {
+		log.Fatal().Err(err).Msg("échec lecture configuration des e-mails")
+	}
+
+	var ratingConfig models.RatingConfig
+	if err := env.Parse(&ratingConfig); err != nil {
+		log.Fatal().Err(err).Msg("échec lecture configuration du classement")
+	}
+	ratings, err := rating.NewEngines(ratingConfig)
+	if err != nil {
+		log.Fatal().Err(err).Msg("configuration du classement invalide")
+	}
+
+	var matchmakingConfig models.MatchmakingConfig
+	if err := env.Parse(&matchmakingConfig); err != nil {
+		log.Fatal().Err(err).Msg("échec lecture configuration du matchmaking")
+	}
+	var outboxConfig models.OutboxConfig
+	if err := env.Parse(&outboxConfig); err != nil {
+		log.Fatal().Err(err).Msg("échec lecture configuration de l'outbox")
+	}
+	var notifierConfig models.NotifierConfig
+	if err := env.Parse(&notifierConfig); err != nil {
+		log.Fatal().Err(err).Msg("échec lecture configuration des notifications")
+	}
+	appClock := clock.New(parisLocation)
+	db := database.Database{Database: sqlxDB}
+	appMailer, err := mailer.New(&mailerConfig, db, appClock)
+	if err != nil {
+		log.Fatal().Err(err).Msg("configuration des e-mails invalide")
+	}
+
 	app := &App{
-		db: database.Database{Database: sqlxDB},
+		db:      db,
+		mailer:  appMailer,
+		ratings: ratings,
+		clock:   appClock,
+		matcher: matchmaking.Matcher{
+			Clock:           appClock,
+			MaxEloGap:       matchmakingConfig.MaxEloGap,
+			EloGapPerMinute: matchmakingConfig.EloGapPerMinute,
+			LeadTime:        matchmakingConfig.LeadTime,
+		},
+		dispatcher: outbox.Dispatcher{
+			Store:    db,
+			Clock:    appClock,
+			Config:   outboxConfig,
+			Handlers: domain.OutboxHandlers(db, appMailer, notifier.New(notifierConfig, db)),
+		},
+		startingSoon: notifierConfig.StartingSoon,
+	}
+
+	// Deployed as a Lambda, the binary is only triggered by the scheduler cron rule.
+	if os.Getenv("AWS_LAMBDA_FUNCTION_NAME") != "" {
+		lambda.Start(func(ctx context.Context) error {
+			return RunScheduler(ctx, app.db, app.ratings, app.clock, app.matcher, app.dispatcher, app.startingSoon)
+		})
+		return
 	}
 
 	if len(os.Args) < 2 {
@@ -98,6 +176,78 @@ func main() {
 		}
 		log.Info().Msg("✅ create-match terminé avec succès")
 
+	case "scheduler":
+		fs := flag.NewFlagSet("scheduler", flag.ExitOnError)
+		var every time.Duration
+		fs.DurationVar(&every, "every", 0, "Relance le passage à cet intervalle (ex: 1m) ; un seul passage si absent")
+		_ = fs.Parse(os.Args[2:])
+
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			err := RunScheduler(ctx, app.db, app.ratings, app.clock, app.matcher, app.dispatcher, app.startingSoon)
+			cancel()
+			if err != nil {
+				log.Fatal().Err(err).Msg("❌ scheduler a échoué")
+			}
+			if every <= 0 {
+				break
+			}
+			time.Sleep(every)
+		}
+		log.Info().Msg("✅ scheduler terminé avec succès")
+
+	case "replay-ratings":
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+
+		if err := RunReplayRatings(ctx, app.db, app.ratings); err != nil {
+			log.Fatal().Err(err).Msg("❌ replay-ratings a échoué")
+		}
+		log.Info().Msg("✅ replay-ratings terminé avec succès")
+
+	case "matchmaking":
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		if err := RunMatchmaking(ctx, app.db, app.ratings, app.matcher); err != nil {
+			log.Fatal().Err(err).Msg("❌ matchmaking a échoué")
+		}
+		log.Info().Msg("✅ matchmaking terminé avec succès")
+
+	case "outbox":
+		fs := flag.NewFlagSet("outbox", flag.ExitOnError)
+		var (
+			every   time.Duration
+			requeue bool
+		)
+		fs.DurationVar(&every, "every", 0, "Relance la livraison à cet intervalle (ex: 10s) ; un seul passage si absent")
+		fs.BoolVar(&requeue, "requeue-dead", false, "Remet en attente les évènements abandonnés avant de livrer")
+		_ = fs.Parse(os.Args[2:])
+
+		if requeue {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			n, err := app.db.RequeueDeadOutboxEvents(ctx, app.clock.Now())
+			cancel()
+			if err != nil {
+				log.Fatal().Err(err).Msg("❌ requeue des évènements abandonnés a échoué")
+			}
+			log.Info().Int("requeued", n).Msg("évènements abandonnés remis en attente")
+		}
+
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			err := RunOutbox(ctx, app.dispatcher)
+			cancel()
+			if err != nil {
+				log.Fatal().Err(err).Msg("❌ outbox a échoué")
+			}
+			if every <= 0 {
+				break
+			}
+			time.Sleep(every)
+		}
+		log.Info().Msg("✅ outbox terminé avec succès")
+
 	default:
 		log.Error().Str("cmd", cmd).Msg("commande inconnue")
 		printUsage()
@@ -107,5 +257,12 @@ func main() {
 
 func printUsage() {
 	fmt.Println(`Usage:
-  go run ./command-handler <command> [options]`)
+  go run ./command-handler <command> [options]
+
+Commands:
+  create-match   Crée un match et y inscrit son créateur
+  scheduler      Applique les transitions d'état liées à la date des matchs
+  replay-ratings Recalcule tous les classements en rejouant les matchs terminés
+  matchmaking    Forme les matchs à partir de la file d'attente du matchmaking
+  outbox         Livre les e-mails et notifications en attente dans l'outbox (-requeue-dead pour relancer les abandonnés)`)
 }
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"PLIC/database"
+	"PLIC/domain"
+	"PLIC/matchmaking"
+	"PLIC/rating"
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RunMatchmaking forms matches out of the matchmaking queue; see domain.RunMatchmaking.
+func RunMatchmaking(ctx context.Context, db database.Database, engines rating.Engines, matcher matchmaking.Matcher) error {
+	created, err := domain.RunMatchmaking(ctx, db, engines, matcher)
+	if err != nil {
+		return fmt.Errorf("matchmaking: %w", err)
+	}
+
+	for _, m := range created {
+		log.Info().
+			Str("match_id", m.Id).
+			Str("sport", string(m.Sport)).
+			Str("court_id", m.CourtID).
+			Time("date", m.Date).
+			Int("players", m.ParticipantNber).
+			Msg("matchmaking: match créé")
+	}
+	log.Info().Int("created", len(created)).Msg("🤝 matchmaking: passage terminé")
+	return nil
+}
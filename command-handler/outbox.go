@@ -0,0 +1,24 @@
+package main
+
+import (
+	"PLIC/outbox"
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RunOutbox delivers the due outbox events; see outbox.Dispatcher.
+func RunOutbox(ctx context.Context, dispatcher outbox.Dispatcher) error {
+	res, err := dispatcher.Drain(ctx)
+	if err != nil {
+		return fmt.Errorf("outbox: %w", err)
+	}
+
+	log.Info().
+		Int("delivered", res.Delivered).
+		Int("retried", res.Retried).
+		Int("dead", res.Dead).
+		Msg("📬 outbox: passage terminé")
+	return nil
+}
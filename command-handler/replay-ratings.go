@@ -0,0 +1,29 @@
+package main
+
+import (
+	"PLIC/database"
+	"PLIC/rating"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RunReplayRatings rebuilds every ranking and the ranking history by replaying all
+// finished matches with the configured engines. Run it after changing the engine or
+// the parameters of a sport.
+func RunReplayRatings(ctx context.Context, db database.Database, engines rating.Engines) error {
+	start := time.Now()
+
+	replayed, err := db.ReplayRatings(ctx, engines.Rate, engines.NewRanking)
+	if err != nil {
+		return fmt.Errorf("replay ratings: %w", err)
+	}
+
+	log.Info().
+		Int("matches", replayed).
+		Dur("duration", time.Since(start)).
+		Msg("classements recalculés")
+	return nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"PLIC/clock"
+	"PLIC/database"
+	"PLIC/domain"
+	"PLIC/matchmaking"
+	"PLIC/models"
+	"PLIC/outbox"
+	"PLIC/rating"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// matchTimeLimits is how long a match can stay En cours after its date before the
+// scheduler stops waiting and asks the players for the score.
+var matchTimeLimits = map[models.Sport]time.Duration{
+	models.Basket:   2 * time.Hour,
+	models.Foot:     2 * time.Hour,
+	models.PingPong: time.Hour,
+}
+
+// RunScheduler applies the date-driven match transitions:
+//   - Manque joueur matches whose date has passed are cancelled,
+//   - the players of Valide matches starting within startingSoon are reminded, once,
+//   - Valide matches whose date has come are started,
+//   - En cours matches older than their sport's time limit move to Manque Score,
+//   - Manque Score matches past their vote deadline are resolved: an uncontested
+//     vote becomes the final score, otherwise the match is voided.
+//
+// It then runs the matchmaker, so that queued players are matched on every pass, and
+// delivers the outbox events, including the emails and notifications queued by this
+// pass.
+//
+// Each transition is a single conditional UPDATE, so running it concurrently with
+// the HTTP handlers or with another scheduler run is safe.
+func RunScheduler(ctx context.Context, db database.Database, engines rating.Engines, clk clock.Clock, matcher matchmaking.Matcher, dispatcher outbox.Dispatcher, startingSoon time.Duration) error {
+	now := clk.Now()
+
+	cancelled, err := db.CancelExpiredMatches(ctx, now)
+	if err != nil {
+		return fmt.Errorf("cancel expired matches: %w", err)
+	}
+	logTransitions(cancelled, models.ManqueJoueur, models.Annule)
+
+	reminded, err := db.MarkMatchesStartingSoon(ctx, now.Add(startingSoon), now)
+	if err != nil {
+		return fmt.Errorf("remind matches starting soon: %w", err)
+	}
+
+	started, err := db.StartDueMatches(ctx, now)
+	if err != nil {
+		return fmt.Errorf("start due matches: %w", err)
+	}
+	logTransitions(started, models.Valide, models.EnCours)
+
+	expiredCount := 0
+	for _, sport := range []models.Sport{models.Basket, models.Foot, models.PingPong} {
+		expired, err := db.ExpireStartedMatches(ctx, sport, now.Add(-matchTimeLimits[sport]), now)
+		if err != nil {
+			return fmt.Errorf("expire %s matches: %w", sport, err)
+		}
+		logTransitions(expired, models.EnCours, models.ManqueScore)
+		expiredCount += len(expired)
+	}
+
+	resolved, err := resolveScoreDeadlines(ctx, db, engines, now)
+	if err != nil {
+		return err
+	}
+
+	log.Info().
+		Time("now", now).
+		Int("cancelled", len(cancelled)).
+		Int("reminded", len(reminded)).
+		Int("started", len(started)).
+		Int("expired", expiredCount).
+		Int("resolved", resolved).
+		Msg("⏱️ scheduler: passage terminé")
+
+	if err := RunMatchmaking(ctx, db, engines, matcher); err != nil {
+		return err
+	}
+	return RunOutbox(ctx, dispatcher)
+}
+
+func resolveScoreDeadlines(ctx context.Context, db database.Database, engines rating.Engines, now time.Time) (int, error) {
+	ids, err := db.GetMatchIDsPastScoreDeadline(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("list score deadlines: %w", err)
+	}
+
+	resolved := 0
+	for _, id := range ids {
+		match, _, err := domain.ResolveScoreDeadline(ctx, db, engines, id, now)
+		if errors.Is(err, database.ErrMatchWrongState) {
+			continue
+		}
+		if err != nil {
+			return resolved, fmt.Errorf("resolve score deadline of %s: %w", id, err)
+		}
+		resolved++
+		logTransitions([]models.DBMatches{match}, models.ManqueScore, match.CurrentState)
+	}
+	return resolved, nil
+}
+
+func logTransitions(matches []models.DBMatches, from, to models.MatchState) {
+	for _, m := range matches {
+		log.Info().
+			Str("match_id", m.Id).
+			Str("sport", string(m.Sport)).
+			Time("date", m.Date).
+			Str("from", string(from)).
+			Str("to", string(to)).
+			Msg("scheduler: transition de match")
+	}
+}
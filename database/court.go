@@ -6,15 +6,16 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"math"
 	"time"
 )
 
 func (db Database) InsertCourt(ctx context.Context, id string, p models.Place, createdTime time.Time) error {
 	_, err := db.Database.ExecContext(ctx, `
-		INSERT INTO courts (id, address, longitude, latitude, created_at, name)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO courts (id, address, city, longitude, latitude, created_at, name)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		ON CONFLICT DO NOTHING`,
-		id, p.Address, p.Geometry.Location.Lng, p.Geometry.Location.Lat, createdTime, p.Name,
+		id, p.Address, models.ParseCity(p.Address), p.Geometry.Location.Lng, p.Geometry.Location.Lat, createdTime, p.Name,
 	)
 	if err != nil {
 		return fmt.Errorf("échec de len'insertion du terrain : %w", err)
@@ -27,7 +28,7 @@ func (db Database) GetTerrainByAddress(ctx context.Context, address string) (*mo
 	var court models.DBCourt
 
 	err := db.Database.GetContext(ctx, &court, `
-		SELECT id, address, longitude, latitude, created_at, name
+		SELECT id, address, city, longitude, latitude, created_at, name
 		FROM courts
 		WHERE address = $1`, address)
 	if err != nil {
@@ -43,7 +44,7 @@ func (db Database) GetTerrainByAddress(ctx context.Context, address string) (*mo
 func (db Database) GetAllCourts(ctx context.Context) ([]models.DBCourt, error) {
 	var terrains []models.DBCourt
 	err := db.Database.SelectContext(ctx, &terrains, `
-		SELECT id, address, longitude, latitude, created_at, name
+		SELECT id, address, city, longitude, latitude, created_at, name
 		FROM courts`)
 	if err != nil {
 		return nil, fmt.Errorf("échec de la récupération des terrains : %w", err)
@@ -68,7 +69,7 @@ func (db Database) GetVisitedFieldCountByUserID(ctx context.Context, userID stri
 func (db Database) GetCourtByID(ctx context.Context, id string) (*models.DBCourt, error) {
 	var court models.DBCourt
 	err := db.Database.GetContext(ctx, &court, `
-		SELECT id, address, name, longitude, latitude, created_at
+		SELECT id, address, city, name, longitude, latitude, created_at
 		FROM courts
 		WHERE id = $1
 	`, id)
@@ -84,7 +85,7 @@ func (db Database) GetCourtByID(ctx context.Context, id string) (*models.DBCourt
 
 func (db Database) GetCourtsByIDs(ctx context.Context, ids []string) ([]models.DBCourt, error) {
 	query := `
-        SELECT id, address, longitude, latitude, created_at, name
+        SELECT id, address, city, longitude, latitude, created_at, name
         FROM courts
         WHERE id = ANY($1)
     `
@@ -94,20 +95,88 @@ func (db Database) GetCourtsByIDs(ctx context.Context, ids []string) ([]models.D
 }
 
 func (db Database) InsertCourtForTest(ctx context.Context, court models.DBCourt) error {
+	if court.City == nil {
+		court.City = models.ParseCity(court.Address)
+	}
 	_, err := db.Database.NamedExecContext(ctx, `
-		INSERT INTO courts (id, name, address, latitude, longitude, created_at)
-		VALUES (:id, :name, :address, :latitude, :longitude, :created_at)`, court)
+		INSERT INTO courts (id, name, address, city, latitude, longitude, created_at)
+		VALUES (:id, :name, :address, :city, :latitude, :longitude, :created_at)`, court)
 	return err
 }
 
 func (db Database) CreateCourt(ctx context.Context, court models.DBCourt) error {
+	if court.City == nil {
+		court.City = models.ParseCity(court.Address)
+	}
 	_, err := db.Database.ExecContext(ctx, `
-		INSERT INTO courts (id, name, address, longitude, latitude, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-	`, court.Id, court.Name, court.Address, court.Longitude, court.Latitude, court.CreatedAt)
+		INSERT INTO courts (id, name, address, city, longitude, latitude, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, court.Id, court.Name, court.Address, court.City, court.Longitude, court.Latitude, court.CreatedAt)
 
 	if err != nil {
 		return fmt.Errorf("échec de len'insertion court : %w", err)
 	}
 	return nil
 }
+
+const earthRadiusMeters = 6371000.0
+const metersPerDegreeLatitude = 111320.0
+
+// boundingBox returns the lat/lng rectangle enclosing a circle of radiusM meters,
+// used as an index-friendly prefilter before the exact haversine distance.
+func boundingBox(lat, lng, radiusM float64) (minLat, maxLat, minLng, maxLng float64) {
+	latDelta := radiusM / metersPerDegreeLatitude
+	minLat = math.Max(lat-latDelta, -90)
+	maxLat = math.Min(lat+latDelta, 90)
+
+	cosLat := math.Cos(lat * math.Pi / 180)
+	if cosLat < 1e-6 {
+		return minLat, maxLat, -180, 180
+	}
+	lngDelta := radiusM / (metersPerDegreeLatitude * cosLat)
+	minLng = lng - lngDelta
+	maxLng = lng + lngDelta
+	if minLng < -180 || maxLng > 180 {
+		return minLat, maxLat, -180, 180
+	}
+	return minLat, maxLat, minLng, maxLng
+}
+
+func (db Database) GetCourtsNearby(ctx context.Context, q models.NearbyCourtsQuery) ([]models.DBCourtWithDistance, error) {
+	minLat, maxLat, minLng, maxLng := boundingBox(q.Latitude, q.Longitude, q.RadiusMeters)
+
+	var sport interface{}
+	if q.Sport != nil {
+		sport = string(*q.Sport)
+	}
+
+	var courts []models.DBCourtWithDistance
+	err := db.Database.SelectContext(ctx, &courts, `
+		WITH candidates AS (
+			SELECT c.id, c.address, c.city, c.longitude, c.latitude, c.created_at, c.name,
+				2 * $1::double precision * ASIN(LEAST(1, SQRT(
+					POWER(SIN(RADIANS(c.latitude - $2::double precision) / 2), 2) +
+					COS(RADIANS($2::double precision)) * COS(RADIANS(c.latitude)) *
+					POWER(SIN(RADIANS(c.longitude - $3::double precision) / 2), 2)
+				))) AS distance_m
+			FROM courts c
+			WHERE c.latitude BETWEEN $4 AND $5
+			  AND c.longitude BETWEEN $6 AND $7
+			  AND ($8::sport IS NULL OR EXISTS (
+				SELECT 1
+				FROM matches m
+				WHERE m.court_id = c.id
+				  AND m.sport = $8::sport
+			  ))
+		)
+		SELECT id, address, city, longitude, latitude, created_at, name, distance_m
+		FROM candidates
+		WHERE distance_m <= $9
+		ORDER BY distance_m, id
+		LIMIT $10
+	`, earthRadiusMeters, q.Latitude, q.Longitude, minLat, maxLat, minLng, maxLng, sport, q.RadiusMeters, q.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch nearby courts: %w", err)
+	}
+	return courts, nil
+}
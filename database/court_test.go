@@ -12,8 +12,10 @@ import (
 
 func TestDatabase_InsertTerrain(t *testing.T) {
 	type testCase struct {
-		name  string
-		param string
+		name         string
+		param        string
+		address      string
+		expectedCity *string
 	}
 
 	testLat := 48.8566
@@ -22,8 +24,21 @@ func TestDatabase_InsertTerrain(t *testing.T) {
 	id := uuid.NewString()
 	testCases := []testCase{
 		{
-			name:  "Basic test",
-			param: id,
+			name:    "Basic test",
+			param:   id,
+			address: "123 Rue Test",
+		},
+		{
+			name:         "City parsed from the address",
+			param:        id,
+			address:      "12 Rue de Rivoli, 75004  Paris",
+			expectedCity: ptr("Paris"),
+		},
+		{
+			name:         "City without postal code",
+			param:        id,
+			address:      "Parc des Sports, Saint-Denis",
+			expectedCity: ptr("Saint-Denis"),
 		},
 	}
 
@@ -41,7 +56,7 @@ func TestDatabase_InsertTerrain(t *testing.T) {
 
 			err := s.db.InsertCourt(ctx, id, models.Place{
 				Name:    "Test Court",
-				Address: "123 Rue Test",
+				Address: c.address,
 				Geometry: struct {
 					Location struct {
 						Lat float64 `json:"lat"`
@@ -58,9 +73,10 @@ func TestDatabase_InsertTerrain(t *testing.T) {
 				},
 			}, time.Now())
 			require.NoError(t, err)
-			court, err := s.db.GetTerrainByAddress(ctx, "123 Rue Test")
+			court, err := s.db.GetTerrainByAddress(ctx, c.address)
 			require.NoError(t, err)
 			require.Equal(t, court.Id, c.param)
+			require.Equal(t, c.expectedCity, court.City)
 		})
 	}
 }
@@ -374,3 +390,112 @@ func TestDatabase_GetCourtsByIDs(t *testing.T) {
 		})
 	}
 }
+
+func TestDatabase_GetCourtsNearby(t *testing.T) {
+	type expected struct {
+		ids []string
+	}
+
+	type testCase struct {
+		name     string
+		fixtures DBFixtures
+		param    models.NearbyCourtsQuery
+		expected expected
+	}
+
+	creatorID := uuid.NewString()
+	center := models.NewDBCourtFixture().
+		WithName("Hôtel de Ville").
+		WithLatitude(48.8566).
+		WithLongitude(2.3522)
+	louvre := models.NewDBCourtFixture().
+		WithName("Louvre").
+		WithLatitude(48.8606).
+		WithLongitude(2.3376)
+	lyon := models.NewDBCourtFixture().
+		WithName("Lyon").
+		WithLatitude(45.7640).
+		WithLongitude(4.8357)
+
+	fixtures := DBFixtures{
+		Users:  []models.DBUsers{models.NewDBUsersFixture().WithId(creatorID)},
+		Courts: []models.DBCourt{center, louvre, lyon},
+		Matches: []models.DBMatches{
+			models.NewDBMatchesFixture().
+				WithCourtId(louvre.Id).
+				WithCreatorId(creatorID).
+				WithSport(models.Basket),
+		},
+	}
+
+	testCases := []testCase{
+		{
+			name:     "Courts within radius sorted by distance",
+			fixtures: fixtures,
+			param: models.NearbyCourtsQuery{
+				Latitude:     48.8570,
+				Longitude:    2.3510,
+				RadiusMeters: 5000,
+				Limit:        10,
+			},
+			expected: expected{ids: []string{center.Id, louvre.Id}},
+		},
+		{
+			name:     "Small radius keeps only the closest court",
+			fixtures: fixtures,
+			param: models.NearbyCourtsQuery{
+				Latitude:     48.8566,
+				Longitude:    2.3522,
+				RadiusMeters: 500,
+				Limit:        10,
+			},
+			expected: expected{ids: []string{center.Id}},
+		},
+		{
+			name:     "Sport filter keeps courts that hosted this sport",
+			fixtures: fixtures,
+			param: models.NearbyCourtsQuery{
+				Latitude:     48.8566,
+				Longitude:    2.3522,
+				RadiusMeters: 5000,
+				Sport:        ptr(models.Basket),
+				Limit:        10,
+			},
+			expected: expected{ids: []string{louvre.Id}},
+		},
+		{
+			name:     "Limit is applied",
+			fixtures: fixtures,
+			param: models.NearbyCourtsQuery{
+				Latitude:     48.8566,
+				Longitude:    2.3522,
+				RadiusMeters: 1000000,
+				Limit:        2,
+			},
+			expected: expected{ids: []string{center.Id, louvre.Id}},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() { _ = cleanup() }()
+			s.loadFixtures(c.fixtures)
+
+			got, err := s.db.GetCourtsNearby(context.Background(), c.param)
+			require.NoError(t, err)
+
+			ids := make([]string, 0, len(got))
+			for i, ct := range got {
+				ids = append(ids, ct.Id)
+				require.LessOrEqual(t, ct.DistanceMeters, c.param.RadiusMeters)
+				if i > 0 {
+					require.GreaterOrEqual(t, ct.DistanceMeters, got[i-1].DistanceMeters)
+				}
+			}
+			require.Equal(t, c.expected.ids, ids)
+		})
+	}
+}
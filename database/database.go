@@ -1,7 +1,30 @@
 package database
 
-import "github.com/jmoiron/sqlx"
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
 
 type Database struct {
 	Database *sqlx.DB
 }
+
+// inTx runs fn in a transaction, committed only when fn returns no error.
+func (db Database) inTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	tx, err := db.Database.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package database
+
+import "errors"
+
+// The sentinel errors of the package wrap one of these kinds, so that a caller can
+// tell a missing row or a conflicting write apart without knowing every sentinel.
+var (
+	ErrNotFound = errors.New("not found")
+	ErrConflict = errors.New("conflict")
+)
+
+// kindError is a sentinel error of a given kind, keeping its own message.
+type kindError struct {
+	msg  string
+	kind error
+}
+
+func (e *kindError) Error() string { return e.msg }
+func (e *kindError) Unwrap() error { return e.kind }
+
+func notFoundError(msg string) error { return &kindError{msg: msg, kind: ErrNotFound} }
+func conflictError(msg string) error { return &kindError{msg: msg, kind: ErrConflict} }
@@ -0,0 +1,233 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrFriendshipBlocked     = errors.New("one of the users blocked the other")
+	ErrAlreadyFriends        = conflictError("users are already friends")
+	ErrFriendRequestPending  = conflictError("friend request already sent")
+	ErrFriendRequestNotFound = notFoundError("friend request not found")
+)
+
+// lockFriendship serializes the transactions touching the relation between two users,
+// whatever the direction, and returns that relation, nil if there is none.
+func lockFriendship(ctx context.Context, tx *sqlx.Tx, userA, userB string) (*models.DBFriendship, error) {
+	if _, err := tx.ExecContext(ctx, `
+		SELECT pg_advisory_xact_lock(hashtext(LEAST($1::text, $2::text) || ':' || GREATEST($1::text, $2::text)))`,
+		userA, userB); err != nil {
+		return nil, fmt.Errorf("failed to lock friendship: %w", err)
+	}
+
+	return getFriendship(ctx, tx, userA, userB)
+}
+
+// RequestFriendship sends a friend request from requesterID to addresseeID. When
+// addresseeID already asked requesterID, both requests cross and the friendship is
+// accepted right away. A declined request can be sent again. It returns the status of
+// the relation once done.
+func (db Database) RequestFriendship(ctx context.Context, requesterID, addresseeID string, now time.Time) (models.FriendshipStatus, error) {
+	var status models.FriendshipStatus
+	err := db.inTx(ctx, func(tx *sqlx.Tx) error {
+		existing, err := lockFriendship(ctx, tx, requesterID, addresseeID)
+		if err != nil {
+			return err
+		}
+
+		if existing != nil {
+			switch existing.Status {
+			case models.FriendshipBlocked:
+				return ErrFriendshipBlocked
+			case models.FriendshipAccepted:
+				return ErrAlreadyFriends
+			case models.FriendshipPending:
+				if existing.RequesterID == requesterID {
+					return ErrFriendRequestPending
+				}
+				status = models.FriendshipAccepted
+				if _, err := tx.ExecContext(ctx, `
+					UPDATE friendships
+					SET status = 'accepted', updated_at = $3
+					WHERE requester_id = $1 AND addressee_id = $2`,
+					existing.RequesterID, existing.AddresseeID, now); err != nil {
+					return fmt.Errorf("failed to accept crossed friend request: %w", err)
+				}
+				return nil
+			}
+
+			if _, err := tx.ExecContext(ctx, `
+				DELETE FROM friendships
+				WHERE requester_id = $1 AND addressee_id = $2`,
+				existing.RequesterID, existing.AddresseeID); err != nil {
+				return fmt.Errorf("failed to delete declined friend request: %w", err)
+			}
+		}
+
+		status = models.FriendshipPending
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO friendships (requester_id, addressee_id, status, created_at, updated_at)
+			VALUES ($1, $2, 'pending', $3, $3)`,
+			requesterID, addresseeID, now); err != nil {
+			return fmt.Errorf("failed to insert friend request: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return status, nil
+}
+
+// RespondFriendRequest accepts or declines the pending request requesterID sent to
+// addresseeID.
+func (db Database) RespondFriendRequest(ctx context.Context, addresseeID, requesterID string, accept bool, now time.Time) error {
+	status := models.FriendshipDeclined
+	if accept {
+		status = models.FriendshipAccepted
+	}
+
+	res, err := db.Database.ExecContext(ctx, `
+		UPDATE friendships
+		SET status = $3, updated_at = $4
+		WHERE requester_id = $1 AND addressee_id = $2 AND status = 'pending'`,
+		requesterID, addresseeID, status, now)
+	if err != nil {
+		return fmt.Errorf("failed to answer friend request: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to answer friend request: %w", err)
+	}
+	if n == 0 {
+		return ErrFriendRequestNotFound
+	}
+	return nil
+}
+
+// BlockUser replaces the relation between the two users with a block from blockerID.
+// A block already set by blockedID is kept: either way, neither user can befriend the
+// other anymore.
+func (db Database) BlockUser(ctx context.Context, blockerID, blockedID string, now time.Time) error {
+	return db.inTx(ctx, func(tx *sqlx.Tx) error {
+		existing, err := lockFriendship(ctx, tx, blockerID, blockedID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			if existing.Status == models.FriendshipBlocked {
+				return nil
+			}
+			if _, err := tx.ExecContext(ctx, `
+				DELETE FROM friendships
+				WHERE requester_id = $1 AND addressee_id = $2`,
+				existing.RequesterID, existing.AddresseeID); err != nil {
+				return fmt.Errorf("failed to delete friendship: %w", err)
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO friendships (requester_id, addressee_id, status, created_at, updated_at)
+			VALUES ($1, $2, 'blocked', $3, $3)`,
+			blockerID, blockedID, now); err != nil {
+			return fmt.Errorf("failed to block user: %w", err)
+		}
+		return nil
+	})
+}
+
+func (db Database) InsertFriendship(ctx context.Context, f models.DBFriendship) error {
+	_, err := db.Database.NamedExecContext(ctx, `
+		INSERT INTO friendships (requester_id, addressee_id, status, created_at, updated_at)
+		VALUES (:requester_id, :addressee_id, :status, :created_at, :updated_at)`, f)
+	if err != nil {
+		return fmt.Errorf("failed to insert friendship: %w", err)
+	}
+	return nil
+}
+
+// GetFriendship returns the relation between two users, nil if there is none.
+func (db Database) GetFriendship(ctx context.Context, userA, userB string) (*models.DBFriendship, error) {
+	return getFriendship(ctx, db.Database, userA, userB)
+}
+
+func getFriendship(ctx context.Context, q sqlx.QueryerContext, userA, userB string) (*models.DBFriendship, error) {
+	var f models.DBFriendship
+	err := sqlx.GetContext(ctx, q, &f, `
+		SELECT requester_id, addressee_id, status, created_at, updated_at
+		FROM friendships
+		WHERE (requester_id = $1 AND addressee_id = $2)
+		   OR (requester_id = $2 AND addressee_id = $1)`, userA, userB)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch friendship: %w", err)
+	}
+	return &f, nil
+}
+
+// GetFriends returns the accepted friends of the user, sorted by username.
+func (db Database) GetFriends(ctx context.Context, userID string) ([]models.DBFriend, error) {
+	var friends []models.DBFriend
+	err := db.Database.SelectContext(ctx, &friends, `
+		SELECT u.id AS user_id, u.username, u.email, f.updated_at
+		FROM friendships f
+		JOIN users u ON u.id = CASE WHEN f.requester_id = $1 THEN f.addressee_id ELSE f.requester_id END
+		WHERE (f.requester_id = $1 OR f.addressee_id = $1)
+		  AND f.status = 'accepted'
+		ORDER BY u.username`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch friends: %w", err)
+	}
+	return friends, nil
+}
+
+// GetFriendsByIDs returns the users of userIDs who are accepted friends of userID.
+func (db Database) GetFriendsByIDs(ctx context.Context, userID string, userIDs []string) ([]models.DBFriend, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	var friends []models.DBFriend
+	err := db.Database.SelectContext(ctx, &friends, `
+		SELECT u.id AS user_id, u.username, u.email, f.updated_at
+		FROM friendships f
+		JOIN users u ON u.id = CASE WHEN f.requester_id = $1 THEN f.addressee_id ELSE f.requester_id END
+		WHERE (f.requester_id = $1 OR f.addressee_id = $1)
+		  AND f.status = 'accepted'
+		  AND u.id = ANY($2)
+		ORDER BY u.username`, userID, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch friends: %w", err)
+	}
+	return friends, nil
+}
+
+// GetPendingFriendRequests returns the pending requests the user received and sent,
+// oldest first.
+func (db Database) GetPendingFriendRequests(ctx context.Context, userID string) (incoming, outgoing []models.DBFriend, err error) {
+	if err := db.Database.SelectContext(ctx, &incoming, `
+		SELECT u.id AS user_id, u.username, u.email, f.updated_at
+		FROM friendships f
+		JOIN users u ON u.id = f.requester_id
+		WHERE f.addressee_id = $1 AND f.status = 'pending'
+		ORDER BY f.updated_at, u.id`, userID); err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch incoming friend requests: %w", err)
+	}
+	if err := db.Database.SelectContext(ctx, &outgoing, `
+		SELECT u.id AS user_id, u.username, u.email, f.updated_at
+		FROM friendships f
+		JOIN users u ON u.id = f.addressee_id
+		WHERE f.requester_id = $1 AND f.status = 'pending'
+		ORDER BY f.updated_at, u.id`, userID); err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch outgoing friend requests: %w", err)
+	}
+	return incoming, outgoing, nil
+}
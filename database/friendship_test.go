@@ -0,0 +1,211 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase_RequestFriendship(t *testing.T) {
+	type expected struct {
+		err       error
+		status    models.FriendshipStatus
+		requester string
+	}
+
+	type testCase struct {
+		name     string
+		existing []models.DBFriendship
+		expected expected
+	}
+
+	alice := models.NewDBUsersFixture().WithUsername("alice").WithEmail("alice@test.com")
+	bob := models.NewDBUsersFixture().WithUsername("bob").WithEmail("bob@test.com")
+
+	fromAlice := models.NewDBFriendshipFixture().WithRequesterId(alice.Id).WithAddresseeId(bob.Id)
+	fromBob := models.NewDBFriendshipFixture().WithRequesterId(bob.Id).WithAddresseeId(alice.Id)
+
+	testCases := []testCase{
+		{
+			name:     "No relation -> pending",
+			expected: expected{status: models.FriendshipPending, requester: alice.Id},
+		},
+		{
+			name:     "Already sent",
+			existing: []models.DBFriendship{fromAlice},
+			expected: expected{err: ErrFriendRequestPending, status: models.FriendshipPending, requester: alice.Id},
+		},
+		{
+			name:     "Crossed requests -> accepted",
+			existing: []models.DBFriendship{fromBob},
+			expected: expected{status: models.FriendshipAccepted, requester: bob.Id},
+		},
+		{
+			name:     "Declined request is sent again",
+			existing: []models.DBFriendship{fromBob.WithStatus(models.FriendshipDeclined)},
+			expected: expected{status: models.FriendshipPending, requester: alice.Id},
+		},
+		{
+			name:     "Already friends",
+			existing: []models.DBFriendship{fromBob.WithStatus(models.FriendshipAccepted)},
+			expected: expected{err: ErrAlreadyFriends, status: models.FriendshipAccepted, requester: bob.Id},
+		},
+		{
+			name:     "Blocked by the addressee",
+			existing: []models.DBFriendship{fromBob.WithStatus(models.FriendshipBlocked)},
+			expected: expected{err: ErrFriendshipBlocked, status: models.FriendshipBlocked, requester: bob.Id},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() {
+				if err := cleanup(); err != nil {
+					t.Logf("cleanup error: %v", err)
+				}
+			}()
+			s.loadFixtures(DBFixtures{
+				Users:       []models.DBUsers{alice, bob},
+				Friendships: c.existing,
+			})
+
+			ctx := context.Background()
+			status, err := s.db.RequestFriendship(ctx, alice.Id, bob.Id, time.Now())
+			if c.expected.err != nil {
+				require.ErrorIs(t, err, c.expected.err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, c.expected.status, status)
+			}
+
+			stored, err := s.db.GetFriendship(ctx, bob.Id, alice.Id)
+			require.NoError(t, err)
+			require.NotNil(t, stored)
+			require.Equal(t, c.expected.status, stored.Status)
+			require.Equal(t, c.expected.requester, stored.RequesterID)
+		})
+	}
+}
+
+func TestDatabase_RespondFriendRequest(t *testing.T) {
+	alice := models.NewDBUsersFixture().WithUsername("alice").WithEmail("alice@test.com")
+	bob := models.NewDBUsersFixture().WithUsername("bob").WithEmail("bob@test.com")
+
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() {
+		if err := cleanup(); err != nil {
+			t.Logf("cleanup error: %v", err)
+		}
+	}()
+	s.loadFixtures(DBFixtures{
+		Users: []models.DBUsers{alice, bob},
+		Friendships: []models.DBFriendship{
+			models.NewDBFriendshipFixture().WithRequesterId(alice.Id).WithAddresseeId(bob.Id),
+		},
+	})
+
+	ctx := context.Background()
+
+	// Only the addressee can answer
+	err := s.db.RespondFriendRequest(ctx, alice.Id, bob.Id, true, time.Now())
+	require.ErrorIs(t, err, ErrFriendRequestNotFound)
+
+	require.NoError(t, s.db.RespondFriendRequest(ctx, bob.Id, alice.Id, true, time.Now()))
+
+	friends, err := s.db.GetFriends(ctx, alice.Id)
+	require.NoError(t, err)
+	require.Len(t, friends, 1)
+	require.Equal(t, bob.Id, friends[0].UserID)
+
+	friends, err = s.db.GetFriends(ctx, bob.Id)
+	require.NoError(t, err)
+	require.Len(t, friends, 1)
+	require.Equal(t, alice.Id, friends[0].UserID)
+
+	// The request is no longer pending
+	err = s.db.RespondFriendRequest(ctx, bob.Id, alice.Id, false, time.Now())
+	require.ErrorIs(t, err, ErrFriendRequestNotFound)
+}
+
+func TestDatabase_BlockUser(t *testing.T) {
+	alice := models.NewDBUsersFixture().WithUsername("alice").WithEmail("alice@test.com")
+	bob := models.NewDBUsersFixture().WithUsername("bob").WithEmail("bob@test.com")
+
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() {
+		if err := cleanup(); err != nil {
+			t.Logf("cleanup error: %v", err)
+		}
+	}()
+	s.loadFixtures(DBFixtures{
+		Users: []models.DBUsers{alice, bob},
+		Friendships: []models.DBFriendship{
+			models.NewDBFriendshipFixture().
+				WithRequesterId(alice.Id).
+				WithAddresseeId(bob.Id).
+				WithStatus(models.FriendshipAccepted),
+		},
+	})
+
+	ctx := context.Background()
+	require.NoError(t, s.db.BlockUser(ctx, bob.Id, alice.Id, time.Now()))
+
+	friends, err := s.db.GetFriends(ctx, alice.Id)
+	require.NoError(t, err)
+	require.Empty(t, friends)
+
+	stored, err := s.db.GetFriendship(ctx, alice.Id, bob.Id)
+	require.NoError(t, err)
+	require.Equal(t, models.FriendshipBlocked, stored.Status)
+	require.Equal(t, bob.Id, stored.RequesterID)
+
+	// The block set by bob stays when alice blocks him too
+	require.NoError(t, s.db.BlockUser(ctx, alice.Id, bob.Id, time.Now()))
+	stored, err = s.db.GetFriendship(ctx, alice.Id, bob.Id)
+	require.NoError(t, err)
+	require.Equal(t, bob.Id, stored.RequesterID)
+
+	_, err = s.db.RequestFriendship(ctx, alice.Id, bob.Id, time.Now())
+	require.ErrorIs(t, err, ErrFriendshipBlocked)
+}
+
+func TestDatabase_GetPendingFriendRequests(t *testing.T) {
+	me := models.NewDBUsersFixture().WithUsername("me").WithEmail("me@test.com")
+	sender := models.NewDBUsersFixture().WithUsername("sender").WithEmail("sender@test.com")
+	receiver := models.NewDBUsersFixture().WithUsername("receiver").WithEmail("receiver@test.com")
+	friend := models.NewDBUsersFixture().WithUsername("friend").WithEmail("friend@test.com")
+
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() {
+		if err := cleanup(); err != nil {
+			t.Logf("cleanup error: %v", err)
+		}
+	}()
+	s.loadFixtures(DBFixtures{
+		Users: []models.DBUsers{me, sender, receiver, friend},
+		Friendships: []models.DBFriendship{
+			models.NewDBFriendshipFixture().WithRequesterId(sender.Id).WithAddresseeId(me.Id),
+			models.NewDBFriendshipFixture().WithRequesterId(me.Id).WithAddresseeId(receiver.Id),
+			models.NewDBFriendshipFixture().
+				WithRequesterId(friend.Id).
+				WithAddresseeId(me.Id).
+				WithStatus(models.FriendshipAccepted),
+		},
+	})
+
+	incoming, outgoing, err := s.db.GetPendingFriendRequests(context.Background(), me.Id)
+	require.NoError(t, err)
+	require.Len(t, incoming, 1)
+	require.Equal(t, sender.Id, incoming[0].UserID)
+	require.Len(t, outgoing, 1)
+	require.Equal(t, receiver.Id, outgoing[0].UserID)
+}
@@ -23,11 +23,20 @@ type Service struct {
 }
 
 type DBFixtures struct {
-	Users       []models.DBUsers
-	Courts      []models.DBCourt
-	Matches     []models.DBMatches
-	UserMatches []models.DBUserMatch
-	Rankings    []models.DBRanking
+	Users        []models.DBUsers
+	Courts       []models.DBCourt
+	Matches      []models.DBMatches
+	UserMatches  []models.DBUserMatch
+	Rankings     []models.DBRanking
+	Sessions     []models.DBSession
+	Friendships  []models.DBFriendship
+	QueueEntries []models.DBQueueEntry
+	OutboxEvents []models.DBOutboxEvent
+	Devices      []models.DBDevice
+
+	NotificationPreferences []models.DBNotificationPreferences
+
+	PasswordResetTokens []models.DBPasswordResetToken
 }
 
 func findLatestMigrationFile(dir string) (string, error) {
@@ -195,4 +204,46 @@ func (s *Service) loadFixtures(fixtures DBFixtures) {
 			panic(fmt.Sprintf("failed to insert ranking: %v", err))
 		}
 	}
+
+	for _, f := range fixtures.Friendships {
+		if err := s.db.InsertFriendship(ctx, f); err != nil {
+			panic(fmt.Sprintf("failed to insert friendship: %v", err))
+		}
+	}
+
+	for _, e := range fixtures.QueueEntries {
+		if err := s.db.JoinQueue(ctx, e); err != nil {
+			panic(fmt.Sprintf("failed to insert queue entry: %v", err))
+		}
+	}
+
+	for _, e := range fixtures.OutboxEvents {
+		if err := s.db.InsertOutboxEvent(ctx, e); err != nil {
+			panic(fmt.Sprintf("failed to insert outbox event: %v", err))
+		}
+	}
+
+	for _, d := range fixtures.Devices {
+		if err := s.db.RegisterDevice(ctx, d); err != nil {
+			panic(fmt.Sprintf("failed to insert device: %v", err))
+		}
+	}
+
+	for _, p := range fixtures.NotificationPreferences {
+		if err := s.db.UpsertNotificationPreferences(ctx, p); err != nil {
+			panic(fmt.Sprintf("failed to insert notification preferences: %v", err))
+		}
+	}
+
+	for _, session := range fixtures.Sessions {
+		if err := s.db.CreateSession(ctx, session); err != nil {
+			panic(fmt.Sprintf("failed to insert session: %v", err))
+		}
+	}
+
+	for _, token := range fixtures.PasswordResetTokens {
+		if err := s.db.CreatePasswordResetToken(ctx, token); err != nil {
+			panic(fmt.Sprintf("failed to insert password reset token: %v", err))
+		}
+	}
 }
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// leaderboardCTE ranks the players of a sport by their aggregated rating: the average
+// of their court ratings weighted by the games played on each court. Only courts of
+// city $2 count when it is not NULL. Players who never played are left out.
+const leaderboardCTE = `
+	WITH ratings AS (
+		SELECT r.user_id,
+		       ROUND(SUM(r.elo * r.games_played)::numeric / SUM(r.games_played))::int AS rating,
+		       SUM(r.games_played)::int AS games_played
+		FROM ranking r
+		JOIN courts c ON c.id = r.court_id
+		WHERE r.sport = $1::sport
+		  AND r.games_played > 0
+		  AND ($2::text IS NULL OR LOWER(c.city) = LOWER($2::text))
+		GROUP BY r.user_id
+	),
+	ranked AS (
+		SELECT RANK() OVER (ORDER BY ra.rating DESC) AS rank,
+		       ra.user_id, u.username, ra.rating, ra.games_played
+		FROM ratings ra
+		JOIN users u ON u.id = ra.user_id
+	)`
+
+// GetLeaderboard returns one page of the leaderboard and the number of ranked players.
+func (db Database) GetLeaderboard(ctx context.Context, q models.LeaderboardQuery) ([]models.DBLeaderboardEntry, int, error) {
+	city := nullableCity(q.City)
+
+	var entries []models.DBLeaderboardEntry
+	if err := db.Database.SelectContext(ctx, &entries, leaderboardCTE+`
+		SELECT rank, user_id, username, rating, games_played
+		FROM ranked
+		ORDER BY rank, user_id
+		LIMIT $3 OFFSET $4`,
+		string(q.Sport), city, q.Limit, q.Offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch leaderboard: %w", err)
+	}
+
+	var total int
+	if err := db.Database.GetContext(ctx, &total, leaderboardCTE+`
+		SELECT COUNT(*) FROM ranked`,
+		string(q.Sport), city); err != nil {
+		return nil, 0, fmt.Errorf("failed to count leaderboard: %w", err)
+	}
+	return entries, total, nil
+}
+
+// GetLeaderboardEntry returns the leaderboard entry of a user, nil if they are not ranked.
+func (db Database) GetLeaderboardEntry(ctx context.Context, sport models.Sport, city *string, userID string) (*models.DBLeaderboardEntry, error) {
+	var entry models.DBLeaderboardEntry
+	err := db.Database.GetContext(ctx, &entry, leaderboardCTE+`
+		SELECT rank, user_id, username, rating, games_played
+		FROM ranked
+		WHERE user_id = $3`,
+		string(sport), nullableCity(city), userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch leaderboard entry: %w", err)
+	}
+	return &entry, nil
+}
+
+func nullableCity(city *string) interface{} {
+	if city == nil {
+		return nil
+	}
+	return *city
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase_GetLeaderboard(t *testing.T) {
+	type expected struct {
+		users []string
+		ranks []int
+		total int
+	}
+
+	type testCase struct {
+		name     string
+		query    models.LeaderboardQuery
+		expected expected
+	}
+
+	paris1 := models.NewDBCourtFixture().WithAddress("1 rue A, 75001 Paris")
+	paris2 := models.NewDBCourtFixture().WithAddress("2 rue B, Paris")
+	lyon := models.NewDBCourtFixture().WithAddress("3 rue C, 69001 Lyon")
+
+	a := models.NewDBUsersFixture().WithUsername("a").WithEmail("a@test.com")
+	b := models.NewDBUsersFixture().WithUsername("b").WithEmail("b@test.com")
+	c := models.NewDBUsersFixture().WithUsername("c").WithEmail("c@test.com")
+	d := models.NewDBUsersFixture().WithUsername("d").WithEmail("d@test.com")
+
+	basket := func(user models.DBUsers, court models.DBCourt, elo, games int) models.DBRanking {
+		return models.NewDBRankingFixture().
+			WithUserId(user.Id).
+			WithCourtId(court.Id).
+			WithSport(models.Basket).
+			WithElo(elo).
+			WithGamesPlayed(games)
+	}
+
+	fixtures := DBFixtures{
+		Courts: []models.DBCourt{paris1, paris2, lyon},
+		Users:  []models.DBUsers{a, b, c, d},
+		Rankings: []models.DBRanking{
+			basket(a, paris1, 1200, 3),
+			basket(a, lyon, 900, 1),
+			basket(b, paris2, 1100, 2),
+			basket(c, lyon, 1300, 5),
+			basket(d, paris1, 1500, 0),
+			basket(a, paris2, 2000, 1).WithSport(models.Foot),
+		},
+	}
+
+	paris := "paris"
+	testCases := []testCase{
+		{
+			name:     "Global, weighted by games played",
+			query:    models.LeaderboardQuery{Sport: models.Basket, Limit: 10},
+			expected: expected{users: []string{c.Id, a.Id, b.Id}, ranks: []int{1, 2, 3}, total: 3},
+		},
+		{
+			name:     "City, case insensitive",
+			query:    models.LeaderboardQuery{Sport: models.Basket, City: &paris, Limit: 10},
+			expected: expected{users: []string{a.Id, b.Id}, ranks: []int{1, 2}, total: 2},
+		},
+		{
+			name:     "Paginated",
+			query:    models.LeaderboardQuery{Sport: models.Basket, Limit: 1, Offset: 1},
+			expected: expected{users: []string{a.Id}, ranks: []int{2}, total: 3},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() { _ = cleanup() }()
+			s.loadFixtures(fixtures)
+
+			entries, total, err := s.db.GetLeaderboard(context.Background(), tc.query)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected.total, total)
+
+			users := make([]string, 0, len(entries))
+			ranks := make([]int, 0, len(entries))
+			for _, e := range entries {
+				users = append(users, e.UserID)
+				ranks = append(ranks, e.Rank)
+			}
+			require.Equal(t, tc.expected.users, users)
+			require.Equal(t, tc.expected.ranks, ranks)
+		})
+	}
+
+	t.Run("Entry of a user", func(t *testing.T) {
+		t.Parallel()
+		s := &Service{}
+		cleanup := s.InitServiceTest()
+		defer func() { _ = cleanup() }()
+		s.loadFixtures(fixtures)
+		ctx := context.Background()
+
+		entry, err := s.db.GetLeaderboardEntry(ctx, models.Basket, &paris, a.Id)
+		require.NoError(t, err)
+		require.NotNil(t, entry)
+		require.Equal(t, 1, entry.Rank)
+		require.Equal(t, 1200, entry.Rating)
+		require.Equal(t, 3, entry.GamesPlayed)
+
+		entry, err = s.db.GetLeaderboardEntry(ctx, models.Basket, nil, d.Id)
+		require.NoError(t, err)
+		require.Nil(t, entry)
+	})
+}
@@ -6,14 +6,18 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/jmoiron/sqlx"
 )
 
 func (db Database) GetMatchById(ctx context.Context, id string) (*models.DBMatches, error) {
 	var match models.DBMatches
 
 	err := db.Database.GetContext(ctx, &match, `
-        SELECT id, sport, date, participant_nber, current_state, score1, score2, creator_id, court_id, created_at, updated_at
+        SELECT id, sport, date, participant_nber, current_state, score1, score2, creator_id, court_id, disputed, score_deadline, elo_applied_at, visibility, auto_balance, created_at, updated_at
         FROM matches
         WHERE id = $1`, id)
 
@@ -30,7 +34,7 @@ func (db Database) GetMatchById(ctx context.Context, id string) (*models.DBMatch
 func (db Database) GetUsersByMatchId(ctx context.Context, matchId string) ([]models.DBUsers, error) {
 	var users []models.DBUsers
 	err := db.Database.SelectContext(ctx, &users, `
-        SELECT u.id, u.username, u.email, u.bio, u.current_field_id, u.password, u.created_at, u.updated_at
+        SELECT u.id, u.username, u.email, u.bio, u.current_field_id, u.password, u.locale, u.created_at, u.updated_at
         FROM user_match um
         JOIN users u ON um.user_id = u.id
         WHERE um.match_id = $1`, matchId)
@@ -44,7 +48,7 @@ func (db Database) GetUsersByMatchId(ctx context.Context, matchId string) ([]mod
 func (db Database) GetMatchesByUserID(ctx context.Context, userID string) ([]models.DBMatches, error) {
 	var dbMatches []models.DBMatches
 	err := db.Database.SelectContext(ctx, &dbMatches, `
-		SELECT m.id, m.sport, m.date, m.participant_nber, m.current_state, m.score1, m.score2, m.court_id, m.creator_id, m.created_at, m.updated_at
+		SELECT m.id, m.sport, m.date, m.participant_nber, m.current_state, m.score1, m.score2, m.court_id, m.creator_id, m.visibility, m.auto_balance, m.created_at, m.updated_at
 		FROM matches m
 		JOIN user_match um ON m.id = um.match_id
 		WHERE um.user_id = $1
@@ -56,14 +60,17 @@ func (db Database) GetMatchesByUserID(ctx context.Context, userID string) ([]mod
 	return dbMatches, nil
 }
 
-func (db Database) GetMatchesByCourtId(ctx context.Context, courtID string) ([]models.DBMatches, error) {
+// GetMatchesByCourtId returns the matches of a court visible to the viewer, see
+// matchVisibleTo.
+func (db Database) GetMatchesByCourtId(ctx context.Context, courtID, viewerID string) ([]models.DBMatches, error) {
 	var dbMatches []models.DBMatches
 	err := db.Database.SelectContext(ctx, &dbMatches, `
-        SELECT id, sport, date, participant_nber, current_state, score1, score2, court_id, creator_id, created_at, updated_at
-        FROM matches
-        WHERE court_id = $1
-        ORDER BY date DESC
-    `, courtID)
+        SELECT m.id, m.sport, m.date, m.participant_nber, m.current_state, m.score1, m.score2, m.court_id, m.creator_id, m.visibility, m.auto_balance, m.created_at, m.updated_at
+        FROM matches m
+        WHERE m.court_id = $1
+          AND `+matchVisibleTo("$2")+`
+        ORDER BY m.date DESC
+    `, courtID, viewerID)
 	if err != nil {
 		msg := fmt.Errorf("error querying matches for court %s: %w", courtID, err)
 		return nil, msg
@@ -87,11 +94,13 @@ func (db Database) GetMatchCountByUserID(ctx context.Context, userID string) (in
 	return count, nil
 }
 
-func (db Database) GetAllMatches(ctx context.Context) ([]models.DBMatches, error) {
+// GetAllMatches returns the matches visible to viewerID, see matchVisibleTo.
+func (db Database) GetAllMatches(ctx context.Context, viewerID string) ([]models.DBMatches, error) {
 	var matches []models.DBMatches
 	err := db.Database.SelectContext(ctx, &matches, `
-        SELECT id, sport, date, participant_nber, current_state, score1, score2, court_id, creator_id, created_at, updated_at
-        FROM matches`)
+        SELECT m.id, m.sport, m.date, m.participant_nber, m.current_state, m.score1, m.score2, m.court_id, m.creator_id, m.visibility, m.auto_balance, m.created_at, m.updated_at
+        FROM matches m
+        WHERE `+matchVisibleTo("$1"), viewerID)
 	if err != nil {
 		return nil, fmt.Errorf("échec de la récupération des matchs : %w", err)
 	}
@@ -99,11 +108,18 @@ func (db Database) GetAllMatches(ctx context.Context) ([]models.DBMatches, error
 }
 
 func (db Database) CreateMatch(ctx context.Context, match models.DBMatches) error {
-	_, err := db.Database.NamedExecContext(ctx, `
+	return createMatch(ctx, db.Database, match)
+}
+
+func createMatch(ctx context.Context, ext sqlx.ExtContext, match models.DBMatches) error {
+	if match.Visibility == "" {
+		match.Visibility = models.VisibilityPublic
+	}
+	_, err := sqlx.NamedExecContext(ctx, ext, `
     INSERT INTO matches (
-        id, sport, date, participant_nber, current_state, score1, score2, court_id, creator_id, created_at, updated_at
+        id, sport, date, participant_nber, current_state, score1, score2, court_id, creator_id, disputed, score_deadline, elo_applied_at, visibility, auto_balance, created_at, updated_at
     ) VALUES (
-        :id, :sport, :date, :participant_nber, :current_state, :score1, :score2, :court_id, :creator_id, :created_at, :updated_at
+        :id, :sport, :date, :participant_nber, :current_state, :score1, :score2, :court_id, :creator_id, :disputed, :score_deadline, :elo_applied_at, :visibility, :auto_balance, :created_at, :updated_at
     )`, match)
 
 	if err != nil {
@@ -143,16 +159,23 @@ func (db Database) DeleteMatch(ctx context.Context, matchID string) error {
 }
 
 func (db Database) CreateUserMatch(ctx context.Context, um models.DBUserMatch) error {
-	_, err := db.Database.ExecContext(ctx,
+	return createUserMatch(ctx, db.Database, um)
+}
+
+func createUserMatch(ctx context.Context, ext sqlx.ExecerContext, um models.DBUserMatch) error {
+	_, err := ext.ExecContext(ctx,
 		`INSERT INTO user_match (user_id, match_id, team, created_at) VALUES ($1, $2, $3, $4)`,
 		um.UserID, um.MatchID, um.Team, um.CreatedAt)
 	return err
 }
 
 func (db Database) UpsertMatch(ctx context.Context, match models.DBMatches, now time.Time) error {
+	if match.Visibility == "" {
+		match.Visibility = models.VisibilityPublic
+	}
 	_, err := db.Database.ExecContext(ctx, `
-		INSERT INTO matches (id, sport, date, participant_nber, current_state, score1, score2, court_id, creator_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		INSERT INTO matches (id, sport, date, participant_nber, current_state, score1, score2, court_id, creator_id, created_at, updated_at, disputed, score_deadline, visibility, auto_balance)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 		ON CONFLICT (id) DO UPDATE SET
 			sport = EXCLUDED.sport,
 			date = EXCLUDED.date,
@@ -162,8 +185,12 @@ func (db Database) UpsertMatch(ctx context.Context, match models.DBMatches, now
 			score2 = EXCLUDED.score2,
 			court_id = EXCLUDED.court_id,
 			creator_id = EXCLUDED.creator_id,
+			disputed = EXCLUDED.disputed,
+			score_deadline = EXCLUDED.score_deadline,
+			visibility = EXCLUDED.visibility,
+			auto_balance = EXCLUDED.auto_balance,
 			updated_at = $11
-	`, match.Id, match.Sport, match.Date, match.ParticipantNber, match.CurrentState, match.Score1, match.Score2, match.CourtID, match.CreatorID, match.CreatedAt, now)
+	`, match.Id, match.Sport, match.Date, match.ParticipantNber, match.CurrentState, match.Score1, match.Score2, match.CourtID, match.CreatorID, match.CreatedAt, now, match.Disputed, match.ScoreDeadline, match.Visibility, match.AutoBalance)
 
 	return err
 }
@@ -270,7 +297,7 @@ func (db Database) GetUsersByMatchIDs(ctx context.Context, matchIDs []string) (m
 	query := `
         SELECT mu.match_id,
                u.id, u.username, u.email, u.bio, u.current_field_id, 
-               u.password, u.created_at, u.updated_at
+               u.password, u.locale, u.created_at, u.updated_at
         FROM user_match mu
         JOIN users u ON u.id = mu.user_id
         WHERE mu.match_id = ANY($1)
@@ -323,3 +350,143 @@ func (db Database) GetUsersWithTeamByMatchID(
 
 	return rows, nil
 }
+
+// matchSearchBuilder accumulates the positional arguments and the conditions of the
+// match search query.
+type matchSearchBuilder struct {
+	args  []interface{}
+	inner []string
+	outer []string
+}
+
+// arg registers a query argument and returns its placeholder.
+func (b *matchSearchBuilder) arg(v interface{}) string {
+	b.args = append(b.args, v)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+func sqlWhere(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conds, "\n\t\t  AND ")
+}
+
+// buildMatchSearchQuery returns the SQL and arguments of SearchMatches. Filters on
+// matches and courts are applied in the candidates CTE; filters on computed columns
+// (distance, free seats, Elo) and the cursor on its result. It fetches one match more
+// than the limit to know whether there is a next page.
+func buildMatchSearchQuery(q models.MatchSearchQuery) (string, []interface{}) {
+	b := &matchSearchBuilder{}
+
+	viewer := b.arg(q.ViewerID)
+	defaultElo := b.arg(q.DefaultElo)
+	b.inner = append(b.inner, matchVisibleTo(viewer))
+
+	if q.Sport != nil {
+		b.inner = append(b.inner, "m.sport = "+b.arg(string(*q.Sport))+"::sport")
+	}
+	if len(q.States) > 0 {
+		states := make([]string, len(q.States))
+		for i, st := range q.States {
+			states[i] = string(st)
+		}
+		b.inner = append(b.inner, "m.current_state = ANY("+b.arg(states)+"::etat_match[])")
+	}
+	if q.From != nil {
+		b.inner = append(b.inner, "m.date >= "+b.arg(*q.From))
+	}
+	if q.To != nil {
+		b.inner = append(b.inner, "m.date < "+b.arg(*q.To))
+	}
+	if q.CourtID != nil {
+		b.inner = append(b.inner, "m.court_id = "+b.arg(*q.CourtID))
+	}
+
+	distance := "NULL::double precision"
+	if q.Near != nil {
+		minLat, maxLat, minLng, maxLng := boundingBox(q.Near.Latitude, q.Near.Longitude, q.Near.RadiusMeters)
+		lat, lng := b.arg(q.Near.Latitude), b.arg(q.Near.Longitude)
+		distance = `2 * ` + b.arg(earthRadiusMeters) + `::double precision * ASIN(LEAST(1, SQRT(
+					POWER(SIN(RADIANS(c.latitude - ` + lat + `::double precision) / 2), 2) +
+					COS(RADIANS(` + lat + `::double precision)) * COS(RADIANS(c.latitude)) *
+					POWER(SIN(RADIANS(c.longitude - ` + lng + `::double precision) / 2), 2)
+				)))`
+		b.inner = append(b.inner,
+			"c.latitude BETWEEN "+b.arg(minLat)+" AND "+b.arg(maxLat),
+			"c.longitude BETWEEN "+b.arg(minLng)+" AND "+b.arg(maxLng))
+		b.outer = append(b.outer, "distance_m <= "+b.arg(q.Near.RadiusMeters))
+	}
+
+	if q.MinPlayersNeeded != nil {
+		b.outer = append(b.outer, "participant_nber - players_count >= "+b.arg(*q.MinPlayersNeeded))
+	}
+	if q.EloRange != nil {
+		b.outer = append(b.outer, "ABS(COALESCE(average_elo, viewer_elo) - viewer_elo) <= "+b.arg(*q.EloRange))
+	}
+
+	orderBy := "date, id"
+	if q.Sort == models.MatchSortDistance {
+		orderBy = "distance_m, id"
+	}
+	if c := q.Cursor; c != nil {
+		if c.Sort == models.MatchSortDistance {
+			b.outer = append(b.outer, "(distance_m, id) > ("+b.arg(c.DistanceMeters)+"::double precision, "+b.arg(c.Id)+")")
+		} else {
+			b.outer = append(b.outer, "(date, id) > ("+b.arg(c.Date)+"::timestamptz, "+b.arg(c.Id)+")")
+		}
+	}
+
+	query := `
+		WITH candidates AS (
+			SELECT m.id, m.sport, m.date, m.participant_nber, m.current_state, m.score1, m.score2,
+				m.court_id, m.creator_id, m.visibility, m.created_at, m.updated_at,
+				c.name AS court_name, c.city AS court_city,
+				(SELECT COUNT(*) FROM user_match um WHERE um.match_id = m.id)::int AS players_count,
+				(SELECT ROUND(AVG(COALESCE(r.elo, ` + defaultElo + `)))::int
+				 FROM user_match um
+				 LEFT JOIN ranking r ON r.user_id = um.user_id AND r.court_id = m.court_id AND r.sport = m.sport
+				 WHERE um.match_id = m.id) AS average_elo,
+				COALESCE((SELECT r.elo FROM ranking r
+				          WHERE r.user_id = ` + viewer + ` AND r.court_id = m.court_id AND r.sport = m.sport), ` + defaultElo + `) AS viewer_elo,
+				` + distance + ` AS distance_m
+			FROM matches m
+			JOIN courts c ON c.id = m.court_id
+			` + sqlWhere(b.inner) + `
+		)
+		SELECT id, sport, date, participant_nber, current_state, score1, score2, court_id, creator_id,
+			visibility, created_at, updated_at, court_name, court_city, players_count, average_elo, distance_m
+		FROM candidates
+		` + sqlWhere(b.outer) + `
+		ORDER BY ` + orderBy + `
+		LIMIT ` + b.arg(q.Limit+1)
+
+	return query, b.args
+}
+
+// SearchMatches returns one page of the matches matching q, visible to q.ViewerID,
+// and the cursor of the next page, nil on the last page.
+func (db Database) SearchMatches(ctx context.Context, q models.MatchSearchQuery) ([]models.DBMatchSearchResult, *models.MatchSearchCursor, error) {
+	query, args := buildMatchSearchQuery(q)
+
+	var results []models.DBMatchSearchResult
+	if err := db.Database.SelectContext(ctx, &results, query, args...); err != nil {
+		return nil, nil, fmt.Errorf("failed to search matches: %w", err)
+	}
+	if len(results) <= q.Limit {
+		return results, nil, nil
+	}
+
+	results = results[:q.Limit]
+	last := results[len(results)-1]
+	next := &models.MatchSearchCursor{Sort: q.Sort, Id: last.Id}
+	if q.Sort == models.MatchSortDistance {
+		if last.DistanceMeters != nil {
+			next.DistanceMeters = *last.DistanceMeters
+		}
+	} else {
+		next.Sort = models.MatchSortDate
+		next.Date = last.Date
+	}
+	return results, next, nil
+}
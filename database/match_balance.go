@@ -0,0 +1,88 @@
+package database
+
+import (
+	"PLIC/matchmaking"
+	"PLIC/models"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// balanceTeamsTx splits the players of a match locked by tx into two teams whose
+// average Elo on the match's court are as close as possible. Players without a
+// ranking there get their starting one from newRanking first.
+func balanceTeamsTx(ctx context.Context, tx *sqlx.Tx, match models.DBMatches, newRanking NewRankingFunc, now time.Time) error {
+	var userIDs []string
+	if err := tx.SelectContext(ctx, &userIDs, `
+		SELECT user_id
+		FROM user_match
+		WHERE match_id = $1
+		ORDER BY created_at, user_id`, match.Id); err != nil {
+		return fmt.Errorf("failed to fetch match players: %w", err)
+	}
+	if len(userIDs)%2 != 0 {
+		return fmt.Errorf("cannot balance %d players into two teams", len(userIDs))
+	}
+
+	for _, userID := range userIDs {
+		if err := insertRankingIfMissing(ctx, tx, newRanking(userID, match.CourtID, match.Sport, now)); err != nil {
+			return err
+		}
+	}
+
+	var rankings []struct {
+		UserID string `db:"user_id"`
+		Elo    int    `db:"elo"`
+	}
+	if err := tx.SelectContext(ctx, &rankings, `
+		SELECT user_id, elo
+		FROM ranking
+		WHERE court_id = $1 AND sport = $2 AND user_id = ANY($3)`,
+		match.CourtID, match.Sport, userIDs); err != nil {
+		return fmt.Errorf("failed to fetch players rankings: %w", err)
+	}
+	eloByUser := make(map[string]int, len(rankings))
+	for _, rk := range rankings {
+		eloByUser[rk.UserID] = rk.Elo
+	}
+
+	elos := make([]int, len(userIDs))
+	for i, userID := range userIDs {
+		elos[i] = eloByUser[userID]
+	}
+	team1, team2 := matchmaking.BalanceTeams(elos)
+
+	for team, members := range [][]int{team1, team2} {
+		ids := make([]string, len(members))
+		for i, m := range members {
+			ids[i] = userIDs[m]
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE user_match
+			SET team = $3
+			WHERE match_id = $1 AND user_id = ANY($2)`, match.Id, ids, team+1); err != nil {
+			return fmt.Errorf("failed to assign team %d: %w", team+1, err)
+		}
+	}
+	return nil
+}
+
+// RebalanceMatch lets the creator of a full match split its players again into the
+// most balanced teams, before it starts.
+func (db Database) RebalanceMatch(ctx context.Context, matchID, requestedBy string, newRanking NewRankingFunc, now time.Time) error {
+	return db.inTx(ctx, func(tx *sqlx.Tx) error {
+		match, err := lockMatch(ctx, tx, matchID)
+		if err != nil {
+			return err
+		}
+		if match.CreatorID != requestedBy {
+			return ErrNotMatchCreator
+		}
+		if match.CurrentState != models.Valide {
+			return ErrMatchWrongState
+		}
+		return balanceTeamsTx(ctx, tx, match, newRanking, now)
+	})
+}
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"PLIC/models"
+	"PLIC/rating"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase_RebalanceMatch(t *testing.T) {
+	type testCase struct {
+		name        string
+		state       models.MatchState
+		requestedBy int
+		expected    error
+	}
+
+	court := models.NewDBCourtFixture()
+	users := []models.DBUsers{
+		models.NewDBUsersFixture().WithUsername("p1").WithEmail("p1@test.com"),
+		models.NewDBUsersFixture().WithUsername("p2").WithEmail("p2@test.com"),
+		models.NewDBUsersFixture().WithUsername("p3").WithEmail("p3@test.com"),
+		models.NewDBUsersFixture().WithUsername("p4").WithEmail("p4@test.com"),
+	}
+	// The two best players start in the same team.
+	elos := []int{1500, 1400, 1000, 1100}
+	teams := []int{1, 1, 2, 2}
+
+	match := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(users[0].Id).
+		WithParticipantNber(4)
+
+	testCases := []testCase{
+		{name: "Creator, full match -> balanced", state: models.Valide},
+		{name: "Not the creator", state: models.Valide, requestedBy: 1, expected: ErrNotMatchCreator},
+		{name: "Match started", state: models.EnCours, expected: ErrMatchWrongState},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			fixtures := DBFixtures{
+				Courts:  []models.DBCourt{court},
+				Users:   users,
+				Matches: []models.DBMatches{match.WithCurrentState(c.state)},
+			}
+			for i, u := range users {
+				fixtures.Rankings = append(fixtures.Rankings,
+					models.NewDBRankingFixture().WithUserId(u.Id).WithCourtId(court.Id).WithSport(match.Sport).WithElo(elos[i]))
+				fixtures.UserMatches = append(fixtures.UserMatches,
+					models.NewDBUserMatchFixture().WithUserId(u.Id).WithMatchId(match.Id).WithTeam(teams[i]))
+			}
+
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() {
+				if err := cleanup(); err != nil {
+					t.Logf("cleanup error: %v", err)
+				}
+			}()
+			s.loadFixtures(fixtures)
+
+			ctx := context.Background()
+			err := s.db.RebalanceMatch(ctx, match.Id, users[c.requestedBy].Id, rating.DefaultEngines().NewRanking, time.Now())
+
+			teamOf := map[string]int{}
+			userMatches, fetchErr := s.db.GetUserMatchesByMatchID(ctx, match.Id)
+			require.NoError(t, fetchErr)
+			for _, um := range userMatches {
+				teamOf[um.UserID] = um.Team
+			}
+
+			if c.expected != nil {
+				require.ErrorIs(t, err, c.expected)
+				for i, u := range users {
+					require.Equal(t, teams[i], teamOf[u.Id])
+				}
+				return
+			}
+			require.NoError(t, err)
+			// 1500 + 1000 against 1400 + 1100
+			require.Equal(t, teamOf[users[0].Id], teamOf[users[2].Id])
+			require.Equal(t, teamOf[users[1].Id], teamOf[users[3].Id])
+			require.NotEqual(t, teamOf[users[0].Id], teamOf[users[1].Id])
+		})
+	}
+}
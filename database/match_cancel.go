@@ -0,0 +1,43 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// CancelMatch moves a match that has not started yet to Annule. Only the creator can
+// cancel; the match and its players are kept so it stays visible in history. The
+// other players are emailed through the outbox.
+func (db Database) CancelMatch(ctx context.Context, matchID, requestedBy string, now time.Time) (models.DBMatches, error) {
+	var match models.DBMatches
+	err := db.inTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		match, err = lockMatch(ctx, tx, matchID)
+		if err != nil {
+			return err
+		}
+
+		if match.CreatorID != requestedBy {
+			return ErrNotMatchCreator
+		}
+		if match.CurrentState != models.ManqueJoueur && match.CurrentState != models.Valide {
+			return ErrMatchWrongState
+		}
+
+		match.CurrentState = models.Annule
+		match.UpdatedAt = now
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE matches
+			SET current_state = $2, updated_at = $3
+			WHERE id = $1`, match.Id, match.CurrentState, now); err != nil {
+			return fmt.Errorf("échec de l'annulation du match : %w", err)
+		}
+
+		return enqueueForMatchPlayers(ctx, tx, models.OutboxMatchCancelledEmail, match.Id, requestedBy, now)
+	})
+	return match, err
+}
@@ -0,0 +1,110 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase_CancelMatch(t *testing.T) {
+	type expected struct {
+		err   error
+		state models.MatchState
+	}
+
+	type testCase struct {
+		name        string
+		state       models.MatchState
+		matchID     string
+		requestedBy string
+		expected    expected
+	}
+
+	court := models.NewDBCourtFixture()
+	creator := models.NewDBUsersFixture().WithUsername("creator").WithEmail("creator@test.com")
+	player := models.NewDBUsersFixture().WithUsername("player").WithEmail("player@test.com")
+
+	match := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(creator.Id)
+
+	testCases := []testCase{
+		{
+			name:        "Creator cancels a match waiting for players",
+			state:       models.ManqueJoueur,
+			matchID:     match.Id,
+			requestedBy: creator.Id,
+			expected:    expected{state: models.Annule},
+		},
+		{
+			name:        "Creator cancels a full match",
+			state:       models.Valide,
+			matchID:     match.Id,
+			requestedBy: creator.Id,
+			expected:    expected{state: models.Annule},
+		},
+		{
+			name:        "Not the creator",
+			state:       models.ManqueJoueur,
+			matchID:     match.Id,
+			requestedBy: player.Id,
+			expected:    expected{err: ErrNotMatchCreator, state: models.ManqueJoueur},
+		},
+		{
+			name:        "Match already started",
+			state:       models.EnCours,
+			matchID:     match.Id,
+			requestedBy: creator.Id,
+			expected:    expected{err: ErrMatchWrongState, state: models.EnCours},
+		},
+		{
+			name:        "Match not found",
+			state:       models.ManqueJoueur,
+			matchID:     uuid.NewString(),
+			requestedBy: creator.Id,
+			expected:    expected{err: ErrMatchNotFound, state: models.ManqueJoueur},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() {
+				if err := cleanup(); err != nil {
+					t.Logf("cleanup error: %v", err)
+				}
+			}()
+			s.loadFixtures(DBFixtures{
+				Courts:  []models.DBCourt{court},
+				Users:   []models.DBUsers{creator, player},
+				Matches: []models.DBMatches{match.WithCurrentState(c.state)},
+				UserMatches: []models.DBUserMatch{
+					models.NewDBUserMatchFixture().WithUserId(creator.Id).WithMatchId(match.Id).WithTeam(1),
+					models.NewDBUserMatchFixture().WithUserId(player.Id).WithMatchId(match.Id).WithTeam(2),
+				},
+			})
+
+			ctx := context.Background()
+			_, err := s.db.CancelMatch(ctx, c.matchID, c.requestedBy, time.Now())
+			if c.expected.err != nil {
+				require.ErrorIs(t, err, c.expected.err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			stored, err := s.db.GetMatchById(ctx, match.Id)
+			require.NoError(t, err)
+			require.Equal(t, c.expected.state, stored.CurrentState)
+
+			users, err := s.db.GetUsersByMatchId(ctx, match.Id)
+			require.NoError(t, err)
+			require.Len(t, users, 2)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// openScoreDispute flags a Manque Score match as disputed and opens the re-vote round
+// until deadline. It returns false when the match was already disputed or is no
+// longer waiting for its score.
+func openScoreDispute(ctx context.Context, ext sqlx.ExecerContext, matchID string, deadline, now time.Time) (bool, error) {
+	res, err := ext.ExecContext(ctx, `
+		UPDATE matches
+		SET disputed = TRUE, score_deadline = $2, updated_at = $3
+		WHERE id = $1
+		  AND current_state = 'Manque Score'
+		  AND NOT disputed`, matchID, deadline, now)
+	if err != nil {
+		return false, fmt.Errorf("échec de l'ouverture du litige : %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("échec de l'ouverture du litige : %w", err)
+	}
+	return n == 1, nil
+}
+
+func (db Database) GetMatchIDsPastScoreDeadline(ctx context.Context, now time.Time) ([]string, error) {
+	var ids []string
+	err := db.Database.SelectContext(ctx, &ids, `
+		SELECT id
+		FROM matches
+		WHERE current_state = 'Manque Score'
+		  AND score_deadline < $1
+		ORDER BY score_deadline`, now)
+	if err != nil {
+		return nil, fmt.Errorf("échec de la récupération des votes expirés : %w", err)
+	}
+	return ids, nil
+}
+
+// ResolveScoreDeadline closes the score vote of a match whose deadline has passed. If
+// only one team voted in the current round, that uncontested vote becomes the final
+// score: the match is finalized as by FinalizeMatch and accepted is true. Otherwise
+// (nobody voted, or the teams still disagree) the match is voided: it moves to Annule
+// and no ranking changes.
+// ErrMatchWrongState is returned when the match was resolved in the meantime.
+func (db Database) ResolveScoreDeadline(ctx context.Context, matchID string, now time.Time, rate RateFunc) (match models.DBMatches, accepted bool, err error) {
+	err = db.inTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		match, err = lockMatch(ctx, tx, matchID)
+		if err != nil {
+			return err
+		}
+		if match.CurrentState != models.ManqueScore || match.ScoreDeadline == nil || match.ScoreDeadline.After(now) {
+			return ErrMatchWrongState
+		}
+
+		var votes []models.DBMatchScoreVote
+		if err := tx.SelectContext(ctx, &votes, `
+			SELECT match_id, user_id, team, score1, score2, round, created_at
+			FROM match_score_vote
+			WHERE match_id = $1 AND round = $2`, matchID, match.VoteRound()); err != nil {
+			return fmt.Errorf("échec de la récupération des votes : %w", err)
+		}
+
+		teams := map[int]bool{}
+		for _, v := range votes {
+			teams[v.Team] = true
+		}
+
+		if len(teams) == 1 {
+			last := votes[0]
+			for _, v := range votes[1:] {
+				if v.CreatedAt.After(last.CreatedAt) {
+					last = v
+				}
+			}
+			if err := finalizeMatchTx(ctx, tx, &match, last.Score1, last.Score2, now, rate); err != nil {
+				return err
+			}
+			accepted = true
+			return nil
+		}
+
+		match.Score1 = nil
+		match.Score2 = nil
+		match.CurrentState = models.Annule
+		match.ScoreDeadline = nil
+		match.UpdatedAt = now
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE matches
+			SET current_state = $2, score1 = NULL, score2 = NULL, score_deadline = NULL, updated_at = $3
+			WHERE id = $1`, match.Id, match.CurrentState, now); err != nil {
+			return fmt.Errorf("échec de l'annulation du match : %w", err)
+		}
+		return nil
+	})
+	return match, accepted, err
+}
@@ -0,0 +1,192 @@
+package database
+
+import (
+	"PLIC/models"
+	"PLIC/rating"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_openScoreDispute(t *testing.T) {
+	type testCase struct {
+		name     string
+		match    models.DBMatches
+		expected bool
+	}
+
+	now := time.Now()
+	court := models.NewDBCourtFixture()
+	creator := models.NewDBUsersFixture()
+	match := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(creator.Id).
+		WithCurrentState(models.ManqueScore).
+		WithScoreDeadline(now.Add(time.Hour))
+
+	testCases := []testCase{
+		{
+			name:     "Votes disagree -> dispute opened",
+			match:    match,
+			expected: true,
+		},
+		{
+			name:     "Already disputed -> unchanged",
+			match:    match.WithDisputed(now.Add(time.Hour)),
+			expected: false,
+		},
+		{
+			name:     "Match already finished -> unchanged",
+			match:    match.WithCurrentState(models.Termine),
+			expected: false,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() {
+				if err := cleanup(); err != nil {
+					t.Logf("cleanup error: %v", err)
+				}
+			}()
+			s.loadFixtures(DBFixtures{
+				Courts:  []models.DBCourt{court},
+				Users:   []models.DBUsers{creator},
+				Matches: []models.DBMatches{c.match},
+			})
+
+			ctx := context.Background()
+			deadline := now.Add(models.DisputeVoteWindow)
+			opened, err := openScoreDispute(ctx, s.db.Database, c.match.Id, deadline, now)
+			require.NoError(t, err)
+			require.Equal(t, c.expected, opened)
+
+			stored, err := s.db.GetMatchById(ctx, c.match.Id)
+			require.NoError(t, err)
+			if c.expected {
+				require.True(t, stored.Disputed)
+				require.NotNil(t, stored.ScoreDeadline)
+				require.WithinDuration(t, deadline, *stored.ScoreDeadline, time.Millisecond)
+			} else {
+				require.Equal(t, c.match.Disputed, stored.Disputed)
+			}
+		})
+	}
+}
+
+func TestDatabase_ResolveScoreDeadline(t *testing.T) {
+	type expected struct {
+		err      error
+		accepted bool
+		state    models.MatchState
+		score1   *int
+		score2   *int
+	}
+
+	type testCase struct {
+		name     string
+		match    models.DBMatches
+		votes    []models.DBMatchScoreVote
+		expected expected
+	}
+
+	now := time.Now()
+	court := models.NewDBCourtFixture()
+	userA := models.NewDBUsersFixture().WithUsername("userA").WithEmail("a@test.com")
+	userB := models.NewDBUsersFixture().WithUsername("userB").WithEmail("b@test.com")
+	match := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(userA.Id).
+		WithCurrentState(models.ManqueScore).
+		WithScoreDeadline(now.Add(-time.Minute))
+
+	vote := func(user models.DBUsers, team, score1, score2, round int) models.DBMatchScoreVote {
+		return models.DBMatchScoreVote{MatchId: match.Id, UserId: user.Id, Team: team, Score1: score1, Score2: score2, Round: round}
+	}
+
+	testCases := []testCase{
+		{
+			name:     "Only one team voted -> vote accepted",
+			match:    match,
+			votes:    []models.DBMatchScoreVote{vote(userA, 1, 3, 1, 0)},
+			expected: expected{accepted: true, state: models.Termine, score1: ptr(3), score2: ptr(1)},
+		},
+		{
+			name:     "Nobody voted -> voided",
+			match:    match,
+			expected: expected{state: models.Annule},
+		},
+		{
+			name:  "Dispute: only one team re-voted -> re-vote accepted",
+			match: match.WithDisputed(now.Add(-time.Minute)),
+			votes: []models.DBMatchScoreVote{
+				vote(userA, 1, 3, 1, 0),
+				vote(userB, 2, 1, 3, 0),
+				vote(userB, 2, 2, 3, 1),
+			},
+			expected: expected{accepted: true, state: models.Termine, score1: ptr(2), score2: ptr(3)},
+		},
+		{
+			name:  "Dispute: teams still disagree -> voided",
+			match: match.WithDisputed(now.Add(-time.Minute)),
+			votes: []models.DBMatchScoreVote{
+				vote(userA, 1, 3, 1, 0),
+				vote(userB, 2, 1, 3, 0),
+				vote(userA, 1, 3, 1, 1),
+				vote(userB, 2, 1, 3, 1),
+			},
+			expected: expected{state: models.Annule},
+		},
+		{
+			name:     "Deadline not reached -> wrong state",
+			match:    match.WithScoreDeadline(now.Add(time.Hour)),
+			votes:    []models.DBMatchScoreVote{vote(userA, 1, 3, 1, 0)},
+			expected: expected{err: ErrMatchWrongState, state: models.ManqueScore},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() {
+				if err := cleanup(); err != nil {
+					t.Logf("cleanup error: %v", err)
+				}
+			}()
+			s.loadFixtures(DBFixtures{
+				Courts:  []models.DBCourt{court},
+				Users:   []models.DBUsers{userA, userB},
+				Matches: []models.DBMatches{c.match},
+			})
+
+			ctx := context.Background()
+			for _, v := range c.votes {
+				require.NoError(t, s.db.UpsertMatchScoreVote(ctx, v))
+			}
+
+			_, accepted, err := s.db.ResolveScoreDeadline(ctx, c.match.Id, now, rating.DefaultEngines().Rate)
+			if c.expected.err != nil {
+				require.ErrorIs(t, err, c.expected.err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, c.expected.accepted, accepted)
+
+			stored, err := s.db.GetMatchById(ctx, c.match.Id)
+			require.NoError(t, err)
+			require.Equal(t, c.expected.state, stored.CurrentState)
+			if c.expected.err == nil {
+				require.Equal(t, c.expected.score1, stored.Score1)
+				require.Equal(t, c.expected.score2, stored.Score2)
+				require.Nil(t, stored.ScoreDeadline)
+			}
+		})
+	}
+}
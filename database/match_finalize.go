@@ -0,0 +1,134 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// RateFunc computes the rankings of both teams after a match of sport. It is provided
+// by the rating package so the database layer stays free of any rating logic.
+type RateFunc func(sport models.Sport, team1, team2 []models.DBRanking, score1, score2 int, now time.Time) []models.DBRanking
+
+// FinalizeMatch records the final score of a Manque Score match, moves it to Termine
+// and applies the rating update, all in one transaction, which also queues the result
+// email and push notification of every player in the outbox. The update is guarded by
+// elo_applied_at: finalizing an already finalized match changes nothing and returns
+// applied = false, so concurrent or repeated calls apply the rankings and notify the
+// players exactly once.
+func (db Database) FinalizeMatch(ctx context.Context, matchID string, score1, score2 int, now time.Time, rate RateFunc) (match models.DBMatches, applied bool, err error) {
+	err = db.inTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		match, err = lockMatch(ctx, tx, matchID)
+		if err != nil {
+			return err
+		}
+		if match.EloAppliedAt != nil {
+			return nil
+		}
+		if match.CurrentState != models.ManqueScore {
+			return ErrMatchWrongState
+		}
+
+		if err := finalizeMatchTx(ctx, tx, &match, score1, score2, now, rate); err != nil {
+			return err
+		}
+		applied = true
+		return nil
+	})
+	return match, applied, err
+}
+
+// finalizeMatchTx does the work of FinalizeMatch on a match already locked by tx.
+func finalizeMatchTx(ctx context.Context, tx *sqlx.Tx, match *models.DBMatches, score1, score2 int, now time.Time, rate RateFunc) error {
+	var userMatches []models.DBUserMatch
+	if err := tx.SelectContext(ctx, &userMatches, `
+		SELECT user_id, match_id, team, created_at
+		FROM user_match
+		WHERE match_id = $1
+		ORDER BY team, user_id`, match.Id); err != nil {
+		return fmt.Errorf("failed to fetch user_match rows: %w", err)
+	}
+
+	var team1, team2 []models.DBRanking
+	for _, um := range userMatches {
+		var rk models.DBRanking
+		if err := tx.GetContext(ctx, &rk, `
+			SELECT user_id, court_id, sport, elo, rating_deviation, volatility, games_played, created_at, updated_at
+			FROM ranking
+			WHERE user_id = $1 AND court_id = $2 AND sport = $3
+			FOR UPDATE`, um.UserID, match.CourtID, match.Sport); err != nil {
+			return fmt.Errorf("ranking missing for user=%s court=%s sport=%s: %w", um.UserID, match.CourtID, match.Sport, err)
+		}
+		switch um.Team {
+		case 1:
+			team1 = append(team1, rk)
+		case 2:
+			team2 = append(team2, rk)
+		}
+	}
+
+	if len(team1) > 0 && len(team2) > 0 {
+		eloBefore := make(map[string]int, len(team1)+len(team2))
+		for _, rk := range append(append([]models.DBRanking{}, team1...), team2...) {
+			eloBefore[rk.UserID] = rk.Elo
+		}
+
+		for _, rk := range rate(match.Sport, team1, team2, score1, score2, now) {
+			if err := updateRanking(ctx, tx, rk); err != nil {
+				return err
+			}
+
+			if err := insertRankingHistory(ctx, tx, models.DBRankingHistory{
+				Id:        uuid.NewString(),
+				UserID:    rk.UserID,
+				CourtID:   rk.CourtID,
+				Sport:     rk.Sport,
+				MatchID:   match.Id,
+				EloBefore: eloBefore[rk.UserID],
+				EloAfter:  rk.Elo,
+				Delta:     rk.Elo - eloBefore[rk.UserID],
+				CreatedAt: now,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	match.Score1 = &score1
+	match.Score2 = &score2
+	match.CurrentState = models.Termine
+	match.ScoreDeadline = nil
+	match.EloAppliedAt = &now
+	match.UpdatedAt = now
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE matches
+		SET current_state = $2, score1 = $3, score2 = $4, score_deadline = NULL, elo_applied_at = $5, updated_at = $5
+		WHERE id = $1`, match.Id, match.CurrentState, score1, score2, now); err != nil {
+		return fmt.Errorf("échec de la finalisation du match : %w", err)
+	}
+
+	recipients := make([]models.MatchRecipientPayload, 0, len(userMatches))
+	for _, um := range userMatches {
+		recipients = append(recipients, models.MatchRecipientPayload{MatchID: match.Id, UserID: um.UserID})
+	}
+	if err := enqueueMatchEmails(ctx, tx, models.OutboxMatchResultEmail, recipients, now); err != nil {
+		return err
+	}
+	return enqueueMatchEmails(ctx, tx, models.OutboxMatchResultPush, recipients, now)
+}
+
+func updateRanking(ctx context.Context, ext sqlx.ExecerContext, rk models.DBRanking) error {
+	if _, err := ext.ExecContext(ctx, `
+		UPDATE ranking
+		SET elo = $4, rating_deviation = $5, volatility = $6, games_played = $7, updated_at = $8
+		WHERE user_id = $1 AND court_id = $2 AND sport = $3`,
+		rk.UserID, rk.CourtID, rk.Sport, rk.Elo, rk.Deviation, rk.Volatility, rk.GamesPlayed, rk.UpdatedAt); err != nil {
+		return fmt.Errorf("error updating ranking: %w", err)
+	}
+	return nil
+}
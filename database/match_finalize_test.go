@@ -0,0 +1,137 @@
+package database
+
+import (
+	"PLIC/models"
+	"PLIC/rating"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase_FinalizeMatch(t *testing.T) {
+	type expected struct {
+		err      error
+		applied  bool
+		state    models.MatchState
+		eloUserA int
+		eloUserB int
+	}
+
+	type testCase struct {
+		name     string
+		match    models.DBMatches
+		calls    int
+		expected expected
+	}
+
+	now := time.Now()
+	court := models.NewDBCourtFixture()
+	userA := models.NewDBUsersFixture().WithUsername("userA").WithEmail("a@test.com")
+	userB := models.NewDBUsersFixture().WithUsername("userB").WithEmail("b@test.com")
+	match := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(userA.Id).
+		WithSport(models.Basket).
+		WithCurrentState(models.ManqueScore).
+		WithScoreDeadline(now.Add(time.Hour))
+
+	testCases := []testCase{
+		{
+			name:     "Manque Score -> Termine and Elo applied",
+			match:    match,
+			calls:    1,
+			expected: expected{applied: true, state: models.Termine, eloUserA: 1016, eloUserB: 984},
+		},
+		{
+			name:     "Finalized twice -> Elo applied once",
+			match:    match,
+			calls:    2,
+			expected: expected{applied: false, state: models.Termine, eloUserA: 1016, eloUserB: 984},
+		},
+		{
+			name:     "Match not waiting for its score",
+			match:    match.WithCurrentState(models.EnCours),
+			calls:    1,
+			expected: expected{err: ErrMatchWrongState, state: models.EnCours, eloUserA: 1000, eloUserB: 1000},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() {
+				if err := cleanup(); err != nil {
+					t.Logf("cleanup error: %v", err)
+				}
+			}()
+			s.loadFixtures(DBFixtures{
+				Courts:  []models.DBCourt{court},
+				Users:   []models.DBUsers{userA, userB},
+				Matches: []models.DBMatches{c.match},
+				UserMatches: []models.DBUserMatch{
+					models.NewDBUserMatchFixture().WithUserId(userA.Id).WithMatchId(match.Id).WithTeam(1),
+					models.NewDBUserMatchFixture().WithUserId(userB.Id).WithMatchId(match.Id).WithTeam(2),
+				},
+				Rankings: []models.DBRanking{
+					models.NewDBRankingFixture().WithUserId(userA.Id).WithCourtId(court.Id).WithSport(models.Basket).WithElo(1000),
+					models.NewDBRankingFixture().WithUserId(userB.Id).WithCourtId(court.Id).WithSport(models.Basket).WithElo(1000),
+				},
+			})
+
+			ctx := context.Background()
+			var (
+				applied bool
+				err     error
+			)
+			for i := 0; i < c.calls; i++ {
+				_, applied, err = s.db.FinalizeMatch(ctx, match.Id, 21, 15, now, rating.DefaultEngines().Rate)
+			}
+			if c.expected.err != nil {
+				require.ErrorIs(t, err, c.expected.err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, c.expected.applied, applied)
+
+			stored, err := s.db.GetMatchById(ctx, match.Id)
+			require.NoError(t, err)
+			require.Equal(t, c.expected.state, stored.CurrentState)
+			if c.expected.state == models.Termine {
+				require.Equal(t, ptr(21), stored.Score1)
+				require.Equal(t, ptr(15), stored.Score2)
+				require.NotNil(t, stored.EloAppliedAt)
+				require.Nil(t, stored.ScoreDeadline)
+			}
+
+			rA, err := s.db.GetRankingByUserCourtSport(ctx, userA.Id, court.Id, models.Basket)
+			require.NoError(t, err)
+			require.Equal(t, c.expected.eloUserA, rA.Elo)
+			rB, err := s.db.GetRankingByUserCourtSport(ctx, userB.Id, court.Id, models.Basket)
+			require.NoError(t, err)
+			require.Equal(t, c.expected.eloUserB, rB.Elo)
+
+			history, err := s.db.GetRankingHistoryByMatchIDs(ctx, []string{match.Id})
+			require.NoError(t, err)
+			if c.expected.state != models.Termine {
+				require.Empty(t, history[match.Id])
+				return
+			}
+			require.Len(t, history[match.Id], 2)
+			for _, h := range history[match.Id] {
+				switch h.UserID {
+				case userA.Id:
+					require.Equal(t, 1000, h.EloBefore)
+					require.Equal(t, c.expected.eloUserA, h.EloAfter)
+				case userB.Id:
+					require.Equal(t, 1000, h.EloBefore)
+					require.Equal(t, c.expected.eloUserB, h.EloAfter)
+				}
+				require.Equal(t, h.EloAfter-h.EloBefore, h.Delta)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrMatchNotVisible = errors.New("match is not visible to the user")
+
+// matchVisibleTo is the condition for match m to be visible to the user whose id is
+// the query parameter viewer: public matches, matches they created, play in or were
+// invited to, and friends-only matches of their friends.
+func matchVisibleTo(viewer string) string {
+	return `(
+		m.visibility = 'public'
+		OR m.creator_id = ` + viewer + `
+		OR EXISTS (SELECT 1 FROM user_match vum WHERE vum.match_id = m.id AND vum.user_id = ` + viewer + `)
+		OR EXISTS (SELECT 1 FROM match_invitations vmi WHERE vmi.match_id = m.id AND vmi.user_id = ` + viewer + `)
+		OR (m.visibility = 'friends' AND EXISTS (
+			SELECT 1 FROM friendships vf
+			WHERE vf.status = 'accepted'
+			  AND ((vf.requester_id = m.creator_id AND vf.addressee_id = ` + viewer + `)
+			    OR (vf.addressee_id = m.creator_id AND vf.requester_id = ` + viewer + `))))
+	)`
+}
+
+// IsMatchVisibleTo tells whether the match is visible to the user, see matchVisibleTo.
+// It is false when the match does not exist.
+func (db Database) IsMatchVisibleTo(ctx context.Context, matchID, userID string) (bool, error) {
+	return isMatchVisibleTo(ctx, db.Database, matchID, userID)
+}
+
+func isMatchVisibleTo(ctx context.Context, q sqlx.QueryerContext, matchID, userID string) (bool, error) {
+	var visible bool
+	if err := sqlx.GetContext(ctx, q, &visible, `
+		SELECT EXISTS (
+			SELECT 1 FROM matches m
+			WHERE m.id = $2 AND `+matchVisibleTo("$1")+`
+		)`, userID, matchID); err != nil {
+		return false, fmt.Errorf("failed to check match visibility: %w", err)
+	}
+	return visible, nil
+}
+
+// InviteToMatch invites users to a match waiting for players. Only its creator can
+// invite. Users invited before are skipped; it returns the ids of the users invited
+// by this call, whose invitation emails are queued in the outbox.
+func (db Database) InviteToMatch(ctx context.Context, matchID, invitedBy string, userIDs []string, now time.Time) (match models.DBMatches, invited []string, err error) {
+	err = db.inTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		match, err = lockMatch(ctx, tx, matchID)
+		if err != nil {
+			return err
+		}
+		if match.CreatorID != invitedBy {
+			return ErrNotMatchCreator
+		}
+		if match.CurrentState != models.ManqueJoueur {
+			return ErrMatchWrongState
+		}
+
+		if err := tx.SelectContext(ctx, &invited, `
+			INSERT INTO match_invitations (match_id, user_id, invited_by, created_at)
+			SELECT $1, u, $2, $3
+			FROM UNNEST($4::text[]) AS u
+			ON CONFLICT (match_id, user_id) DO NOTHING
+			RETURNING user_id`,
+			matchID, invitedBy, now, userIDs); err != nil {
+			return fmt.Errorf("failed to insert match invitations: %w", err)
+		}
+
+		recipients := make([]models.MatchRecipientPayload, 0, len(invited))
+		for _, id := range invited {
+			recipients = append(recipients, models.MatchRecipientPayload{MatchID: matchID, UserID: id, InviterID: invitedBy})
+		}
+		return enqueueMatchEmails(ctx, tx, models.OutboxMatchInvitationEmail, recipients, now)
+	})
+	if err != nil {
+		return models.DBMatches{}, nil, err
+	}
+	return match, invited, nil
+}
+
+// GetMatchInvitations returns the ids of the users invited to the match.
+func (db Database) GetMatchInvitations(ctx context.Context, matchID string) ([]string, error) {
+	var userIDs []string
+	if err := db.Database.SelectContext(ctx, &userIDs, `
+		SELECT user_id
+		FROM match_invitations
+		WHERE match_id = $1
+		ORDER BY created_at, user_id`, matchID); err != nil {
+		return nil, fmt.Errorf("failed to fetch match invitations: %w", err)
+	}
+	return userIDs, nil
+}
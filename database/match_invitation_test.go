@@ -0,0 +1,155 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase_GetAllMatches_Visibility(t *testing.T) {
+	court := models.NewDBCourtFixture()
+	creator := models.NewDBUsersFixture().WithUsername("creator").WithEmail("creator@test.com")
+	friend := models.NewDBUsersFixture().WithUsername("friend").WithEmail("friend@test.com")
+	stranger := models.NewDBUsersFixture().WithUsername("stranger").WithEmail("stranger@test.com")
+	invited := models.NewDBUsersFixture().WithUsername("invited").WithEmail("invited@test.com")
+
+	public := models.NewDBMatchesFixture().WithCourtId(court.Id).WithCreatorId(creator.Id)
+	friendsOnly := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(creator.Id).
+		WithVisibility(models.VisibilityFriends)
+	inviteOnly := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(creator.Id).
+		WithVisibility(models.VisibilityInviteOnly)
+
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() {
+		if err := cleanup(); err != nil {
+			t.Logf("cleanup error: %v", err)
+		}
+	}()
+	s.loadFixtures(DBFixtures{
+		Courts:  []models.DBCourt{court},
+		Users:   []models.DBUsers{creator, friend, stranger, invited},
+		Matches: []models.DBMatches{public, friendsOnly, inviteOnly},
+		Friendships: []models.DBFriendship{
+			models.NewDBFriendshipFixture().
+				WithRequesterId(creator.Id).
+				WithAddresseeId(friend.Id).
+				WithStatus(models.FriendshipAccepted),
+			models.NewDBFriendshipFixture().
+				WithRequesterId(invited.Id).
+				WithAddresseeId(creator.Id).
+				WithStatus(models.FriendshipAccepted),
+		},
+	})
+
+	ctx := context.Background()
+	_, _, err := s.db.InviteToMatch(ctx, inviteOnly.Id, creator.Id, []string{invited.Id}, time.Now())
+	require.NoError(t, err)
+
+	for _, c := range []struct {
+		viewer   string
+		expected []string
+	}{
+		{viewer: creator.Id, expected: []string{public.Id, friendsOnly.Id, inviteOnly.Id}},
+		{viewer: friend.Id, expected: []string{public.Id, friendsOnly.Id}},
+		{viewer: invited.Id, expected: []string{public.Id, friendsOnly.Id, inviteOnly.Id}},
+		{viewer: stranger.Id, expected: []string{public.Id}},
+	} {
+		matches, err := s.db.GetAllMatches(ctx, c.viewer)
+		require.NoError(t, err)
+		ids := make([]string, 0, len(matches))
+		for _, m := range matches {
+			ids = append(ids, m.Id)
+		}
+		require.ElementsMatch(t, c.expected, ids)
+	}
+}
+
+func TestDatabase_InviteToMatch(t *testing.T) {
+	type testCase struct {
+		name      string
+		match     models.DBMatches
+		invitedBy string
+		expected  error
+	}
+
+	court := models.NewDBCourtFixture()
+	creator := models.NewDBUsersFixture().WithUsername("creator").WithEmail("creator@test.com")
+	friend := models.NewDBUsersFixture().WithUsername("friend").WithEmail("friend@test.com")
+
+	match := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(creator.Id).
+		WithVisibility(models.VisibilityInviteOnly)
+
+	testCases := []testCase{
+		{
+			name:      "Creator invites",
+			match:     match,
+			invitedBy: creator.Id,
+		},
+		{
+			name:      "Not the creator",
+			match:     match,
+			invitedBy: friend.Id,
+			expected:  ErrNotMatchCreator,
+		},
+		{
+			name:      "Match already full",
+			match:     match.WithCurrentState(models.Valide),
+			invitedBy: creator.Id,
+			expected:  ErrMatchWrongState,
+		},
+		{
+			name:      "Match not found",
+			match:     match.WithId(uuid.NewString()),
+			invitedBy: creator.Id,
+			expected:  ErrMatchNotFound,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() {
+				if err := cleanup(); err != nil {
+					t.Logf("cleanup error: %v", err)
+				}
+			}()
+			fixtures := DBFixtures{
+				Courts:  []models.DBCourt{court},
+				Users:   []models.DBUsers{creator, friend},
+				Matches: []models.DBMatches{match.WithCurrentState(c.match.CurrentState)},
+			}
+			s.loadFixtures(fixtures)
+
+			ctx := context.Background()
+			_, invited, err := s.db.InviteToMatch(ctx, c.match.Id, c.invitedBy, []string{friend.Id}, time.Now())
+			if c.expected != nil {
+				require.ErrorIs(t, err, c.expected)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, []string{friend.Id}, invited)
+
+			// A second invitation is not recorded again
+			_, invited, err = s.db.InviteToMatch(ctx, c.match.Id, c.invitedBy, []string{friend.Id}, time.Now())
+			require.NoError(t, err)
+			require.Empty(t, invited)
+
+			userIDs, err := s.db.GetMatchInvitations(ctx, match.Id)
+			require.NoError(t, err)
+			require.Equal(t, []string{friend.Id}, userIDs)
+		})
+	}
+}
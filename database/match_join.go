@@ -0,0 +1,141 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrMatchNotFound   = notFoundError("match not found")
+	ErrMatchWrongState = errors.New("match is not in the right state")
+	ErrAlreadyInMatch  = conflictError("user already joined the match")
+	ErrTeamFull        = errors.New("this team is full")
+	ErrInvalidTeam     = errors.New("team must be 1 or 2")
+)
+
+// lockMatch loads the match and locks its row until the end of the transaction.
+func lockMatch(ctx context.Context, tx *sqlx.Tx, matchID string) (models.DBMatches, error) {
+	var match models.DBMatches
+	err := tx.GetContext(ctx, &match, `
+		SELECT id, sport, date, participant_nber, current_state, score1, score2, creator_id, court_id, disputed, score_deadline, elo_applied_at, visibility, auto_balance, created_at, updated_at
+		FROM matches
+		WHERE id = $1
+		FOR UPDATE`, matchID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return match, ErrMatchNotFound
+		}
+		return match, fmt.Errorf("échec du verrouillage du match : %w", err)
+	}
+	return match, nil
+}
+
+// JoinMatch adds the user to a team of the match, creates their ranking on the court
+// with newRanking if they have none, and moves the match to Valide once every seat is
+// taken, notifying the other players through the outbox. In an auto_balance match the
+// player joins without a team (team 0) and the teams are balanced when the match
+// becomes Valide. Friends-only and invite-only matches can only be joined by the users
+// they are visible to. The match row is locked for the whole transaction, so
+// concurrent joins are serialized and can neither overfill a team nor miss the state
+// change.
+func (db Database) JoinMatch(ctx context.Context, um models.DBUserMatch, now time.Time, newRanking NewRankingFunc) (*models.DBMatches, error) {
+	var match models.DBMatches
+	err := db.inTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		match, err = lockMatch(ctx, tx, um.MatchID)
+		if err != nil {
+			return err
+		}
+
+		if match.CurrentState != models.ManqueJoueur {
+			return ErrMatchWrongState
+		}
+
+		if match.Visibility != models.VisibilityPublic {
+			visible, err := isMatchVisibleTo(ctx, tx, um.MatchID, um.UserID)
+			if err != nil {
+				return err
+			}
+			if !visible {
+				return ErrMatchNotVisible
+			}
+		}
+
+		var alreadyIn bool
+		if err := tx.GetContext(ctx, &alreadyIn, `
+			SELECT EXISTS (
+				SELECT 1 FROM user_match WHERE user_id = $1 AND match_id = $2
+			)`, um.UserID, um.MatchID); err != nil {
+			return fmt.Errorf("erreur lors de la vérification de user_match: %w", err)
+		}
+		if alreadyIn {
+			return ErrAlreadyInMatch
+		}
+
+		if match.AutoBalance {
+			um.Team = 0
+		} else {
+			if um.Team != 1 && um.Team != 2 {
+				return ErrInvalidTeam
+			}
+			var teamCount int
+			if err := tx.GetContext(ctx, &teamCount, `
+				SELECT COUNT(*) FROM user_match WHERE match_id = $1 AND team = $2`, um.MatchID, um.Team); err != nil {
+				return fmt.Errorf("échec du comptage de l'équipe %d : %w", um.Team, err)
+			}
+			if teamCount >= match.ParticipantNber/2 {
+				return ErrTeamFull
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO user_match (user_id, match_id, team, created_at) VALUES ($1, $2, $3, $4)`,
+			um.UserID, um.MatchID, um.Team, um.CreatedAt); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return ErrAlreadyInMatch
+			}
+			return fmt.Errorf("échec de l'insertion de user_match : %w", err)
+		}
+		if err := insertRankingIfMissing(ctx, tx, newRanking(um.UserID, match.CourtID, match.Sport, now)); err != nil {
+			return err
+		}
+
+		var total int
+		if err := tx.GetContext(ctx, &total, `
+			SELECT COUNT(*) FROM user_match WHERE match_id = $1`, um.MatchID); err != nil {
+			return fmt.Errorf("échec du comptage des joueurs : %w", err)
+		}
+		if total >= match.ParticipantNber {
+			match.CurrentState = models.Valide
+			if match.AutoBalance {
+				if err := balanceTeamsTx(ctx, tx, match, newRanking, now); err != nil {
+					return err
+				}
+			}
+		}
+		match.UpdatedAt = now
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE matches
+			SET current_state = $2, updated_at = $3
+			WHERE id = $1`, match.Id, match.CurrentState, now); err != nil {
+			return fmt.Errorf("échec de la mise à jour du match : %w", err)
+		}
+		if match.CurrentState == models.Valide {
+			return enqueueForMatchPlayers(ctx, tx, models.OutboxMatchFilledPush, match.Id, um.UserID, now)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &match, nil
+}
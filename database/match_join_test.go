@@ -0,0 +1,234 @@
+package database
+
+import (
+	"PLIC/models"
+	"PLIC/rating"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase_JoinMatch(t *testing.T) {
+	type expected struct {
+		err   error
+		state models.MatchState
+	}
+
+	type testCase struct {
+		name     string
+		fixtures DBFixtures
+		param    models.DBUserMatch
+		expected expected
+	}
+
+	court := models.NewDBCourtFixture()
+	creator := models.NewDBUsersFixture().WithUsername("creator").WithEmail("creator@test.com")
+	joiner := models.NewDBUsersFixture().WithUsername("joiner").WithEmail("joiner@test.com")
+	other := models.NewDBUsersFixture().WithUsername("other").WithEmail("other@test.com")
+
+	match := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(creator.Id).
+		WithParticipantNber(2).
+		WithCurrentState(models.ManqueJoueur)
+
+	baseFixtures := func(state models.MatchState, userMatches ...models.DBUserMatch) DBFixtures {
+		return DBFixtures{
+			Courts:      []models.DBCourt{court},
+			Users:       []models.DBUsers{creator, joiner, other},
+			Matches:     []models.DBMatches{match.WithCurrentState(state)},
+			UserMatches: userMatches,
+		}
+	}
+
+	testCases := []testCase{
+		{
+			name:     "Seat available -> joined, still Manque joueur",
+			fixtures: baseFixtures(models.ManqueJoueur),
+			param:    models.NewDBUserMatchFixture().WithUserId(joiner.Id).WithMatchId(match.Id).WithTeam(1),
+			expected: expected{state: models.ManqueJoueur},
+		},
+		{
+			name: "Last seat -> match becomes Valide",
+			fixtures: baseFixtures(models.ManqueJoueur,
+				models.NewDBUserMatchFixture().WithUserId(other.Id).WithMatchId(match.Id).WithTeam(1)),
+			param:    models.NewDBUserMatchFixture().WithUserId(joiner.Id).WithMatchId(match.Id).WithTeam(2),
+			expected: expected{state: models.Valide},
+		},
+		{
+			name: "Team full",
+			fixtures: baseFixtures(models.ManqueJoueur,
+				models.NewDBUserMatchFixture().WithUserId(other.Id).WithMatchId(match.Id).WithTeam(1)),
+			param:    models.NewDBUserMatchFixture().WithUserId(joiner.Id).WithMatchId(match.Id).WithTeam(1),
+			expected: expected{err: ErrTeamFull, state: models.ManqueJoueur},
+		},
+		{
+			name: "Already in match",
+			fixtures: baseFixtures(models.ManqueJoueur,
+				models.NewDBUserMatchFixture().WithUserId(joiner.Id).WithMatchId(match.Id).WithTeam(1)),
+			param:    models.NewDBUserMatchFixture().WithUserId(joiner.Id).WithMatchId(match.Id).WithTeam(2),
+			expected: expected{err: ErrAlreadyInMatch, state: models.ManqueJoueur},
+		},
+		{
+			name:     "Wrong state",
+			fixtures: baseFixtures(models.Valide),
+			param:    models.NewDBUserMatchFixture().WithUserId(joiner.Id).WithMatchId(match.Id).WithTeam(1),
+			expected: expected{err: ErrMatchWrongState, state: models.Valide},
+		},
+		{
+			name: "Friends-only match, joiner is not a friend",
+			fixtures: DBFixtures{
+				Courts:  []models.DBCourt{court},
+				Users:   []models.DBUsers{creator, joiner, other},
+				Matches: []models.DBMatches{match.WithVisibility(models.VisibilityFriends)},
+			},
+			param:    models.NewDBUserMatchFixture().WithUserId(joiner.Id).WithMatchId(match.Id).WithTeam(1),
+			expected: expected{err: ErrMatchNotVisible, state: models.ManqueJoueur},
+		},
+		{
+			name: "Friends-only match, joiner is a friend of the creator",
+			fixtures: DBFixtures{
+				Courts:  []models.DBCourt{court},
+				Users:   []models.DBUsers{creator, joiner, other},
+				Matches: []models.DBMatches{match.WithVisibility(models.VisibilityFriends)},
+				Friendships: []models.DBFriendship{
+					models.NewDBFriendshipFixture().
+						WithRequesterId(joiner.Id).
+						WithAddresseeId(creator.Id).
+						WithStatus(models.FriendshipAccepted),
+				},
+			},
+			param:    models.NewDBUserMatchFixture().WithUserId(joiner.Id).WithMatchId(match.Id).WithTeam(1),
+			expected: expected{state: models.ManqueJoueur},
+		},
+		{
+			name: "Invite-only match, friend not invited",
+			fixtures: DBFixtures{
+				Courts:  []models.DBCourt{court},
+				Users:   []models.DBUsers{creator, joiner, other},
+				Matches: []models.DBMatches{match.WithVisibility(models.VisibilityInviteOnly)},
+				Friendships: []models.DBFriendship{
+					models.NewDBFriendshipFixture().
+						WithRequesterId(creator.Id).
+						WithAddresseeId(joiner.Id).
+						WithStatus(models.FriendshipAccepted),
+				},
+			},
+			param:    models.NewDBUserMatchFixture().WithUserId(joiner.Id).WithMatchId(match.Id).WithTeam(1),
+			expected: expected{err: ErrMatchNotVisible, state: models.ManqueJoueur},
+		},
+		{
+			name:     "No team in a manual match",
+			fixtures: baseFixtures(models.ManqueJoueur),
+			param:    models.NewDBUserMatchFixture().WithUserId(joiner.Id).WithMatchId(match.Id).WithTeam(0),
+			expected: expected{err: ErrInvalidTeam, state: models.ManqueJoueur},
+		},
+		{
+			name:     "Match not found",
+			fixtures: baseFixtures(models.ManqueJoueur),
+			param:    models.NewDBUserMatchFixture().WithUserId(joiner.Id).WithMatchId(uuid.NewString()).WithTeam(1),
+			expected: expected{err: ErrMatchNotFound, state: models.ManqueJoueur},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() {
+				if err := cleanup(); err != nil {
+					t.Logf("cleanup error: %v", err)
+				}
+			}()
+			s.loadFixtures(c.fixtures)
+
+			ctx := context.Background()
+			_, err := s.db.JoinMatch(ctx, c.param, time.Now(), rating.DefaultEngines().NewRanking)
+			if c.expected.err != nil {
+				require.ErrorIs(t, err, c.expected.err)
+			} else {
+				require.NoError(t, err)
+				joined, err := s.db.IsUserInMatch(ctx, c.param.UserID, c.param.MatchID)
+				require.NoError(t, err)
+				require.True(t, joined)
+			}
+
+			stored, err := s.db.GetMatchById(ctx, match.Id)
+			require.NoError(t, err)
+			require.Equal(t, c.expected.state, stored.CurrentState)
+		})
+	}
+}
+
+func TestDatabase_JoinMatch_AutoBalance(t *testing.T) {
+	court := models.NewDBCourtFixture()
+	users := []models.DBUsers{
+		models.NewDBUsersFixture().WithUsername("p1").WithEmail("p1@test.com"),
+		models.NewDBUsersFixture().WithUsername("p2").WithEmail("p2@test.com"),
+		models.NewDBUsersFixture().WithUsername("p3").WithEmail("p3@test.com"),
+		models.NewDBUsersFixture().WithUsername("p4").WithEmail("p4@test.com"),
+	}
+	elos := []int{1400, 1100, 1000, 1300}
+
+	match := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(users[0].Id).
+		WithParticipantNber(4).
+		WithAutoBalance(true)
+
+	fixtures := DBFixtures{
+		Courts:  []models.DBCourt{court},
+		Users:   users,
+		Matches: []models.DBMatches{match},
+	}
+	for i, u := range users {
+		fixtures.Rankings = append(fixtures.Rankings,
+			models.NewDBRankingFixture().WithUserId(u.Id).WithCourtId(court.Id).WithSport(match.Sport).WithElo(elos[i]))
+	}
+	for _, u := range users[:2] {
+		fixtures.UserMatches = append(fixtures.UserMatches,
+			models.NewDBUserMatchFixture().WithUserId(u.Id).WithMatchId(match.Id).WithTeam(0))
+	}
+
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() {
+		if err := cleanup(); err != nil {
+			t.Logf("cleanup error: %v", err)
+		}
+	}()
+	s.loadFixtures(fixtures)
+
+	ctx := context.Background()
+	newRanking := rating.DefaultEngines().NewRanking
+
+	// The requested team is ignored: players wait without a team until the match is full.
+	joined, err := s.db.JoinMatch(ctx, models.NewDBUserMatchFixture().WithUserId(users[2].Id).WithMatchId(match.Id).WithTeam(1), time.Now(), newRanking)
+	require.NoError(t, err)
+	require.Equal(t, models.ManqueJoueur, joined.CurrentState)
+	userMatches, err := s.db.GetUserMatchesByMatchID(ctx, match.Id)
+	require.NoError(t, err)
+	for _, um := range userMatches {
+		require.Zero(t, um.Team)
+	}
+
+	joined, err = s.db.JoinMatch(ctx, models.NewDBUserMatchFixture().WithUserId(users[3].Id).WithMatchId(match.Id), time.Now(), newRanking)
+	require.NoError(t, err)
+	require.Equal(t, models.Valide, joined.CurrentState)
+
+	teamOf := map[string]int{}
+	userMatches, err = s.db.GetUserMatchesByMatchID(ctx, match.Id)
+	require.NoError(t, err)
+	for _, um := range userMatches {
+		require.Contains(t, []int{1, 2}, um.Team)
+		teamOf[um.UserID] = um.Team
+	}
+	// 1400 + 1000 against 1100 + 1300
+	require.Equal(t, teamOf[users[0].Id], teamOf[users[2].Id])
+	require.Equal(t, teamOf[users[1].Id], teamOf[users[3].Id])
+	require.NotEqual(t, teamOf[users[0].Id], teamOf[users[1].Id])
+}
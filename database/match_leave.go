@@ -0,0 +1,94 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrNotInMatch      = errors.New("user is not in the match")
+	ErrNotMatchCreator = errors.New("user is not the match creator")
+)
+
+// RemoveMatchPlayer frees the seat of userID in the match. requestedBy is either the
+// leaving player or the match creator kicking them. A Valide match goes back to
+// Manque joueur. When the creator leaves, the oldest remaining player becomes the
+// creator; when the creator is alone, the match is cancelled (Annule) and cancelled
+// is true.
+func (db Database) RemoveMatchPlayer(ctx context.Context, matchID, userID, requestedBy string, now time.Time) (match models.DBMatches, cancelled bool, err error) {
+	err = db.inTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		match, err = lockMatch(ctx, tx, matchID)
+		if err != nil {
+			return err
+		}
+
+		if requestedBy != userID && match.CreatorID != requestedBy {
+			return ErrNotMatchCreator
+		}
+		if match.CurrentState != models.ManqueJoueur && match.CurrentState != models.Valide {
+			return ErrMatchWrongState
+		}
+
+		var nextCreator string
+		if match.CreatorID == userID {
+			err := tx.GetContext(ctx, &nextCreator, `
+				SELECT user_id
+				FROM user_match
+				WHERE match_id = $1 AND user_id <> $2
+				ORDER BY created_at, user_id
+				LIMIT 1`, matchID, userID)
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				// The creator is alone: leaving cancels the match, and their seat is
+				// kept so the match stays in their history.
+				match.CurrentState = models.Annule
+				match.UpdatedAt = now
+				if _, err := tx.ExecContext(ctx, `
+					UPDATE matches
+					SET current_state = $2, updated_at = $3
+					WHERE id = $1`, match.Id, match.CurrentState, now); err != nil {
+					return fmt.Errorf("échec de l'annulation du match : %w", err)
+				}
+				cancelled = true
+				return nil
+			case err != nil:
+				return fmt.Errorf("échec de la recherche du nouveau créateur : %w", err)
+			}
+		}
+
+		res, err := tx.ExecContext(ctx, `
+			DELETE FROM user_match
+			WHERE match_id = $1 AND user_id = $2`, matchID, userID)
+		if err != nil {
+			return fmt.Errorf("échec de la suppression de user_match : %w", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("échec de la suppression de user_match : %w", err)
+		}
+		if n == 0 {
+			return ErrNotInMatch
+		}
+		if nextCreator != "" {
+			match.CreatorID = nextCreator
+		}
+
+		match.CurrentState = models.ManqueJoueur
+		match.UpdatedAt = now
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE matches
+			SET current_state = $2, creator_id = $3, updated_at = $4
+			WHERE id = $1`, match.Id, match.CurrentState, match.CreatorID, now); err != nil {
+			return fmt.Errorf("échec de la mise à jour du match : %w", err)
+		}
+		return nil
+	})
+	return match, cancelled, err
+}
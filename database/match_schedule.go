@@ -0,0 +1,97 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// CancelExpiredMatches cancels the Manque joueur matches whose date has passed and
+// returns the updated matches.
+func (db Database) CancelExpiredMatches(ctx context.Context, now time.Time) ([]models.DBMatches, error) {
+	var matches []models.DBMatches
+	err := db.Database.SelectContext(ctx, &matches, `
+		UPDATE matches
+		SET current_state = $2, updated_at = $3
+		WHERE current_state = $1 AND date < $3
+		RETURNING id, sport, date, participant_nber, current_state, score1, score2, creator_id, court_id, disputed, score_deadline, visibility, auto_balance, created_at, updated_at`,
+		models.ManqueJoueur, models.Annule, now)
+	if err != nil {
+		return nil, fmt.Errorf("échec de l'annulation des matchs expirés : %w", err)
+	}
+	return matches, nil
+}
+
+// StartDueMatches moves the Valide matches whose date has come to En cours and
+// returns the updated matches.
+func (db Database) StartDueMatches(ctx context.Context, now time.Time) ([]models.DBMatches, error) {
+	var matches []models.DBMatches
+	err := db.Database.SelectContext(ctx, &matches, `
+		UPDATE matches
+		SET current_state = $2, updated_at = $3
+		WHERE current_state = $1 AND date <= $3
+		RETURNING id, sport, date, participant_nber, current_state, score1, score2, creator_id, court_id, disputed, score_deadline, visibility, auto_balance, created_at, updated_at`,
+		models.Valide, models.EnCours, now)
+	if err != nil {
+		return nil, fmt.Errorf("échec du démarrage des matchs : %w", err)
+	}
+	return matches, nil
+}
+
+// ExpireStartedMatches moves the En cours matches of a sport that started before
+// startedBefore to Manque Score, opens their score vote until now + ScoreVoteWindow,
+// queues the vote reminder of their players and returns the updated matches.
+func (db Database) ExpireStartedMatches(ctx context.Context, sport models.Sport, startedBefore time.Time, now time.Time) ([]models.DBMatches, error) {
+	var matches []models.DBMatches
+	err := db.inTx(ctx, func(tx *sqlx.Tx) error {
+		if err := tx.SelectContext(ctx, &matches, `
+			UPDATE matches
+			SET current_state = $2, score_deadline = $6, updated_at = $5
+			WHERE current_state = $1 AND sport = $3 AND date < $4
+			RETURNING id, sport, date, participant_nber, current_state, score1, score2, creator_id, court_id, disputed, score_deadline, visibility, auto_balance, created_at, updated_at`,
+			models.EnCours, models.ManqueScore, sport, startedBefore, now, now.Add(models.ScoreVoteWindow)); err != nil {
+			return fmt.Errorf("échec de l'expiration des matchs en cours : %w", err)
+		}
+		for _, m := range matches {
+			if err := enqueueForMatchPlayers(ctx, tx, models.OutboxScoreVotePush, m.Id, "", now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// StartScoreVote moves an En cours match to Manque Score, opens its score vote until
+// deadline and queues the vote reminder of its players.
+func (db Database) StartScoreVote(ctx context.Context, matchID string, deadline, now time.Time) (models.DBMatches, error) {
+	var match models.DBMatches
+	err := db.inTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		match, err = lockMatch(ctx, tx, matchID)
+		if err != nil {
+			return err
+		}
+		if match.CurrentState != models.EnCours {
+			return ErrMatchWrongState
+		}
+
+		match.CurrentState = models.ManqueScore
+		match.ScoreDeadline = &deadline
+		match.UpdatedAt = now
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE matches
+			SET current_state = $2, score_deadline = $3, updated_at = $4
+			WHERE id = $1`, match.Id, match.CurrentState, deadline, now); err != nil {
+			return fmt.Errorf("échec de l'ouverture du vote : %w", err)
+		}
+		return enqueueForMatchPlayers(ctx, tx, models.OutboxScoreVotePush, match.Id, "", now)
+	})
+	return match, err
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase_ScheduledMatchTransitions(t *testing.T) {
+	type testCase struct {
+		name          string
+		match         models.DBMatches
+		run           func(ctx context.Context, db Database, now time.Time) ([]models.DBMatches, error)
+		expectedState models.MatchState
+	}
+
+	now := time.Now()
+	court := models.NewDBCourtFixture()
+	creator := models.NewDBUsersFixture()
+	match := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(creator.Id).
+		WithSport(models.Basket)
+
+	cancelExpired := func(ctx context.Context, db Database, now time.Time) ([]models.DBMatches, error) {
+		return db.CancelExpiredMatches(ctx, now)
+	}
+	startDue := func(ctx context.Context, db Database, now time.Time) ([]models.DBMatches, error) {
+		return db.StartDueMatches(ctx, now)
+	}
+	expireBasket := func(ctx context.Context, db Database, now time.Time) ([]models.DBMatches, error) {
+		return db.ExpireStartedMatches(ctx, models.Basket, now.Add(-2*time.Hour), now)
+	}
+
+	testCases := []testCase{
+		{
+			name:          "Manque joueur in the past -> Annule",
+			match:         match.WithCurrentState(models.ManqueJoueur).WithDate(now.Add(-time.Minute)),
+			run:           cancelExpired,
+			expectedState: models.Annule,
+		},
+		{
+			name:          "Manque joueur in the future -> unchanged",
+			match:         match.WithCurrentState(models.ManqueJoueur).WithDate(now.Add(time.Hour)),
+			run:           cancelExpired,
+			expectedState: models.ManqueJoueur,
+		},
+		{
+			name:          "Valide at its date -> En cours",
+			match:         match.WithCurrentState(models.Valide).WithDate(now),
+			run:           startDue,
+			expectedState: models.EnCours,
+		},
+		{
+			name:          "Valide in the future -> unchanged",
+			match:         match.WithCurrentState(models.Valide).WithDate(now.Add(time.Hour)),
+			run:           startDue,
+			expectedState: models.Valide,
+		},
+		{
+			name:          "Valide in the past is not cancelled",
+			match:         match.WithCurrentState(models.Valide).WithDate(now.Add(-time.Hour)),
+			run:           cancelExpired,
+			expectedState: models.Valide,
+		},
+		{
+			name:          "En cours past the sport limit -> Manque Score",
+			match:         match.WithCurrentState(models.EnCours).WithDate(now.Add(-3 * time.Hour)),
+			run:           expireBasket,
+			expectedState: models.ManqueScore,
+		},
+		{
+			name:          "En cours within the sport limit -> unchanged",
+			match:         match.WithCurrentState(models.EnCours).WithDate(now.Add(-time.Hour)),
+			run:           expireBasket,
+			expectedState: models.EnCours,
+		},
+		{
+			name:          "En cours of another sport -> unchanged",
+			match:         match.WithSport(models.Foot).WithCurrentState(models.EnCours).WithDate(now.Add(-3 * time.Hour)),
+			run:           expireBasket,
+			expectedState: models.EnCours,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() {
+				if err := cleanup(); err != nil {
+					t.Logf("cleanup error: %v", err)
+				}
+			}()
+			s.loadFixtures(DBFixtures{
+				Courts:  []models.DBCourt{court},
+				Users:   []models.DBUsers{creator},
+				Matches: []models.DBMatches{c.match},
+			})
+
+			ctx := context.Background()
+			transitioned, err := c.run(ctx, s.db, now)
+			require.NoError(t, err)
+			if c.expectedState == c.match.CurrentState {
+				require.Empty(t, transitioned)
+			} else {
+				require.Len(t, transitioned, 1)
+				require.Equal(t, c.match.Id, transitioned[0].Id)
+				require.Equal(t, c.expectedState, transitioned[0].CurrentState)
+			}
+
+			stored, err := s.db.GetMatchById(ctx, c.match.Id)
+			require.NoError(t, err)
+			require.Equal(t, c.expectedState, stored.CurrentState)
+			require.WithinDuration(t, c.match.Date, stored.Date, time.Millisecond)
+			if c.expectedState == models.ManqueScore {
+				require.NotNil(t, stored.ScoreDeadline)
+				require.WithinDuration(t, now.Add(models.ScoreVoteWindow), *stored.ScoreDeadline, time.Millisecond)
+			}
+		})
+	}
+}
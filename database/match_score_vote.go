@@ -6,14 +6,91 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrNotMatchPlayer   = notFoundError("user is not a player of the match")
+	ErrTeamAlreadyVoted = errors.New("a teammate already voted in this round")
 )
 
+// SubmitScoreVote records the score vote of a player of a Manque Score match in the
+// round currently open. The match row is locked for the whole transaction, so a vote
+// can neither race a teammate's nor land in a round closed meanwhile by a dispute or
+// the vote deadline. consensus is true when the other team voted the same score, in
+// which case the caller finalizes the match. Otherwise the vote becomes the proposed
+// score, and if the other team voted differently in the first round the dispute opens
+// until now + models.DisputeVoteWindow, reported by disputeOpened.
+func (db Database) SubmitScoreVote(ctx context.Context, matchID, userID string, score1, score2 int, now time.Time) (vote models.DBMatchScoreVote, consensus, disputeOpened bool, err error) {
+	err = db.inTx(ctx, func(tx *sqlx.Tx) error {
+		match, err := lockMatch(ctx, tx, matchID)
+		if err != nil {
+			return err
+		}
+		if match.CurrentState != models.ManqueScore {
+			return ErrMatchWrongState
+		}
+
+		var team int
+		if err := tx.GetContext(ctx, &team, `
+			SELECT team FROM user_match WHERE user_id = $1 AND match_id = $2`, userID, matchID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotMatchPlayer
+			}
+			return fmt.Errorf("failed to fetch the team of the voter: %w", err)
+		}
+
+		vote = models.DBMatchScoreVote{
+			MatchId: matchID,
+			UserId:  userID,
+			Team:    team,
+			Score1:  score1,
+			Score2:  score2,
+			Round:   match.VoteRound(),
+		}
+		teammateVoted, err := hasOtherTeamVote(ctx, tx, matchID, vote.Round, team, userID)
+		if err != nil {
+			return err
+		}
+		if teammateVoted {
+			return ErrTeamAlreadyVoted
+		}
+		if err := upsertMatchScoreVote(ctx, tx, vote); err != nil {
+			return fmt.Errorf("failed to upsert score vote: %w", err)
+		}
+
+		consensus, err = hasConsensusScore(ctx, tx, matchID, vote.Round, team, score1, score2)
+		if err != nil || consensus {
+			return err
+		}
+
+		if !match.Disputed {
+			opponentVote, err := getScoreVoteByMatchAndTeam(ctx, tx, matchID, vote.Round, 3-team) // the other team
+			if err != nil {
+				return err
+			}
+			if opponentVote != nil {
+				disputeOpened, err = openScoreDispute(ctx, tx, matchID, now.Add(models.DisputeVoteWindow), now)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return updateProposedScore(ctx, tx, matchID, score1, score2, now)
+	})
+	return vote, consensus, disputeOpened, err
+}
+
 func (db Database) GetMatchScoreVote(ctx context.Context, matchID, userID string) (*models.DBMatchScoreVote, error) {
 	var v models.DBMatchScoreVote
 	err := db.Database.GetContext(ctx, &v, `
-		SELECT match_id, user_id, team, score1, score2, created_at
+		SELECT match_id, user_id, team, score1, score2, round, created_at
 		FROM match_score_vote
 		WHERE match_id = $1 AND user_id = $2
+		ORDER BY round DESC
+		LIMIT 1
 	`, matchID, userID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -25,61 +102,71 @@ func (db Database) GetMatchScoreVote(ctx context.Context, matchID, userID string
 }
 
 func (db Database) UpsertMatchScoreVote(ctx context.Context, matchScoreVote models.DBMatchScoreVote) error {
-	_, err := db.Database.ExecContext(ctx, `
-		INSERT INTO match_score_vote (match_id, user_id, team, score1, score2)
-		VALUES ($1, $2, $3, $4, $5)
-		ON CONFLICT (match_id, user_id)
+	return upsertMatchScoreVote(ctx, db.Database, matchScoreVote)
+}
+
+func upsertMatchScoreVote(ctx context.Context, ext sqlx.ExecerContext, matchScoreVote models.DBMatchScoreVote) error {
+	_, err := ext.ExecContext(ctx, `
+		INSERT INTO match_score_vote (match_id, user_id, team, score1, score2, round)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (match_id, user_id, round)
 		DO UPDATE SET score1 = EXCLUDED.score1,
 					  score2 = EXCLUDED.score2,
 					  team   = EXCLUDED.team,
 					  created_at = NOW();
-	`, matchScoreVote.MatchId, matchScoreVote.UserId, matchScoreVote.Team, matchScoreVote.Score1, matchScoreVote.Score2)
+	`, matchScoreVote.MatchId, matchScoreVote.UserId, matchScoreVote.Team, matchScoreVote.Score1, matchScoreVote.Score2, matchScoreVote.Round)
 	return err
 }
 
-func (db Database) HasConsensusScore(ctx context.Context, matchID string, team, score1, score2 int) (bool, error) {
+func hasConsensusScore(ctx context.Context, q sqlx.QueryerContext, matchID string, round, team, score1, score2 int) (bool, error) {
 	var exists bool
-	err := db.Database.GetContext(ctx, &exists, `
+	err := sqlx.GetContext(ctx, q, &exists, `
 		SELECT EXISTS(
 			SELECT 1
 			FROM match_score_vote
 			WHERE match_id = $1
+			  AND round = $5
 			  AND team <> $2
 			  AND score1 = $3
 			  AND score2 = $4
 		)
-	`, matchID, team, score1, score2)
+	`, matchID, team, score1, score2, round)
 	if err != nil {
 		return false, fmt.Errorf("failed to check consensus score: %w", err)
 	}
 	return exists, nil
 }
 
-func (db Database) HasOtherTeamVote(ctx context.Context, matchID string, team int, userID string) (bool, error) {
+func hasOtherTeamVote(ctx context.Context, q sqlx.QueryerContext, matchID string, round, team int, userID string) (bool, error) {
 	var exists bool
-	err := db.Database.GetContext(ctx, &exists, `
+	err := sqlx.GetContext(ctx, q, &exists, `
 		SELECT EXISTS (
 		  SELECT 1
 		  FROM match_score_vote
 		  WHERE match_id = $1
+		    AND round = $4
 		    AND team = $2
 		    AND user_id <> $3
 		)
-	`, matchID, team, userID)
+	`, matchID, team, userID, round)
 	if err != nil {
 		return false, fmt.Errorf("failed to check team duplicate vote: %w", err)
 	}
 	return exists, nil
 }
 
-func (db Database) GetScoreVoteByMatchAndTeam(ctx context.Context, matchID string, team int) (*models.DBMatchScoreVote, error) {
+func (db Database) GetScoreVoteByMatchAndTeam(ctx context.Context, matchID string, round, team int) (*models.DBMatchScoreVote, error) {
+	return getScoreVoteByMatchAndTeam(ctx, db.Database, matchID, round, team)
+}
+
+func getScoreVoteByMatchAndTeam(ctx context.Context, q sqlx.QueryerContext, matchID string, round, team int) (*models.DBMatchScoreVote, error) {
 	var row models.DBMatchScoreVote
-	err := db.Database.GetContext(ctx, &row, `
-		SELECT match_id, user_id, team, score1, score2, created_at
+	err := sqlx.GetContext(ctx, q, &row, `
+		SELECT match_id, user_id, team, score1, score2, round, created_at
 		FROM match_score_vote
-		WHERE match_id = $1 AND team = $2
+		WHERE match_id = $1 AND round = $3 AND team = $2
 		LIMIT 1
-	`, matchID, team)
+	`, matchID, team, round)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -89,3 +176,16 @@ func (db Database) GetScoreVoteByMatchAndTeam(ctx context.Context, matchID strin
 	}
 	return &row, nil
 }
+
+// updateProposedScore shows the latest proposal on a match still waiting for its
+// score. It never touches a finalized match.
+func updateProposedScore(ctx context.Context, ext sqlx.ExecerContext, matchID string, score1, score2 int, now time.Time) error {
+	_, err := ext.ExecContext(ctx, `
+		UPDATE matches
+		SET score1 = $2, score2 = $3, updated_at = $4
+		WHERE id = $1 AND current_state = 'Manque Score'`, matchID, score1, score2, now)
+	if err != nil {
+		return fmt.Errorf("failed to update proposed score: %w", err)
+	}
+	return nil
+}
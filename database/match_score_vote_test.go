@@ -4,6 +4,7 @@ import (
 	"PLIC/models"
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
@@ -126,6 +127,148 @@ func TestDatabase_GetMatchScoreVote(t *testing.T) {
 	}
 }
 
+func TestDatabase_SubmitScoreVote(t *testing.T) {
+	type expected struct {
+		err           error
+		round         int
+		consensus     bool
+		disputeOpened bool
+		proposed      *[2]int
+	}
+
+	type testCase struct {
+		name     string
+		match    models.DBMatches
+		votes    []models.DBMatchScoreVote
+		voter    string
+		expected expected
+	}
+
+	now := time.Now()
+	court := models.NewDBCourtFixture()
+	p1 := models.NewDBUsersFixture().WithUsername("p1").WithEmail("p1@test.com")
+	p2 := models.NewDBUsersFixture().WithUsername("p2").WithEmail("p2@test.com")
+	p3 := models.NewDBUsersFixture().WithUsername("p3").WithEmail("p3@test.com")
+	outsider := models.NewDBUsersFixture().WithUsername("outsider").WithEmail("outsider@test.com")
+
+	match := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(p1.Id).
+		WithParticipantNber(4).
+		WithCurrentState(models.ManqueScore).
+		WithScoreDeadline(now.Add(time.Hour))
+
+	vote := func(userID string, team, score1, score2, round int) models.DBMatchScoreVote {
+		return models.DBMatchScoreVote{MatchId: match.Id, UserId: userID, Team: team, Score1: score1, Score2: score2, Round: round}
+	}
+
+	testCases := []testCase{
+		{
+			name:     "First vote -> proposed score",
+			match:    match,
+			voter:    p1.Id,
+			expected: expected{proposed: &[2]int{3, 2}},
+		},
+		{
+			name:     "Same score as the other team -> consensus",
+			match:    match,
+			votes:    []models.DBMatchScoreVote{vote(p3.Id, 2, 3, 2, 0)},
+			voter:    p1.Id,
+			expected: expected{consensus: true},
+		},
+		{
+			name:     "Other score than the other team -> dispute opened",
+			match:    match,
+			votes:    []models.DBMatchScoreVote{vote(p3.Id, 2, 1, 0, 0)},
+			voter:    p1.Id,
+			expected: expected{disputeOpened: true, proposed: &[2]int{3, 2}},
+		},
+		{
+			name:     "Disputed match -> vote in the re-vote round",
+			match:    match.WithDisputed(now.Add(time.Hour)),
+			votes:    []models.DBMatchScoreVote{vote(p1.Id, 1, 3, 2, 0), vote(p3.Id, 2, 1, 0, 0)},
+			voter:    p1.Id,
+			expected: expected{round: 1, proposed: &[2]int{3, 2}},
+		},
+		{
+			name:     "Teammate already voted",
+			match:    match,
+			votes:    []models.DBMatchScoreVote{vote(p2.Id, 1, 1, 0, 0)},
+			voter:    p1.Id,
+			expected: expected{err: ErrTeamAlreadyVoted},
+		},
+		{
+			name:     "Not a player of the match",
+			match:    match,
+			voter:    outsider.Id,
+			expected: expected{err: ErrNotMatchPlayer},
+		},
+		{
+			name:     "Match no longer waiting for its score",
+			match:    match.WithCurrentState(models.Annule),
+			voter:    p1.Id,
+			expected: expected{err: ErrMatchWrongState},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() { _ = cleanup() }()
+			s.loadFixtures(DBFixtures{
+				Courts:  []models.DBCourt{court},
+				Users:   []models.DBUsers{p1, p2, p3, outsider},
+				Matches: []models.DBMatches{c.match},
+				UserMatches: []models.DBUserMatch{
+					models.NewDBUserMatchFixture().WithUserId(p1.Id).WithMatchId(match.Id).WithTeam(1),
+					models.NewDBUserMatchFixture().WithUserId(p2.Id).WithMatchId(match.Id).WithTeam(1),
+					models.NewDBUserMatchFixture().WithUserId(p3.Id).WithMatchId(match.Id).WithTeam(2),
+				},
+			})
+
+			ctx := context.Background()
+			for _, v := range c.votes {
+				require.NoError(t, s.db.UpsertMatchScoreVote(ctx, v))
+			}
+
+			got, consensus, disputeOpened, err := s.db.SubmitScoreVote(ctx, match.Id, c.voter, 3, 2, now)
+			if c.expected.err != nil {
+				require.ErrorIs(t, err, c.expected.err)
+				stored, err := s.db.GetMatchScoreVote(ctx, match.Id, c.voter)
+				require.NoError(t, err)
+				if stored != nil {
+					require.NotEqual(t, [2]int{3, 2}, [2]int{stored.Score1, stored.Score2}, "a refused vote must not be stored")
+				}
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected.round, got.Round)
+			require.Equal(t, c.expected.consensus, consensus)
+			require.Equal(t, c.expected.disputeOpened, disputeOpened)
+
+			stored, err := s.db.GetMatchScoreVote(ctx, match.Id, c.voter)
+			require.NoError(t, err)
+			require.NotNil(t, stored)
+			require.Equal(t, c.expected.round, stored.Round)
+
+			m, err := s.db.GetMatchById(ctx, match.Id)
+			require.NoError(t, err)
+			require.Equal(t, models.ManqueScore, m.CurrentState)
+			if c.expected.disputeOpened {
+				require.True(t, m.Disputed)
+				require.NotNil(t, m.ScoreDeadline)
+				require.WithinDuration(t, now.Add(models.DisputeVoteWindow), *m.ScoreDeadline, time.Millisecond)
+			}
+			if c.expected.proposed != nil {
+				require.NotNil(t, m.Score1)
+				require.NotNil(t, m.Score2)
+				require.Equal(t, *c.expected.proposed, [2]int{*m.Score1, *m.Score2})
+			}
+		})
+	}
+}
+
 func TestDatabase_UpsertMatchScoreVote(t *testing.T) {
 	type expected struct {
 		team        int
@@ -278,7 +421,7 @@ func TestDatabase_UpsertMatchScoreVote(t *testing.T) {
 	}
 }
 
-func TestDatabase_HasConsensusScore(t *testing.T) {
+func Test_hasConsensusScore(t *testing.T) {
 	type expected struct {
 		consensus bool
 	}
@@ -389,14 +532,14 @@ func TestDatabase_HasConsensusScore(t *testing.T) {
 				require.NoError(t, err)
 			}
 
-			got, err := s.db.HasConsensusScore(ctx, tc.callArgs.matchID, tc.callArgs.team, tc.callArgs.s1, tc.callArgs.s2)
+			got, err := hasConsensusScore(ctx, s.db.Database, tc.callArgs.matchID, 0, tc.callArgs.team, tc.callArgs.s1, tc.callArgs.s2)
 			require.NoError(t, err)
 			require.Equal(t, tc.want.consensus, got)
 		})
 	}
 }
 
-func TestDatabase_HasOtherTeamVote(t *testing.T) {
+func Test_hasOtherTeamVote(t *testing.T) {
 	type expected struct {
 		hasOther bool
 	}
@@ -507,7 +650,7 @@ func TestDatabase_HasOtherTeamVote(t *testing.T) {
 				require.NoError(t, err)
 			}
 
-			got, err := s.db.HasOtherTeamVote(ctx, tc.callArgs.matchID, tc.callArgs.team, tc.callArgs.userID)
+			got, err := hasOtherTeamVote(ctx, s.db.Database, tc.callArgs.matchID, 0, tc.callArgs.team, tc.callArgs.userID)
 			require.NoError(t, err)
 			require.Equal(t, tc.want.hasOther, got)
 		})
@@ -643,7 +786,7 @@ func TestDatabase_GetScoreVoteByMatchAndTeam(t *testing.T) {
 			}
 
 			ctx := context.Background()
-			got, err := s.db.GetScoreVoteByMatchAndTeam(ctx, tc.param.matchID, tc.param.team)
+			got, err := s.db.GetScoreVoteByMatchAndTeam(ctx, tc.param.matchID, 0, tc.param.team)
 			require.NoError(t, err)
 
 			if !tc.expected.found {
@@ -0,0 +1,211 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMatchSearchQuery(t *testing.T) {
+	sport := models.Basket
+	court := "court-id"
+	needed := 2
+	eloRange := 150
+	now := time.Date(2025, 6, 1, 18, 0, 0, 0, time.UTC)
+
+	t.Run("No filter", func(t *testing.T) {
+		query, args := buildMatchSearchQuery(models.MatchSearchQuery{
+			ViewerID:   "viewer",
+			DefaultElo: 1000,
+			Sort:       models.MatchSortDate,
+			Limit:      20,
+		})
+		require.Equal(t, []interface{}{"viewer", 1000, 21}, args)
+		require.Contains(t, query, "ORDER BY date, id")
+		require.Contains(t, query, "NULL::double precision AS distance_m")
+		require.Contains(t, query, "LIMIT $3")
+	})
+
+	t.Run("Every filter and a distance cursor", func(t *testing.T) {
+		query, args := buildMatchSearchQuery(models.MatchSearchQuery{
+			ViewerID:         "viewer",
+			DefaultElo:       1000,
+			Sport:            &sport,
+			States:           []models.MatchState{models.ManqueJoueur, models.Valide},
+			From:             &now,
+			CourtID:          &court,
+			Near:             &models.GeoFilter{Latitude: 48.85, Longitude: 2.35, RadiusMeters: 3000},
+			MinPlayersNeeded: &needed,
+			EloRange:         &eloRange,
+			Sort:             models.MatchSortDistance,
+			Cursor:           &models.MatchSearchCursor{Sort: models.MatchSortDistance, DistanceMeters: 120.5, Id: "last"},
+			Limit:            10,
+		})
+
+		require.Contains(t, query, "m.sport = $3::sport")
+		require.Contains(t, query, "m.current_state = ANY($4::etat_match[])")
+		require.Contains(t, query, "m.date >= $5")
+		require.Contains(t, query, "m.court_id = $6")
+		require.Contains(t, query, "distance_m <= ")
+		require.Contains(t, query, "participant_nber - players_count >= ")
+		require.Contains(t, query, "ABS(COALESCE(average_elo, viewer_elo) - viewer_elo) <= ")
+		require.Contains(t, query, "(distance_m, id) > (")
+		require.Contains(t, query, "ORDER BY distance_m, id")
+		require.NotContains(t, query, "m.date < ")
+
+		require.Equal(t, "basket", args[2])
+		require.Equal(t, []string{"Manque joueur", "Valide"}, args[3])
+		require.Equal(t, 11, args[len(args)-1])
+		require.True(t, strings.HasSuffix(strings.TrimSpace(query), fmt.Sprintf("LIMIT $%d", len(args))))
+	})
+}
+
+func TestDatabase_SearchMatches(t *testing.T) {
+	viewer := models.NewDBUsersFixture().WithUsername("viewer").WithEmail("viewer@test.com")
+	strong := models.NewDBUsersFixture().WithUsername("strong").WithEmail("strong@test.com")
+
+	paris := models.NewDBCourtFixture().WithLatitude(48.8566).WithLongitude(2.3522)
+	lyon := models.NewDBCourtFixture().WithLatitude(45.7640).WithLongitude(4.8357)
+
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	match := func(court models.DBCourt, sport models.Sport, at time.Time) models.DBMatches {
+		return models.NewDBMatchesFixture().
+			WithCourtId(court.Id).
+			WithCreatorId(viewer.Id).
+			WithSport(sport).
+			WithParticipantNber(2).
+			WithDate(at)
+	}
+
+	parisFoot1 := match(paris, models.Foot, start)
+	parisFoot2 := match(paris, models.Foot, start.Add(time.Hour))
+	parisBasket := match(paris, models.Basket, start.Add(2*time.Hour))
+	lyonFoot := match(lyon, models.Foot, start.Add(3*time.Hour))
+	parisFull := match(paris, models.Foot, start.Add(4*time.Hour))
+	strongMatch := match(paris, models.Foot, start.Add(5*time.Hour)).WithCreatorId(strong.Id)
+	past := match(paris, models.Foot, start.Add(-48*time.Hour)).WithCurrentState(models.Termine)
+
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() {
+		if err := cleanup(); err != nil {
+			t.Logf("cleanup error: %v", err)
+		}
+	}()
+	s.loadFixtures(DBFixtures{
+		Users:   []models.DBUsers{viewer, strong},
+		Courts:  []models.DBCourt{paris, lyon},
+		Matches: []models.DBMatches{parisFoot1, parisFoot2, parisBasket, lyonFoot, parisFull, strongMatch, past},
+		UserMatches: []models.DBUserMatch{
+			models.NewDBUserMatchFixture().WithUserId(viewer.Id).WithMatchId(parisFull.Id).WithTeam(1),
+			models.NewDBUserMatchFixture().WithUserId(strong.Id).WithMatchId(parisFull.Id).WithTeam(2),
+			models.NewDBUserMatchFixture().WithUserId(strong.Id).WithMatchId(strongMatch.Id).WithTeam(1),
+		},
+		Rankings: []models.DBRanking{
+			models.NewDBRankingFixture().
+				WithUserId(strong.Id).
+				WithCourtId(paris.Id).
+				WithSport(models.Foot).
+				WithElo(1600),
+		},
+	})
+
+	ctx := context.Background()
+	ids := func(results []models.DBMatchSearchResult) []string {
+		out := make([]string, 0, len(results))
+		for _, r := range results {
+			out = append(out, r.Id)
+		}
+		return out
+	}
+	base := models.MatchSearchQuery{
+		ViewerID:   viewer.Id,
+		DefaultElo: 1000,
+		States:     []models.MatchState{models.ManqueJoueur},
+		Sort:       models.MatchSortDate,
+		Limit:      20,
+	}
+
+	t.Run("Date order with cursor pagination", func(t *testing.T) {
+		q := base
+		q.Limit = 2
+		page, next, err := s.db.SearchMatches(ctx, q)
+		require.NoError(t, err)
+		require.Equal(t, []string{parisFoot1.Id, parisFoot2.Id}, ids(page))
+		require.NotNil(t, next)
+
+		q.Cursor = next
+		page, next, err = s.db.SearchMatches(ctx, q)
+		require.NoError(t, err)
+		require.Equal(t, []string{parisBasket.Id, lyonFoot.Id}, ids(page))
+		require.NotNil(t, next)
+
+		q.Cursor = next
+		page, next, err = s.db.SearchMatches(ctx, q)
+		require.NoError(t, err)
+		require.Equal(t, []string{parisFull.Id, strongMatch.Id}, ids(page))
+		require.Nil(t, next)
+	})
+
+	t.Run("Sport and free seats", func(t *testing.T) {
+		q := base
+		sport := models.Foot
+		needed := 2
+		q.Sport = &sport
+		q.MinPlayersNeeded = &needed
+		page, _, err := s.db.SearchMatches(ctx, q)
+		require.NoError(t, err)
+		require.Equal(t, []string{parisFoot1.Id, parisFoot2.Id, lyonFoot.Id}, ids(page))
+	})
+
+	t.Run("Nearby sorted by distance", func(t *testing.T) {
+		q := base
+		q.Near = &models.GeoFilter{Latitude: 48.86, Longitude: 2.35, RadiusMeters: 5000}
+		q.Sort = models.MatchSortDistance
+		q.Limit = 3
+		page, next, err := s.db.SearchMatches(ctx, q)
+		require.NoError(t, err)
+		require.Len(t, page, 3)
+		require.NotNil(t, next)
+		for _, r := range page {
+			require.NotNil(t, r.DistanceMeters)
+			require.Less(t, *r.DistanceMeters, 5000.0)
+		}
+
+		q.Cursor = next
+		rest, next, err := s.db.SearchMatches(ctx, q)
+		require.NoError(t, err)
+		require.Nil(t, next)
+		require.ElementsMatch(t,
+			[]string{parisFoot1.Id, parisFoot2.Id, parisBasket.Id, parisFull.Id, strongMatch.Id},
+			append(ids(page), ids(rest)...))
+	})
+
+	t.Run("Elo range", func(t *testing.T) {
+		q := base
+		eloRange := 200
+		q.EloRange = &eloRange
+		page, _, err := s.db.SearchMatches(ctx, q)
+		require.NoError(t, err)
+		require.NotContains(t, ids(page), strongMatch.Id)
+		require.NotContains(t, ids(page), parisFull.Id)
+		require.Contains(t, ids(page), parisFoot1.Id)
+	})
+
+	t.Run("Date range and state", func(t *testing.T) {
+		q := base
+		from := start.Add(-72 * time.Hour)
+		to := start
+		q.From = &from
+		q.To = &to
+		q.States = []models.MatchState{models.Termine}
+		page, _, err := s.db.SearchMatches(ctx, q)
+		require.NoError(t, err)
+		require.Equal(t, []string{past.Id}, ids(page))
+	})
+}
@@ -180,7 +180,7 @@ func TestDatabase_GetAllMatches(t *testing.T) {
 			}()
 			s.loadFixtures(c.fixtures)
 
-			matches, err := s.db.GetAllMatches(ctx)
+			matches, err := s.db.GetAllMatches(ctx, user.Id)
 			require.NoError(t, err)
 
 			require.Equal(t, len(matches), len(c.expected))
@@ -442,9 +442,11 @@ func Test_GetMatchesByCourtId(t *testing.T) {
 		name     string
 		fixtures DBFixtures
 		param    string
+		viewer   string
 		expected expected
 	}
 	user := models.NewDBUsersFixture()
+	stranger := models.NewDBUsersFixture().WithUsername("stranger").WithEmail("stranger@test.com")
 
 	court1 := models.NewDBCourtFixture()
 	court2 := models.NewDBCourtFixture()
@@ -455,6 +457,10 @@ func Test_GetMatchesByCourtId(t *testing.T) {
 	match2 := models.NewDBMatchesFixture().
 		WithCourtId(court2.Id).
 		WithCreatorId(user.Id)
+	friendsOnly := models.NewDBMatchesFixture().
+		WithCourtId(court1.Id).
+		WithCreatorId(user.Id).
+		WithVisibility(models.VisibilityFriends)
 
 	testCases := []testCase{
 		{
@@ -464,7 +470,8 @@ func Test_GetMatchesByCourtId(t *testing.T) {
 				Courts:  []models.DBCourt{court1, court2},
 				Matches: []models.DBMatches{match1, match2},
 			},
-			param: court1.Id,
+			param:  court1.Id,
+			viewer: user.Id,
 			expected: expected{
 				found:   true,
 				len:     1,
@@ -478,7 +485,8 @@ func Test_GetMatchesByCourtId(t *testing.T) {
 				Courts:  []models.DBCourt{court1, court2},
 				Matches: []models.DBMatches{match1, match2},
 			},
-			param: court2.Id,
+			param:  court2.Id,
+			viewer: user.Id,
 			expected: expected{
 				found:   true,
 				len:     1,
@@ -492,7 +500,8 @@ func Test_GetMatchesByCourtId(t *testing.T) {
 				Courts:  []models.DBCourt{court1, court2},
 				Matches: []models.DBMatches{match1, match2},
 			},
-			param: uuid.NewString(),
+			param:  uuid.NewString(),
+			viewer: user.Id,
 			expected: expected{
 				found:   false,
 				len:     0,
@@ -505,13 +514,44 @@ func Test_GetMatchesByCourtId(t *testing.T) {
 				Courts:  []models.DBCourt{},
 				Matches: []models.DBMatches{},
 			},
-			param: court1.Id,
+			param:  court1.Id,
+			viewer: user.Id,
 			expected: expected{
 				found:   false,
 				len:     0,
 				isError: false,
 			},
 		},
+		{
+			name: "Friends-only match hidden from a stranger",
+			fixtures: DBFixtures{
+				Users:   []models.DBUsers{user, stranger},
+				Courts:  []models.DBCourt{court1},
+				Matches: []models.DBMatches{match1, friendsOnly},
+			},
+			param:  court1.Id,
+			viewer: stranger.Id,
+			expected: expected{
+				found:   true,
+				len:     1,
+				isError: false,
+			},
+		},
+		{
+			name: "Friends-only match shown to its creator",
+			fixtures: DBFixtures{
+				Users:   []models.DBUsers{user, stranger},
+				Courts:  []models.DBCourt{court1},
+				Matches: []models.DBMatches{match1, friendsOnly},
+			},
+			param:  court1.Id,
+			viewer: user.Id,
+			expected: expected{
+				found:   true,
+				len:     2,
+				isError: false,
+			},
+		},
 	}
 
 	for _, c := range testCases {
@@ -526,7 +566,7 @@ func Test_GetMatchesByCourtId(t *testing.T) {
 			s.loadFixtures(c.fixtures)
 
 			ctx := context.Background()
-			res, err := s.db.GetMatchesByCourtId(ctx, c.param)
+			res, err := s.db.GetMatchesByCourtId(ctx, c.param, c.viewer)
 
 			if c.expected.isError {
 				require.Error(t, err)
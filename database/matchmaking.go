@@ -0,0 +1,269 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrAlreadyQueued        = conflictError("user is already waiting in the queue for this sport")
+	ErrQueueEntryNotFound   = notFoundError("queue entry not found")
+	ErrQueueEntryNotWaiting = conflictError("queue entry is not waiting anymore")
+	ErrQueueEntryTaken      = conflictError("queue entry was matched or left meanwhile")
+)
+
+// JoinQueue puts the player in the matchmaking queue. A player waits at most once per
+// sport.
+func (db Database) JoinQueue(ctx context.Context, entry models.DBQueueEntry) error {
+	return db.inTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := tx.NamedExecContext(ctx, `
+			INSERT INTO matchmaking_queue (id, user_id, sport, team_size, latitude, longitude, radius_m, window_start, window_end, status, match_id, created_at, updated_at)
+			VALUES (:id, :user_id, :sport, :team_size, :latitude, :longitude, :radius_m, :window_start, :window_end, :status, :match_id, :created_at, :updated_at)`,
+			entry); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return ErrAlreadyQueued
+			}
+			return fmt.Errorf("failed to insert queue entry: %w", err)
+		}
+
+		if len(entry.CourtIDs) > 0 {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO matchmaking_queue_courts (entry_id, court_id)
+				SELECT $1, UNNEST($2::text[])
+				ON CONFLICT DO NOTHING`,
+				entry.Id, entry.CourtIDs); err != nil {
+				return fmt.Errorf("failed to insert queue entry courts: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetQueueEntry returns the entry with its preferred courts, nil if there is none.
+func (db Database) GetQueueEntry(ctx context.Context, id string) (*models.DBQueueEntry, error) {
+	var entry models.DBQueueEntry
+	err := db.Database.GetContext(ctx, &entry, `
+		SELECT id, user_id, sport, team_size, latitude, longitude, radius_m, window_start, window_end, status, match_id, created_at, updated_at
+		FROM matchmaking_queue
+		WHERE id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch queue entry: %w", err)
+	}
+
+	entries := []models.DBQueueEntry{entry}
+	if err := db.loadQueueEntryCourts(ctx, entries); err != nil {
+		return nil, err
+	}
+	return &entries[0], nil
+}
+
+// GetUserQueueEntries returns the entries of the user, newest first.
+func (db Database) GetUserQueueEntries(ctx context.Context, userID string) ([]models.DBQueueEntry, error) {
+	var entries []models.DBQueueEntry
+	if err := db.Database.SelectContext(ctx, &entries, `
+		SELECT id, user_id, sport, team_size, latitude, longitude, radius_m, window_start, window_end, status, match_id, created_at, updated_at
+		FROM matchmaking_queue
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id`, userID); err != nil {
+		return nil, fmt.Errorf("failed to fetch queue entries: %w", err)
+	}
+	if err := db.loadQueueEntryCourts(ctx, entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (db Database) loadQueueEntryCourts(ctx context.Context, entries []models.DBQueueEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	ids := make([]string, len(entries))
+	for i, e := range entries {
+		ids[i] = e.Id
+	}
+
+	var rows []struct {
+		EntryID string `db:"entry_id"`
+		CourtID string `db:"court_id"`
+	}
+	if err := db.Database.SelectContext(ctx, &rows, `
+		SELECT entry_id, court_id
+		FROM matchmaking_queue_courts
+		WHERE entry_id = ANY($1)
+		ORDER BY entry_id, court_id`, ids); err != nil {
+		return fmt.Errorf("failed to fetch queue entry courts: %w", err)
+	}
+
+	courts := make(map[string][]string, len(entries))
+	for _, r := range rows {
+		courts[r.EntryID] = append(courts[r.EntryID], r.CourtID)
+	}
+	for i := range entries {
+		entries[i].CourtIDs = courts[entries[i].Id]
+	}
+	return nil
+}
+
+// CancelQueueEntry takes a waiting entry of the user out of the queue.
+func (db Database) CancelQueueEntry(ctx context.Context, userID, entryID string, now time.Time) error {
+	return db.inTx(ctx, func(tx *sqlx.Tx) error {
+		var status models.QueueStatus
+		err := tx.GetContext(ctx, &status, `
+			SELECT status
+			FROM matchmaking_queue
+			WHERE id = $1 AND user_id = $2
+			FOR UPDATE`, entryID, userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrQueueEntryNotFound
+			}
+			return fmt.Errorf("failed to lock queue entry: %w", err)
+		}
+		if status != models.QueueWaiting {
+			return ErrQueueEntryNotWaiting
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE matchmaking_queue
+			SET status = 'cancelled', updated_at = $2
+			WHERE id = $1`, entryID, now); err != nil {
+			return fmt.Errorf("failed to cancel queue entry: %w", err)
+		}
+		return nil
+	})
+}
+
+// ExpireQueueEntries marks as expired the waiting entries whose window ends before
+// `before`: no match can be scheduled for them anymore. It returns how many expired.
+func (db Database) ExpireQueueEntries(ctx context.Context, before, now time.Time) (int, error) {
+	res, err := db.Database.ExecContext(ctx, `
+		UPDATE matchmaking_queue
+		SET status = 'expired', updated_at = $2
+		WHERE status = 'waiting' AND window_end < $1`, before, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire queue entries: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire queue entries: %w", err)
+	}
+	return int(n), nil
+}
+
+// GetMatchmakingCandidates returns the waiting entries of a sport, once per court the
+// player accepts: their preferred courts, at distance 0, and the courts within their
+// search area. The rating of a player is the average of their court ratings in the
+// sport weighted by the games played, defaultElo when they never played it.
+func (db Database) GetMatchmakingCandidates(ctx context.Context, sport models.Sport, defaultElo int) ([]models.DBMatchmakingCandidate, error) {
+	var candidates []models.DBMatchmakingCandidate
+	err := db.Database.SelectContext(ctx, &candidates, `
+		WITH entries AS (
+			SELECT id, user_id, team_size, latitude, longitude, radius_m, window_start, window_end, created_at
+			FROM matchmaking_queue
+			WHERE sport = $1::sport AND status = 'waiting'
+		),
+		ratings AS (
+			SELECT r.user_id,
+			       ROUND(SUM(r.elo * r.games_played)::numeric / SUM(r.games_played))::int AS elo
+			FROM ranking r
+			WHERE r.sport = $1::sport
+			  AND r.games_played > 0
+			  AND r.user_id IN (SELECT user_id FROM entries)
+			GROUP BY r.user_id
+		),
+		nearby AS (
+			SELECT e.id AS entry_id, e.radius_m, c.id AS court_id,
+				2 * $3::double precision * ASIN(LEAST(1, SQRT(
+					POWER(SIN(RADIANS(c.latitude - e.latitude) / 2), 2) +
+					COS(RADIANS(e.latitude)) * COS(RADIANS(c.latitude)) *
+					POWER(SIN(RADIANS(c.longitude - e.longitude) / 2), 2)
+				))) AS distance_m
+			FROM entries e
+			JOIN courts c
+			  ON c.latitude BETWEEN e.latitude - e.radius_m / $4::double precision
+			                    AND e.latitude + e.radius_m / $4::double precision
+			WHERE e.latitude IS NOT NULL
+		),
+		accepted AS (
+			SELECT qc.entry_id, qc.court_id, 0::double precision AS distance_m
+			FROM matchmaking_queue_courts qc
+			JOIN entries e ON e.id = qc.entry_id
+			UNION ALL
+			SELECT entry_id, court_id, distance_m
+			FROM nearby
+			WHERE distance_m <= radius_m
+		)
+		SELECT e.id AS entry_id, e.user_id, e.team_size, e.window_start, e.window_end, e.created_at,
+		       COALESCE(ra.elo, $2) AS elo, a.court_id, MIN(a.distance_m) AS distance_m
+		FROM entries e
+		JOIN accepted a ON a.entry_id = e.id
+		LEFT JOIN ratings ra ON ra.user_id = e.user_id
+		GROUP BY e.id, e.user_id, e.team_size, e.window_start, e.window_end, e.created_at, ra.elo, a.court_id
+		ORDER BY e.created_at, e.id, a.court_id`,
+		string(sport), defaultElo, earthRadiusMeters, metersPerDegreeLatitude)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch matchmaking candidates: %w", err)
+	}
+	return candidates, nil
+}
+
+// CreateMatchFromQueue creates the match found for the entries, already full and
+// Valide, with its players, and marks the entries as matched. Players who never
+// played the sport on the court get their starting ranking from newRanking, and every
+// player's email is queued in the outbox. The
+// entries are locked first: when one was cancelled or matched meanwhile, nothing is
+// written and ErrQueueEntryTaken is returned.
+func (db Database) CreateMatchFromQueue(ctx context.Context, match models.DBMatches, players []models.DBUserMatch, entryIDs []string, newRanking NewRankingFunc, now time.Time) error {
+	return db.inTx(ctx, func(tx *sqlx.Tx) error {
+		var waiting int
+		if err := tx.GetContext(ctx, &waiting, `
+			WITH locked AS (
+				SELECT id
+				FROM matchmaking_queue
+				WHERE id = ANY($1) AND status = 'waiting'
+				FOR UPDATE
+			)
+			SELECT COUNT(*) FROM locked`, entryIDs); err != nil {
+			return fmt.Errorf("failed to lock queue entries: %w", err)
+		}
+		if waiting != len(entryIDs) {
+			return ErrQueueEntryTaken
+		}
+
+		if err := createMatch(ctx, tx, match); err != nil {
+			return err
+		}
+		for _, um := range players {
+			if err := createUserMatch(ctx, tx, um); err != nil {
+				return fmt.Errorf("échec de l'insertion de user_match : %w", err)
+			}
+			if err := insertRankingIfMissing(ctx, tx, newRanking(um.UserID, match.CourtID, match.Sport, now)); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE matchmaking_queue
+			SET status = 'matched', match_id = $2, updated_at = $3
+			WHERE id = ANY($1)`, entryIDs, match.Id, now); err != nil {
+			return fmt.Errorf("failed to mark queue entries as matched: %w", err)
+		}
+
+		recipients := make([]models.MatchRecipientPayload, 0, len(players))
+		for _, um := range players {
+			recipients = append(recipients, models.MatchRecipientPayload{MatchID: match.Id, UserID: um.UserID})
+		}
+		return enqueueMatchEmails(ctx, tx, models.OutboxMatchFoundEmail, recipients, now)
+	})
+}
@@ -0,0 +1,229 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase_JoinQueue(t *testing.T) {
+	court := models.NewDBCourtFixture()
+	user := models.NewDBUsersFixture()
+	entry := models.NewDBQueueEntryFixture().WithUserId(user.Id).WithCourtIds(court.Id)
+
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() {
+		if err := cleanup(); err != nil {
+			t.Logf("cleanup error: %v", err)
+		}
+	}()
+	s.loadFixtures(DBFixtures{
+		Courts:       []models.DBCourt{court},
+		Users:        []models.DBUsers{user},
+		QueueEntries: []models.DBQueueEntry{entry},
+	})
+
+	ctx := context.Background()
+
+	got, err := s.db.GetQueueEntry(ctx, entry.Id)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Equal(t, []string{court.Id}, got.CourtIDs)
+	require.Equal(t, models.QueueWaiting, got.Status)
+
+	second := models.NewDBQueueEntryFixture().WithUserId(user.Id).WithCourtIds(court.Id)
+	require.ErrorIs(t, s.db.JoinQueue(ctx, second), ErrAlreadyQueued)
+
+	other := models.NewDBQueueEntryFixture().WithUserId(user.Id).WithSport(models.Foot).WithCourtIds(court.Id)
+	require.NoError(t, s.db.JoinQueue(ctx, other))
+
+	require.ErrorIs(t, s.db.CancelQueueEntry(ctx, uuid.NewString(), entry.Id, time.Now()), ErrQueueEntryNotFound)
+	require.NoError(t, s.db.CancelQueueEntry(ctx, user.Id, entry.Id, time.Now()))
+	require.ErrorIs(t, s.db.CancelQueueEntry(ctx, user.Id, entry.Id, time.Now()), ErrQueueEntryNotWaiting)
+
+	// Once the waiting entry is cancelled, the player can queue again for the sport.
+	require.NoError(t, s.db.JoinQueue(ctx, second))
+
+	entries, err := s.db.GetUserQueueEntries(ctx, user.Id)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+}
+
+func TestDatabase_GetMatchmakingCandidates(t *testing.T) {
+	near := models.NewDBCourtFixture().WithLatitude(48.8566).WithLongitude(2.3522)
+	nextDoor := models.NewDBCourtFixture().WithLatitude(48.8600).WithLongitude(2.3522)
+	far := models.NewDBCourtFixture().WithLatitude(45.7640).WithLongitude(4.8357)
+
+	ranked := models.NewDBUsersFixture().WithUsername("ranked").WithEmail("ranked@test.com")
+	newcomer := models.NewDBUsersFixture().WithUsername("newcomer").WithEmail("newcomer@test.com")
+	cancelled := models.NewDBUsersFixture().WithUsername("cancelled").WithEmail("cancelled@test.com")
+
+	rankedEntry := models.NewDBQueueEntryFixture().
+		WithUserId(ranked.Id).
+		WithCourtIds(far.Id).
+		WithArea(48.8566, 2.3522, 1000)
+	newcomerEntry := models.NewDBQueueEntryFixture().
+		WithUserId(newcomer.Id).
+		WithCourtIds(near.Id)
+	cancelledEntry := models.NewDBQueueEntryFixture().
+		WithUserId(cancelled.Id).
+		WithCourtIds(near.Id).
+		WithStatus(models.QueueCancelled)
+
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() {
+		if err := cleanup(); err != nil {
+			t.Logf("cleanup error: %v", err)
+		}
+	}()
+	s.loadFixtures(DBFixtures{
+		Courts: []models.DBCourt{near, nextDoor, far},
+		Users:  []models.DBUsers{ranked, newcomer, cancelled},
+		Rankings: []models.DBRanking{
+			models.NewDBRankingFixture().WithUserId(ranked.Id).WithCourtId(near.Id).WithSport(models.PingPong).WithElo(1200).WithGamesPlayed(3),
+			models.NewDBRankingFixture().WithUserId(ranked.Id).WithCourtId(far.Id).WithSport(models.PingPong).WithElo(1100).WithGamesPlayed(1),
+			models.NewDBRankingFixture().WithUserId(ranked.Id).WithCourtId(near.Id).WithSport(models.Foot).WithElo(1500).WithGamesPlayed(10),
+		},
+		QueueEntries: []models.DBQueueEntry{rankedEntry, newcomerEntry, cancelledEntry},
+	})
+
+	candidates, err := s.db.GetMatchmakingCandidates(context.Background(), models.PingPong, 1000)
+	require.NoError(t, err)
+
+	courts := map[string]map[string]float64{}
+	for _, c := range candidates {
+		if courts[c.UserID] == nil {
+			courts[c.UserID] = map[string]float64{}
+		}
+		courts[c.UserID][c.CourtID] = c.DistanceMeters
+		switch c.UserID {
+		case ranked.Id:
+			require.Equal(t, 1175, c.Elo)
+		case newcomer.Id:
+			require.Equal(t, 1000, c.Elo)
+		}
+	}
+
+	require.Len(t, courts, 2)
+	require.Len(t, courts[ranked.Id], 3)
+	require.Zero(t, courts[ranked.Id][near.Id])
+	require.Zero(t, courts[ranked.Id][far.Id])
+	require.InDelta(t, 378, courts[ranked.Id][nextDoor.Id], 5)
+	require.Equal(t, map[string]float64{near.Id: 0}, courts[newcomer.Id])
+}
+
+func TestDatabase_CreateMatchFromQueue(t *testing.T) {
+	court := models.NewDBCourtFixture()
+	user1 := models.NewDBUsersFixture().WithUsername("user1").WithEmail("user1@test.com")
+	user2 := models.NewDBUsersFixture().WithUsername("user2").WithEmail("user2@test.com")
+	entry1 := models.NewDBQueueEntryFixture().WithUserId(user1.Id).WithCourtIds(court.Id)
+	entry2 := models.NewDBQueueEntryFixture().WithUserId(user2.Id).WithCourtIds(court.Id)
+
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() {
+		if err := cleanup(); err != nil {
+			t.Logf("cleanup error: %v", err)
+		}
+	}()
+	s.loadFixtures(DBFixtures{
+		Courts:       []models.DBCourt{court},
+		Users:        []models.DBUsers{user1, user2},
+		Rankings:     []models.DBRanking{models.NewDBRankingFixture().WithUserId(user1.Id).WithCourtId(court.Id).WithSport(models.PingPong).WithElo(1300)},
+		QueueEntries: []models.DBQueueEntry{entry1, entry2},
+	})
+
+	ctx := context.Background()
+	now := time.Now()
+	newRanking := func(userID, courtID string, sport models.Sport, now time.Time) models.DBRanking {
+		return models.NewDBRankingFixture().WithUserId(userID).WithCourtId(courtID).WithSport(sport)
+	}
+	newMatch := func() (models.DBMatches, []models.DBUserMatch) {
+		match := models.NewDBMatchesFixture().
+			WithCourtId(court.Id).
+			WithCreatorId(user1.Id).
+			WithSport(models.PingPong).
+			WithCurrentState(models.Valide)
+		return match, []models.DBUserMatch{
+			models.NewDBUserMatchFixture().WithMatchId(match.Id).WithUserId(user1.Id).WithTeam(1),
+			models.NewDBUserMatchFixture().WithMatchId(match.Id).WithUserId(user2.Id).WithTeam(2),
+		}
+	}
+
+	match, players := newMatch()
+	require.NoError(t, s.db.CreateMatchFromQueue(ctx, match, players, []string{entry1.Id, entry2.Id}, newRanking, now))
+
+	got, err := s.db.GetMatchById(ctx, match.Id)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Equal(t, models.Valide, got.CurrentState)
+
+	userMatches, err := s.db.GetUserMatchesByMatchID(ctx, match.Id)
+	require.NoError(t, err)
+	require.Len(t, userMatches, 2)
+
+	existing, err := s.db.GetRankingByUserCourtSport(ctx, user1.Id, court.Id, models.PingPong)
+	require.NoError(t, err)
+	require.Equal(t, 1300, existing.Elo)
+	created, err := s.db.GetRankingByUserCourtSport(ctx, user2.Id, court.Id, models.PingPong)
+	require.NoError(t, err)
+	require.NotNil(t, created)
+
+	for _, id := range []string{entry1.Id, entry2.Id} {
+		entry, err := s.db.GetQueueEntry(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, models.QueueMatched, entry.Status)
+		require.Equal(t, &match.Id, entry.MatchID)
+	}
+
+	// The entries are not waiting anymore: a second match cannot take them.
+	again, againPlayers := newMatch()
+	err = s.db.CreateMatchFromQueue(ctx, again, againPlayers, []string{entry1.Id, entry2.Id}, newRanking, now)
+	require.ErrorIs(t, err, ErrQueueEntryTaken)
+	notCreated, err := s.db.GetMatchById(ctx, again.Id)
+	require.NoError(t, err)
+	require.Nil(t, notCreated)
+}
+
+func TestDatabase_ExpireQueueEntries(t *testing.T) {
+	now := time.Now()
+	court := models.NewDBCourtFixture()
+	user1 := models.NewDBUsersFixture().WithUsername("user1").WithEmail("user1@test.com")
+	user2 := models.NewDBUsersFixture().WithUsername("user2").WithEmail("user2@test.com")
+	past := models.NewDBQueueEntryFixture().
+		WithUserId(user1.Id).
+		WithCourtIds(court.Id).
+		WithWindow(now.Add(-2*time.Hour), now.Add(10*time.Minute))
+	upcoming := models.NewDBQueueEntryFixture().WithUserId(user2.Id).WithCourtIds(court.Id)
+
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() {
+		if err := cleanup(); err != nil {
+			t.Logf("cleanup error: %v", err)
+		}
+	}()
+	s.loadFixtures(DBFixtures{
+		Courts:       []models.DBCourt{court},
+		Users:        []models.DBUsers{user1, user2},
+		QueueEntries: []models.DBQueueEntry{past, upcoming},
+	})
+
+	ctx := context.Background()
+	expired, err := s.db.ExpireQueueEntries(ctx, now.Add(30*time.Minute), now)
+	require.NoError(t, err)
+	require.Equal(t, 1, expired)
+
+	entry, err := s.db.GetQueueEntry(ctx, past.Id)
+	require.NoError(t, err)
+	require.Equal(t, models.QueueExpired, entry.Status)
+	entry, err = s.db.GetQueueEntry(ctx, upcoming.Id)
+	require.NoError(t, err)
+	require.Equal(t, models.QueueWaiting, entry.Status)
+}
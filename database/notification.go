@@ -0,0 +1,149 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrDeviceNotFound = notFoundError("device not found")
+
+// RegisterDevice records the push token of a user's device. A token already known,
+// even for another user, now belongs to this user: a device only has one owner.
+func (db Database) RegisterDevice(ctx context.Context, d models.DBDevice) error {
+	if _, err := db.Database.ExecContext(ctx, `
+		INSERT INTO devices (token, user_id, platform, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (token) DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			platform = EXCLUDED.platform,
+			updated_at = EXCLUDED.updated_at`,
+		d.Token, d.UserID, d.Platform, d.CreatedAt, d.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to register device: %w", err)
+	}
+	return nil
+}
+
+// DeleteDevice removes a device of the user, ErrDeviceNotFound if they have no device
+// with this token.
+func (db Database) DeleteDevice(ctx context.Context, userID, token string) error {
+	res, err := db.Database.ExecContext(ctx, `
+		DELETE FROM devices
+		WHERE token = $1 AND user_id = $2`, token, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete device: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete device: %w", err)
+	}
+	if n == 0 {
+		return ErrDeviceNotFound
+	}
+	return nil
+}
+
+// DeleteDeviceToken forgets a token the push provider no longer accepts.
+func (db Database) DeleteDeviceToken(ctx context.Context, token string) error {
+	if _, err := db.Database.ExecContext(ctx, `
+		DELETE FROM devices
+		WHERE token = $1`, token); err != nil {
+		return fmt.Errorf("failed to delete device token: %w", err)
+	}
+	return nil
+}
+
+// GetUserDevices returns the devices of the user, most recently registered first.
+func (db Database) GetUserDevices(ctx context.Context, userID string) ([]models.DBDevice, error) {
+	var devices []models.DBDevice
+	if err := db.Database.SelectContext(ctx, &devices, `
+		SELECT token, user_id, platform, created_at, updated_at
+		FROM devices
+		WHERE user_id = $1
+		ORDER BY updated_at DESC, token`, userID); err != nil {
+		return nil, fmt.Errorf("failed to fetch devices: %w", err)
+	}
+	return devices, nil
+}
+
+// GetNotificationPreferences returns the preferences of the user, the defaults if they
+// never changed them.
+func (db Database) GetNotificationPreferences(ctx context.Context, userID string) (models.DBNotificationPreferences, error) {
+	var p models.DBNotificationPreferences
+	err := db.Database.GetContext(ctx, &p, `
+		SELECT user_id, match_filled, match_starting, score_vote, match_result, updated_at
+		FROM notification_preferences
+		WHERE user_id = $1`, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.DefaultNotificationPreferences(userID), nil
+	}
+	if err != nil {
+		return p, fmt.Errorf("failed to fetch notification preferences: %w", err)
+	}
+	return p, nil
+}
+
+func (db Database) UpsertNotificationPreferences(ctx context.Context, p models.DBNotificationPreferences) error {
+	if _, err := db.Database.ExecContext(ctx, `
+		INSERT INTO notification_preferences (user_id, match_filled, match_starting, score_vote, match_result, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id) DO UPDATE SET
+			match_filled = EXCLUDED.match_filled,
+			match_starting = EXCLUDED.match_starting,
+			score_vote = EXCLUDED.score_vote,
+			match_result = EXCLUDED.match_result,
+			updated_at = EXCLUDED.updated_at`,
+		p.UserID, p.MatchFilled, p.MatchStarting, p.ScoreVote, p.MatchResult, p.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+	return nil
+}
+
+// enqueueForMatchPlayers queues one eventType event per player of the match, except
+// the user whose id is except (none when empty).
+func enqueueForMatchPlayers(ctx context.Context, ext sqlx.ExtContext, eventType models.OutboxEventType, matchID, except string, now time.Time) error {
+	var playerIDs []string
+	if err := sqlx.SelectContext(ctx, ext, &playerIDs, `
+		SELECT user_id
+		FROM user_match
+		WHERE match_id = $1 AND user_id <> $2
+		ORDER BY created_at, user_id`, matchID, except); err != nil {
+		return fmt.Errorf("failed to fetch match players: %w", err)
+	}
+	recipients := make([]models.MatchRecipientPayload, 0, len(playerIDs))
+	for _, id := range playerIDs {
+		recipients = append(recipients, models.MatchRecipientPayload{MatchID: matchID, UserID: id})
+	}
+	return enqueueMatchEmails(ctx, ext, eventType, recipients, now)
+}
+
+// MarkMatchesStartingSoon flags the Valide matches starting before startsBefore whose
+// players were not reminded yet, and queues the reminder of each player. It returns
+// the ids of the matches flagged.
+func (db Database) MarkMatchesStartingSoon(ctx context.Context, startsBefore, now time.Time) ([]string, error) {
+	var ids []string
+	err := db.inTx(ctx, func(tx *sqlx.Tx) error {
+		if err := tx.SelectContext(ctx, &ids, `
+			UPDATE matches
+			SET starting_soon_notified_at = $3
+			WHERE current_state = $1 AND date <= $2 AND date > $3 AND starting_soon_notified_at IS NULL
+			RETURNING id`, models.Valide, startsBefore, now); err != nil {
+			return fmt.Errorf("failed to flag matches starting soon: %w", err)
+		}
+		for _, id := range ids {
+			if err := enqueueForMatchPlayers(ctx, tx, models.OutboxMatchStartingPush, id, "", now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
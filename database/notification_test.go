@@ -0,0 +1,105 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase_Devices(t *testing.T) {
+	alice := models.NewDBUsersFixture()
+	bob := models.NewDBUsersFixture().WithUsername("bob").WithEmail("bob@example.com")
+
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() {
+		if err := cleanup(); err != nil {
+			t.Logf("cleanup error: %v", err)
+		}
+	}()
+	s.loadFixtures(DBFixtures{
+		Users: []models.DBUsers{alice, bob},
+		Devices: []models.DBDevice{
+			models.NewDBDeviceFixture().WithToken("shared").WithUserId(alice.Id),
+		},
+	})
+
+	ctx := context.Background()
+
+	// The device changes hands: bob logs in on alice's phone.
+	require.NoError(t, s.db.RegisterDevice(ctx, models.NewDBDeviceFixture().WithToken("shared").WithUserId(bob.Id).WithPlatform(models.PlatformIOS)))
+
+	devices, err := s.db.GetUserDevices(ctx, alice.Id)
+	require.NoError(t, err)
+	require.Empty(t, devices)
+	devices, err = s.db.GetUserDevices(ctx, bob.Id)
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	require.Equal(t, models.PlatformIOS, devices[0].Platform)
+
+	require.ErrorIs(t, s.db.DeleteDevice(ctx, alice.Id, "shared"), ErrDeviceNotFound)
+	require.NoError(t, s.db.DeleteDevice(ctx, bob.Id, "shared"))
+	devices, err = s.db.GetUserDevices(ctx, bob.Id)
+	require.NoError(t, err)
+	require.Empty(t, devices)
+}
+
+func TestDatabase_MarkMatchesStartingSoon(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	court := models.NewDBCourtFixture()
+	creator := models.NewDBUsersFixture()
+	player := models.NewDBUsersFixture().WithUsername("player").WithEmail("player@example.com")
+	soon := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(creator.Id).
+		WithCurrentState(models.Valide).
+		WithDate(now.Add(30 * time.Minute))
+	later := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(creator.Id).
+		WithCurrentState(models.Valide).
+		WithDate(now.Add(3 * time.Hour))
+	notFull := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(creator.Id).
+		WithCurrentState(models.ManqueJoueur).
+		WithDate(now.Add(30 * time.Minute))
+
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() {
+		if err := cleanup(); err != nil {
+			t.Logf("cleanup error: %v", err)
+		}
+	}()
+	s.loadFixtures(DBFixtures{
+		Courts:  []models.DBCourt{court},
+		Users:   []models.DBUsers{creator, player},
+		Matches: []models.DBMatches{soon, later, notFull},
+		UserMatches: []models.DBUserMatch{
+			models.NewDBUserMatchFixture().WithUserId(creator.Id).WithMatchId(soon.Id).WithTeam(1),
+			models.NewDBUserMatchFixture().WithUserId(player.Id).WithMatchId(soon.Id).WithTeam(2),
+		},
+	})
+
+	ctx := context.Background()
+
+	ids, err := s.db.MarkMatchesStartingSoon(ctx, now.Add(time.Hour), now)
+	require.NoError(t, err)
+	require.Equal(t, []string{soon.Id}, ids)
+
+	pending, err := s.db.GetOutboxEvents(ctx, models.OutboxPending)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	for _, e := range pending {
+		require.Equal(t, models.OutboxMatchStartingPush, e.Type)
+	}
+
+	// Players are only reminded once.
+	ids, err = s.db.MarkMatchesStartingSoon(ctx, now.Add(time.Hour), now.Add(time.Minute))
+	require.NoError(t, err)
+	require.Empty(t, ids)
+}
@@ -0,0 +1,134 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const outboxEventColumns = `id, type, payload, status, attempts, next_attempt_at, last_error, created_at, processed_at`
+
+// insertOutboxEvents records side effects in the transaction of the change causing
+// them, so they are delivered if and only if it commits.
+func insertOutboxEvents(ctx context.Context, ext sqlx.ExecerContext, events ...models.DBOutboxEvent) error {
+	for _, e := range events {
+		if _, err := ext.ExecContext(ctx, `
+			INSERT INTO outbox_events (id, type, payload, status, attempts, next_attempt_at, last_error, created_at, processed_at)
+			VALUES ($1, $2, $3::jsonb, $4, $5, $6, $7, $8, $9)`,
+			e.Id, e.Type, string(e.Payload), e.Status, e.Attempts, e.NextAttemptAt, e.LastError, e.CreatedAt, e.ProcessedAt); err != nil {
+			return fmt.Errorf("failed to insert %s outbox event: %w", e.Type, err)
+		}
+	}
+	return nil
+}
+
+// enqueueMatchEmails records one eventType event per recipient of the match.
+func enqueueMatchEmails(ctx context.Context, ext sqlx.ExecerContext, eventType models.OutboxEventType, payloads []models.MatchRecipientPayload, now time.Time) error {
+	events := make([]models.DBOutboxEvent, 0, len(payloads))
+	for _, p := range payloads {
+		e, err := models.NewOutboxEvent(eventType, p, now)
+		if err != nil {
+			return err
+		}
+		events = append(events, e)
+	}
+	return insertOutboxEvents(ctx, ext, events...)
+}
+
+func (db Database) InsertOutboxEvent(ctx context.Context, e models.DBOutboxEvent) error {
+	return insertOutboxEvents(ctx, db.Database, e)
+}
+
+// ClaimOutboxEvents picks up to limit pending events due at now, oldest first, counts
+// the attempt and pushes their next attempt to now + lease: a dispatcher that crashes
+// while delivering leaves them to be retried once the lease is over. Events claimed
+// by a concurrent dispatcher are skipped.
+func (db Database) ClaimOutboxEvents(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.DBOutboxEvent, error) {
+	var events []models.DBOutboxEvent
+	err := db.Database.SelectContext(ctx, &events, `
+		WITH due AS (
+			SELECT id
+			FROM outbox_events
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at, created_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE outbox_events o
+		SET attempts = o.attempts + 1, next_attempt_at = $2
+		FROM due
+		WHERE o.id = due.id
+		RETURNING o.id, o.type, o.payload, o.status, o.attempts, o.next_attempt_at, o.last_error, o.created_at, o.processed_at`,
+		now, now.Add(lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+	return events, nil
+}
+
+// CompleteOutboxEvent marks a delivered event as done.
+func (db Database) CompleteOutboxEvent(ctx context.Context, id string, now time.Time) error {
+	if _, err := db.Database.ExecContext(ctx, `
+		UPDATE outbox_events
+		SET status = 'done', last_error = NULL, processed_at = $2
+		WHERE id = $1`, id, now); err != nil {
+		return fmt.Errorf("failed to complete outbox event: %w", err)
+	}
+	return nil
+}
+
+// RetryOutboxEvent schedules the next delivery of a failed event.
+func (db Database) RetryOutboxEvent(ctx context.Context, id string, next time.Time, lastErr string) error {
+	if _, err := db.Database.ExecContext(ctx, `
+		UPDATE outbox_events
+		SET next_attempt_at = $2, last_error = $3
+		WHERE id = $1`, id, next, lastErr); err != nil {
+		return fmt.Errorf("failed to reschedule outbox event: %w", err)
+	}
+	return nil
+}
+
+// KillOutboxEvent gives up on an event: it stays in the table as dead until it is
+// requeued.
+func (db Database) KillOutboxEvent(ctx context.Context, id string, now time.Time, lastErr string) error {
+	if _, err := db.Database.ExecContext(ctx, `
+		UPDATE outbox_events
+		SET status = 'dead', last_error = $3, processed_at = $2
+		WHERE id = $1`, id, now, lastErr); err != nil {
+		return fmt.Errorf("failed to mark outbox event as dead: %w", err)
+	}
+	return nil
+}
+
+// RequeueDeadOutboxEvents gives the dead events a fresh set of attempts, due at now.
+// It returns how many were requeued.
+func (db Database) RequeueDeadOutboxEvents(ctx context.Context, now time.Time) (int, error) {
+	res, err := db.Database.ExecContext(ctx, `
+		UPDATE outbox_events
+		SET status = 'pending', attempts = 0, next_attempt_at = $1, processed_at = NULL
+		WHERE status = 'dead'`, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue dead outbox events: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue dead outbox events: %w", err)
+	}
+	return int(n), nil
+}
+
+// GetOutboxEvents returns the events with the given status, oldest first.
+func (db Database) GetOutboxEvents(ctx context.Context, status models.OutboxStatus) ([]models.DBOutboxEvent, error) {
+	var events []models.DBOutboxEvent
+	if err := db.Database.SelectContext(ctx, &events, `
+		SELECT `+outboxEventColumns+`
+		FROM outbox_events
+		WHERE status = $1
+		ORDER BY created_at, id`, status); err != nil {
+		return nil, fmt.Errorf("failed to fetch outbox events: %w", err)
+	}
+	return events, nil
+}
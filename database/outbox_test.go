@@ -0,0 +1,118 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase_ClaimOutboxEvents(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	oldest := models.NewDBOutboxEventFixture().WithNextAttemptAt(now.Add(-time.Hour))
+	due := models.NewDBOutboxEventFixture().WithNextAttemptAt(now.Add(-time.Minute))
+	later := models.NewDBOutboxEventFixture().WithNextAttemptAt(now.Add(time.Hour))
+	done := models.NewDBOutboxEventFixture().WithNextAttemptAt(now.Add(-time.Hour)).WithStatus(models.OutboxDone)
+
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() {
+		if err := cleanup(); err != nil {
+			t.Logf("cleanup error: %v", err)
+		}
+	}()
+	s.loadFixtures(DBFixtures{
+		OutboxEvents: []models.DBOutboxEvent{oldest, due, later, done},
+	})
+
+	ctx := context.Background()
+
+	claimed, err := s.db.ClaimOutboxEvents(ctx, now, 5*time.Minute, 1)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, oldest.Id, claimed[0].Id)
+	require.Equal(t, 1, claimed[0].Attempts)
+	require.WithinDuration(t, now.Add(5*time.Minute), claimed[0].NextAttemptAt, time.Second)
+
+	// The claimed event is leased: only the other due one is left.
+	claimed, err = s.db.ClaimOutboxEvents(ctx, now, 5*time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, due.Id, claimed[0].Id)
+
+	require.NoError(t, s.db.CompleteOutboxEvent(ctx, oldest.Id, now))
+	require.NoError(t, s.db.KillOutboxEvent(ctx, due.Id, now, "smtp down"))
+
+	dead, err := s.db.GetOutboxEvents(ctx, models.OutboxDead)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	require.Equal(t, due.Id, dead[0].Id)
+	require.Equal(t, "smtp down", *dead[0].LastError)
+
+	n, err := s.db.RequeueDeadOutboxEvents(ctx, now)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	claimed, err = s.db.ClaimOutboxEvents(ctx, now, 5*time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, due.Id, claimed[0].Id)
+	require.Equal(t, 1, claimed[0].Attempts)
+
+	require.NoError(t, s.db.RetryOutboxEvent(ctx, due.Id, now.Add(30*time.Minute), "timeout"))
+	claimed, err = s.db.ClaimOutboxEvents(ctx, now.Add(31*time.Minute), 5*time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, 2, claimed[0].Attempts)
+}
+
+func TestDatabase_CancelMatch_QueuesEmails(t *testing.T) {
+	court := models.NewDBCourtFixture()
+	creator := models.NewDBUsersFixture()
+	player := models.NewDBUsersFixture().WithUsername("player").WithEmail("player@example.com")
+	match := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(creator.Id).
+		WithCurrentState(models.ManqueJoueur)
+
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() {
+		if err := cleanup(); err != nil {
+			t.Logf("cleanup error: %v", err)
+		}
+	}()
+	s.loadFixtures(DBFixtures{
+		Courts:  []models.DBCourt{court},
+		Users:   []models.DBUsers{creator, player},
+		Matches: []models.DBMatches{match},
+		UserMatches: []models.DBUserMatch{
+			models.NewDBUserMatchFixture().WithUserId(creator.Id).WithMatchId(match.Id).WithTeam(1),
+			models.NewDBUserMatchFixture().WithUserId(player.Id).WithMatchId(match.Id).WithTeam(2),
+		},
+	})
+
+	ctx := context.Background()
+
+	// A refused cancellation queues nothing.
+	_, err := s.db.CancelMatch(ctx, match.Id, player.Id, time.Now())
+	require.ErrorIs(t, err, ErrNotMatchCreator)
+	pending, err := s.db.GetOutboxEvents(ctx, models.OutboxPending)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	_, err = s.db.CancelMatch(ctx, match.Id, creator.Id, time.Now())
+	require.NoError(t, err)
+
+	pending, err = s.db.GetOutboxEvents(ctx, models.OutboxPending)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, models.OutboxMatchCancelledEmail, pending[0].Type)
+
+	var payload models.MatchRecipientPayload
+	require.NoError(t, json.Unmarshal(pending[0].Payload, &payload))
+	require.Equal(t, models.MatchRecipientPayload{MatchID: match.Id, UserID: player.Id}, payload)
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// CreatePasswordResetToken stores a new reset token and invalidates the previous
+// unused ones of the user, so only the last link sent can be used.
+func (db Database) CreatePasswordResetToken(ctx context.Context, token models.DBPasswordResetToken) error {
+	return db.inTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE password_reset_tokens
+			SET used_at = $2
+			WHERE user_id = $1
+			  AND used_at IS NULL`, token.UserID, token.CreatedAt); err != nil {
+			return fmt.Errorf("failed to invalidate previous reset tokens: %w", err)
+		}
+
+		if _, err := tx.NamedExecContext(ctx, `
+			INSERT INTO password_reset_tokens (id, user_id, token_hash, expires_at, used_at, created_at)
+			VALUES (:id, :user_id, :token_hash, :expires_at, :used_at, :created_at)`, token); err != nil {
+			return fmt.Errorf("failed to create reset token: %w", err)
+		}
+		return nil
+	})
+}
+
+func (db Database) GetValidPasswordResetToken(ctx context.Context, hash string, now time.Time) (*models.DBPasswordResetToken, error) {
+	var token models.DBPasswordResetToken
+	err := db.Database.GetContext(ctx, &token, `
+		SELECT id, user_id, token_hash, expires_at, used_at, created_at
+		FROM password_reset_tokens
+		WHERE token_hash = $1
+		  AND used_at IS NULL
+		  AND expires_at > $2`, hash, now)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch reset token: %w", err)
+	}
+	return &token, nil
+}
+
+// ResetPasswordWithToken consumes the reset token, updates the password and revokes
+// every session of the user in a single transaction. It returns false when the token
+// is unknown, expired or already used.
+func (db Database) ResetPasswordWithToken(ctx context.Context, hash string, newPasswordHash string, now time.Time) (bool, error) {
+	consumed := false
+	err := db.inTx(ctx, func(tx *sqlx.Tx) error {
+		var userID string
+		err := tx.GetContext(ctx, &userID, `
+			UPDATE password_reset_tokens
+			SET used_at = $2
+			WHERE token_hash = $1
+			  AND used_at IS NULL
+			  AND expires_at > $2
+			RETURNING user_id`, hash, now)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return fmt.Errorf("failed to consume reset token: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE users
+			SET password = $2, updated_at = $3
+			WHERE id = $1`, userID, newPasswordHash, now); err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE sessions
+			SET revoked_at = $2, updated_at = $2
+			WHERE user_id = $1
+			  AND revoked_at IS NULL`, userID, now); err != nil {
+			return fmt.Errorf("failed to revoke user sessions: %w", err)
+		}
+
+		consumed = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return consumed, nil
+}
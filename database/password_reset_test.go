@@ -0,0 +1,79 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase_ResetPasswordWithToken(t *testing.T) {
+	type testCase struct {
+		name            string
+		token           models.DBPasswordResetToken
+		expectedSuccess bool
+	}
+
+	user := models.NewDBUsersFixture().WithPassword("old-hash")
+	now := time.Now()
+
+	testCases := []testCase{
+		{
+			name:            "Valid token -> password updated",
+			token:           models.NewDBPasswordResetTokenFixture().WithUserId(user.Id).WithTokenHash("hash"),
+			expectedSuccess: true,
+		},
+		{
+			name:  "Expired token -> rejected",
+			token: models.NewDBPasswordResetTokenFixture().WithUserId(user.Id).WithTokenHash("hash").WithExpiresAt(now.Add(-time.Minute)),
+		},
+		{
+			name:  "Used token -> rejected",
+			token: models.NewDBPasswordResetTokenFixture().WithUserId(user.Id).WithTokenHash("hash").WithUsedAt(now.Add(-time.Minute)),
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() {
+				if err := cleanup(); err != nil {
+					t.Logf("cleanup error: %v", err)
+				}
+			}()
+
+			ctx := context.Background()
+			session := models.NewDBSessionFixture().WithUserId(user.Id)
+			s.loadFixtures(DBFixtures{
+				Users:               []models.DBUsers{user},
+				Sessions:            []models.DBSession{session},
+				PasswordResetTokens: []models.DBPasswordResetToken{c.token},
+			})
+
+			ok, err := s.db.ResetPasswordWithToken(ctx, "hash", "new-hash", now)
+			require.NoError(t, err)
+			require.Equal(t, c.expectedSuccess, ok)
+
+			stored, err := s.db.GetUserById(ctx, user.Id)
+			require.NoError(t, err)
+			active, err := s.db.IsSessionActive(ctx, session.Id, user.Id, now)
+			require.NoError(t, err)
+
+			if !c.expectedSuccess {
+				require.Equal(t, "old-hash", stored.Password)
+				require.True(t, active)
+				return
+			}
+			require.Equal(t, "new-hash", stored.Password)
+			require.False(t, active)
+
+			ok, err = s.db.ResetPasswordWithToken(ctx, "hash", "other-hash", now)
+			require.NoError(t, err)
+			require.False(t, ok, "a reset token can only be used once")
+		})
+	}
+}
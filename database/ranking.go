@@ -6,6 +6,9 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
 )
 
 func (db Database) GetRankedFieldsByUserID(ctx context.Context, userID string) ([]models.Field, error) {
@@ -49,13 +52,37 @@ func (db Database) GetRankedFieldsByUserID(ctx context.Context, userID string) (
 }
 
 func (db Database) InsertRanking(ctx context.Context, ranking models.DBRanking) error {
-	_, err := db.Database.ExecContext(ctx, `
-		INSERT INTO ranking (user_id, court_id, elo, sport, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+	return upsertRanking(ctx, db.Database, ranking)
+}
+
+func upsertRanking(ctx context.Context, ext sqlx.ExecerContext, ranking models.DBRanking) error {
+	_, err := ext.ExecContext(ctx, `
+		INSERT INTO ranking (user_id, court_id, elo, rating_deviation, volatility, games_played, sport, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		ON CONFLICT (user_id, court_id, sport) DO UPDATE
 		SET elo = EXCLUDED.elo,
+		    rating_deviation = EXCLUDED.rating_deviation,
+		    volatility = EXCLUDED.volatility,
+		    games_played = EXCLUDED.games_played,
 		    updated_at = EXCLUDED.updated_at`,
-		ranking.UserID, ranking.CourtID, ranking.Elo, ranking.Sport, ranking.CreatedAt, ranking.UpdatedAt,
+		ranking.UserID, ranking.CourtID, ranking.Elo, ranking.Deviation, ranking.Volatility, ranking.GamesPlayed,
+		ranking.Sport, ranking.CreatedAt, ranking.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error inserting ranking: %w", err)
+	}
+	return nil
+}
+
+// insertRankingIfMissing inserts the ranking unless the player already has one on that
+// court in that sport.
+func insertRankingIfMissing(ctx context.Context, ext sqlx.ExecerContext, ranking models.DBRanking) error {
+	_, err := ext.ExecContext(ctx, `
+		INSERT INTO ranking (user_id, court_id, elo, rating_deviation, volatility, games_played, sport, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (user_id, court_id, sport) DO NOTHING`,
+		ranking.UserID, ranking.CourtID, ranking.Elo, ranking.Deviation, ranking.Volatility, ranking.GamesPlayed,
+		ranking.Sport, ranking.CreatedAt, ranking.UpdatedAt,
 	)
 	if err != nil {
 		return fmt.Errorf("error inserting ranking: %w", err)
@@ -79,25 +106,10 @@ func (db Database) GetRankingByUserAndCourt(ctx context.Context, userID, courtID
 	return &ranking, nil
 }
 
-func (db Database) GetRankingsByCourtID(ctx context.Context, courtID string, sport models.Sport) ([]models.DBRanking, error) {
-	var rows []models.DBRanking
-	err := db.Database.SelectContext(ctx, &rows, `
-				SELECT user_id, court_id, elo, sport, created_at, updated_at
-				FROM ranking
-				WHERE court_id = $1
-				AND sport = $2
-				ORDER BY elo DESC, user_id
-			`, courtID, sport)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch rankings: %w", err)
-	}
-	return rows, nil
-}
-
 func (db Database) GetRankingByUserCourtSport(ctx context.Context, userID, courtID string, sport models.Sport) (*models.DBRanking, error) {
 	var ranking models.DBRanking
 	err := db.Database.GetContext(ctx, &ranking, `
-		SELECT user_id, court_id, sport, elo, created_at, updated_at
+		SELECT user_id, court_id, sport, elo, rating_deviation, volatility, games_played, created_at, updated_at
 		FROM ranking
 		WHERE user_id = $1 AND court_id = $2 AND sport = $3
 		LIMIT 1`,
@@ -111,3 +123,108 @@ func (db Database) GetRankingByUserCourtSport(ctx context.Context, userID, court
 	}
 	return &ranking, nil
 }
+
+// GetCourtRanking returns one page of the ranking of a court for a sport, ordered by
+// elo then user_id, and the number of ranked players.
+func (db Database) GetCourtRanking(ctx context.Context, q models.CourtRankingQuery) ([]models.DBCourtRankingEntry, int, error) {
+	var entries []models.DBCourtRankingEntry
+	if err := db.Database.SelectContext(ctx, &entries, `
+		SELECT RANK() OVER (ORDER BY r.elo DESC) AS rank,
+		       r.user_id, u.username, r.elo
+		FROM ranking r
+		JOIN users u ON u.id = r.user_id
+		WHERE r.court_id = $1
+		  AND r.sport = $2
+		ORDER BY r.elo DESC, r.user_id
+		LIMIT $3 OFFSET $4`,
+		q.CourtID, q.Sport, q.Limit, q.Offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch court ranking: %w", err)
+	}
+
+	var total int
+	if err := db.Database.GetContext(ctx, &total, `
+		SELECT COUNT(*)
+		FROM ranking
+		WHERE court_id = $1
+		  AND sport = $2`,
+		q.CourtID, q.Sport); err != nil {
+		return nil, 0, fmt.Errorf("failed to count court ranking: %w", err)
+	}
+	return entries, total, nil
+}
+
+// GetCourtRankingPosition returns the 0-based position of a user in the ranking of a
+// court, in the order of GetCourtRanking, or nil if they are not ranked there.
+func (db Database) GetCourtRankingPosition(ctx context.Context, courtID string, sport models.Sport, userID string) (*int, error) {
+	var position int
+	err := db.Database.GetContext(ctx, &position, `
+		WITH ordered AS (
+			SELECT user_id, ROW_NUMBER() OVER (ORDER BY elo DESC, user_id) - 1 AS position
+			FROM ranking
+			WHERE court_id = $1
+			  AND sport = $2
+		)
+		SELECT position
+		FROM ordered
+		WHERE user_id = $3`,
+		courtID, sport, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch court ranking position: %w", err)
+	}
+	return &position, nil
+}
+
+// GetCourtPlayerStatsByIDs returns, for each user, their finished matches on a court
+// in a sport: count, win rate over decided matches and date of the last one. Users
+// without any are missing from the map.
+func (db Database) GetCourtPlayerStatsByIDs(ctx context.Context, courtID string, sport models.Sport, userIDs []string) (map[string]*models.CourtPlayerStats, error) {
+	type row struct {
+		UserID     string    `db:"user_id"`
+		Matches    int       `db:"matches"`
+		Wins       int       `db:"wins"`
+		Decided    int       `db:"decided"`
+		LastPlayed time.Time `db:"last_played"`
+	}
+	var rows []row
+	if err := db.Database.SelectContext(ctx, &rows, `
+		SELECT
+		  um.user_id,
+		  COUNT(*) AS matches,
+		  COUNT(*) FILTER (
+			WHERE (um.team = 1 AND m.score1 > m.score2) OR
+			      (um.team = 2 AND m.score2 > m.score1)
+		  ) AS wins,
+		  COUNT(*) FILTER (WHERE m.score1 <> m.score2) AS decided,
+		  MAX(m.date) AS last_played
+		FROM user_match um
+		JOIN matches m ON m.id = um.match_id
+		WHERE um.user_id = ANY($1)
+		  AND m.court_id = $2
+		  AND m.sport = $3
+		  AND m.current_state = 'Termine'
+		  AND m.score1 IS NOT NULL
+		  AND m.score2 IS NOT NULL
+		GROUP BY um.user_id`,
+		userIDs, courtID, sport); err != nil {
+		return nil, fmt.Errorf("failed to fetch court player stats: %w", err)
+	}
+
+	stats := make(map[string]*models.CourtPlayerStats, len(rows))
+	for _, r := range rows {
+		var pct *int
+		if r.Decided > 0 {
+			v := int((float64(r.Wins)/float64(r.Decided))*100.0 + 0.5)
+			pct = &v
+		}
+		lastPlayed := r.LastPlayed
+		stats[r.UserID] = &models.CourtPlayerStats{
+			MatchesPlayed: r.Matches,
+			Winrate:       pct,
+			LastPlayedAt:  &lastPlayed,
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func (db Database) InsertRankingHistory(ctx context.Context, h models.DBRankingHistory) error {
+	return insertRankingHistory(ctx, db.Database, h)
+}
+
+func insertRankingHistory(ctx context.Context, ext sqlx.ExecerContext, h models.DBRankingHistory) error {
+	_, err := ext.ExecContext(ctx, `
+		INSERT INTO ranking_history (id, user_id, court_id, sport, match_id, elo_before, elo_after, delta, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (user_id, match_id) DO NOTHING`,
+		h.Id, h.UserID, h.CourtID, h.Sport, h.MatchID, h.EloBefore, h.EloAfter, h.Delta, h.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error inserting ranking history: %w", err)
+	}
+	return nil
+}
+
+// GetRankingHistory returns the rating changes of a user, oldest first. courtID and
+// sport are optional filters.
+func (db Database) GetRankingHistory(ctx context.Context, userID string, courtID *string, sport *models.Sport) ([]models.DBRankingHistory, error) {
+	var history []models.DBRankingHistory
+	err := db.Database.SelectContext(ctx, &history, `
+		SELECT id, user_id, court_id, sport, match_id, elo_before, elo_after, delta, created_at
+		FROM ranking_history
+		WHERE user_id = $1
+		  AND ($2::text IS NULL OR court_id = $2)
+		  AND ($3::sport IS NULL OR sport = $3)
+		ORDER BY created_at, id`,
+		userID, courtID, sport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch ranking history: %w", err)
+	}
+	return history, nil
+}
+
+func (db Database) GetRankingHistoryByMatchIDs(ctx context.Context, matchIDs []string) (map[string][]models.DBRankingHistory, error) {
+	var rows []models.DBRankingHistory
+	err := db.Database.SelectContext(ctx, &rows, `
+		SELECT id, user_id, court_id, sport, match_id, elo_before, elo_after, delta, created_at
+		FROM ranking_history
+		WHERE match_id = ANY($1)
+		ORDER BY match_id, user_id`,
+		matchIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch ranking history by matchIDs: %w", err)
+	}
+
+	result := make(map[string][]models.DBRankingHistory)
+	for _, r := range rows {
+		result[r.MatchID] = append(result[r.MatchID], r)
+	}
+	return result, nil
+}
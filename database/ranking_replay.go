@@ -0,0 +1,130 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// NewRankingFunc returns the starting ranking of a player. Like RateFunc, it is provided
+// by the rating package.
+type NewRankingFunc func(userID, courtID string, sport models.Sport, now time.Time) models.DBRanking
+
+// ReplayRatings rebuilds every ranking and the whole ranking history from scratch: all
+// rankings are reset with newRanking, then every rated match is replayed with rate in
+// the order it was rated. It runs in one transaction holding a lock on ranking and
+// ranking_history, so matches finalized meanwhile wait for it and are rated on top of
+// the rebuilt rankings. It returns the number of matches replayed.
+func (db Database) ReplayRatings(ctx context.Context, rate RateFunc, newRanking NewRankingFunc) (replayed int, err error) {
+	err = db.inTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, `LOCK TABLE ranking, ranking_history IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+			return fmt.Errorf("failed to lock rankings: %w", err)
+		}
+
+		type rankingKey struct {
+			userID, courtID string
+			sport           models.Sport
+		}
+
+		var current []models.DBRanking
+		if err := tx.SelectContext(ctx, &current, `
+			SELECT user_id, court_id, sport, created_at
+			FROM ranking`); err != nil {
+			return fmt.Errorf("failed to fetch rankings: %w", err)
+		}
+		rankings := make(map[rankingKey]models.DBRanking, len(current))
+		for _, rk := range current {
+			rankings[rankingKey{rk.UserID, rk.CourtID, rk.Sport}] = newRanking(rk.UserID, rk.CourtID, rk.Sport, rk.CreatedAt)
+		}
+
+		var matches []models.DBMatches
+		if err := tx.SelectContext(ctx, &matches, `
+			SELECT id, sport, court_id, score1, score2, elo_applied_at
+			FROM matches
+			WHERE current_state = 'Termine'
+			  AND elo_applied_at IS NOT NULL
+			  AND score1 IS NOT NULL
+			  AND score2 IS NOT NULL
+			ORDER BY elo_applied_at, id`); err != nil {
+			return fmt.Errorf("failed to fetch rated matches: %w", err)
+		}
+
+		var userMatches []models.DBUserMatch
+		if err := tx.SelectContext(ctx, &userMatches, `
+			SELECT um.user_id, um.match_id, um.team, um.created_at
+			FROM user_match um
+			JOIN matches m ON m.id = um.match_id
+			WHERE m.current_state = 'Termine'
+			  AND m.elo_applied_at IS NOT NULL
+			ORDER BY um.match_id, um.team, um.user_id`); err != nil {
+			return fmt.Errorf("failed to fetch user_match rows: %w", err)
+		}
+		playersByMatch := make(map[string][]models.DBUserMatch)
+		for _, um := range userMatches {
+			playersByMatch[um.MatchID] = append(playersByMatch[um.MatchID], um)
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM ranking_history`); err != nil {
+			return fmt.Errorf("failed to clear ranking history: %w", err)
+		}
+
+		for _, m := range matches {
+			ratedAt := *m.EloAppliedAt
+
+			var team1, team2 []models.DBRanking
+			for _, um := range playersByMatch[m.Id] {
+				key := rankingKey{um.UserID, m.CourtID, m.Sport}
+				rk, ok := rankings[key]
+				if !ok {
+					rk = newRanking(um.UserID, m.CourtID, m.Sport, ratedAt)
+					rankings[key] = rk
+				}
+				switch um.Team {
+				case 1:
+					team1 = append(team1, rk)
+				case 2:
+					team2 = append(team2, rk)
+				}
+			}
+			if len(team1) == 0 || len(team2) == 0 {
+				continue
+			}
+
+			for _, rk := range rate(m.Sport, team1, team2, *m.Score1, *m.Score2, ratedAt) {
+				key := rankingKey{rk.UserID, rk.CourtID, rk.Sport}
+				before := rankings[key].Elo
+				rankings[key] = rk
+
+				if err := insertRankingHistory(ctx, tx, models.DBRankingHistory{
+					Id:        uuid.NewString(),
+					UserID:    rk.UserID,
+					CourtID:   rk.CourtID,
+					Sport:     rk.Sport,
+					MatchID:   m.Id,
+					EloBefore: before,
+					EloAfter:  rk.Elo,
+					Delta:     rk.Elo - before,
+					CreatedAt: ratedAt,
+				}); err != nil {
+					return err
+				}
+			}
+			replayed++
+		}
+
+		for _, rk := range rankings {
+			if err := upsertRanking(ctx, tx, rk); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return replayed, nil
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"PLIC/models"
+	"PLIC/rating"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase_ReplayRatings(t *testing.T) {
+	t.Parallel()
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() {
+		if err := cleanup(); err != nil {
+			t.Logf("cleanup error: %v", err)
+		}
+	}()
+
+	now := time.Now()
+	court := models.NewDBCourtFixture()
+	userA := models.NewDBUsersFixture().WithUsername("userA").WithEmail("a@test.com")
+	userB := models.NewDBUsersFixture().WithUsername("userB").WithEmail("b@test.com")
+
+	finished := func(appliedAt time.Time, score1, score2 int) models.DBMatches {
+		return models.NewDBMatchesFixture().
+			WithCourtId(court.Id).
+			WithCreatorId(userA.Id).
+			WithSport(models.Basket).
+			WithCurrentState(models.Termine).
+			WithScore1(score1).
+			WithScore2(score2).
+			WithEloAppliedAt(appliedAt)
+	}
+	first := finished(now.Add(-2*time.Hour), 21, 10)
+	second := finished(now.Add(-time.Hour), 21, 18)
+	pending := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(userA.Id).
+		WithSport(models.Basket).
+		WithCurrentState(models.ManqueScore)
+
+	var userMatches []models.DBUserMatch
+	for _, m := range []models.DBMatches{first, second, pending} {
+		userMatches = append(userMatches,
+			models.NewDBUserMatchFixture().WithUserId(userA.Id).WithMatchId(m.Id).WithTeam(1),
+			models.NewDBUserMatchFixture().WithUserId(userB.Id).WithMatchId(m.Id).WithTeam(2),
+		)
+	}
+
+	s.loadFixtures(DBFixtures{
+		Courts:      []models.DBCourt{court},
+		Users:       []models.DBUsers{userA, userB},
+		Matches:     []models.DBMatches{first, second, pending},
+		UserMatches: userMatches,
+		Rankings: []models.DBRanking{
+			models.NewDBRankingFixture().WithUserId(userA.Id).WithCourtId(court.Id).WithSport(models.Basket).WithElo(1400).WithGamesPlayed(9),
+			models.NewDBRankingFixture().WithUserId(userB.Id).WithCourtId(court.Id).WithSport(models.Basket).WithElo(600).WithGamesPlayed(9),
+		},
+	})
+
+	ctx := context.Background()
+	engines := rating.DefaultEngines()
+	replayed, err := s.db.ReplayRatings(ctx, engines.Rate, engines.NewRanking)
+	require.NoError(t, err)
+	require.Equal(t, 2, replayed)
+
+	rA, err := s.db.GetRankingByUserCourtSport(ctx, userA.Id, court.Id, models.Basket)
+	require.NoError(t, err)
+	require.Equal(t, 1031, rA.Elo)
+	require.Equal(t, 2, rA.GamesPlayed)
+	rB, err := s.db.GetRankingByUserCourtSport(ctx, userB.Id, court.Id, models.Basket)
+	require.NoError(t, err)
+	require.Equal(t, 969, rB.Elo)
+	require.Equal(t, 2, rB.GamesPlayed)
+
+	history, err := s.db.GetRankingHistory(ctx, userA.Id, &court.Id, nil)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.Equal(t, first.Id, history[0].MatchID)
+	require.Equal(t, 1000, history[0].EloBefore)
+	require.Equal(t, 16, history[0].Delta)
+	require.Equal(t, second.Id, history[1].MatchID)
+	require.Equal(t, 1016, history[1].EloBefore)
+	require.Equal(t, 15, history[1].Delta)
+
+	// Replaying is idempotent: same rankings, history rebuilt rather than appended.
+	_, err = s.db.ReplayRatings(ctx, engines.Rate, engines.NewRanking)
+	require.NoError(t, err)
+	history, err = s.db.GetRankingHistory(ctx, userA.Id, &court.Id, nil)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	rA, err = s.db.GetRankingByUserCourtSport(ctx, userA.Id, court.Id, models.Basket)
+	require.NoError(t, err)
+	require.Equal(t, 1031, rA.Elo)
+}
@@ -403,10 +403,11 @@ func TestDatabase_GetRankingByUserAndCourt(t *testing.T) {
 	}
 }
 
-func TestDatabase_GetRankingsByCourtID(t *testing.T) {
+func TestDatabase_GetCourtRanking(t *testing.T) {
 	type expected struct {
 		wantLen   int
 		checkSort bool
+		ranks     []int
 	}
 
 	type testCase struct {
@@ -482,6 +483,7 @@ func TestDatabase_GetRankingsByCourtID(t *testing.T) {
 			expected: expected{
 				wantLen:   3,
 				checkSort: true,
+				ranks:     []int{1, 2, 2},
 			},
 		},
 		{
@@ -510,6 +512,7 @@ func TestDatabase_GetRankingsByCourtID(t *testing.T) {
 			expected: expected{
 				wantLen:   1,
 				checkSort: true,
+				ranks:     []int{1},
 			},
 		},
 		{
@@ -529,7 +532,7 @@ func TestDatabase_GetRankingsByCourtID(t *testing.T) {
 	}
 
 	// ---------- Helper de tri ----------
-	isSorted := func(rs []models.DBRanking) bool {
+	isSorted := func(rs []models.DBCourtRankingEntry) bool {
 		return sort.SliceIsSorted(rs, func(i, j int) bool {
 			if rs[i].Elo == rs[j].Elo {
 				return rs[i].UserID < rs[j].UserID
@@ -547,22 +550,26 @@ func TestDatabase_GetRankingsByCourtID(t *testing.T) {
 
 			s.loadFixtures(tc.fixtures)
 
-			out, err := s.db.GetRankingsByCourtID(
-				context.Background(),
-				tc.courtID,
-				tc.sport,
-			)
+			out, total, err := s.db.GetCourtRanking(context.Background(), models.CourtRankingQuery{
+				CourtID: tc.courtID,
+				Sport:   tc.sport,
+				Limit:   10,
+			})
 
 			require.NoError(t, err)
 			require.Len(t, out, tc.expected.wantLen)
+			require.Equal(t, tc.expected.wantLen, total)
 
 			if tc.expected.checkSort {
 				require.True(
 					t,
 					isSorted(out),
-					"les rankings doivent être triés par (elo asc, user_id asc)",
+					"les rankings doivent être triés par (elo desc, user_id asc)",
 				)
 			}
+			for i, rank := range tc.expected.ranks {
+				require.Equal(t, rank, out[i].Rank, "mauvais rang pour %s", out[i].Username)
+			}
 		})
 	}
 }
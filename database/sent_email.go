@@ -0,0 +1,93 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// recentEmailQuery tells whether an email with a throttle key ($1) was sent, or is being
+// sent, since $2.
+const recentEmailQuery = `
+	SELECT EXISTS (
+		SELECT 1 FROM sent_emails
+		WHERE throttle_key = $1 AND created_at > $2 AND status <> $3
+	)`
+
+// ReserveEmail records e as being sent unless an email with the same throttle key was
+// sent, or is being sent, since since. It returns false when the email is throttled.
+// Concurrent reservations of a key are serialized by an advisory lock, so only one of
+// them wins, whichever instance they run on.
+func (db Database) ReserveEmail(ctx context.Context, e models.DBSentEmail, since time.Time) (bool, error) {
+	reserved := false
+	err := db.inTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, e.ThrottleKey); err != nil {
+			return fmt.Errorf("failed to lock throttle key: %w", err)
+		}
+
+		var recent bool
+		if err := tx.GetContext(ctx, &recent, recentEmailQuery, e.ThrottleKey, since, models.SentEmailFailed); err != nil {
+			return fmt.Errorf("failed to check sent emails: %w", err)
+		}
+		if recent {
+			return nil
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO sent_emails (id, kind, throttle_key, recipient, subject, status, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			e.Id, e.Kind, e.ThrottleKey, e.Recipient, e.Subject, models.SentEmailSending, e.CreatedAt); err != nil {
+			return fmt.Errorf("failed to reserve email: %w", err)
+		}
+		reserved = true
+		return nil
+	})
+	return reserved, err
+}
+
+// IsEmailThrottled tells whether an email with throttleKey was sent, or is being sent,
+// since since, without reserving anything.
+func (db Database) IsEmailThrottled(ctx context.Context, throttleKey string, since time.Time) (bool, error) {
+	var recent bool
+	if err := db.Database.GetContext(ctx, &recent, recentEmailQuery, throttleKey, since, models.SentEmailFailed); err != nil {
+		return false, fmt.Errorf("failed to check sent emails: %w", err)
+	}
+	return recent, nil
+}
+
+// CompleteEmail records the outcome of a reserved email: sent at now, or failed with
+// sendErr, in which case it no longer throttles its key.
+func (db Database) CompleteEmail(ctx context.Context, id string, now time.Time, sendErr error) error {
+	var err error
+	if sendErr == nil {
+		_, err = db.Database.ExecContext(ctx, `
+			UPDATE sent_emails
+			SET status = $2, sent_at = $3
+			WHERE id = $1`, id, models.SentEmailSent, now)
+	} else {
+		_, err = db.Database.ExecContext(ctx, `
+			UPDATE sent_emails
+			SET status = $2, error = $3
+			WHERE id = $1`, id, models.SentEmailFailed, sendErr.Error())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to complete email: %w", err)
+	}
+	return nil
+}
+
+// GetSentEmails returns the emails sent to recipient, most recent first.
+func (db Database) GetSentEmails(ctx context.Context, recipient string) ([]models.DBSentEmail, error) {
+	var emails []models.DBSentEmail
+	if err := db.Database.SelectContext(ctx, &emails, `
+		SELECT id, kind, throttle_key, recipient, subject, status, error, created_at, sent_at
+		FROM sent_emails
+		WHERE recipient = $1
+		ORDER BY created_at DESC, id`, recipient); err != nil {
+		return nil, fmt.Errorf("failed to fetch sent emails: %w", err)
+	}
+	return emails, nil
+}
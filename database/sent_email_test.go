@@ -0,0 +1,64 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase_ReserveEmail(t *testing.T) {
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() {
+		if err := cleanup(); err != nil {
+			t.Logf("cleanup error: %v", err)
+		}
+	}()
+
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	email := func(at time.Time) models.DBSentEmail {
+		return models.DBSentEmail{
+			Id:          uuid.NewString(),
+			Kind:        "verify_email",
+			ThrottleKey: "u1:verify_email",
+			Recipient:   "a@b.c",
+			Subject:     "Confirme ton adresse e-mail",
+			CreatedAt:   at,
+		}
+	}
+
+	first := email(now)
+	reserved, err := s.db.ReserveEmail(ctx, first, now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.True(t, reserved)
+
+	// Same key within the window -> throttled, even before the first one is sent.
+	reserved, err = s.db.ReserveEmail(ctx, email(now.Add(10*time.Second)), now.Add(10*time.Second-time.Minute))
+	require.NoError(t, err)
+	require.False(t, reserved)
+	throttled, err := s.db.IsEmailThrottled(ctx, first.ThrottleKey, now.Add(10*time.Second-time.Minute))
+	require.NoError(t, err)
+	require.True(t, throttled)
+
+	// A failed send no longer throttles the key.
+	require.NoError(t, s.db.CompleteEmail(ctx, first.Id, now, errors.New("smtp down")))
+	retry := email(now.Add(20 * time.Second))
+	reserved, err = s.db.ReserveEmail(ctx, retry, now.Add(20*time.Second-time.Minute))
+	require.NoError(t, err)
+	require.True(t, reserved)
+	require.NoError(t, s.db.CompleteEmail(ctx, retry.Id, now.Add(21*time.Second), nil))
+
+	emails, err := s.db.GetSentEmails(ctx, "a@b.c")
+	require.NoError(t, err)
+	require.Len(t, emails, 2)
+	require.Equal(t, models.SentEmailSent, emails[0].Status)
+	require.NotNil(t, emails[0].SentAt)
+	require.Equal(t, models.SentEmailFailed, emails[1].Status)
+	require.Equal(t, "smtp down", *emails[1].Error)
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+func (db Database) CreateSession(ctx context.Context, session models.DBSession) error {
+	_, err := db.Database.NamedExecContext(ctx, `
+		INSERT INTO sessions (id, user_id, refresh_token_hash, expires_at, revoked_at, created_at, updated_at)
+		VALUES (:id, :user_id, :refresh_token_hash, :expires_at, :revoked_at, :created_at, :updated_at)`, session)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	return nil
+}
+
+func (db Database) GetSessionByRefreshTokenHash(ctx context.Context, hash string) (*models.DBSession, error) {
+	var session models.DBSession
+	err := db.Database.GetContext(ctx, &session, `
+		SELECT id, user_id, refresh_token_hash, expires_at, revoked_at, created_at, updated_at
+		FROM sessions
+		WHERE refresh_token_hash = $1`, hash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch session: %w", err)
+	}
+	return &session, nil
+}
+
+func (db Database) IsSessionActive(ctx context.Context, sessionID, userID string, now time.Time) (bool, error) {
+	var active bool
+	err := db.Database.GetContext(ctx, &active, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM sessions
+			WHERE id = $1
+			  AND user_id = $2
+			  AND revoked_at IS NULL
+			  AND expires_at > $3
+		)`, sessionID, userID, now)
+	if err != nil {
+		return false, fmt.Errorf("failed to check session: %w", err)
+	}
+	return active, nil
+}
+
+// RotateSessionRefreshToken swaps the refresh token of an active session. It only
+// succeeds for the caller holding the current token, so a refresh token can be used once.
+func (db Database) RotateSessionRefreshToken(ctx context.Context, sessionID, oldHash, newHash string, expiresAt, now time.Time) (bool, error) {
+	res, err := db.Database.ExecContext(ctx, `
+		UPDATE sessions
+		SET refresh_token_hash = $3,
+		    expires_at = $4,
+		    updated_at = $5
+		WHERE id = $1
+		  AND refresh_token_hash = $2
+		  AND revoked_at IS NULL
+		  AND expires_at > $5`,
+		sessionID, oldHash, newHash, expiresAt, now)
+	if err != nil {
+		return false, fmt.Errorf("failed to rotate session: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to rotate session: %w", err)
+	}
+	return n == 1, nil
+}
+
+func (db Database) RevokeSession(ctx context.Context, sessionID, userID string, now time.Time) error {
+	_, err := db.Database.ExecContext(ctx, `
+		UPDATE sessions
+		SET revoked_at = $3, updated_at = $3
+		WHERE id = $1
+		  AND user_id = $2
+		  AND revoked_at IS NULL`, sessionID, userID, now)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+func (db Database) RevokeUserSessions(ctx context.Context, userID string, exceptSessionID string, now time.Time) error {
+	_, err := db.Database.ExecContext(ctx, `
+		UPDATE sessions
+		SET revoked_at = $3, updated_at = $3
+		WHERE user_id = $1
+		  AND id <> $2
+		  AND revoked_at IS NULL`, userID, exceptSessionID, now)
+	if err != nil {
+		return fmt.Errorf("failed to revoke user sessions: %w", err)
+	}
+	return nil
+}
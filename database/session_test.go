@@ -0,0 +1,76 @@
+package database
+
+import (
+	"PLIC/models"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase_RotateSessionRefreshToken(t *testing.T) {
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() {
+		if err := cleanup(); err != nil {
+			t.Logf("cleanup error: %v", err)
+		}
+	}()
+
+	ctx := context.Background()
+	now := time.Now()
+	user := models.NewDBUsersFixture()
+	session := models.NewDBSessionFixture().
+		WithUserId(user.Id).
+		WithRefreshTokenHash("old-hash")
+
+	s.loadFixtures(DBFixtures{
+		Users:    []models.DBUsers{user},
+		Sessions: []models.DBSession{session},
+	})
+
+	rotated, err := s.db.RotateSessionRefreshToken(ctx, session.Id, "old-hash", "new-hash", now.Add(time.Hour), now)
+	require.NoError(t, err)
+	require.True(t, rotated)
+
+	rotated, err = s.db.RotateSessionRefreshToken(ctx, session.Id, "old-hash", "other-hash", now.Add(time.Hour), now)
+	require.NoError(t, err)
+	require.False(t, rotated, "an already rotated refresh token must not be accepted twice")
+
+	stored, err := s.db.GetSessionByRefreshTokenHash(ctx, "new-hash")
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	require.Equal(t, session.Id, stored.Id)
+}
+
+func TestDatabase_RevokeUserSessions(t *testing.T) {
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() {
+		if err := cleanup(); err != nil {
+			t.Logf("cleanup error: %v", err)
+		}
+	}()
+
+	ctx := context.Background()
+	now := time.Now()
+	user := models.NewDBUsersFixture()
+	kept := models.NewDBSessionFixture().WithUserId(user.Id)
+	revoked := models.NewDBSessionFixture().WithUserId(user.Id)
+
+	s.loadFixtures(DBFixtures{
+		Users:    []models.DBUsers{user},
+		Sessions: []models.DBSession{kept, revoked},
+	})
+
+	require.NoError(t, s.db.RevokeUserSessions(ctx, user.Id, kept.Id, now))
+
+	active, err := s.db.IsSessionActive(ctx, kept.Id, user.Id, now)
+	require.NoError(t, err)
+	require.True(t, active)
+
+	active, err = s.db.IsSessionActive(ctx, revoked.Id, user.Id, now)
+	require.NoError(t, err)
+	require.False(t, active)
+}
@@ -0,0 +1,8 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS pending_email TEXT;
+
+-- Accounts created before email verification existed are considered verified.
+UPDATE users
+SET email_verified_at = created_at
+WHERE email_verified_at IS NULL;
@@ -0,0 +1,9 @@
+-- Keep a single row per (user, match) before enforcing uniqueness.
+DELETE FROM user_match a
+    USING user_match b
+WHERE a.ctid > b.ctid
+  AND a.user_id = b.user_id
+  AND a.match_id = b.match_id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_user_match_user_match
+    ON user_match (user_id, match_id);
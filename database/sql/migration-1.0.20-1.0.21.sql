@@ -0,0 +1 @@
+ALTER TYPE etat_match ADD VALUE IF NOT EXISTS 'Annule';
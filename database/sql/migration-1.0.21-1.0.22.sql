@@ -0,0 +1,51 @@
+ALTER TABLE matches
+    ADD COLUMN IF NOT EXISTS disputed BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS score_deadline TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE match_score_vote
+    ADD COLUMN IF NOT EXISTS round INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE match_score_vote DROP CONSTRAINT IF EXISTS match_score_vote_pkey;
+ALTER TABLE match_score_vote ADD PRIMARY KEY (match_id, user_id, round);
+
+CREATE OR REPLACE FUNCTION try_finalize_match() RETURNS trigger AS $$
+DECLARE
+    other_team INT;
+    agree_exists BOOLEAN;
+BEGIN
+    IF NEW.team = 1 THEN other_team := 2; ELSE other_team := 1; END IF;
+
+    SELECT EXISTS (
+        SELECT 1
+        FROM match_score_vote v
+        WHERE v.match_id = NEW.match_id
+          AND v.round = NEW.round
+          AND v.team = other_team
+          AND v.score1 = NEW.score1
+          AND v.score2 = NEW.score2
+    ) INTO agree_exists;
+
+    IF agree_exists THEN
+        UPDATE matches
+        SET score1 = NEW.score1,
+            score2 = NEW.score2,
+            current_state = 'Termine',
+            score_deadline = NULL,
+            updated_at = NOW()
+        WHERE id = NEW.match_id
+          AND current_state = 'Manque Score';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Matches already waiting for scores get a fresh voting window instead of staying stuck.
+UPDATE matches
+SET score_deadline = NOW() + INTERVAL '48 hours'
+WHERE current_state = 'Manque Score'
+  AND score_deadline IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_matches_score_deadline
+    ON matches (score_deadline)
+    WHERE current_state = 'Manque Score';
@@ -0,0 +1,12 @@
+-- Match finalization now lives in the FinalizeMatch service only.
+DROP TRIGGER IF EXISTS trg_try_finalize_match ON match_score_vote;
+DROP FUNCTION IF EXISTS try_finalize_match();
+
+ALTER TABLE matches
+    ADD COLUMN IF NOT EXISTS elo_applied_at TIMESTAMP WITH TIME ZONE;
+
+-- Matches finished before this version are considered rated, so they are never rated twice.
+UPDATE matches
+SET elo_applied_at = updated_at
+WHERE current_state = 'Termine'
+  AND elo_applied_at IS NULL;
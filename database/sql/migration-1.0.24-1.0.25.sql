@@ -0,0 +1,21 @@
+-- The SQL default now matches rating.DefaultElo.
+ALTER TABLE ranking
+    ALTER COLUMN elo SET DEFAULT 1000;
+
+-- State used by the rating engines: deviation and volatility for Glicko-2, games
+-- played for the Elo provisional period.
+ALTER TABLE ranking
+    ADD COLUMN IF NOT EXISTS rating_deviation DOUBLE PRECISION NOT NULL DEFAULT 350,
+    ADD COLUMN IF NOT EXISTS volatility DOUBLE PRECISION NOT NULL DEFAULT 0.06,
+    ADD COLUMN IF NOT EXISTS games_played INTEGER NOT NULL DEFAULT 0;
+
+UPDATE ranking r
+SET games_played = (
+    SELECT COUNT(*)
+    FROM user_match um
+    JOIN matches m ON m.id = um.match_id
+    WHERE um.user_id = r.user_id
+      AND m.court_id = r.court_id
+      AND m.sport = r.sport
+      AND m.elo_applied_at IS NOT NULL
+);
@@ -0,0 +1,14 @@
+ALTER TABLE courts
+    ADD COLUMN IF NOT EXISTS city TEXT;
+
+-- Same rule as models.ParseCity: last comma-separated part of the address, postal
+-- code removed.
+UPDATE courts
+SET city = NULLIF(BTRIM(REGEXP_REPLACE(
+        REGEXP_REPLACE(SUBSTRING(address FROM '[^,]*$'), '\m\d{5}\M', '', 'g'),
+        '\s+', ' ', 'g')), '')
+WHERE address LIKE '%,%'
+  AND city IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_courts_city
+    ON courts (LOWER(city));
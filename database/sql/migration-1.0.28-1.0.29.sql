@@ -0,0 +1,3 @@
+-- Equipes reparties par le serveur : les joueurs rejoignent sans equipe (team = 0)
+-- et sont repartis selon leur ELO quand le match passe a "Valide"
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS auto_balance BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,23 @@
+CREATE TYPE outbox_status AS ENUM(
+    'pending', -- a livrer, a partir de next_attempt_at
+    'done', -- livre
+    'dead' -- abandonne apres trop d'echecs, voir last_error
+    );
+
+-- Effets de bord (e-mails...) ecrits dans la meme transaction que le changement d'etat,
+-- livres ensuite par le dispatcher
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id TEXT PRIMARY KEY,
+    type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status outbox_status NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    processed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending
+    ON outbox_events (next_attempt_at)
+    WHERE status = 'pending';
@@ -0,0 +1,28 @@
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS starting_soon_notified_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TYPE device_platform AS ENUM(
+    'ios', -- APNs
+    'android' -- FCM
+    );
+
+-- Appareils qui recoivent les notifications push ; un jeton appartient a un seul utilisateur
+CREATE TABLE IF NOT EXISTS devices (
+    token TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    platform device_platform NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_devices_user
+    ON devices (user_id);
+
+-- Sans ligne, toutes les notifications sont activees
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    match_filled BOOLEAN NOT NULL DEFAULT TRUE,
+    match_starting BOOLEAN NOT NULL DEFAULT TRUE,
+    score_vote BOOLEAN NOT NULL DEFAULT TRUE,
+    match_result BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
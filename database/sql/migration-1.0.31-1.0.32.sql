@@ -0,0 +1,21 @@
+CREATE TYPE sent_email_status AS ENUM(
+    'sending', -- reserve, compte pour la limitation d'envoi
+    'sent',
+    'failed' -- ne compte pas pour la limitation, l'envoi peut etre retente
+    );
+
+-- Journal des e-mails envoyes, sert d'audit et de limitation d'envoi partagee entre les instances
+CREATE TABLE IF NOT EXISTS sent_emails (
+    id TEXT PRIMARY KEY,
+    kind TEXT NOT NULL,
+    throttle_key TEXT NOT NULL,
+    recipient TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    status sent_email_status NOT NULL DEFAULT 'sending',
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_sent_emails_throttle
+    ON sent_emails (throttle_key, created_at DESC);
@@ -0,0 +1,2 @@
+-- Langue des e-mails et des notifications de l'utilisateur
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale TEXT NOT NULL DEFAULT 'fr' CHECK (locale IN ('fr', 'en'));
@@ -33,7 +33,7 @@ func (db Database) GetUserByUsername(ctx context.Context, username string) (*mod
 	var user models.DBUsers
 
 	err := db.Database.GetContext(ctx, &user, `
-		SELECT id, username, email, bio, password, created_at, updated_at
+		SELECT id, username, email, bio, current_field_id, password, email_verified_at, pending_email, locale, created_at, updated_at
 		FROM users
 		WHERE username = $1`, username)
 	if err != nil {
@@ -50,7 +50,7 @@ func (db Database) GetUserByEmail(ctx context.Context, email string) (*models.DB
 	var user models.DBUsers
 
 	err := db.Database.GetContext(ctx, &user, `
-		SELECT id, username, email, bio, password, created_at, updated_at
+		SELECT id, username, email, bio, current_field_id, password, email_verified_at, pending_email, locale, created_at, updated_at
 		FROM users
 		WHERE email = $1`, email)
 	if err != nil {
@@ -67,7 +67,7 @@ func (db Database) GetUserById(ctx context.Context, id string) (*models.DBUsers,
 	var user models.DBUsers
 
 	err := db.Database.GetContext(ctx, &user, `
-		SELECT id, username, email, bio, current_field_id, password, created_at, updated_at
+		SELECT id, username, email, bio, current_field_id, password, email_verified_at, pending_email, locale, created_at, updated_at
 		FROM users
 		WHERE id = $1`, id)
 	if err != nil {
@@ -105,6 +105,11 @@ func (db Database) UpdateUser(ctx context.Context, data models.UserPatchRequest,
 		args = append(args, *data.CurrentFieldId)
 		argPos++
 	}
+	if data.Locale != nil {
+		query += fmt.Sprintf(" locale = $%d,", argPos)
+		args = append(args, *data.Locale)
+		argPos++
+	}
 
 	if len(args) == 0 {
 		return nil
@@ -122,14 +127,14 @@ func (db Database) UpdateUser(ctx context.Context, data models.UserPatchRequest,
 }
 
 var (
-	ErrEmailTaken    = errors.New("email already taken")
-	ErrUsernameTaken = errors.New("username already taken")
+	ErrEmailTaken    = conflictError("email already taken")
+	ErrUsernameTaken = conflictError("username already taken")
 )
 
 func (db Database) CreateUser(ctx context.Context, user models.DBUsers) error {
 	_, err := db.Database.NamedExecContext(ctx, `
-		INSERT INTO users (id, username, email, bio, password, created_at, updated_at)
-		VALUES (:id, :username, :email, :bio, :password, :created_at, :updated_at)`, user)
+		INSERT INTO users (id, username, email, bio, password, email_verified_at, pending_email, locale, created_at, updated_at)
+		VALUES (:id, :username, :email, :bio, :password, :email_verified_at, :pending_email, :locale, :created_at, :updated_at)`, user)
 	if err == nil {
 		return nil
 	}
@@ -436,3 +441,72 @@ func (db Database) GetUserStatsByIDs(ctx context.Context, userIDs []string) (map
 
 	return stats, nil
 }
+
+func (db Database) IsEmailVerified(ctx context.Context, userId string) (bool, error) {
+	var verified bool
+	err := db.Database.GetContext(ctx, &verified, `
+		SELECT email_verified_at IS NOT NULL
+		FROM users
+		WHERE id = $1`, userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("échec de la requête SQL : %w", err)
+	}
+	return verified, nil
+}
+
+// MarkEmailVerified flags the current email of the user as verified. It returns false
+// when the email of the user is no longer the one the verification was sent to.
+func (db Database) MarkEmailVerified(ctx context.Context, userId string, email string, now time.Time) (bool, error) {
+	res, err := db.Database.ExecContext(ctx, `
+		UPDATE users
+		SET email_verified_at = COALESCE(email_verified_at, $3), updated_at = $3
+		WHERE id = $1
+		  AND email = $2`, userId, email, now)
+	if err != nil {
+		return false, fmt.Errorf("échec de la vérification de l'email : %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("échec de la vérification de l'email : %w", err)
+	}
+	return n == 1, nil
+}
+
+func (db Database) SetPendingEmail(ctx context.Context, userId string, email string, now time.Time) error {
+	_, err := db.Database.ExecContext(ctx, `
+		UPDATE users
+		SET pending_email = $2, updated_at = $3
+		WHERE id = $1`, userId, email, now)
+	if err != nil {
+		return fmt.Errorf("échec de la mise à jour de l'email en attente : %w", err)
+	}
+	return nil
+}
+
+// ConfirmPendingEmail replaces the email of the user by its verified pending email.
+// It returns false when the pending email changed since the verification was sent.
+func (db Database) ConfirmPendingEmail(ctx context.Context, userId string, email string, now time.Time) (bool, error) {
+	res, err := db.Database.ExecContext(ctx, `
+		UPDATE users
+		SET email = pending_email,
+		    pending_email = NULL,
+		    email_verified_at = $3,
+		    updated_at = $3
+		WHERE id = $1
+		  AND pending_email = $2`, userId, email, now)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return false, ErrEmailTaken
+		}
+		return false, fmt.Errorf("échec de la confirmation de l'email : %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("échec de la confirmation de l'email : %w", err)
+	}
+	return n == 1, nil
+}
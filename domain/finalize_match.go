@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"PLIC/database"
+	"PLIC/models"
+	"PLIC/rating"
+	"context"
+	"time"
+)
+
+// FinalizeMatch is the only way a match reaches Termine with a score. The score, the
+// state change, the rating update by the engine of the match's sport and the result
+// emails queued in the outbox are written in one transaction guarded by
+// matches.elo_applied_at. Calling it again for a finalized match is a no-op and
+// returns applied = false, so ratings and emails happen exactly once whichever
+// caller gets there first.
+func FinalizeMatch(ctx context.Context, db database.Database, engines rating.Engines, matchID string, score1, score2 int, now time.Time) (match models.DBMatches, applied bool, err error) {
+	return db.FinalizeMatch(ctx, matchID, score1, score2, now, engines.Rate)
+}
+
+// ResolveScoreDeadline closes the score vote of a match past its deadline. An
+// uncontested vote finalizes the match like FinalizeMatch does; otherwise the match
+// is voided. accepted tells which one happened.
+func ResolveScoreDeadline(ctx context.Context, db database.Database, engines rating.Engines, matchID string, now time.Time) (match models.DBMatches, accepted bool, err error) {
+	return db.ResolveScoreDeadline(ctx, matchID, now, engines.Rate)
+}
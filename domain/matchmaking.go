@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"PLIC/database"
+	"PLIC/matchmaking"
+	"PLIC/models"
+	"PLIC/rating"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// RunMatchmaking runs one pass of the matchmaker: entries that can no longer be
+// scheduled expire, then the waiting players of each sport are grouped by matcher and
+// every group becomes a Valide match with balanced teams. The emails to the players
+// are queued in the outbox with their match. It returns the matches created.
+func RunMatchmaking(ctx context.Context, db database.Database, engines rating.Engines, matcher matchmaking.Matcher) ([]models.DBMatches, error) {
+	now := matcher.Clock.Now()
+
+	expired, err := db.ExpireQueueEntries(ctx, now.Add(matcher.LeadTime), now)
+	if err != nil {
+		return nil, fmt.Errorf("expire queue entries: %w", err)
+	}
+	if expired > 0 {
+		log.Info().Int("expired", expired).Msg("matchmaking: entrées expirées")
+	}
+
+	var created []models.DBMatches
+	for _, sport := range []models.Sport{models.Basket, models.Foot, models.PingPong} {
+		rows, err := db.GetMatchmakingCandidates(ctx, sport, rating.DefaultElo)
+		if err != nil {
+			return created, fmt.Errorf("list %s candidates: %w", sport, err)
+		}
+
+		for _, p := range matcher.Match(toCandidates(rows)) {
+			match, err := createMatchFromProposal(ctx, db, engines, sport, p, now)
+			if errors.Is(err, database.ErrQueueEntryTaken) {
+				continue
+			}
+			if err != nil {
+				return created, fmt.Errorf("create %s match: %w", sport, err)
+			}
+			created = append(created, match)
+		}
+	}
+	return created, nil
+}
+
+// toCandidates folds the candidate rows, one per entry and accepted court, into one
+// candidate per entry.
+func toCandidates(rows []models.DBMatchmakingCandidate) []matchmaking.Candidate {
+	var candidates []matchmaking.Candidate
+	index := make(map[string]int)
+	for _, r := range rows {
+		i, ok := index[r.EntryID]
+		if !ok {
+			i = len(candidates)
+			index[r.EntryID] = i
+			candidates = append(candidates, matchmaking.Candidate{
+				EntryID:     r.EntryID,
+				UserID:      r.UserID,
+				Elo:         r.Elo,
+				TeamSize:    r.TeamSize,
+				WindowStart: r.WindowStart,
+				WindowEnd:   r.WindowEnd,
+				QueuedAt:    r.CreatedAt,
+				Courts:      make(map[string]float64),
+			})
+		}
+		candidates[i].Courts[r.CourtID] = r.DistanceMeters
+	}
+	return candidates
+}
+
+// createMatchFromProposal creates the match of a proposal. The player who waited the
+// longest is its creator. The match is auto_balance, so players who replace those
+// who leave are spread again into balanced teams.
+func createMatchFromProposal(ctx context.Context, db database.Database, engines rating.Engines, sport models.Sport, p matchmaking.Proposal, now time.Time) (models.DBMatches, error) {
+	players := append(append([]matchmaking.Candidate(nil), p.Team1...), p.Team2...)
+	creator := players[0]
+	for _, c := range players[1:] {
+		if c.QueuedAt.Before(creator.QueuedAt) {
+			creator = c
+		}
+	}
+
+	match := models.DBMatches{
+		Id:              uuid.NewString(),
+		Sport:           sport,
+		Date:            p.Date,
+		ParticipantNber: len(players),
+		CurrentState:    models.Valide,
+		CourtID:         p.CourtID,
+		CreatorID:       creator.UserID,
+		Visibility:      models.VisibilityPublic,
+		AutoBalance:     true,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	var userMatches []models.DBUserMatch
+	var entryIDs []string
+	for team, members := range [][]matchmaking.Candidate{p.Team1, p.Team2} {
+		for _, c := range members {
+			userMatches = append(userMatches, models.DBUserMatch{
+				UserID:    c.UserID,
+				MatchID:   match.Id,
+				Team:      team + 1,
+				CreatedAt: now,
+			})
+			entryIDs = append(entryIDs, c.EntryID)
+		}
+	}
+
+	if err := db.CreateMatchFromQueue(ctx, match, userMatches, entryIDs, engines.NewRanking, now); err != nil {
+		return match, err
+	}
+	return match, nil
+}
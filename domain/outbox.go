@@ -0,0 +1,170 @@
+package domain
+
+import (
+	"PLIC/mailer"
+	"PLIC/models"
+	"PLIC/outbox"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
+// MatchEmailStore is what the match email handlers read, implemented by
+// database.Database.
+type MatchEmailStore interface {
+	GetMatchById(ctx context.Context, id string) (*models.DBMatches, error)
+	GetCourtByID(ctx context.Context, id string) (*models.DBCourt, error)
+	GetUserById(ctx context.Context, id string) (*models.DBUsers, error)
+	GetUserInMatch(ctx context.Context, userID, matchID string) (*models.DBUserMatch, error)
+}
+
+// MatchEmails delivers the match emails queued in the outbox, one recipient per
+// event. Each handler reads the match as it is when the event is delivered.
+type MatchEmails struct {
+	Store MatchEmailStore
+	Mail  mailer.MailSender
+}
+
+// errSkip ends the delivery of an event that has become pointless, e.g. because its
+// recipient deleted their account.
+var errSkip = errors.New("nothing to deliver")
+
+// OutboxHandlers returns the handlers of every outbox event type.
+func OutboxHandlers(store MatchEmailStore, mail mailer.MailSender, push PushSender) map[models.OutboxEventType]outbox.Handler {
+	h := MatchEmails{Store: store, Mail: mail}
+	p := MatchPushes{Store: store, Push: push}
+	return map[models.OutboxEventType]outbox.Handler{
+		models.OutboxMatchResultEmail:     outbox.HandlerFunc(h.SendResult),
+		models.OutboxMatchCancelledEmail:  outbox.HandlerFunc(h.SendCancelled),
+		models.OutboxMatchInvitationEmail: outbox.HandlerFunc(h.SendInvitation),
+		models.OutboxMatchFoundEmail:      outbox.HandlerFunc(h.SendMatchFound),
+		models.OutboxMatchFilledPush:      outbox.HandlerFunc(p.SendMatchFilled),
+		models.OutboxMatchStartingPush:    outbox.HandlerFunc(p.SendMatchStarting),
+		models.OutboxScoreVotePush:        outbox.HandlerFunc(p.SendScoreVote),
+		models.OutboxMatchResultPush:      outbox.HandlerFunc(p.SendResult),
+	}
+}
+
+// loadMatchRecipient decodes the payload and reads the match, its court and the
+// recipient.
+func loadMatchRecipient(ctx context.Context, store MatchEmailStore, event models.DBOutboxEvent) (models.MatchRecipientPayload, *models.DBMatches, *models.DBCourt, *models.DBUsers, error) {
+	var p models.MatchRecipientPayload
+	if err := json.Unmarshal(event.Payload, &p); err != nil {
+		return p, nil, nil, nil, outbox.Permanent(fmt.Errorf("invalid payload: %w", err))
+	}
+
+	match, err := store.GetMatchById(ctx, p.MatchID)
+	if err != nil {
+		return p, nil, nil, nil, err
+	}
+	if match == nil {
+		return p, nil, nil, nil, errSkip
+	}
+	court, err := store.GetCourtByID(ctx, match.CourtID)
+	if err != nil {
+		return p, nil, nil, nil, err
+	}
+	if court == nil {
+		return p, nil, nil, nil, outbox.Permanent(fmt.Errorf("court %s not found", match.CourtID))
+	}
+	user, err := store.GetUserById(ctx, p.UserID)
+	if err != nil {
+		return p, nil, nil, nil, err
+	}
+	if user == nil {
+		return p, nil, nil, nil, errSkip
+	}
+	return p, match, court, user, nil
+}
+
+// deliver runs send once the event is loaded, treating errSkip as done.
+func (h MatchEmails) deliver(ctx context.Context, event models.DBOutboxEvent, send func(models.MatchRecipientPayload, models.DBMatches, models.DBCourt, models.DBUsers) error) error {
+	logger := log.With().
+		Str("method", "MatchEmails").
+		Str("event_id", event.Id).
+		Str("type", string(event.Type)).
+		Logger()
+
+	p, match, court, user, err := loadMatchRecipient(ctx, h.Store, event)
+	if errors.Is(err, errSkip) {
+		logger.Warn().Msg("match or recipient gone, email skipped")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := send(p, *match, *court, *user); errors.Is(err, errSkip) {
+		logger.Warn().Str("match_id", match.Id).Str("user_id", user.Id).Msg("email no longer relevant, skipped")
+		return nil
+	} else if errors.Is(err, mailer.ErrThrottled) {
+		// The send log has the same email to this recipient, e.g. sent just before a
+		// crash kept the event from being marked done.
+		logger.Warn().Str("match_id", match.Id).Str("user_id", user.Id).Msg("email already sent, skipped")
+		return nil
+	} else if err != nil {
+		return err
+	}
+	logger.Info().Str("match_id", match.Id).Str("email", user.Email).Msg("match email sent")
+	return nil
+}
+
+// SendResult sends the final score to a player, from their team's point of view.
+func (h MatchEmails) SendResult(ctx context.Context, event models.DBOutboxEvent) error {
+	return h.deliver(ctx, event, func(p models.MatchRecipientPayload, match models.DBMatches, court models.DBCourt, u models.DBUsers) error {
+		if match.Score1 == nil || match.Score2 == nil {
+			return errSkip
+		}
+		um, err := h.Store.GetUserInMatch(ctx, u.Id, match.Id)
+		if err != nil {
+			return err
+		}
+		if um == nil {
+			return errSkip
+		}
+
+		teamScore, oppScore := *match.Score1, *match.Score2
+		if um.Team == 2 {
+			teamScore, oppScore = oppScore, teamScore
+		}
+		return h.Mail.SendMatchResultEmail(match.Id, u.Email, u.Username, match.Sport, court.Name, teamScore, oppScore, u.Locale)
+	})
+}
+
+// SendCancelled tells a player their match was cancelled.
+func (h MatchEmails) SendCancelled(ctx context.Context, event models.DBOutboxEvent) error {
+	return h.deliver(ctx, event, func(_ models.MatchRecipientPayload, match models.DBMatches, court models.DBCourt, u models.DBUsers) error {
+		return h.Mail.SendMatchCancelledEmail(match.Id, u.Email, u.Username, match.Sport, court.Name, match.Date, u.Locale)
+	})
+}
+
+// SendInvitation tells a friend the match creator invited them.
+func (h MatchEmails) SendInvitation(ctx context.Context, event models.DBOutboxEvent) error {
+	return h.deliver(ctx, event, func(p models.MatchRecipientPayload, match models.DBMatches, court models.DBCourt, u models.DBUsers) error {
+		inviter, err := h.Store.GetUserById(ctx, p.InviterID)
+		if err != nil {
+			return err
+		}
+		if inviter == nil {
+			return errSkip
+		}
+		return h.Mail.SendMatchInvitationEmail(match.Id, u.Email, u.Username, inviter.Username, match.Sport, court.Name, match.Date, u.Locale)
+	})
+}
+
+// SendMatchFound tells a player from the matchmaking queue when, where and in which
+// team they play.
+func (h MatchEmails) SendMatchFound(ctx context.Context, event models.DBOutboxEvent) error {
+	return h.deliver(ctx, event, func(_ models.MatchRecipientPayload, match models.DBMatches, court models.DBCourt, u models.DBUsers) error {
+		um, err := h.Store.GetUserInMatch(ctx, u.Id, match.Id)
+		if err != nil {
+			return err
+		}
+		if um == nil {
+			return errSkip
+		}
+		return h.Mail.SendMatchFoundEmail(match.Id, u.Email, u.Username, match.Sport, court.Name, match.Date, um.Team, u.Locale)
+	})
+}
@@ -0,0 +1,285 @@
+package domain
+
+import (
+	"PLIC/i18n"
+	"PLIC/mailer"
+	"PLIC/models"
+	"PLIC/notifier"
+	"PLIC/outbox"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEmailStore serves the match email handlers from memory.
+type fakeEmailStore struct {
+	matches     map[string]models.DBMatches
+	courts      map[string]models.DBCourt
+	users       map[string]models.DBUsers
+	userMatches map[string]models.DBUserMatch
+}
+
+func (f fakeEmailStore) GetMatchById(_ context.Context, id string) (*models.DBMatches, error) {
+	if m, ok := f.matches[id]; ok {
+		return &m, nil
+	}
+	return nil, nil
+}
+
+func (f fakeEmailStore) GetCourtByID(_ context.Context, id string) (*models.DBCourt, error) {
+	if c, ok := f.courts[id]; ok {
+		return &c, nil
+	}
+	return nil, nil
+}
+
+func (f fakeEmailStore) GetUserById(_ context.Context, id string) (*models.DBUsers, error) {
+	if u, ok := f.users[id]; ok {
+		return &u, nil
+	}
+	return nil, nil
+}
+
+func (f fakeEmailStore) GetUserInMatch(_ context.Context, userID, matchID string) (*models.DBUserMatch, error) {
+	if um, ok := f.userMatches[userID+":"+matchID]; ok {
+		return &um, nil
+	}
+	return nil, nil
+}
+
+// failingMailer fails every cancellation email.
+type failingMailer struct {
+	*mailer.MockMailer
+}
+
+func (failingMailer) SendMatchCancelledEmail(string, string, string, models.Sport, string, time.Time, i18n.Locale) error {
+	return errors.New("smtp down")
+}
+
+// throttledMailer reports every invitation email as already sent.
+type throttledMailer struct {
+	*mailer.MockMailer
+}
+
+func (throttledMailer) SendMatchInvitationEmail(string, string, string, string, models.Sport, string, time.Time, i18n.Locale) error {
+	return fmt.Errorf("match_invitation email: %w", mailer.ErrThrottled)
+}
+
+func TestMatchEmails(t *testing.T) {
+	court := models.NewDBCourtFixture()
+	creator := models.NewDBUsersFixture()
+	player := models.NewDBUsersFixture().WithUsername("player").WithEmail("player@example.com")
+	match := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(creator.Id).
+		WithScore1(3).
+		WithScore2(1)
+
+	store := fakeEmailStore{
+		matches: map[string]models.DBMatches{match.Id: match},
+		courts:  map[string]models.DBCourt{court.Id: court},
+		users:   map[string]models.DBUsers{creator.Id: creator, player.Id: player},
+		userMatches: map[string]models.DBUserMatch{
+			player.Id + ":" + match.Id: models.NewDBUserMatchFixture().WithUserId(player.Id).WithMatchId(match.Id).WithTeam(2),
+		},
+	}
+
+	type testCase struct {
+		name      string
+		eventType models.OutboxEventType
+		payload   any
+		mail      func(*mailer.MockMailer) mailer.MailSender
+		wantErr   bool
+		permanent bool
+		sent      map[string]int
+	}
+
+	testCases := []testCase{
+		{
+			name:      "Result email",
+			eventType: models.OutboxMatchResultEmail,
+			payload:   models.MatchRecipientPayload{MatchID: match.Id, UserID: player.Id},
+			sent:      map[string]int{"result": 1},
+		},
+		{
+			name:      "Invitation email",
+			eventType: models.OutboxMatchInvitationEmail,
+			payload:   models.MatchRecipientPayload{MatchID: match.Id, UserID: player.Id, InviterID: creator.Id},
+			sent:      map[string]int{"invitation": 1},
+		},
+		{
+			name:      "Match found email",
+			eventType: models.OutboxMatchFoundEmail,
+			payload:   models.MatchRecipientPayload{MatchID: match.Id, UserID: player.Id},
+			sent:      map[string]int{"match_found": 1},
+		},
+		{
+			name:      "Recipient no longer in the match -> skipped",
+			eventType: models.OutboxMatchResultEmail,
+			payload:   models.MatchRecipientPayload{MatchID: match.Id, UserID: creator.Id},
+			sent:      map[string]int{"result": 0},
+		},
+		{
+			name:      "Deleted recipient -> skipped",
+			eventType: models.OutboxMatchCancelledEmail,
+			payload:   models.MatchRecipientPayload{MatchID: match.Id, UserID: "deleted"},
+			sent:      map[string]int{"cancelled": 0},
+		},
+		{
+			name:      "Invalid payload -> permanent error",
+			eventType: models.OutboxMatchCancelledEmail,
+			payload:   []int{1},
+			wantErr:   true,
+			permanent: true,
+		},
+		{
+			name:      "SMTP failure -> error to retry",
+			eventType: models.OutboxMatchCancelledEmail,
+			payload:   models.MatchRecipientPayload{MatchID: match.Id, UserID: player.Id},
+			mail:      func(m *mailer.MockMailer) mailer.MailSender { return failingMailer{m} },
+			wantErr:   true,
+		},
+		{
+			name:      "Email already in the send log -> done",
+			eventType: models.OutboxMatchInvitationEmail,
+			payload:   models.MatchRecipientPayload{MatchID: match.Id, UserID: player.Id, InviterID: creator.Id},
+			mail:      func(m *mailer.MockMailer) mailer.MailSender { return throttledMailer{m} },
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			mock := mailer.NewMockMailer()
+			var mail mailer.MailSender = mock
+			if c.mail != nil {
+				mail = c.mail(mock)
+			}
+
+			event := models.NewDBOutboxEventFixture().WithType(c.eventType).WithPayload(c.payload)
+			err := OutboxHandlers(store, mail, &recordingPush{})[c.eventType].Handle(context.Background(), event)
+
+			if !c.wantErr {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				require.Equal(t, c.permanent, outbox.IsPermanent(err))
+			}
+			for kind, n := range c.sent {
+				require.Equal(t, n, mock.GetSentCounts(kind), kind)
+			}
+		})
+	}
+}
+
+// recordingPush records the notifications instead of sending them.
+type recordingPush struct {
+	sent []notifier.Notification
+	err  error
+}
+
+func (r *recordingPush) Notify(_ context.Context, _ string, _ models.NotificationKind, n notifier.Notification) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	r.sent = append(r.sent, n)
+	return 1, nil
+}
+
+func TestMatchPushes(t *testing.T) {
+	court := models.NewDBCourtFixture().WithName("Court Central")
+	player := models.NewDBUsersFixture()
+	english := models.NewDBUsersFixture().WithLocale(i18n.EN)
+	valide := models.NewDBMatchesFixture().WithCourtId(court.Id).WithCurrentState(models.Valide)
+	cancelled := models.NewDBMatchesFixture().WithCourtId(court.Id).WithCurrentState(models.Annule)
+	finished := models.NewDBMatchesFixture().WithCourtId(court.Id).WithCurrentState(models.Termine).WithScore1(3).WithScore2(5)
+
+	store := fakeEmailStore{
+		matches: map[string]models.DBMatches{valide.Id: valide, cancelled.Id: cancelled, finished.Id: finished},
+		courts:  map[string]models.DBCourt{court.Id: court},
+		users:   map[string]models.DBUsers{player.Id: player, english.Id: english},
+		userMatches: map[string]models.DBUserMatch{
+			english.Id + ":" + finished.Id: models.NewDBUserMatchFixture().WithUserId(english.Id).WithMatchId(finished.Id).WithTeam(1),
+			player.Id + ":" + finished.Id:  models.NewDBUserMatchFixture().WithUserId(player.Id).WithMatchId(finished.Id).WithTeam(2),
+		},
+	}
+
+	type testCase struct {
+		name      string
+		eventType models.OutboxEventType
+		matchID   string
+		userID    string
+		pushErr   error
+		wantErr   bool
+		wantBody  string
+	}
+
+	testCases := []testCase{
+		{
+			name:      "Match filled",
+			eventType: models.OutboxMatchFilledPush,
+			matchID:   valide.Id,
+			wantBody:  "Court Central",
+		},
+		{
+			name:      "Starting soon reminder of a cancelled match -> skipped",
+			eventType: models.OutboxMatchStartingPush,
+			matchID:   cancelled.Id,
+		},
+		{
+			name:      "Score vote of a match no longer waiting for scores -> skipped",
+			eventType: models.OutboxScoreVotePush,
+			matchID:   valide.Id,
+		},
+		{
+			name:      "Result from the player's team point of view",
+			eventType: models.OutboxMatchResultPush,
+			matchID:   finished.Id,
+			wantBody:  "5 - 3",
+		},
+		{
+			name:      "Result in the player's language",
+			eventType: models.OutboxMatchResultPush,
+			matchID:   finished.Id,
+			userID:    english.Id,
+			wantBody:  "Your Football match at Court Central: 3 - 5.",
+		},
+		{
+			name:      "Provider failure -> error to retry",
+			eventType: models.OutboxMatchFilledPush,
+			matchID:   valide.Id,
+			pushErr:   errors.New("apns down"),
+			wantErr:   true,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			push := &recordingPush{err: c.pushErr}
+			userID := c.userID
+			if userID == "" {
+				userID = player.Id
+			}
+			event := models.NewDBOutboxEventFixture().
+				WithType(c.eventType).
+				WithPayload(models.MatchRecipientPayload{MatchID: c.matchID, UserID: userID})
+			err := OutboxHandlers(store, mailer.NewMockMailer(), push)[c.eventType].Handle(context.Background(), event)
+
+			if c.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if c.wantBody == "" {
+				require.Empty(t, push.sent)
+				return
+			}
+			require.Len(t, push.sent, 1)
+			require.Contains(t, push.sent[0].Body, c.wantBody)
+			require.Equal(t, c.matchID, push.sent[0].Data["match_id"])
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"PLIC/i18n"
+	"PLIC/mailer"
+	"PLIC/models"
+	"PLIC/notifier"
+	"context"
+	"errors"
+
+	"github.com/rs/zerolog/log"
+)
+
+// PushSender sends a push notification to every device of a user, implemented by
+// notifier.Notifier.
+type PushSender interface {
+	Notify(ctx context.Context, userID string, kind models.NotificationKind, n notifier.Notification) (int, error)
+}
+
+// MatchPushes delivers the match push notifications queued in the outbox, one
+// recipient per event. Like the emails, each handler reads the match as it is when
+// the event is delivered, so a reminder about a match cancelled since is dropped.
+type MatchPushes struct {
+	Store MatchEmailStore
+	Push  PushSender
+}
+
+// deliver runs build once the event is loaded and sends the notification it returns,
+// treating errSkip as done.
+func (h MatchPushes) deliver(ctx context.Context, event models.DBOutboxEvent, kind models.NotificationKind, build func(models.DBMatches, models.DBCourt, models.DBUsers) (notifier.Notification, error)) error {
+	logger := log.With().
+		Str("method", "MatchPushes").
+		Str("event_id", event.Id).
+		Str("type", string(event.Type)).
+		Logger()
+
+	_, match, court, user, err := loadMatchRecipient(ctx, h.Store, event)
+	if errors.Is(err, errSkip) {
+		logger.Warn().Msg("match or recipient gone, push skipped")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	n, err := build(*match, *court, *user)
+	if errors.Is(err, errSkip) {
+		logger.Info().Str("match_id", match.Id).Str("user_id", user.Id).Msg("push no longer relevant, skipped")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	n.Data = map[string]string{"type": string(kind), "match_id": match.Id}
+	sent, err := h.Push.Notify(ctx, user.Id, kind, n)
+	if err != nil {
+		return err
+	}
+	logger.Info().Str("match_id", match.Id).Str("user_id", user.Id).Int("devices", sent).Msg("match push sent")
+	return nil
+}
+
+// SendMatchFilled tells a player every seat of their match is taken.
+func (h MatchPushes) SendMatchFilled(ctx context.Context, event models.DBOutboxEvent) error {
+	return h.deliver(ctx, event, models.NotifyMatchFilled, func(match models.DBMatches, court models.DBCourt, u models.DBUsers) (notifier.Notification, error) {
+		if match.CurrentState != models.Valide {
+			return notifier.Notification{}, errSkip
+		}
+		label, emoji := mailer.SportMeta(u.Locale, match.Sport)
+		return notifier.Notification{
+			Title: emoji + " " + i18n.T(u.Locale, i18n.PushMatchFilledTitle),
+			Body:  i18n.T(u.Locale, i18n.PushMatchFilledBody, label, court.Name, match.Date.Format(i18n.T(u.Locale, i18n.ShortDateTimeLayout))),
+		}, nil
+	})
+}
+
+// SendMatchStarting reminds a player their match starts soon.
+func (h MatchPushes) SendMatchStarting(ctx context.Context, event models.DBOutboxEvent) error {
+	return h.deliver(ctx, event, models.NotifyMatchStarting, func(match models.DBMatches, court models.DBCourt, u models.DBUsers) (notifier.Notification, error) {
+		if match.CurrentState != models.Valide {
+			return notifier.Notification{}, errSkip
+		}
+		label, emoji := mailer.SportMeta(u.Locale, match.Sport)
+		return notifier.Notification{
+			Title: emoji + " " + i18n.T(u.Locale, i18n.PushMatchStartingTitle),
+			Body:  i18n.T(u.Locale, i18n.PushMatchStartingBody, label, court.Name, match.Date.Format(i18n.T(u.Locale, i18n.TimeLayout))),
+		}, nil
+	})
+}
+
+// SendScoreVote asks a player to vote the score of their match.
+func (h MatchPushes) SendScoreVote(ctx context.Context, event models.DBOutboxEvent) error {
+	return h.deliver(ctx, event, models.NotifyScoreVote, func(match models.DBMatches, court models.DBCourt, u models.DBUsers) (notifier.Notification, error) {
+		if match.CurrentState != models.ManqueScore {
+			return notifier.Notification{}, errSkip
+		}
+		label, emoji := mailer.SportMeta(u.Locale, match.Sport)
+		return notifier.Notification{
+			Title: emoji + " " + i18n.T(u.Locale, i18n.PushScoreVoteTitle),
+			Body:  i18n.T(u.Locale, i18n.PushScoreVoteBody, label, court.Name),
+		}, nil
+	})
+}
+
+// SendResult tells a player the final score, from their team's point of view.
+func (h MatchPushes) SendResult(ctx context.Context, event models.DBOutboxEvent) error {
+	return h.deliver(ctx, event, models.NotifyMatchResult, func(match models.DBMatches, court models.DBCourt, u models.DBUsers) (notifier.Notification, error) {
+		if match.Score1 == nil || match.Score2 == nil {
+			return notifier.Notification{}, errSkip
+		}
+		um, err := h.Store.GetUserInMatch(ctx, u.Id, match.Id)
+		if err != nil {
+			return notifier.Notification{}, err
+		}
+		if um == nil {
+			return notifier.Notification{}, errSkip
+		}
+
+		teamScore, oppScore := *match.Score1, *match.Score2
+		if um.Team == 2 {
+			teamScore, oppScore = oppScore, teamScore
+		}
+		label, emoji := mailer.SportMeta(u.Locale, match.Sport)
+		return notifier.Notification{
+			Title: emoji + " " + i18n.T(u.Locale, i18n.PushMatchResultTitle),
+			Body:  i18n.T(u.Locale, i18n.PushMatchResultBody, label, court.Name, teamScore, oppScore),
+		}, nil
+	})
+}
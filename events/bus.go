@@ -0,0 +1,21 @@
+// Package events carries the match events from the handlers that change a match to
+// the clients following it.
+package events
+
+import (
+	"PLIC/models"
+	"context"
+)
+
+// Bus publishes match events and streams them to the subscribers of a match. The
+// in-memory implementation only reaches the subscribers of the same process; a
+// deployment running several instances needs a shared transport behind this interface.
+type Bus interface {
+	// Publish sends the event to the current subscribers of its match. It does not
+	// wait for them to read it.
+	Publish(ctx context.Context, event models.MatchEvent) error
+	// Subscribe returns the events published for the match from now on. The channel
+	// is closed once ctx is done, or when the subscriber falls too far behind and
+	// must resync.
+	Subscribe(ctx context.Context, matchID string) (<-chan models.MatchEvent, error)
+}
@@ -0,0 +1,77 @@
+package events
+
+import (
+	"PLIC/models"
+	"context"
+	"sync"
+)
+
+// subscriberBuffer is how many events a subscriber can lag behind before being
+// dropped.
+const subscriberBuffer = 16
+
+type subscriber struct {
+	ch chan models.MatchEvent
+}
+
+// MemoryBus is a Bus local to the process. It is safe for concurrent use.
+type MemoryBus struct {
+	mu   sync.Mutex
+	subs map[string]map[*subscriber]struct{}
+}
+
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{subs: make(map[string]map[*subscriber]struct{})}
+}
+
+// Publish never blocks: a subscriber whose buffer is full is removed and its channel
+// closed, so that it refetches the match instead of silently missing events.
+func (b *MemoryBus) Publish(_ context.Context, event models.MatchEvent) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subs[event.MatchID] {
+		select {
+		case sub.ch <- event:
+		default:
+			b.removeLocked(event.MatchID, sub)
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBus) Subscribe(ctx context.Context, matchID string) (<-chan models.MatchEvent, error) {
+	sub := &subscriber{ch: make(chan models.MatchEvent, subscriberBuffer)}
+
+	b.mu.Lock()
+	if b.subs[matchID] == nil {
+		b.subs[matchID] = make(map[*subscriber]struct{})
+	}
+	b.subs[matchID][sub] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.removeLocked(matchID, sub)
+	}()
+
+	return sub.ch, nil
+}
+
+// removeLocked closes the channel of sub if it is still subscribed. b.mu must be held.
+func (b *MemoryBus) removeLocked(matchID string, sub *subscriber) {
+	subs, ok := b.subs[matchID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	close(sub.ch)
+	if len(subs) == 0 {
+		delete(b.subs, matchID)
+	}
+}
@@ -0,0 +1,66 @@
+package events
+
+import (
+	"PLIC/models"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryBus(t *testing.T) {
+	t.Run("Only the subscribers of the match receive its events", func(t *testing.T) {
+		bus := NewMemoryBus()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ch1, err := bus.Subscribe(ctx, "m1")
+		require.NoError(t, err)
+		ch2, err := bus.Subscribe(ctx, "m2")
+		require.NoError(t, err)
+
+		event := models.MatchEvent{Type: models.MatchEventPlayerJoined, MatchID: "m1", UserID: "u1"}
+		require.NoError(t, bus.Publish(ctx, event))
+
+		require.Equal(t, event, <-ch1)
+		require.Empty(t, ch2)
+	})
+
+	t.Run("Cancelling the context closes the channel", func(t *testing.T) {
+		bus := NewMemoryBus()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		ch, err := bus.Subscribe(ctx, "m1")
+		require.NoError(t, err)
+		cancel()
+
+		select {
+		case _, ok := <-ch:
+			require.False(t, ok)
+		case <-time.After(time.Second):
+			t.Fatal("channel not closed")
+		}
+
+		require.NoError(t, bus.Publish(context.Background(), models.MatchEvent{MatchID: "m1"}))
+	})
+
+	t.Run("A subscriber too far behind is dropped", func(t *testing.T) {
+		bus := NewMemoryBus()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ch, err := bus.Subscribe(ctx, "m1")
+		require.NoError(t, err)
+
+		for i := 0; i <= subscriberBuffer; i++ {
+			require.NoError(t, bus.Publish(ctx, models.MatchEvent{MatchID: "m1"}))
+		}
+
+		received := 0
+		for range ch {
+			received++
+		}
+		require.Equal(t, subscriberBuffer, received)
+	})
+}
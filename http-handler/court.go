@@ -2,8 +2,10 @@ package main
 
 import (
 	"PLIC/httpx"
+	"PLIC/i18n"
 	"PLIC/models"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/rs/zerolog/log"
@@ -28,19 +30,126 @@ func (s *Service) GetAllCourts(w http.ResponseWriter, r *http.Request, ai models
 
 	if !ai.IsConnected {
 		logger.Warn().Msg("unauthorized")
-		return httpx.WriteError(w, http.StatusUnauthorized, "not authorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
 	}
 
 	terrains, err := s.db.GetAllCourts(r.Context())
 	if err != nil {
 		logger.Error().Err(err).Msg("db get all courts failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "failed to fetch terrains")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 
 	logger.Info().Int("count", len(terrains)).Msg("courts fetched successfully")
 	return httpx.Write(w, http.StatusOK, terrains)
 }
 
+const (
+	defaultNearbyRadiusMeters = 5000.0
+	maxNearbyRadiusMeters     = 50000.0
+	defaultNearbyLimit        = 50
+	maxNearbyLimit            = 200
+)
+
+// GetNearbyCourts godoc
+// @Summary      Liste les terrains proches d'une position
+// @Description  Retourne les terrains situés dans un rayon donné autour d'un point, triés par distance croissante, avec la distance en mètres
+// @Tags         terrain
+// @Produce      json
+// @Param        lat       query     number  true   "Latitude du point de recherche"
+// @Param        lng       query     number  true   "Longitude du point de recherche"
+// @Param        radius_m  query     number  false  "Rayon de recherche en mètres (défaut 5000, max 50000)"
+// @Param        sport     query     string  false  "Ne garder que les terrains ayant accueilli ce sport"
+// @Param        limit     query     int     false  "Nombre maximum de terrains (défaut 50, max 200)"
+// @Success      200  {array}   models.NearbyCourtResponse  "Terrains triés par distance"
+// @Failure      400  {object}  models.Error  "Paramètres invalides"
+// @Failure      401  {object}  models.Error  "Utilisateur non autorisé"
+// @Failure      500  {object}  models.Error  "Erreur lors de la récupération des terrains"
+// @Router       /court/nearby [get]
+func (s *Service) GetNearbyCourts(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+	baseLogger := log.With().
+		Str("method", "GetNearbyCourts").
+		Str("user_id", ai.UserID).
+		Logger()
+
+	if !ai.IsConnected {
+		baseLogger.Warn().Msg("unauthorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+
+	query := r.URL.Query()
+
+	lat, err := parseFiniteFloat(query.Get("lat"))
+	if err != nil || lat < -90 || lat > 90 {
+		baseLogger.Warn().Str("lat", query.Get("lat")).Msg("invalid latitude")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrInvalidParameter, "lat")
+	}
+	lng, err := parseFiniteFloat(query.Get("lng"))
+	if err != nil || lng < -180 || lng > 180 {
+		baseLogger.Warn().Str("lng", query.Get("lng")).Msg("invalid longitude")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrInvalidParameter, "lng")
+	}
+
+	radius := defaultNearbyRadiusMeters
+	if raw := query.Get("radius_m"); raw != "" {
+		radius, err = parseFiniteFloat(raw)
+		if err != nil || radius <= 0 || radius > maxNearbyRadiusMeters {
+			baseLogger.Warn().Str("radius_m", raw).Msg("invalid radius")
+			return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrInvalidParameter, "radius_m")
+		}
+	}
+
+	limit := defaultNearbyLimit
+	if raw := query.Get("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit <= 0 || limit > maxNearbyLimit {
+			baseLogger.Warn().Str("limit", raw).Msg("invalid limit")
+			return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrInvalidParameter, "limit")
+		}
+	}
+
+	var sport *models.Sport
+	if raw := query.Get("sport"); raw != "" {
+		sp := models.Sport(raw)
+		switch sp {
+		case models.Basket, models.Foot, models.PingPong:
+		default:
+			baseLogger.Warn().Str("sport", raw).Msg("invalid sport")
+			return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrInvalidSport)
+		}
+		sport = &sp
+	}
+
+	logger := baseLogger.With().
+		Float64("lat", lat).
+		Float64("lng", lng).
+		Float64("radius_m", radius).
+		Int("limit", limit).
+		Logger()
+
+	courts, err := s.db.GetCourtsNearby(r.Context(), models.NearbyCourtsQuery{
+		Latitude:     lat,
+		Longitude:    lng,
+		RadiusMeters: radius,
+		Sport:        sport,
+		Limit:        limit,
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("db get nearby courts failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+
+	res := make([]models.NearbyCourtResponse, 0, len(courts))
+	for _, c := range courts {
+		res = append(res, models.NearbyCourtResponse{
+			DBCourt:        c.DBCourt,
+			DistanceMeters: c.DistanceMeters,
+		})
+	}
+
+	logger.Info().Int("count", len(res)).Msg("nearby courts fetched")
+	return httpx.Write(w, http.StatusOK, res)
+}
+
 // GetCourtByID godoc
 // @Summary      Récupère un terrain par son ID
 // @Description  Retourne les informations d’un terrain (court) en fonction de son identifiant passé dans l’URL
@@ -66,22 +175,22 @@ func (s *Service) GetCourtByID(w http.ResponseWriter, r *http.Request, ai models
 
 	if !ai.IsConnected {
 		logger.Warn().Msg("unauthorized")
-		return httpx.WriteError(w, http.StatusUnauthorized, "not authorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
 	}
 
 	if id == "" {
 		logger.Warn().Msg("missing id in url params")
-		return httpx.WriteError(w, http.StatusBadRequest, "missing id in url params")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMissingParameter, "id")
 	}
 
 	court, err := s.db.GetCourtByID(r.Context(), id)
 	if err != nil {
 		logger.Error().Err(err).Msg("db get court by id failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "failed to fetch court")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 	if court == nil {
 		logger.Warn().Msg("court not found")
-		return httpx.WriteError(w, http.StatusNotFound, "court not found")
+		return httpx.WriteError(w, r, http.StatusNotFound, i18n.ErrCourtNotFound)
 	}
 
 	logger.Info().Msg("court fetched successfully")
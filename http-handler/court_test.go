@@ -116,3 +116,130 @@ func Test_GetCourtByID(t *testing.T) {
 		})
 	}
 }
+
+func Test_GetNearbyCourts(t *testing.T) {
+	type expected struct {
+		code int
+		ids  []string
+	}
+
+	type testCase struct {
+		name     string
+		query    string
+		auth     models.AuthInfo
+		expected expected
+	}
+
+	center := models.NewDBCourtFixture().
+		WithName("Hôtel de Ville").
+		WithLatitude(48.8566).
+		WithLongitude(2.3522)
+	louvre := models.NewDBCourtFixture().
+		WithName("Louvre").
+		WithLatitude(48.8606).
+		WithLongitude(2.3376)
+	lyon := models.NewDBCourtFixture().
+		WithName("Lyon").
+		WithLatitude(45.7640).
+		WithLongitude(4.8357)
+
+	connected := models.AuthInfo{IsConnected: true, UserID: uuid.NewString()}
+
+	testCases := []testCase{
+		{
+			name:  "Courts sorted by distance",
+			query: "lat=48.8566&lng=2.3522&radius_m=5000",
+			auth:  connected,
+			expected: expected{
+				code: http.StatusOK,
+				ids:  []string{center.Id, louvre.Id},
+			},
+		},
+		{
+			name:  "Default radius excludes far courts",
+			query: "lat=45.7640&lng=4.8357",
+			auth:  connected,
+			expected: expected{
+				code: http.StatusOK,
+				ids:  []string{lyon.Id},
+			},
+		},
+		{
+			name:     "Missing latitude",
+			query:    "lng=2.3522",
+			auth:     connected,
+			expected: expected{code: http.StatusBadRequest},
+		},
+		{
+			name:     "NaN latitude",
+			query:    "lat=NaN&lng=2.3522",
+			auth:     connected,
+			expected: expected{code: http.StatusBadRequest},
+		},
+		{
+			name:     "NaN radius",
+			query:    "lat=48.8566&lng=2.3522&radius_m=NaN",
+			auth:     connected,
+			expected: expected{code: http.StatusBadRequest},
+		},
+		{
+			name:     "Radius too large",
+			query:    "lat=48.8566&lng=2.3522&radius_m=1000000",
+			auth:     connected,
+			expected: expected{code: http.StatusBadRequest},
+		},
+		{
+			name:     "Wrong sport",
+			query:    "lat=48.8566&lng=2.3522&sport=tennis",
+			auth:     connected,
+			expected: expected{code: http.StatusBadRequest},
+		},
+		{
+			name:     "Not connected",
+			query:    "lat=48.8566&lng=2.3522",
+			auth:     models.AuthInfo{},
+			expected: expected{code: http.StatusUnauthorized},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() {
+				if err := cleanup(); err != nil {
+					t.Logf("cleanup error: %v", err)
+				}
+			}()
+			s.loadFixtures(DBFixtures{
+				Courts: []models.DBCourt{center, louvre, lyon},
+			})
+
+			r := httptest.NewRequest("GET", "/court/nearby?"+c.query, nil)
+			w := httptest.NewRecorder()
+			err := s.GetNearbyCourts(w, r, c.auth)
+			require.NoError(t, err)
+
+			resp := w.Result()
+			defer func(Body io.ReadCloser) {
+				_ = Body.Close()
+			}(resp.Body)
+
+			require.Equal(t, c.expected.code, resp.StatusCode)
+			if c.expected.code != http.StatusOK {
+				return
+			}
+
+			var res []models.NearbyCourtResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+
+			ids := make([]string, 0, len(res))
+			for _, ct := range res {
+				ids = append(ids, ct.Id)
+			}
+			require.Equal(t, c.expected.ids, ids)
+		})
+	}
+}
@@ -42,7 +42,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Allows a connected param to change their password",
+                "description": "Allows a connected param to change their password. Every other session of the user is revoked.",
                 "consumes": [
                     "application/json"
                 ],
@@ -118,6 +118,81 @@ const docTemplate = `{
                 }
             }
         },
+        "/court/nearby": {
+            "get": {
+                "description": "Retourne les terrains situés dans un rayon donné autour d'un point, triés par distance croissante, avec la distance en mètres",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "terrain"
+                ],
+                "summary": "Liste les terrains proches d'une position",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "Latitude du point de recherche",
+                        "name": "lat",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Longitude du point de recherche",
+                        "name": "lng",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Rayon de recherche en mètres (défaut 5000, max 50000)",
+                        "name": "radius_m",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ne garder que les terrains ayant accueilli ce sport",
+                        "name": "sport",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Nombre maximum de terrains (défaut 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Terrains triés par distance",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.NearbyCourtResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Paramètres invalides",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Utilisateur non autorisé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur lors de la récupération des terrains",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            }
+        },
         "/court/{id}": {
             "get": {
                 "description": "Retourne les informations d’un terrain (court) en fonction de son identifiant passé dans l’URL",
@@ -165,9 +240,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/forgot-password": {
+        "/devices": {
             "post": {
-                "description": "Generate a new password and send it via email to the param if the account exists",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Enregistre le jeton push de l'appareil (APNs pour ios, FCM pour android). Un jeton déjà enregistré est rattaché à l'utilisateur courant. Les jetons refusés par le fournisseur sont oubliés automatiquement.",
                 "consumes": [
                     "application/json"
                 ],
@@ -175,32 +255,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "notification"
                 ],
-                "summary": "Request password reset",
+                "summary": "Enregistre un appareil pour les notifications push",
                 "parameters": [
                     {
-                        "description": "Email of the param",
-                        "name": "request",
+                        "description": "Jeton et plateforme de l'appareil",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.MailerRequest"
+                            "$ref": "#/definitions/models.RegisterDeviceRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Success even if param does not exist (for security)"
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.DeviceResponse"
+                        }
                     },
                     "400": {
-                        "description": "Bad request (invalid JSON or email format)",
+                        "description": "Jeton manquant ou plateforme invalide",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Utilisateur non autorisé",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Erreur serveur",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
@@ -208,61 +297,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/hello_world": {
-            "get": {
-                "description": "Returns a greeting with the provided name",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "testing"
-                ],
-                "summary": "Say Hello",
-                "parameters": [
+        "/devices/{token}": {
+            "delete": {
+                "security": [
                     {
-                        "type": "string",
-                        "description": "Name to greet",
-                        "name": "name",
-                        "in": "query",
-                        "required": true
+                        "BearerAuth": []
                     }
                 ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.HelloWorldResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/join/match/{id}": {
-            "post": {
-                "description": "Permet à un utilisateur authentifié de rejoindre un match existant, si ce n’est pas déjà fait",
+                "description": "Arrête l'envoi des notifications push à l'appareil, par exemple à la déconnexion",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "match"
+                    "notification"
                 ],
-                "summary": "Un utilisateur rejoint un match",
+                "summary": "Désenregistre un appareil",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Identifiant du match",
-                        "name": "id",
+                        "description": "Jeton push de l'appareil",
+                        "name": "token",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Informations pour rejoindre un match (team)",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.JoinMatchRequest"
-                        }
                     }
                 ],
                 "responses": {
@@ -270,7 +326,7 @@ const docTemplate = `{
                         "description": "OK"
                     },
                     "400": {
-                        "description": "Identifiant manquant",
+                        "description": "Jeton manquant",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
@@ -282,19 +338,13 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Match non trouvé",
-                        "schema": {
-                            "$ref": "#/definitions/models.Error"
-                        }
-                    },
-                    "409": {
-                        "description": "Utilisateur déjà inscrit au match",
+                        "description": "Appareil non trouvé",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
                     "500": {
-                        "description": "Erreur lors de l'inscription de l'utilisateur au match",
+                        "description": "Erreur serveur",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
@@ -302,9 +352,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/login": {
+        "/forgot-password": {
             "post": {
-                "description": "Authenticate a user with username and password",
+                "description": "Send a single-use link to choose a new password if the account exists. Previous links of the user stop working.",
                 "consumes": [
                     "application/json"
                 ],
@@ -314,33 +364,30 @@ const docTemplate = `{
                 "tags": [
                     "auth"
                 ],
-                "summary": "Login a user",
+                "summary": "Request password reset",
                 "parameters": [
                     {
-                        "description": "User credentials",
+                        "description": "Email of the param",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.LoginRequest"
+                            "$ref": "#/definitions/models.MailerRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.LoginResponse"
-                        }
+                        "description": "Success even if param does not exist (for security)"
                     },
                     "400": {
-                        "description": "Bad request",
+                        "description": "Bad request (invalid JSON or email format)",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
-                    "401": {
-                        "description": "Invalid credentials",
+                    "429": {
+                        "description": "A reset link was sent to this email a few seconds ago",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
@@ -354,46 +401,31 @@ const docTemplate = `{
                 }
             }
         },
-        "/match": {
-            "post": {
-                "description": "Enregistre un nouveau match en base de données à partir des données fournies en JSON",
-                "consumes": [
-                    "application/json"
+        "/friends": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Retourne les amis de l'utilisateur connecté, triés par nom d'utilisateur",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "match"
-                ],
-                "summary": "Crée un nouveau match",
-                "parameters": [
-                    {
-                        "description": "Objet match à créer",
-                        "name": "match",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.MatchRequest"
-                        }
-                    }
+                    "friend"
                 ],
+                "summary": "Liste les amis",
                 "responses": {
-                    "201": {
-                        "description": "Match créé avec succès",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.FriendResponse"
                             }
                         }
                     },
-                    "400": {
-                        "description": "Données invalides ou champ ID manquant",
-                        "schema": {
-                            "$ref": "#/definitions/models.Error"
-                        }
-                    },
                     "401": {
                         "description": "Utilisateur non autorisé",
                         "schema": {
@@ -401,7 +433,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Erreur lors de la création du match",
+                        "description": "Erreur serveur",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
@@ -409,24 +441,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/match/all": {
+        "/friends/requests": {
             "get": {
-                "description": "Retourne la liste complète de tous les matchs stockés en base",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retourne les demandes d'ami reçues et envoyées par l'utilisateur connecté qui attendent une réponse, des plus anciennes aux plus récentes",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "match"
+                    "friend"
                 ],
-                "summary": "Liste tous les matchs",
+                "summary": "Liste les demandes d'ami en attente",
                 "responses": {
                     "200": {
-                        "description": "Liste des matchs",
+                        "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.MatchResponse"
-                            }
+                            "$ref": "#/definitions/models.FriendRequestsResponse"
                         }
                     },
                     "401": {
@@ -436,7 +470,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Erreur serveur lors de la récupération des matchs",
+                        "description": "Erreur serveur",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
@@ -444,34 +478,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/match/{id}": {
-            "get": {
-                "description": "Retourne les informations d’un match en fonction de son identifiant passé en paramètre de requête",
+        "/friends/{userId}/accept": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Accepte la demande d'ami en attente envoyée par l'utilisateur",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "match"
+                    "friend"
                 ],
-                "summary": "Récupère un match par son ID",
+                "summary": "Accepte une demande d'ami",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Identifiant du match",
-                        "name": "id",
+                        "description": "Identifiant de l'utilisateur qui a envoyé la demande",
+                        "name": "userId",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Match trouvé",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.MatchResponse"
+                            "$ref": "#/definitions/models.FriendshipResponse"
                         }
                     },
                     "400": {
-                        "description": "ID manquant ou invalide",
+                        "description": "Identifiant manquant",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
@@ -483,43 +522,53 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Match non trouvé",
+                        "description": "Aucune demande en attente de cet utilisateur",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
                     "500": {
-                        "description": "Erreur serveur ou base de données",
+                        "description": "Erreur serveur",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Supprime un match via son ID",
+            }
+        },
+        "/friends/{userId}/block": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Supprime l'amitié ou la demande en cours avec l'utilisateur et l'empêche d'envoyer une nouvelle demande",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "match"
+                    "friend"
                 ],
-                "summary": "Supprime un match",
+                "summary": "Bloque un utilisateur",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Identifiant du match à supprimer",
-                        "name": "id",
+                        "description": "Identifiant de l'utilisateur à bloquer",
+                        "name": "userId",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.FriendshipResponse"
+                        }
                     },
                     "400": {
-                        "description": "ID manquant",
+                        "description": "Identifiant manquant ou égal à celui de l'appelant",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
@@ -530,8 +579,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.Error"
                         }
                     },
+                    "404": {
+                        "description": "Utilisateur non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
                     "500": {
-                        "description": "Erreur lors de la suppression du match",
+                        "description": "Erreur serveur",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
@@ -539,31 +594,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/match/{id}/finish": {
-            "patch": {
-                "description": "Passe un match de l’état \"En cours\" à \"Manque Score\" afin de permettre la saisie/validation des scores.",
-                "produces": [
-                    "application/json"
-                ],
+        "/friends/{userId}/decline": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Refuse la demande d'ami en attente envoyée par l'utilisateur. Celui-ci pourra en renvoyer une.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "match"
+                    "friend"
                 ],
-                "summary": "Termine un match (passage à la saisie des scores)",
+                "summary": "Refuse une demande d'ami",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID du match",
-                        "name": "id",
+                        "description": "Identifiant de l'utilisateur qui a envoyé la demande",
+                        "name": "userId",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.FriendshipResponse"
+                        }
                     },
                     "400": {
-                        "description": "ID manquant, mauvais état, ou utilisateur non inscrit au match",
+                        "description": "Identifiant manquant",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
@@ -575,13 +638,13 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Match non trouvé",
+                        "description": "Aucune demande en attente de cet utilisateur",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
                     "500": {
-                        "description": "Erreur serveur ou base de données",
+                        "description": "Erreur serveur",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
@@ -589,31 +652,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/match/{id}/start": {
-            "patch": {
-                "description": "Passe un match de l’état \"Valide\" à \"En cours\" et met à jour la date de début à maintenant.",
+        "/friends/{userId}/request": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Envoie une demande d'ami à l'utilisateur. Si celui-ci avait déjà envoyé une demande à l'appelant, l'amitié est acceptée directement. Une demande refusée peut être renvoyée.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "match"
+                    "friend"
                 ],
-                "summary": "Démarre un match",
+                "summary": "Envoie une demande d'ami",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID du match",
-                        "name": "id",
+                        "description": "Identifiant de l'utilisateur",
+                        "name": "userId",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "Statut de la relation : pending ou accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.FriendshipResponse"
+                        }
                     },
                     "400": {
-                        "description": "ID manquant, mauvais état, ou utilisateur non inscrit au match",
+                        "description": "Identifiant manquant ou égal à celui de l'appelant",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
@@ -624,14 +695,26 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.Error"
                         }
                     },
+                    "403": {
+                        "description": "L'un des deux utilisateurs a bloqué l'autre",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
                     "404": {
-                        "description": "Match non trouvé",
+                        "description": "Utilisateur non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Déjà amis ou demande déjà envoyée",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
                     "500": {
-                        "description": "Erreur serveur ou base de données",
+                        "description": "Erreur serveur",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
@@ -639,22 +722,22 @@ const docTemplate = `{
                 }
             }
         },
-        "/match/{id}/teams": {
+        "/hello_world": {
             "get": {
-                "description": "Renvoie la liste des joueurs répartis par équipe (team 1 et team 2) pour un match donné.\nChaque joueur est enrichi avec ses informations publiques (profil, stats, sports, terrains, etc.).",
+                "description": "Returns a greeting with the provided name",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "match"
+                    "testing"
                 ],
-                "summary": "Récupérer les équipes d’un match",
+                "summary": "Say Hello",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID du match",
-                        "name": "id",
-                        "in": "path",
+                        "description": "Name to greet",
+                        "name": "name",
+                        "in": "query",
                         "required": true
                     }
                 ],
@@ -662,58 +745,46 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.TeamsByMatchIdResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "ID du match manquant",
-                        "schema": {
-                            "$ref": "#/definitions/models.Error"
-                        }
-                    },
-                    "401": {
-                        "description": "Utilisateur non autorisé",
-                        "schema": {
-                            "$ref": "#/definitions/models.Error"
-                        }
-                    },
-                    "500": {
-                        "description": "Erreur serveur",
-                        "schema": {
-                            "$ref": "#/definitions/models.Error"
+                            "$ref": "#/definitions/models.HelloWorldResponse"
                         }
                     }
                 }
             }
         },
-        "/match/{id}/vote-status": {
-            "get": {
-                "description": "Renvoie l'équipe du joueur, si son équipe a voté et le score voté (nullable), et la même info pour l'équipe adverse. Ne fonctionne que si le match est en statut \"Termine\".",
+        "/join/match/{id}": {
+            "post": {
+                "description": "Permet à un utilisateur authentifié de rejoindre un match existant, si ce n’est pas déjà fait. L'inscription, la vérification des places et le passage à l'état Valide sont faits dans une seule transaction. Pour un match auto_balance, l'équipe demandée est ignorée : les équipes sont réparties selon l'ELO des joueurs quand le match est complet.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "match"
                 ],
-                "summary": "Statut de vote des scores (par équipe) pour un match terminé",
+                "summary": "Un utilisateur rejoint un match",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID du match",
+                        "description": "Identifiant du match",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Informations pour rejoindre un match (team, facultatif pour un match auto_balance)",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.JoinMatchRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.MatchVoteStatusResponse"
-                        }
+                        "description": "OK"
                     },
                     "400": {
-                        "description": "ID manquant, mauvais état",
+                        "description": "Identifiant manquant, équipe invalide ou complète, ou match dans un mauvais état",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
@@ -724,14 +795,26 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.Error"
                         }
                     },
+                    "403": {
+                        "description": "Adresse e-mail non vérifiée, ou match réservé aux amis ou sur invitation",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
                     "404": {
-                        "description": "Match ou utilisateur non trouvé",
+                        "description": "Match non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Utilisateur déjà inscrit au match",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
                     "500": {
-                        "description": "Erreur serveur",
+                        "description": "Erreur lors de l'inscription de l'utilisateur au match",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
@@ -739,55 +822,85 @@ const docTemplate = `{
                 }
             }
         },
-        "/matches/court/{courtId}": {
-            "get": {
-                "description": "Retourne les matchs associés à un terrain (court) via son ID",
+        "/login": {
+            "post": {
+                "description": "Authenticate a user with username and password",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "match"
+                    "auth"
                 ],
-                "summary": "Liste des matchs pour un court",
+                "summary": "Login a user",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Identifiant du terrain",
-                        "name": "courtId",
-                        "in": "path",
-                        "required": true
+                        "description": "User credentials",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.MatchResponse"
-                            }
+                            "$ref": "#/definitions/models.LoginResponse"
                         }
                     },
                     "400": {
-                        "description": "ID manquant",
+                        "description": "Bad request",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
                     "401": {
-                        "description": "Utilisateur non autorisé",
+                        "description": "Invalid credentials",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
-                    "404": {
-                        "description": "Aucun match trouvé pour ce terrain",
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke the session of the access token used for this request",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Log out the current device",
+                "responses": {
+                    "200": {
+                        "description": "Session revoked"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
                     "500": {
-                        "description": "Erreur interne serveur ou base",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
@@ -795,22 +908,33 @@ const docTemplate = `{
                 }
             }
         },
-        "/place": {
+        "/logout/all": {
             "post": {
-                "description": "Appelle l'API Google Places pour synchroniser les terrains autour d'une position donnée (Paris en dur pour l'instant)",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke every session of the connected user, including the current one",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "google"
+                    "auth"
                 ],
-                "summary": "Synchronise les terrains depuis l'API Google Places",
+                "summary": "Log out all devices",
                 "responses": {
-                    "201": {
-                        "description": "Synchro réussie"
+                    "200": {
+                        "description": "Sessions revoked"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
                     },
                     "500": {
-                        "description": "Erreur lors de la synchronisation",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
@@ -818,46 +942,60 @@ const docTemplate = `{
                 }
             }
         },
-        "/profile_picture/{id}": {
+        "/match": {
             "post": {
-                "description": "Uploads a profile picture to an S3 bucket",
+                "description": "Enregistre un nouveau match en base de données à partir des données fournies en JSON",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "upload"
+                    "match"
                 ],
-                "summary": "Upload a profile picture to S3",
+                "summary": "Crée un nouveau match",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "Image file to upload",
-                        "name": "image",
-                        "in": "formData",
-                        "required": true
+                        "description": "Objet match à créer",
+                        "name": "match",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MatchRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Created"
+                        "description": "Match créé avec succès",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     },
                     "400": {
-                        "description": "Bad request or file not found",
+                        "description": "Données invalides, champ ID manquant ou visibilité inconnue",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Utilisateur non autorisé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Adresse e-mail non vérifiée",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Erreur lors de la création du match",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
@@ -865,48 +1003,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/ranking/court/{id}/sport/{sport}": {
+        "/match/all": {
             "get": {
-                "description": "Retourne la liste des utilisateurs et leur ELO pour un court donné, triée par ELO croissant",
+                "description": "Retourne les matchs visibles par l'utilisateur : matchs publics, matchs qu'il a créés, rejoints ou auxquels il est invité, et matchs réservés aux amis de leur créateur",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "ranking"
-                ],
-                "summary": "Classement ELO par terrain",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Identifiant du court",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Identifiant du sport",
-                        "name": "sport",
-                        "in": "path",
-                        "required": true
-                    }
+                    "match"
                 ],
+                "summary": "Liste tous les matchs visibles",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Liste des matchs",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.CourtRankingResponse"
+                                "$ref": "#/definitions/models.MatchResponse"
                             }
                         }
                     },
-                    "400": {
-                        "description": "ID manquant",
-                        "schema": {
-                            "$ref": "#/definitions/models.Error"
-                        }
-                    },
                     "401": {
                         "description": "Utilisateur non autorisé",
                         "schema": {
@@ -914,7 +1030,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Erreur serveur / base",
+                        "description": "Erreur serveur lors de la récupération des matchs",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
@@ -922,37 +1038,34 @@ const docTemplate = `{
                 }
             }
         },
-        "/ranking/user/{userId}": {
+        "/match/{id}": {
             "get": {
-                "description": "Retourne uniquement la liste des fields associés à un utilisateur (ex: terrains classés/évalués)",
+                "description": "Retourne les informations d’un match en fonction de son identifiant passé en paramètre de requête, s'il est visible par l'utilisateur",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "match"
                 ],
-                "summary": "Liste des terrains (fields) d’un utilisateur",
+                "summary": "Récupère un match par son ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Identifiant de l'utilisateur",
-                        "name": "userId",
+                        "description": "Identifiant du match",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Match trouvé",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Field"
-                            }
+                            "$ref": "#/definitions/models.MatchResponse"
                         }
                     },
                     "400": {
-                        "description": "userId manquant",
+                        "description": "ID manquant ou invalide",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
@@ -963,60 +1076,79 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.Error"
                         }
                     },
+                    "403": {
+                        "description": "Match réservé aux amis ou sur invitation",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Match non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
                     "500": {
-                        "description": "Erreur serveur / base",
+                        "description": "Erreur serveur ou base de données",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     }
                 }
-            }
-        },
-        "/register": {
-            "post": {
-                "description": "Register a user with username and password",
-                "consumes": [
-                    "application/json"
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Passe le match à l'état \"Annule\" au lieu de le supprimer, pour qu'il reste visible dans l'historique. Réservé au créateur, tant que le match n'a pas commencé. Les autres participants sont prévenus par e-mail.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "match"
                 ],
-                "summary": "Register a new user",
+                "summary": "Annule un match",
                 "parameters": [
                     {
-                        "description": "User credentials",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.RegisterRequest"
-                        }
+                        "type": "string",
+                        "description": "Identifiant du match à annuler",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/models.LoginResponse"
-                        }
+                    "200": {
+                        "description": "OK"
                     },
                     "400": {
-                        "description": "Bad request",
+                        "description": "ID manquant ou match déjà commencé",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
                     "401": {
-                        "description": "User already exists",
+                        "description": "Utilisateur non autorisé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "L'utilisateur n'est pas le créateur du match",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Match non trouvé",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Erreur lors de l'annulation du match",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
@@ -1024,43 +1156,63 @@ const docTemplate = `{
                 }
             }
         },
-        "/reset-password/{token}": {
+        "/match/{id}/events": {
             "get": {
-                "description": "Vérifie le token JWT fourni, génère un nouveau mot de passe, l'envoie par email à l'utilisateur et met à jour le mot de passe dans la base de données",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Flux Server-Sent Events des évènements du match : joueur arrivé (player_joined) ou parti (player_left), changement d'état (state_changed), vote de score (vote_submitted) et résultat validé (finalized). Chaque évènement est envoyé avec son type comme nom et un models.MatchEvent en JSON comme données.\nLe flux est fermé si le client prend trop de retard : il doit alors recharger le match et se réabonner. Disponible uniquement sur le serveur HTTP local, pas en mode Lambda. Les transitions faites par le planificateur ne sont pas diffusées.",
                 "produces": [
-                    "text/html"
+                    "text/event-stream"
                 ],
                 "tags": [
-                    "auth"
+                    "match"
                 ],
-                "summary": "Réinitialise le mot de passe d’un utilisateur via un lien sécurisé",
+                "summary": "Suit un match en temps réel",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Token JWT de réinitialisation",
-                        "name": "token",
+                        "description": "ID du match",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "HTML indiquant que le mot de passe a été envoyé (même si l'utilisateur n'existe pas pour des raisons de sécurité)",
+                        "description": "Flux d'évènements",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.MatchEvent"
                         }
                     },
                     "400": {
-                        "description": "Requête invalide (ex: token manquant)",
+                        "description": "ID manquant",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Utilisateur non autorisé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Match réservé aux amis ou sur invitation",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Match non trouvé",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
                     "500": {
-                        "description": "Erreur interne du serveur (génération du mot de passe, envoi de mail, ou mise à jour en base)",
+                        "description": "Erreur serveur",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
@@ -1068,19 +1220,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/score/match/{id}": {
+        "/match/{id}/finish": {
             "patch": {
-                "description": "Met à jour les scores (score1 et score2) d’un match via son ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Passe un match de l’état \"En cours\" à \"Manque Score\" afin de permettre la saisie/validation des scores, ouverte pendant 48 heures.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "match"
                 ],
-                "summary": "Met à jour le score d’un match",
+                "summary": "Termine un match (passage à la saisie des scores)",
                 "parameters": [
                     {
                         "type": "string",
@@ -1088,15 +1237,6 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Nouveaux scores",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UpdateScoreRequest"
-                        }
                     }
                 ],
                 "responses": {
@@ -1104,25 +1244,25 @@ const docTemplate = `{
                         "description": "OK"
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "ID manquant, mauvais état, ou utilisateur non inscrit au match",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Utilisateur non autorisé",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
                     "404": {
-                        "description": "Not Found",
+                        "description": "Match non trouvé",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Erreur serveur ou base de données",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
@@ -1130,55 +1270,75 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/matches/{userId}": {
-            "get": {
-                "description": "Retourne les matchs auxquels un utilisateur a participé",
+        "/match/{id}/invite": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Invite des amis du créateur à un match en attente de joueurs et les prévient par e-mail. Les invités peuvent voir et rejoindre le match quelle que soit sa visibilité. Les utilisateurs déjà invités ne reçoivent pas de nouvel e-mail.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "match"
                 ],
-                "summary": "Liste des matchs d’un utilisateur",
+                "summary": "Invite des amis à un match",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Identifiant de l'utilisateur",
-                        "name": "userId",
+                        "description": "Identifiant du match",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Identifiants des amis à inviter",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.InviteToMatchRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.MatchResponse"
-                            }
+                            "$ref": "#/definitions/models.InviteToMatchResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "ID manquant, liste vide, utilisateur qui n'est pas un ami, ou match qui n'attend plus de joueurs",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Utilisateur non autorisé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "L'utilisateur n'est pas le créateur du match",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
                     "404": {
-                        "description": "Not Found",
+                        "description": "Match non trouvé",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Erreur serveur",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
@@ -1186,28 +1346,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}": {
-            "get": {
+        "/match/{id}/leave": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieve user information, including profile picture and preferences",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Libère la place du joueur connecté. Un match Valide repasse à \"Manque joueur\". Si le créateur quitte le match, le joueur inscrit le plus ancien devient créateur, ou le match passe à \"Annule\" s'il était seul. Refusé une fois le match \"En cours\".",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "match"
                 ],
-                "summary": "Get a user by ID",
+                "summary": "Un joueur quitte un match",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "ID du match",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1215,55 +1372,64 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "ID manquant, mauvais état, ou utilisateur non inscrit au match",
                         "schema": {
-                            "$ref": "#/definitions/models.UserResponse"
+                            "$ref": "#/definitions/models.Error"
                         }
                     },
-                    "400": {
-                        "description": "Missing ID in URL params",
+                    "401": {
+                        "description": "Utilisateur non autorisé",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
                     "404": {
-                        "description": "User not found",
+                        "description": "Match non trouvé",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Erreur serveur ou base de données",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/match/{id}/players/{userId}": {
             "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Remove a user permanently",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Libère la place d'un joueur. Réservé au créateur du match. Un match Valide repasse à \"Manque joueur\". Refusé une fois le match \"En cours\".",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "match"
                 ],
-                "summary": "Delete a user by ID",
+                "summary": "Le créateur retire un joueur d'un match",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "ID du match",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID du joueur à retirer",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -1271,294 +1437,2637 @@ const docTemplate = `{
                         "description": "OK"
                     },
                     "400": {
-                        "description": "Missing ID in URL params",
+                        "description": "ID manquant, mauvais état, ou joueur non inscrit au match",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
-                    "403": {
-                        "description": "Incorrect rights",
+                    "401": {
+                        "description": "Utilisateur non autorisé",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "403": {
+                        "description": "L'utilisateur n'est pas le créateur du match",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Match non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur ou base de données",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/match/{id}/rebalance": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update user fields",
-                "consumes": [
+                "description": "Répartit à nouveau les joueurs d'un match complet (Valide) en deux équipes dont les moyennes d'ELO sur le terrain sont les plus proches possible. Réservé au créateur, avant le début du match.",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "match"
+                ],
+                "summary": "Rééquilibre les équipes d'un match",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID du match",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Nouvelles équipes",
+                        "schema": {
+                            "$ref": "#/definitions/models.TeamsByMatchIdResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "ID manquant, ou match pas complet ou déjà commencé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Utilisateur non autorisé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "L'utilisateur n'est pas le créateur du match",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Match non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/match/{id}/start": {
+            "patch": {
+                "description": "Passe un match de l’état \"Valide\" à \"En cours\" sans attendre le démarrage automatique. La date prévue du match est conservée.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "match"
                 ],
-                "summary": "Patch a user by ID",
+                "summary": "Démarre un match",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "ID du match",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
                     },
-                    {
-                        "description": "User fields to update",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
+                    "400": {
+                        "description": "ID manquant, mauvais état, ou utilisateur non inscrit au match",
                         "schema": {
-                            "$ref": "#/definitions/models.UserPatchRequest"
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Utilisateur non autorisé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Match non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur ou base de données",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
                         }
                     }
+                }
+            }
+        },
+        "/match/{id}/teams": {
+            "get": {
+                "description": "Renvoie la liste des joueurs répartis par équipe (team 1 et team 2) pour un match donné, ainsi que les joueurs d'un match auto_balance pas encore répartis.\nChaque joueur est enrichi avec ses informations publiques (profil, stats, sports, terrains, etc.).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "match"
+                ],
+                "summary": "Récupérer les équipes d’un match",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID du match",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TeamsByMatchIdResponse"
+                        }
                     },
                     "400": {
-                        "description": "Missing ID in URL params",
+                        "description": "ID du match manquant",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Utilisateur non autorisé",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
                     "403": {
-                        "description": "Incorrect rights",
+                        "description": "Match réservé aux amis ou sur invitation",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Match non trouvé",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Erreur serveur",
                         "schema": {
                             "$ref": "#/definitions/models.Error"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.ChangePasswordRequest": {
-            "type": "object",
-            "properties": {
-                "password": {
-                    "type": "string"
+        },
+        "/match/{id}/vote-status": {
+            "get": {
+                "description": "Renvoie l'équipe du joueur, si son équipe a voté et le score voté (nullable), et la même info pour l'équipe adverse, pour le tour de vote en cours.\nEn cas de litige, indique le tour de vote (1), sa date limite et les scores contestés du premier tour. À la date limite, le vote d'une seule équipe est retenu ; sinon le match est annulé.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "match"
+                ],
+                "summary": "Statut de vote des scores (par équipe) pour un match terminé",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID du match",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MatchVoteStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "ID manquant, mauvais état",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Utilisateur non autorisé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Match ou utilisateur non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
                 }
             }
         },
-        "models.CourtRankingResponse": {
+        "/matches/court/{courtId}": {
+            "get": {
+                "description": "Retourne les matchs associés à un terrain (court) via son ID et visibles par l'utilisateur",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "match"
+                ],
+                "summary": "Liste des matchs pour un court",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Identifiant du terrain",
+                        "name": "courtId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.MatchResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "ID manquant",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Utilisateur non autorisé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Aucun match trouvé pour ce terrain",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur interne serveur ou base",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/search": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Fil de découverte des matchs visibles par l'utilisateur, paginé par curseur. Par défaut, seuls les matchs en attente de joueurs sont retournés, du plus proche dans le temps au plus lointain. Le tri par distance nécessite lat et lng.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "match"
+                ],
+                "summary": "Recherche de matchs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sport (basket, foot, ping-pong)",
+                        "name": "sport",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "États séparés par des virgules (défaut : Manque joueur)",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date de début incluse (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date de fin exclue (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Latitude du point de recherche, avec lng",
+                        "name": "lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Longitude du point de recherche, avec lat",
+                        "name": "lng",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Rayon de recherche en mètres (défaut 5000, max 50000)",
+                        "name": "radius_m",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Identifiant du terrain",
+                        "name": "court",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Nombre minimum de places libres",
+                        "name": "players_needed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Écart maximum entre l'ELO moyen des inscrits et celui de l'utilisateur sur le terrain",
+                        "name": "elo_range",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "date (défaut) ou distance",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Curseur next_cursor de la page précédente",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Nombre de matchs par page (défaut 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MatchSearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Paramètres invalides",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Utilisateur non autorisé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur lors de la recherche",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/matchmaking/queue": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Renvoie les demandes de matchmaking de l'utilisateur, de la plus récente à la plus ancienne, avec le match créé pour celles qui ont abouti",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matchmaking"
+                ],
+                "summary": "Liste les demandes de matchmaking",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.QueueEntryResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Utilisateur non autorisé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Inscrit l'utilisateur dans la file d'attente d'un sport. Le matchmaking le regroupe avec des joueurs d'ELO proche qui acceptent un même terrain (terrains préférés ou zone de recherche) et une même heure, crée le match avec des équipes équilibrées et prévient les joueurs par e-mail. Un joueur n'attend qu'une fois par sport.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matchmaking"
+                ],
+                "summary": "Entre dans la file du matchmaking",
+                "parameters": [
+                    {
+                        "description": "Critères de recherche",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.QueueRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.QueueEntryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Sport, taille d'équipe, fenêtre horaire, zone ou terrain invalide",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Utilisateur non autorisé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Adresse e-mail non vérifiée",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Déjà dans la file pour ce sport",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/matchmaking/queue/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retire de la file une demande de matchmaking en attente de l'utilisateur",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matchmaking"
+                ],
+                "summary": "Quitte la file du matchmaking",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Identifiant de la demande",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Identifiant manquant",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Utilisateur non autorisé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Demande non trouvée",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Demande plus en attente (match trouvé, annulée ou expirée)",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/preferences": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Renvoie les notifications push que l'utilisateur reçoit ; toutes sont activées par défaut",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notification"
+                ],
+                "summary": "Préférences de notification",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.NotificationPreferencesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Utilisateur non autorisé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Active ou désactive chaque type de notification push ; les champs absents sont inchangés",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notification"
+                ],
+                "summary": "Modifie les préférences de notification",
+                "parameters": [
+                    {
+                        "description": "Préférences à modifier",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.NotificationPreferencesPatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.NotificationPreferencesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "JSON invalide",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Utilisateur non autorisé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/place": {
+            "post": {
+                "description": "Appelle l'API Google Places pour synchroniser les terrains autour d'une position donnée (Paris en dur pour l'instant)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "google"
+                ],
+                "summary": "Synchronise les terrains depuis l'API Google Places",
+                "responses": {
+                    "201": {
+                        "description": "Synchro réussie"
+                    },
+                    "500": {
+                        "description": "Erreur lors de la synchronisation",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile_picture/{id}": {
+            "post": {
+                "description": "Uploads a profile picture to an S3 bucket",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Upload a profile picture to S3",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Image file to upload",
+                        "name": "image",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created"
+                    },
+                    "400": {
+                        "description": "Bad request or file not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/ranking/city/{city}/{sport}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Classe les joueurs d’un sport sur les terrains d’une ville (insensible à la casse). La note de chaque joueur est la moyenne de ses ELO sur ces terrains, pondérée par le nombre de matchs joués sur chacun.\nRenvoie aussi le rang de l’utilisateur connecté (me), null s’il n’est pas classé dans cette ville.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ranking"
+                ],
+                "summary": "Classement d’une ville par sport",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ville",
+                        "name": "city",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sport (basket, foot, ping-pong)",
+                        "name": "sport",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Nombre maximum de joueurs (défaut 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Nombre de joueurs à sauter (défaut 0)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LeaderboardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ville manquante ou invalide, sport ou pagination invalide",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Utilisateur non autorisé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur / base",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/ranking/court/{id}/sport/{sport}": {
+            "get": {
+                "description": "Retourne le classement d’un court pour un sport, trié par ELO décroissant : rang, pseudo, photo de profil, ELO, matchs terminés sur ce terrain, taux de victoire et date du dernier match.\nPaginé par limit/offset ; le nombre total de joueurs classés est renvoyé dans l’en-tête X-Total-Count.\nAvec around=me, la page est centrée sur l’utilisateur connecté (offset ignoré) ; s’il n’est pas classé, la première page est renvoyée.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ranking"
+                ],
+                "summary": "Classement ELO par terrain",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Identifiant du court",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Identifiant du sport",
+                        "name": "sport",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Nombre maximum de joueurs (défaut 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Nombre de joueurs à sauter (défaut 0)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "me : centre la page sur l’utilisateur connecté",
+                        "name": "around",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CourtRankingResponse"
+                            }
+                        },
+                        "headers": {
+                            "X-Total-Count": {
+                                "type": "int",
+                                "description": "Nombre total de joueurs classés"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "ID manquant, sport ou pagination invalide",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Utilisateur non autorisé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur / base",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/ranking/global/{sport}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Classe les joueurs d’un sport sur l’ensemble des terrains. La note de chaque joueur est la moyenne de ses ELO par terrain, pondérée par le nombre de matchs joués sur chacun.\nRenvoie aussi le rang de l’utilisateur connecté (me), null s’il n’est pas classé.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ranking"
+                ],
+                "summary": "Classement national par sport",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sport (basket, foot, ping-pong)",
+                        "name": "sport",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Nombre maximum de joueurs (défaut 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Nombre de joueurs à sauter (défaut 0)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LeaderboardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Sport ou pagination invalide",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Utilisateur non autorisé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur / base",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/ranking/user/{userId}": {
+            "get": {
+                "description": "Retourne uniquement la liste des fields associés à un utilisateur (ex: terrains classés/évalués)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Liste des terrains (fields) d’un utilisateur",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Identifiant de l'utilisateur",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Field"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "userId manquant",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Utilisateur non autorisé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur / base",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/ranking/user/{userId}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retourne l’évolution de l’ELO d’un utilisateur match après match, du plus ancien au plus récent.\nLes paramètres court et sport permettent de restreindre l’historique à un terrain et/ou un sport.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ranking"
+                ],
+                "summary": "Historique ELO d’un utilisateur",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Identifiant de l'utilisateur",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Identifiant du terrain",
+                        "name": "court",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sport (basket, foot, ping-pong)",
+                        "name": "sport",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RankingHistoryResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "userId manquant / sport invalide",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Utilisateur non autorisé",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur / base",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "Register a user with username and password. A verification link is sent to the email, which must be confirmed before creating or joining a match. The language of the emails is negotiated from Accept-Language and can be changed later with PATCH /users/{id}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "User credentials",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RegisterRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language of the messages and emails (fr, en)",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "User already exists",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/reset-password/{token}": {
+            "get": {
+                "description": "Vérifie que le lien de réinitialisation est valide (non expiré et non utilisé) et affiche un formulaire permettant de choisir un nouveau mot de passe",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Affiche le formulaire de réinitialisation du mot de passe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token de réinitialisation",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Formulaire HTML de réinitialisation",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "HTML indiquant que le lien est invalide ou expiré",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur interne du serveur",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Consomme le token de réinitialisation (utilisable une seule fois), enregistre le mot de passe choisi par l'utilisateur et déconnecte toutes ses sessions",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Réinitialise le mot de passe d’un utilisateur via un lien sécurisé",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token de réinitialisation",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Nouveau mot de passe",
+                        "name": "password",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Confirmation du nouveau mot de passe",
+                        "name": "confirmation",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "HTML indiquant que le mot de passe a été modifié",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "HTML indiquant un formulaire invalide ou un lien invalide, expiré ou déjà utilisé",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Trop de tentatives depuis cette adresse IP",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur interne du serveur",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/score/match/{id}": {
+            "patch": {
+                "description": "Enregistre le vote de score de l'équipe du joueur. Si les deux équipes votent le même score, le match passe à \"Termine\". Si elles votent des scores différents, le match est marqué en litige et un nouveau tour de vote s'ouvre jusqu'à sa date limite.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "match"
+                ],
+                "summary": "Met à jour le score d’un match",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID du match",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Nouveaux scores",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateScoreRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/token/refresh": {
+            "post": {
+                "description": "Exchange a refresh token for a new access token. The refresh token is rotated: the one sent can not be used again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Refresh an access token",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid, expired or revoked refresh token",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/matches/{userId}": {
+            "get": {
+                "description": "Retourne les matchs auxquels un utilisateur a participé",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "match"
+                ],
+                "summary": "Liste des matchs d’un utilisateur",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Identifiant de l'utilisateur",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.MatchResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve user information, including profile picture and preferences",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get a user by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing ID in URL params",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a user permanently",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete a user by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Missing ID in URL params",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Incorrect rights",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update user fields. A new email only takes effect once the link sent to it has been opened. The locale (fr or en) is the language of the emails and push notifications.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Patch a user by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User fields to update",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserPatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Missing ID in URL params or unsupported locale",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Incorrect rights",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Email already taken",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "429": {
+                        "description": "A verification email was sent less than a minute ago",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/verify-email/resend": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Send a new verification link to the pending email of the connected user, or to its current email if it is not verified yet",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Resend the verification email",
+                "responses": {
+                    "200": {
+                        "description": "Verification email sent"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Email already verified",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "429": {
+                        "description": "A verification email was sent less than a minute ago",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/verify-email/{token}": {
+            "get": {
+                "description": "Vérifie le lien signé envoyé par e-mail. Confirme l'adresse du compte, ou applique la nouvelle adresse demandée via PATCH /users/{id}",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Confirme l'adresse e-mail d'un utilisateur",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token de vérification",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "HTML indiquant que l'adresse est confirmée",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "HTML indiquant que le lien est invalide ou expiré",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "HTML indiquant que l'adresse est déjà utilisée par un autre compte",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur interne du serveur",
+                        "schema": {
+                            "$ref": "#/definitions/models.Error"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.ChangePasswordRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CourtRankingResponse": {
+            "type": "object",
+            "properties": {
+                "elo": {
+                    "type": "integer"
+                },
+                "lastPlayedAt": {
+                    "description": "@nullable",
+                    "type": "string"
+                },
+                "matchesPlayed": {
+                    "description": "Matchs terminés sur ce terrain dans ce sport",
+                    "type": "integer"
+                },
+                "profilePicture": {
+                    "description": "@nullable",
+                    "type": "string"
+                },
+                "rank": {
+                    "type": "integer"
+                },
+                "userId": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                },
+                "winrate": {
+                    "description": "@nullable",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.DBCourt": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "city": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DevicePlatform": {
+            "type": "string",
+            "enum": [
+                "ios",
+                "android"
+            ],
+            "x-enum-varnames": [
+                "PlatformIOS",
+                "PlatformAndroid"
+            ]
+        },
+        "models.DeviceResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "platform": {
+                    "$ref": "#/definitions/models.DevicePlatform"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DisputedVotes": {
+            "type": "object",
+            "properties": {
+                "myTeam": {
+                    "$ref": "#/definitions/models.TeamVoteStatus"
+                },
+                "opponent": {
+                    "$ref": "#/definitions/models.TeamVoteStatus"
+                }
+            }
+        },
+        "models.Error": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code stable, à utiliser par les clients",
+                    "type": "string",
+                    "enum": [
+                        "bad_request",
+                        "unauthorized",
+                        "internal_error",
+                        "not_found",
+                        "conflict",
+                        "too_many_requests",
+                        "invalid_json",
+                        "missing_parameter",
+                        "invalid_parameter",
+                        "validation_failed",
+                        "body_too_large",
+                        "match_not_found",
+                        "match_wrong_state",
+                        "match_not_visible",
+                        "not_match_creator",
+                        "not_in_match",
+                        "player_not_found",
+                        "already_in_match",
+                        "invalid_sport",
+                        "invalid_team",
+                        "team_full",
+                        "team_already_voted",
+                        "invite_friends_only",
+                        "invalid_city",
+                        "court_not_found",
+                        "invalid_date_range",
+                        "distance_sort_needs_position",
+                        "user_not_found",
+                        "email_not_verified",
+                        "email_already_verified",
+                        "email_taken",
+                        "username_taken",
+                        "password_too_long",
+                        "invalid_image",
+                        "already_friends",
+                        "friendship_blocked",
+                        "friend_request_not_found",
+                        "friend_request_already_sent",
+                        "cannot_target_self",
+                        "queue_entry_not_found",
+                        "queue_entry_not_waiting",
+                        "already_queued",
+                        "queue_window_too_far",
+                        "queue_area_incomplete",
+                        "queue_area_required",
+                        "device_not_found"
+                    ],
+                    "example": "match_not_found"
+                },
+                "detail": {
+                    "description": "Message dans la langue négociée par Accept-Language",
+                    "type": "string",
+                    "example": "Match introuvable."
+                },
+                "details": {
+                    "description": "Précisions sur l'erreur ; pour validation_failed, un FieldError par champ invalide,\nindexé par son nom JSON\n@nullable",
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/match/42"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "urn:playthestreet:error:match_not_found"
+                }
+            }
+        },
+        "models.Field": {
+            "type": "object",
+            "properties": {
+                "elo": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "ranking": {
+                    "type": "integer"
+                },
+                "sport": {
+                    "$ref": "#/definitions/models.Sport"
+                }
+            }
+        },
+        "models.FriendRequestsResponse": {
+            "type": "object",
+            "properties": {
+                "incoming": {
+                    "description": "Demandes reçues, en attente de réponse",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FriendResponse"
+                    }
+                },
+                "outgoing": {
+                    "description": "Demandes envoyées, en attente de réponse",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FriendResponse"
+                    }
+                }
+            }
+        },
+        "models.FriendResponse": {
+            "type": "object",
+            "properties": {
+                "profilePicture": {
+                    "description": "@nullable",
+                    "type": "string"
+                },
+                "since": {
+                    "description": "Date de la dernière mise à jour de la relation (envoi ou acceptation)",
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FriendshipResponse": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "$ref": "#/definitions/models.FriendshipStatus"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FriendshipStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "accepted",
+                "declined",
+                "blocked"
+            ],
+            "x-enum-varnames": [
+                "FriendshipPending",
+                "FriendshipAccepted",
+                "FriendshipDeclined",
+                "FriendshipBlocked"
+            ]
+        },
+        "models.HelloWorldResponse": {
+            "type": "object",
+            "properties": {
+                "response": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.InviteToMatchRequest": {
+            "type": "object",
+            "properties": {
+                "user_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.InviteToMatchResponse": {
+            "type": "object",
+            "properties": {
+                "invited": {
+                    "description": "Amis invités par cette requête, hors invitations déjà envoyées",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.JoinMatchRequest": {
+            "type": "object",
+            "properties": {
+                "team": {
+                    "description": "1 ou 2 ; ignoré pour un match auto_balance",
+                    "type": "integer",
+                    "maximum": 2,
+                    "minimum": 0
+                }
+            }
+        },
+        "models.LeaderboardEntryResponse": {
+            "type": "object",
+            "properties": {
+                "gamesPlayed": {
+                    "type": "integer"
+                },
+                "rank": {
+                    "type": "integer"
+                },
+                "rating": {
+                    "type": "integer"
+                },
+                "userId": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LeaderboardResponse": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LeaderboardEntryResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "me": {
+                    "description": "@nullable",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.LeaderboardEntryResponse"
+                        }
+                    ]
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LoginRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "expiresIn": {
+                    "type": "integer"
+                },
+                "refreshToken": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MailerRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MatchEloChange": {
+            "type": "object",
+            "properties": {
+                "delta": {
+                    "type": "integer"
+                },
+                "elo_after": {
+                    "type": "integer"
+                },
+                "elo_before": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MatchEvent": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "match_id": {
+                    "type": "string"
+                },
+                "score1": {
+                    "type": "integer"
+                },
+                "score2": {
+                    "type": "integer"
+                },
+                "state": {
+                    "description": "Nouvel état du match (state_changed, finalized)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.MatchState"
+                        }
+                    ]
+                },
+                "team": {
+                    "description": "Équipe du joueur qui a voté",
+                    "type": "integer"
+                },
+                "type": {
+                    "$ref": "#/definitions/models.MatchEventType"
+                },
+                "user_id": {
+                    "description": "Joueur qui a rejoint, quitté le match ou voté",
+                    "type": "string"
+                }
+            }
+        },
+        "models.MatchEventType": {
+            "type": "string",
+            "enum": [
+                "player_joined",
+                "player_left",
+                "state_changed",
+                "vote_submitted",
+                "finalized"
+            ],
+            "x-enum-varnames": [
+                "MatchEventPlayerJoined",
+                "MatchEventPlayerLeft",
+                "MatchEventStateChanged",
+                "MatchEventVoteSubmitted",
+                "MatchEventFinalized"
+            ]
+        },
+        "models.MatchRequest": {
+            "type": "object",
+            "required": [
+                "court_id",
+                "date",
+                "sport"
+            ],
+            "properties": {
+                "auto_balance": {
+                    "description": "Les joueurs rejoignent sans choisir d'équipe ; les équipes sont réparties selon\nl'ELO des joueurs sur le terrain quand le match est complet",
+                    "type": "boolean"
+                },
+                "court_id": {
+                    "type": "string"
+                },
+                "date": {
+                    "description": "Dans le futur",
+                    "type": "string"
+                },
+                "nbre_participant": {
+                    "description": "Pair, au moins 2",
+                    "type": "integer",
+                    "minimum": 2
+                },
+                "sport": {
+                    "$ref": "#/definitions/models.Sport"
+                },
+                "visibility": {
+                    "description": "public (par défaut), friends ou invite_only",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.MatchVisibility"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.MatchResponse": {
+            "type": "object",
+            "properties": {
+                "auto_balance": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "creator_id": {
+                    "type": "string"
+                },
+                "current_state": {
+                    "$ref": "#/definitions/models.MatchState"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "elo_changes": {
+                    "description": "Variation d'ELO de chaque joueur, renseignée une fois le match terminé",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MatchEloChange"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "nbre_participant": {
+                    "type": "integer"
+                },
+                "place": {
+                    "type": "string"
+                },
+                "score1": {
+                    "type": "integer"
+                },
+                "score2": {
+                    "type": "integer"
+                },
+                "sport": {
+                    "$ref": "#/definitions/models.Sport"
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.UserResponse"
+                    }
+                },
+                "visibility": {
+                    "$ref": "#/definitions/models.MatchVisibility"
+                }
+            }
+        },
+        "models.MatchSearchResponse": {
             "type": "object",
             "properties": {
-                "elo": {
-                    "type": "integer"
+                "matches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MatchSummaryResponse"
+                    }
                 },
-                "userId": {
+                "next_cursor": {
+                    "description": "Curseur à passer pour obtenir la page suivante, null sur la dernière page\n@nullable",
                     "type": "string"
                 }
             }
         },
-        "models.DBCourt": {
+        "models.MatchState": {
+            "type": "string",
+            "enum": [
+                "Termine",
+                "Manque Score",
+                "En cours",
+                "Valide",
+                "Manque joueur",
+                "Annule"
+            ],
+            "x-enum-varnames": [
+                "Termine",
+                "ManqueScore",
+                "EnCours",
+                "Valide",
+                "ManqueJoueur",
+                "Annule"
+            ]
+        },
+        "models.MatchSummaryResponse": {
             "type": "object",
             "properties": {
-                "address": {
+                "average_elo": {
+                    "description": "Moyenne des ELO des joueurs inscrits sur ce terrain, null si personne n'est inscrit\n@nullable",
+                    "type": "integer"
+                },
+                "city": {
+                    "description": "@nullable",
                     "type": "string"
                 },
-                "createdAt": {
+                "court_id": {
                     "type": "string"
                 },
-                "id": {
+                "creator_id": {
                     "type": "string"
                 },
-                "latitude": {
-                    "type": "number"
+                "current_state": {
+                    "$ref": "#/definitions/models.MatchState"
                 },
-                "longitude": {
+                "date": {
+                    "type": "string"
+                },
+                "distance_m": {
+                    "description": "Renseignée quand la recherche porte sur une position\n@nullable",
                     "type": "number"
                 },
-                "name": {
-                    "type": "string"
-                }
-            }
-        },
-        "models.Error": {
-            "type": "object",
-            "properties": {
-                "message": {
+                "id": {
                     "type": "string"
-                }
-            }
-        },
-        "models.Field": {
-            "type": "object",
-            "properties": {
-                "elo": {
+                },
+                "nbre_participant": {
                     "type": "integer"
                 },
-                "name": {
+                "place": {
                     "type": "string"
                 },
-                "ranking": {
+                "players_count": {
+                    "type": "integer"
+                },
+                "players_needed": {
                     "type": "integer"
                 },
                 "sport": {
                     "$ref": "#/definitions/models.Sport"
+                },
+                "visibility": {
+                    "$ref": "#/definitions/models.MatchVisibility"
                 }
             }
         },
-        "models.HelloWorldResponse": {
-            "type": "object",
-            "properties": {
-                "response": {
-                    "type": "string"
-                }
-            }
+        "models.MatchVisibility": {
+            "type": "string",
+            "enum": [
+                "public",
+                "friends",
+                "invite_only"
+            ],
+            "x-enum-varnames": [
+                "VisibilityPublic",
+                "VisibilityFriends",
+                "VisibilityInviteOnly"
+            ]
         },
-        "models.JoinMatchRequest": {
+        "models.MatchVoteStatusResponse": {
             "type": "object",
             "properties": {
-                "team": {
+                "deadline": {
+                    "type": "string"
+                },
+                "disputed": {
+                    "type": "boolean"
+                },
+                "disputedVotes": {
+                    "$ref": "#/definitions/models.DisputedVotes"
+                },
+                "matchId": {
+                    "type": "string"
+                },
+                "myTeam": {
+                    "$ref": "#/definitions/models.TeamVoteStatus"
+                },
+                "opponent": {
+                    "$ref": "#/definitions/models.TeamVoteStatus"
+                },
+                "playerTeam": {
+                    "type": "integer"
+                },
+                "round": {
                     "type": "integer"
                 }
             }
         },
-        "models.LoginRequest": {
+        "models.NearbyCourtResponse": {
             "type": "object",
             "properties": {
-                "password": {
+                "address": {
                     "type": "string"
                 },
-                "username": {
+                "city": {
                     "type": "string"
-                }
-            }
-        },
-        "models.LoginResponse": {
-            "type": "object",
-            "properties": {
-                "token": {
+                },
+                "createdAt": {
                     "type": "string"
                 },
-                "userId": {
+                "distanceMeters": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "name": {
                     "type": "string"
                 }
             }
         },
-        "models.MailerRequest": {
+        "models.NotificationPreferencesPatchRequest": {
             "type": "object",
             "properties": {
-                "email": {
-                    "type": "string"
+                "match_filled": {
+                    "description": "@nullable",
+                    "type": "boolean"
+                },
+                "match_result": {
+                    "description": "@nullable",
+                    "type": "boolean"
+                },
+                "match_starting": {
+                    "description": "@nullable",
+                    "type": "boolean"
+                },
+                "score_vote": {
+                    "description": "@nullable",
+                    "type": "boolean"
                 }
             }
         },
-        "models.MatchRequest": {
+        "models.NotificationPreferencesResponse": {
             "type": "object",
             "properties": {
-                "court_id": {
-                    "type": "string"
+                "match_filled": {
+                    "description": "Un match auquel le joueur est inscrit est complet",
+                    "type": "boolean"
                 },
-                "date": {
-                    "type": "string"
+                "match_result": {
+                    "description": "Le résultat d'un match du joueur est validé",
+                    "type": "boolean"
                 },
-                "nbre_participant": {
-                    "type": "integer"
+                "match_starting": {
+                    "description": "Un match du joueur commence bientôt",
+                    "type": "boolean"
                 },
-                "sport": {
-                    "$ref": "#/definitions/models.Sport"
+                "score_vote": {
+                    "description": "Le score d'un match du joueur doit être voté",
+                    "type": "boolean"
                 }
             }
         },
-        "models.MatchResponse": {
+        "models.QueueEntryResponse": {
             "type": "object",
             "properties": {
+                "court_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "created_at": {
                     "type": "string"
                 },
-                "creator_id": {
+                "id": {
                     "type": "string"
                 },
-                "current_state": {
-                    "$ref": "#/definitions/models.MatchState"
+                "latitude": {
+                    "description": "@nullable",
+                    "type": "number"
                 },
-                "date": {
-                    "type": "string"
+                "longitude": {
+                    "description": "@nullable",
+                    "type": "number"
                 },
-                "id": {
+                "match_id": {
+                    "description": "Match créé par le matchmaking, renseigné quand status vaut matched\n@nullable",
                     "type": "string"
                 },
-                "nbre_participant": {
-                    "type": "integer"
+                "radius_m": {
+                    "description": "@nullable",
+                    "type": "number"
                 },
-                "place": {
-                    "type": "string"
+                "sport": {
+                    "$ref": "#/definitions/models.Sport"
                 },
-                "score1": {
-                    "type": "integer"
+                "status": {
+                    "$ref": "#/definitions/models.QueueStatus"
                 },
-                "score2": {
+                "team_size": {
                     "type": "integer"
                 },
-                "sport": {
-                    "$ref": "#/definitions/models.Sport"
+                "window_end": {
+                    "type": "string"
                 },
-                "users": {
+                "window_start": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.QueueRequest": {
+            "type": "object",
+            "required": [
+                "window_end",
+                "window_start"
+            ],
+            "properties": {
+                "court_ids": {
+                    "description": "Terrains acceptés en plus de la zone de recherche",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.UserResponse"
+                        "type": "string"
                     }
+                },
+                "latitude": {
+                    "description": "Centre de la zone de recherche, à renseigner avec radius_m",
+                    "type": "number",
+                    "maximum": 90,
+                    "minimum": -90
+                },
+                "longitude": {
+                    "type": "number",
+                    "maximum": 180,
+                    "minimum": -180
+                },
+                "radius_m": {
+                    "type": "number",
+                    "maximum": 50000,
+                    "minimum": 1
+                },
+                "sport": {
+                    "$ref": "#/definitions/models.Sport"
+                },
+                "team_size": {
+                    "description": "Nombre de joueurs par équipe",
+                    "type": "integer",
+                    "maximum": 11,
+                    "minimum": 1
+                },
+                "window_end": {
+                    "type": "string"
+                },
+                "window_start": {
+                    "description": "Heures de début de match acceptées",
+                    "type": "string"
                 }
             }
         },
-        "models.MatchState": {
+        "models.QueueStatus": {
             "type": "string",
             "enum": [
-                "Termine",
-                "Manque Score",
-                "En cours",
-                "Valide",
-                "Manque joueur"
+                "waiting",
+                "matched",
+                "cancelled",
+                "expired"
             ],
             "x-enum-varnames": [
-                "Termine",
-                "ManqueScore",
-                "EnCours",
-                "Valide",
-                "ManqueJoueur"
+                "QueueWaiting",
+                "QueueMatched",
+                "QueueCancelled",
+                "QueueExpired"
             ]
         },
-        "models.MatchVoteStatusResponse": {
+        "models.RankingHistoryResponse": {
             "type": "object",
             "properties": {
-                "matchId": {
+                "courtId": {
                     "type": "string"
                 },
-                "myTeam": {
-                    "$ref": "#/definitions/models.TeamVoteStatus"
+                "date": {
+                    "type": "string"
                 },
-                "opponent": {
-                    "$ref": "#/definitions/models.TeamVoteStatus"
+                "delta": {
+                    "type": "integer"
                 },
-                "playerTeam": {
+                "eloAfter": {
                     "type": "integer"
+                },
+                "eloBefore": {
+                    "type": "integer"
+                },
+                "matchId": {
+                    "type": "string"
+                },
+                "sport": {
+                    "$ref": "#/definitions/models.Sport"
+                }
+            }
+        },
+        "models.RefreshTokenRequest": {
+            "type": "object",
+            "properties": {
+                "refreshToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RegisterDeviceRequest": {
+            "type": "object",
+            "properties": {
+                "platform": {
+                    "description": "ios ou android",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.DevicePlatform"
+                        }
+                    ]
+                },
+                "token": {
+                    "description": "Jeton push de l'appareil",
+                    "type": "string",
+                    "maxLength": 4096
                 }
             }
         },
         "models.RegisterRequest": {
             "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
             "properties": {
                 "bio": {
                     "type": "string"
@@ -1623,6 +4132,13 @@ const docTemplate = `{
                     "items": {
                         "$ref": "#/definitions/models.UserResponse"
                     }
+                },
+                "unassigned": {
+                    "description": "Joueurs d'un match auto_balance pas encore répartis, le match n'étant pas complet",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.UserResponse"
+                    }
                 }
             }
         },
@@ -1630,10 +4146,12 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "score1": {
-                    "type": "integer"
+                    "type": "integer",
+                    "minimum": 0
                 },
                 "score2": {
-                    "type": "integer"
+                    "type": "integer",
+                    "minimum": 0
                 }
             }
         },
@@ -1652,6 +4170,14 @@ const docTemplate = `{
                     "description": "@nullable",
                     "type": "string"
                 },
+                "locale": {
+                    "description": "@nullable",
+                    "type": "string",
+                    "enum": [
+                        "fr",
+                        "en"
+                    ]
+                },
                 "username": {
                     "description": "@nullable",
                     "type": "string"
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"PLIC/database"
+	"PLIC/httpx"
+	"PLIC/i18n"
+	"PLIC/models"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	emailVerificationTTL     = 24 * time.Hour
+	emailVerificationPurpose = "verify_email"
+)
+
+func generateEmailVerificationToken(userID, email string, now time.Time) (string, error) {
+	claims := jwt.MapClaims{
+		"user_id": userID,
+		"email":   email,
+		"purpose": emailVerificationPurpose,
+		"exp":     now.Add(emailVerificationTTL).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(jwtSecret))
+}
+
+func parseEmailVerificationToken(tokenStr string, now time.Time) (string, string, error) {
+	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(jwtSecret), nil
+	}, jwt.WithExpirationRequired(), jwt.WithTimeFunc(func() time.Time { return now }))
+	if err != nil {
+		return "", "", err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return "", "", fmt.Errorf("invalid token claims: %v", token.Claims)
+	}
+	if purpose, _ := claims["purpose"].(string); purpose != emailVerificationPurpose {
+		return "", "", jwt.ErrTokenInvalidClaims
+	}
+	userID, _ := claims["user_id"].(string)
+	email, _ := claims["email"].(string)
+	if userID == "" || email == "" {
+		return "", "", jwt.ErrTokenMalformed
+	}
+	return userID, email, nil
+}
+
+func (s *Service) sendVerificationEmail(userID, email, username string, locale i18n.Locale) error {
+	token, err := generateEmailVerificationToken(userID, email, s.clock.Now())
+	if err != nil {
+		return err
+	}
+	return s.mailer.SendVerificationEmail(userID, email, username, s.appLink("/verify-email/"+token), locale)
+}
+
+// VerifyEmail godoc
+// @Summary      Confirme l'adresse e-mail d'un utilisateur
+// @Description  Vérifie le lien signé envoyé par e-mail. Confirme l'adresse du compte, ou applique la nouvelle adresse demandée via PATCH /users/{id}
+// @Tags         auth
+// @Produce      html
+// @Param        token path string true "Token de vérification"
+// @Success      200 {string} string "HTML indiquant que l'adresse est confirmée"
+// @Failure      400 {string} string "HTML indiquant que le lien est invalide ou expiré"
+// @Failure      409 {string} string "HTML indiquant que l'adresse est déjà utilisée par un autre compte"
+// @Failure      500 {object} models.Error "Erreur interne du serveur"
+// @Router       /verify-email/{token} [get]
+func (s *Service) VerifyEmail(w http.ResponseWriter, r *http.Request, _ models.AuthInfo) error {
+	logger := log.With().Str("method", "VerifyEmail").Logger()
+
+	ctx := r.Context()
+	token := chi.URLParam(r, "token")
+	if token == "" {
+		logger.Warn().Msg("missing verification token")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrBadRequest)
+	}
+
+	now := s.clock.Now()
+	userID, email, err := parseEmailVerificationToken(token, now)
+	if err != nil {
+		logger.Warn().Err(err).Msg("verification token invalid")
+		return httpx.WriteHTMLMessage(w, r, http.StatusBadRequest, i18n.PageInvalidVerificationLinkTitle, i18n.PageInvalidVerificationLinkMessage)
+	}
+
+	logger = logger.With().Str("user_id", userID).Str("email", email).Logger()
+
+	user, err := s.db.GetUserById(ctx, userID)
+	if err != nil {
+		logger.Error().Err(err).Msg("db get user failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+	if user == nil {
+		logger.Warn().Msg("user not found")
+		return httpx.WriteHTMLMessage(w, r, http.StatusBadRequest, i18n.PageInvalidVerificationLinkTitle, i18n.PageInvalidVerificationLinkMessage)
+	}
+
+	var ok bool
+	if user.PendingEmail != nil && *user.PendingEmail == email {
+		ok, err = s.db.ConfirmPendingEmail(ctx, userID, email, now)
+		if errors.Is(err, database.ErrEmailTaken) {
+			logger.Warn().Msg("pending email taken by another account")
+			return httpx.WriteHTMLMessage(w, r, http.StatusConflict, i18n.PageEmailInUseTitle, i18n.PageEmailInUseMessage)
+		}
+	} else {
+		ok, err = s.db.MarkEmailVerified(ctx, userID, email, now)
+	}
+	if err != nil {
+		logger.Error().Err(err).Msg("db verify email failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+	if !ok {
+		logger.Warn().Msg("email of the user changed since the link was sent")
+		return httpx.WriteHTMLMessage(w, r, http.StatusBadRequest, i18n.PageInvalidVerificationLinkTitle, i18n.PageInvalidVerificationLinkMessage)
+	}
+
+	logger.Info().Msg("email verified")
+	return httpx.WriteHTMLMessage(w, r, http.StatusOK, i18n.PageEmailVerifiedTitle, i18n.PageEmailVerifiedMessage)
+}
+
+// ResendVerificationEmail godoc
+// @Summary      Resend the verification email
+// @Description  Send a new verification link to the pending email of the connected user, or to its current email if it is not verified yet
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} nil "Verification email sent"
+// @Failure      401 {object} models.Error "Unauthorized"
+// @Failure      409 {object} models.Error "Email already verified"
+// @Failure      429 {object} models.Error "A verification email was sent less than a minute ago"
+// @Failure      500 {object} models.Error "Internal server error"
+// @Router       /verify-email/resend [post]
+func (s *Service) ResendVerificationEmail(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+	logger := log.With().
+		Str("method", "ResendVerificationEmail").
+		Str("user_id", ai.UserID).
+		Logger()
+
+	if !ai.IsConnected {
+		logger.Warn().Msg("unauthorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+
+	user, err := s.db.GetUserById(r.Context(), ai.UserID)
+	if err != nil {
+		logger.Error().Err(err).Msg("db get user failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+	if user == nil {
+		logger.Warn().Msg("user not found")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+
+	var to string
+	switch {
+	case user.PendingEmail != nil:
+		to = *user.PendingEmail
+	case user.EmailVerifiedAt == nil:
+		to = user.Email
+	default:
+		logger.Warn().Msg("email already verified")
+		return httpx.WriteError(w, r, http.StatusConflict, i18n.ErrEmailAlreadyVerified)
+	}
+
+	if err := s.sendVerificationEmail(user.Id, to, user.Username, user.Locale); err != nil {
+		return writeError(w, r, logger, err, "sending verification email failed")
+	}
+
+	logger.Info().Msg("verification email sent")
+	return httpx.Write(w, http.StatusOK, nil)
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"PLIC/mailer"
+	"PLIC/models"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_VerifyEmail(t *testing.T) {
+	type expected struct {
+		code            int
+		email           string
+		verified        bool
+		hasPendingEmail bool
+	}
+
+	type testCase struct {
+		name     string
+		fixtures DBFixtures
+		token    func(t *testing.T) string
+		expected expected
+	}
+
+	userId := uuid.NewString()
+	email := "user@example.com"
+	newEmail := "new@example.com"
+
+	validToken := func(email string) func(t *testing.T) string {
+		return func(t *testing.T) string {
+			token, err := generateEmailVerificationToken(userId, email, time.Now())
+			require.NoError(t, err)
+			return token
+		}
+	}
+
+	testCases := []testCase{
+		{
+			name: "Valid link -> email verified",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{
+					models.NewDBUsersFixture().WithId(userId).WithEmail(email).WithUnverifiedEmail(),
+				},
+			},
+			token: validToken(email),
+			expected: expected{
+				code:     http.StatusOK,
+				email:    email,
+				verified: true,
+			},
+		},
+		{
+			name: "Valid link for pending email -> email replaced",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{
+					models.NewDBUsersFixture().WithId(userId).WithEmail(email).WithPendingEmail(newEmail),
+				},
+			},
+			token: validToken(newEmail),
+			expected: expected{
+				code:     http.StatusOK,
+				email:    newEmail,
+				verified: true,
+			},
+		},
+		{
+			name: "Link sent to a previous email -> 400",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{
+					models.NewDBUsersFixture().WithId(userId).WithEmail(email).WithUnverifiedEmail(),
+				},
+			},
+			token: validToken("old@example.com"),
+			expected: expected{
+				code:  http.StatusBadRequest,
+				email: email,
+			},
+		},
+		{
+			name: "Pending email taken meanwhile -> 409",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{
+					models.NewDBUsersFixture().WithId(userId).WithEmail(email).WithPendingEmail(newEmail),
+					models.NewDBUsersFixture().WithUsername("another username").WithEmail(newEmail),
+				},
+			},
+			token: validToken(newEmail),
+			expected: expected{
+				code:            http.StatusConflict,
+				email:           email,
+				verified:        true,
+				hasPendingEmail: true,
+			},
+		},
+		{
+			name: "Expired link -> 400",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{
+					models.NewDBUsersFixture().WithId(userId).WithEmail(email).WithUnverifiedEmail(),
+				},
+			},
+			token: func(t *testing.T) string {
+				token, err := generateEmailVerificationToken(userId, email, time.Now().Add(-2*emailVerificationTTL))
+				require.NoError(t, err)
+				return token
+			},
+			expected: expected{
+				code:  http.StatusBadRequest,
+				email: email,
+			},
+		},
+		{
+			name: "Access token used as verification link -> 400",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{
+					models.NewDBUsersFixture().WithId(userId).WithEmail(email).WithUnverifiedEmail(),
+				},
+			},
+			token: func(t *testing.T) string {
+				token, err := GenerateJWT(userId, uuid.NewString(), time.Now())
+				require.NoError(t, err)
+				return token
+			},
+			expected: expected{
+				code:  http.StatusBadRequest,
+				email: email,
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() {
+				if err := cleanup(); err != nil {
+					t.Logf("cleanup error: %v", err)
+				}
+			}()
+			s.loadFixtures(c.fixtures)
+
+			token := c.token(t)
+			req := httptest.NewRequest("GET", "/verify-email/"+token, nil)
+			routeCtx := chi.NewRouteContext()
+			routeCtx.URLParams.Add("token", token)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+			w := httptest.NewRecorder()
+
+			err := s.VerifyEmail(w, req, models.AuthInfo{})
+			require.NoError(t, err)
+
+			resp := w.Result()
+			defer func(Body io.ReadCloser) {
+				_ = Body.Close()
+			}(resp.Body)
+			require.Equal(t, c.expected.code, resp.StatusCode)
+
+			user, err := s.db.GetUserById(context.Background(), userId)
+			require.NoError(t, err)
+			require.Equal(t, c.expected.email, user.Email)
+			require.Equal(t, c.expected.verified, user.EmailVerifiedAt != nil)
+			require.Equal(t, c.expected.hasPendingEmail, user.PendingEmail != nil)
+		})
+	}
+}
+
+func TestService_ResendVerificationEmail(t *testing.T) {
+	type expected struct {
+		code int
+		to   string
+	}
+
+	type testCase struct {
+		name     string
+		fixtures DBFixtures
+		expected expected
+	}
+
+	userId := uuid.NewString()
+	email := "user@example.com"
+	newEmail := "new@example.com"
+
+	testCases := []testCase{
+		{
+			name: "Unverified email -> sent to current email",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{
+					models.NewDBUsersFixture().WithId(userId).WithEmail(email).WithUnverifiedEmail(),
+				},
+			},
+			expected: expected{
+				code: http.StatusOK,
+				to:   email,
+			},
+		},
+		{
+			name: "Pending email -> sent to pending email",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{
+					models.NewDBUsersFixture().WithId(userId).WithEmail(email).WithPendingEmail(newEmail),
+				},
+			},
+			expected: expected{
+				code: http.StatusOK,
+				to:   newEmail,
+			},
+		},
+		{
+			name: "Already verified -> 409",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{
+					models.NewDBUsersFixture().WithId(userId).WithEmail(email),
+				},
+			},
+			expected: expected{
+				code: http.StatusConflict,
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() {
+				if err := cleanup(); err != nil {
+					t.Logf("cleanup error: %v", err)
+				}
+			}()
+			mockMailer := mailer.NewMockMailer()
+			s.mailer = mockMailer
+			s.loadFixtures(c.fixtures)
+
+			req := httptest.NewRequest("POST", "/verify-email/resend", nil)
+			w := httptest.NewRecorder()
+
+			err := s.ResendVerificationEmail(w, req, models.AuthInfo{IsConnected: true, UserID: userId})
+			require.NoError(t, err)
+
+			resp := w.Result()
+			defer func(Body io.ReadCloser) {
+				_ = Body.Close()
+			}(resp.Body)
+			require.Equal(t, c.expected.code, resp.StatusCode)
+			if c.expected.code != http.StatusOK {
+				require.Equal(t, 0, mockMailer.GetSentCounts("verify_email"))
+				return
+			}
+
+			require.Equal(t, 1, mockMailer.GetSentCounts("verify_email"))
+			require.Equal(t, c.expected.to, mockMailer.LastVerificationTo)
+			require.True(t, strings.HasPrefix(mockMailer.LastVerificationLink, s.configuration.App.BaseURL+"/verify-email/"))
+		})
+	}
+}
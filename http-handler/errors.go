@@ -0,0 +1,81 @@
+package main
+
+import (
+	"PLIC/database"
+	"PLIC/httpx"
+	"PLIC/i18n"
+	"PLIC/mailer"
+	"errors"
+	"net/http"
+
+	"github.com/rs/zerolog"
+)
+
+// knownErrors maps the sentinel errors of the database and mailer packages to their API
+// error. A database sentinel missing here falls back to its kind, see toAppError.
+var knownErrors = []struct {
+	err    error
+	status int
+	code   i18n.Key
+}{
+	{database.ErrMatchNotFound, http.StatusNotFound, i18n.ErrMatchNotFound},
+	{database.ErrDeviceNotFound, http.StatusNotFound, i18n.ErrDeviceNotFound},
+	{database.ErrFriendRequestNotFound, http.StatusNotFound, i18n.ErrFriendRequestNotFound},
+	{database.ErrQueueEntryNotFound, http.StatusNotFound, i18n.ErrQueueEntryNotFound},
+	{database.ErrNotMatchPlayer, http.StatusNotFound, i18n.ErrPlayerNotFound},
+	{database.ErrAlreadyFriends, http.StatusConflict, i18n.ErrAlreadyFriends},
+	{database.ErrAlreadyInMatch, http.StatusConflict, i18n.ErrAlreadyInMatch},
+	{database.ErrAlreadyQueued, http.StatusConflict, i18n.ErrAlreadyQueued},
+	{database.ErrEmailTaken, http.StatusConflict, i18n.ErrEmailTaken},
+	{database.ErrUsernameTaken, http.StatusConflict, i18n.ErrUsernameTaken},
+	{database.ErrFriendRequestPending, http.StatusConflict, i18n.ErrFriendRequestAlreadySent},
+	{database.ErrQueueEntryNotWaiting, http.StatusConflict, i18n.ErrQueueEntryNotWaiting},
+	{database.ErrFriendshipBlocked, http.StatusForbidden, i18n.ErrFriendshipBlocked},
+	{database.ErrMatchNotVisible, http.StatusForbidden, i18n.ErrMatchNotVisible},
+	{database.ErrNotMatchCreator, http.StatusForbidden, i18n.ErrNotMatchCreator},
+	{database.ErrInvalidTeam, http.StatusBadRequest, i18n.ErrInvalidTeam},
+	{database.ErrMatchWrongState, http.StatusBadRequest, i18n.ErrMatchWrongState},
+	{database.ErrNotInMatch, http.StatusBadRequest, i18n.ErrNotInMatch},
+	{database.ErrTeamFull, http.StatusBadRequest, i18n.ErrTeamFull},
+	{database.ErrTeamAlreadyVoted, http.StatusBadRequest, i18n.ErrTeamAlreadyVoted},
+	{mailer.ErrThrottled, http.StatusTooManyRequests, i18n.ErrTooManyRequests},
+}
+
+// toAppError turns any error returned while handling a request into the API error
+// to answer with. An *httpx.AppError is kept as is, an i18n.Message from a validator
+// is a bad request, and an error we know nothing about is an internal error.
+func toAppError(err error) *httpx.AppError {
+	var appErr *httpx.AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	var msg i18n.Message
+	if errors.As(err, &msg) {
+		return httpx.NewError(http.StatusBadRequest, msg.Key, msg.Args...)
+	}
+	for _, e := range knownErrors {
+		if errors.Is(err, e.err) {
+			return httpx.NewError(e.status, e.code).Wrap(err)
+		}
+	}
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		return httpx.NewError(http.StatusNotFound, i18n.ErrNotFound).Wrap(err)
+	case errors.Is(err, database.ErrConflict):
+		return httpx.NewError(http.StatusConflict, i18n.ErrConflict).Wrap(err)
+	default:
+		return httpx.NewError(http.StatusInternalServerError, i18n.ErrInternal).Wrap(err)
+	}
+}
+
+// writeError logs err with msg, as a warning for a client error and as an error
+// otherwise, then writes it as a problem.
+func writeError(w http.ResponseWriter, r *http.Request, logger zerolog.Logger, err error, msg string) error {
+	appErr := toAppError(err)
+	if appErr.Status < http.StatusInternalServerError {
+		logger.Warn().Err(err).Str("code", string(appErr.Code)).Msg(msg)
+	} else {
+		logger.Error().Err(err).Msg(msg)
+	}
+	return httpx.WriteProblem(w, r, appErr)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"PLIC/database"
+	"PLIC/httpx"
+	"PLIC/i18n"
+	"PLIC/mailer"
+	"PLIC/models"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_toAppError(t *testing.T) {
+	type testCase struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   i18n.Key
+	}
+
+	testCases := []testCase{
+		{
+			name:       "App error -> kept",
+			err:        httpx.NewError(http.StatusForbidden, i18n.ErrInviteFriendsOnly),
+			wantStatus: http.StatusForbidden,
+			wantCode:   i18n.ErrInviteFriendsOnly,
+		},
+		{
+			name:       "Validation message -> 400",
+			err:        i18n.M(i18n.ErrInvalidParameter, "cursor"),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   i18n.ErrInvalidParameter,
+		},
+		{
+			name:       "Wrapped not found sentinel -> its own code",
+			err:        fmt.Errorf("join: %w", database.ErrMatchNotFound),
+			wantStatus: http.StatusNotFound,
+			wantCode:   i18n.ErrMatchNotFound,
+		},
+		{
+			name:       "Conflict sentinel",
+			err:        database.ErrUsernameTaken,
+			wantStatus: http.StatusConflict,
+			wantCode:   i18n.ErrUsernameTaken,
+		},
+		{
+			name:       "Forbidden sentinel",
+			err:        database.ErrNotMatchCreator,
+			wantStatus: http.StatusForbidden,
+			wantCode:   i18n.ErrNotMatchCreator,
+		},
+		{
+			name:       "Throttled email -> 429",
+			err:        fmt.Errorf("verify_email email to a@b.c: %w", mailer.ErrThrottled),
+			wantStatus: http.StatusTooManyRequests,
+			wantCode:   i18n.ErrTooManyRequests,
+		},
+		{
+			name:       "Unmapped not found -> generic code",
+			err:        fmt.Errorf("court: %w", database.ErrNotFound),
+			wantStatus: http.StatusNotFound,
+			wantCode:   i18n.ErrNotFound,
+		},
+		{
+			name:       "Unmapped conflict -> generic code",
+			err:        database.ErrQueueEntryTaken,
+			wantStatus: http.StatusConflict,
+			wantCode:   i18n.ErrConflict,
+		},
+		{
+			name:       "Anything else -> 500",
+			err:        errors.New("connection refused"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   i18n.ErrInternal,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			got := toAppError(c.err)
+			require.Equal(t, c.wantStatus, got.Status)
+			require.Equal(t, c.wantCode, got.Code)
+		})
+	}
+}
+
+func Test_writeError(t *testing.T) {
+	r := httptest.NewRequest("DELETE", "/devices/abc", nil)
+	w := httptest.NewRecorder()
+
+	require.NoError(t, writeError(w, r, zerolog.Nop(), errors.New("pq: connection refused"), "db delete device failed"))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Equal(t, httpx.ProblemContentType, w.Header().Get("Content-Type"))
+	require.Contains(t, w.Body.String(), `"code":"internal_error"`)
+	require.NotContains(t, w.Body.String(), "pq:")
+}
+
+// The codes listed in the swagger of models.Error must be the ones the API can answer with.
+func Test_errorCodesDocumented(t *testing.T) {
+	field, ok := reflect.TypeOf(models.Error{}).FieldByName("Code")
+	require.True(t, ok)
+
+	codes := make([]string, 0, len(i18n.ErrorCodes))
+	for _, k := range i18n.ErrorCodes {
+		codes = append(codes, string(k))
+	}
+	require.Equal(t, strings.Join(codes, ","), field.Tag.Get("enums"))
+}
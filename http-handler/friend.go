@@ -0,0 +1,276 @@
+package main
+
+import (
+	"PLIC/httpx"
+	"PLIC/i18n"
+	"PLIC/models"
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+func (s *Service) buildFriendResponses(ctx context.Context, logger zerolog.Logger, friends []models.DBFriend) []models.FriendResponse {
+	userIDs := make([]string, 0, len(friends))
+	for _, f := range friends {
+		userIDs = append(userIDs, f.UserID)
+	}
+	profilePics := s.prefetchProfilePictures(ctx, logger, userIDs)
+
+	res := make([]models.FriendResponse, 0, len(friends))
+	for _, f := range friends {
+		var pic *string
+		if url, ok := profilePics[f.UserID]; ok {
+			pic = &url
+		}
+		res = append(res, models.FriendResponse{
+			UserID:         f.UserID,
+			Username:       f.Username,
+			ProfilePicture: pic,
+			Since:          f.UpdatedAt,
+		})
+	}
+	return res
+}
+
+// checkFriendTarget validates the userId path parameter of the friend endpoints: it
+// must be another existing user. It writes the error response and returns false
+// otherwise.
+func (s *Service) checkFriendTarget(w http.ResponseWriter, r *http.Request, logger zerolog.Logger, userID, targetID string) (bool, error) {
+	if targetID == "" {
+		logger.Warn().Msg("missing user ID")
+		return false, httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMissingParameter, "userId")
+	}
+	if targetID == userID {
+		logger.Warn().Msg("target is the caller")
+		return false, httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrCannotTargetSelf)
+	}
+
+	target, err := s.db.GetUserById(r.Context(), targetID)
+	if err != nil {
+		logger.Error().Err(err).Msg("db get user failed")
+		return false, httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+	if target == nil {
+		logger.Warn().Msg("user not found")
+		return false, httpx.WriteError(w, r, http.StatusNotFound, i18n.ErrUserNotFound)
+	}
+	return true, nil
+}
+
+// RequestFriend godoc
+// @Summary      Envoie une demande d'ami
+// @Description  Envoie une demande d'ami à l'utilisateur. Si celui-ci avait déjà envoyé une demande à l'appelant, l'amitié est acceptée directement. Une demande refusée peut être renvoyée.
+// @Tags         friend
+// @Produce      json
+// @Param        userId  path      string  true  "Identifiant de l'utilisateur"
+// @Success      200     {object}  models.FriendshipResponse  "Statut de la relation : pending ou accepted"
+// @Failure      400     {object}  models.Error  "Identifiant manquant ou égal à celui de l'appelant"
+// @Failure      401     {object}  models.Error  "Utilisateur non autorisé"
+// @Failure      403     {object}  models.Error  "L'un des deux utilisateurs a bloqué l'autre"
+// @Failure      404     {object}  models.Error  "Utilisateur non trouvé"
+// @Failure      409     {object}  models.Error  "Déjà amis ou demande déjà envoyée"
+// @Failure      500     {object}  models.Error  "Erreur serveur"
+// @Router       /friends/{userId}/request [post]
+// @Security     BearerAuth
+func (s *Service) RequestFriend(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+	baseLogger := log.With().
+		Str("method", "RequestFriend").
+		Str("user_id", ai.UserID).
+		Logger()
+
+	if !ai.IsConnected {
+		baseLogger.Warn().Msg("unauthorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+
+	targetID := chi.URLParam(r, "userId")
+	logger := baseLogger.With().Str("target_id", targetID).Logger()
+
+	if ok, err := s.checkFriendTarget(w, r, logger, ai.UserID, targetID); !ok {
+		return err
+	}
+
+	status, err := s.db.RequestFriendship(r.Context(), ai.UserID, targetID, s.clock.Now())
+	if err != nil {
+		return writeError(w, r, logger, err, "db request friendship failed")
+	}
+
+	logger.Info().Str("status", string(status)).Msg("friend request sent")
+	return httpx.Write(w, http.StatusOK, models.FriendshipResponse{UserID: targetID, Status: status})
+}
+
+// AcceptFriendRequest godoc
+// @Summary      Accepte une demande d'ami
+// @Description  Accepte la demande d'ami en attente envoyée par l'utilisateur
+// @Tags         friend
+// @Produce      json
+// @Param        userId  path      string  true  "Identifiant de l'utilisateur qui a envoyé la demande"
+// @Success      200     {object}  models.FriendshipResponse
+// @Failure      400     {object}  models.Error  "Identifiant manquant"
+// @Failure      401     {object}  models.Error  "Utilisateur non autorisé"
+// @Failure      404     {object}  models.Error  "Aucune demande en attente de cet utilisateur"
+// @Failure      500     {object}  models.Error  "Erreur serveur"
+// @Router       /friends/{userId}/accept [post]
+// @Security     BearerAuth
+func (s *Service) AcceptFriendRequest(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+	return s.respondFriendRequest(w, r, ai, true)
+}
+
+// DeclineFriendRequest godoc
+// @Summary      Refuse une demande d'ami
+// @Description  Refuse la demande d'ami en attente envoyée par l'utilisateur. Celui-ci pourra en renvoyer une.
+// @Tags         friend
+// @Produce      json
+// @Param        userId  path      string  true  "Identifiant de l'utilisateur qui a envoyé la demande"
+// @Success      200     {object}  models.FriendshipResponse
+// @Failure      400     {object}  models.Error  "Identifiant manquant"
+// @Failure      401     {object}  models.Error  "Utilisateur non autorisé"
+// @Failure      404     {object}  models.Error  "Aucune demande en attente de cet utilisateur"
+// @Failure      500     {object}  models.Error  "Erreur serveur"
+// @Router       /friends/{userId}/decline [post]
+// @Security     BearerAuth
+func (s *Service) DeclineFriendRequest(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+	return s.respondFriendRequest(w, r, ai, false)
+}
+
+func (s *Service) respondFriendRequest(w http.ResponseWriter, r *http.Request, ai models.AuthInfo, accept bool) error {
+	baseLogger := log.With().
+		Str("method", "respondFriendRequest").
+		Str("user_id", ai.UserID).
+		Bool("accept", accept).
+		Logger()
+
+	if !ai.IsConnected {
+		baseLogger.Warn().Msg("unauthorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+
+	requesterID := chi.URLParam(r, "userId")
+	logger := baseLogger.With().Str("requester_id", requesterID).Logger()
+
+	if requesterID == "" {
+		logger.Warn().Msg("missing user ID")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMissingParameter, "userId")
+	}
+
+	if err := s.db.RespondFriendRequest(r.Context(), ai.UserID, requesterID, accept, s.clock.Now()); err != nil {
+		return writeError(w, r, logger, err, "db respond friend request failed")
+	}
+
+	status := models.FriendshipDeclined
+	if accept {
+		status = models.FriendshipAccepted
+	}
+	logger.Info().Str("status", string(status)).Msg("friend request answered")
+	return httpx.Write(w, http.StatusOK, models.FriendshipResponse{UserID: requesterID, Status: status})
+}
+
+// BlockUser godoc
+// @Summary      Bloque un utilisateur
+// @Description  Supprime l'amitié ou la demande en cours avec l'utilisateur et l'empêche d'envoyer une nouvelle demande
+// @Tags         friend
+// @Produce      json
+// @Param        userId  path      string  true  "Identifiant de l'utilisateur à bloquer"
+// @Success      200     {object}  models.FriendshipResponse
+// @Failure      400     {object}  models.Error  "Identifiant manquant ou égal à celui de l'appelant"
+// @Failure      401     {object}  models.Error  "Utilisateur non autorisé"
+// @Failure      404     {object}  models.Error  "Utilisateur non trouvé"
+// @Failure      500     {object}  models.Error  "Erreur serveur"
+// @Router       /friends/{userId}/block [post]
+// @Security     BearerAuth
+func (s *Service) BlockUser(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+	baseLogger := log.With().
+		Str("method", "BlockUser").
+		Str("user_id", ai.UserID).
+		Logger()
+
+	if !ai.IsConnected {
+		baseLogger.Warn().Msg("unauthorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+
+	targetID := chi.URLParam(r, "userId")
+	logger := baseLogger.With().Str("target_id", targetID).Logger()
+
+	if ok, err := s.checkFriendTarget(w, r, logger, ai.UserID, targetID); !ok {
+		return err
+	}
+
+	if err := s.db.BlockUser(r.Context(), ai.UserID, targetID, s.clock.Now()); err != nil {
+		logger.Error().Err(err).Msg("db block user failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+
+	logger.Info().Msg("user blocked")
+	return httpx.Write(w, http.StatusOK, models.FriendshipResponse{UserID: targetID, Status: models.FriendshipBlocked})
+}
+
+// GetFriends godoc
+// @Summary      Liste les amis
+// @Description  Retourne les amis de l'utilisateur connecté, triés par nom d'utilisateur
+// @Tags         friend
+// @Produce      json
+// @Success      200  {array}   models.FriendResponse
+// @Failure      401  {object}  models.Error  "Utilisateur non autorisé"
+// @Failure      500  {object}  models.Error  "Erreur serveur"
+// @Router       /friends [get]
+// @Security     BearerAuth
+func (s *Service) GetFriends(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+	logger := log.With().
+		Str("method", "GetFriends").
+		Str("user_id", ai.UserID).
+		Logger()
+
+	if !ai.IsConnected {
+		logger.Warn().Msg("unauthorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+
+	ctx := r.Context()
+	friends, err := s.db.GetFriends(ctx, ai.UserID)
+	if err != nil {
+		logger.Error().Err(err).Msg("db get friends failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+
+	logger.Info().Int("count", len(friends)).Msg("friends fetched")
+	return httpx.Write(w, http.StatusOK, s.buildFriendResponses(ctx, logger, friends))
+}
+
+// GetFriendRequests godoc
+// @Summary      Liste les demandes d'ami en attente
+// @Description  Retourne les demandes d'ami reçues et envoyées par l'utilisateur connecté qui attendent une réponse, des plus anciennes aux plus récentes
+// @Tags         friend
+// @Produce      json
+// @Success      200  {object}  models.FriendRequestsResponse
+// @Failure      401  {object}  models.Error  "Utilisateur non autorisé"
+// @Failure      500  {object}  models.Error  "Erreur serveur"
+// @Router       /friends/requests [get]
+// @Security     BearerAuth
+func (s *Service) GetFriendRequests(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+	logger := log.With().
+		Str("method", "GetFriendRequests").
+		Str("user_id", ai.UserID).
+		Logger()
+
+	if !ai.IsConnected {
+		logger.Warn().Msg("unauthorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+
+	ctx := r.Context()
+	incoming, outgoing, err := s.db.GetPendingFriendRequests(ctx, ai.UserID)
+	if err != nil {
+		logger.Error().Err(err).Msg("db get pending friend requests failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+
+	logger.Info().Int("incoming", len(incoming)).Int("outgoing", len(outgoing)).Msg("friend requests fetched")
+	return httpx.Write(w, http.StatusOK, models.FriendRequestsResponse{
+		Incoming: s.buildFriendResponses(ctx, logger, incoming),
+		Outgoing: s.buildFriendResponses(ctx, logger, outgoing),
+	})
+}
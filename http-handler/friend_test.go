@@ -0,0 +1,190 @@
+package main
+
+import (
+	"PLIC/models"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RequestFriend(t *testing.T) {
+	type expected struct {
+		code     int
+		errorMsg string
+		status   models.FriendshipStatus
+	}
+
+	type testCase struct {
+		name     string
+		targetID string
+		existing []models.DBFriendship
+		auth     models.AuthInfo
+		expected expected
+	}
+
+	me := models.NewDBUsersFixture().WithUsername("me").WithEmail("me@example.com")
+	other := models.NewDBUsersFixture().WithUsername("other").WithEmail("other@example.com")
+
+	testCases := []testCase{
+		{
+			name:     "New request -> pending",
+			targetID: other.Id,
+			auth:     models.AuthInfo{IsConnected: true, UserID: me.Id},
+			expected: expected{code: http.StatusOK, status: models.FriendshipPending},
+		},
+		{
+			name:     "Crossed request -> accepted",
+			targetID: other.Id,
+			existing: []models.DBFriendship{
+				models.NewDBFriendshipFixture().WithRequesterId(other.Id).WithAddresseeId(me.Id),
+			},
+			auth:     models.AuthInfo{IsConnected: true, UserID: me.Id},
+			expected: expected{code: http.StatusOK, status: models.FriendshipAccepted},
+		},
+		{
+			name:     "Already sent -> 409",
+			targetID: other.Id,
+			existing: []models.DBFriendship{
+				models.NewDBFriendshipFixture().WithRequesterId(me.Id).WithAddresseeId(other.Id),
+			},
+			auth:     models.AuthInfo{IsConnected: true, UserID: me.Id},
+			expected: expected{code: http.StatusConflict, errorMsg: "friend_request_already_sent"},
+		},
+		{
+			name:     "Blocked -> 403",
+			targetID: other.Id,
+			existing: []models.DBFriendship{
+				models.NewDBFriendshipFixture().
+					WithRequesterId(other.Id).
+					WithAddresseeId(me.Id).
+					WithStatus(models.FriendshipBlocked),
+			},
+			auth:     models.AuthInfo{IsConnected: true, UserID: me.Id},
+			expected: expected{code: http.StatusForbidden, errorMsg: "friendship_blocked"},
+		},
+		{
+			name:     "Self -> 400",
+			targetID: me.Id,
+			auth:     models.AuthInfo{IsConnected: true, UserID: me.Id},
+			expected: expected{code: http.StatusBadRequest, errorMsg: "cannot_target_self"},
+		},
+		{
+			name:     "Unknown user -> 404",
+			targetID: uuid.NewString(),
+			auth:     models.AuthInfo{IsConnected: true, UserID: me.Id},
+			expected: expected{code: http.StatusNotFound, errorMsg: "user_not_found"},
+		},
+		{
+			name:     "Unauthorized -> 401",
+			targetID: other.Id,
+			auth:     models.AuthInfo{IsConnected: false},
+			expected: expected{code: http.StatusUnauthorized},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() { _ = cleanup() }()
+			s.loadFixtures(DBFixtures{
+				Users:       []models.DBUsers{me, other},
+				Friendships: c.existing,
+			})
+
+			r := httptest.NewRequest("POST", "/friends/"+c.targetID+"/request", nil)
+			routeCtx := chi.NewRouteContext()
+			routeCtx.URLParams.Add("userId", c.targetID)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeCtx))
+			w := httptest.NewRecorder()
+
+			err := s.RequestFriend(w, r, c.auth)
+			require.NoError(t, err)
+
+			resp := w.Result()
+			defer func(Body io.ReadCloser) { _ = Body.Close() }(resp.Body)
+			require.Equal(t, c.expected.code, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			if c.expected.errorMsg != "" {
+				require.Contains(t, string(body), c.expected.errorMsg)
+			}
+			if c.expected.code == http.StatusOK {
+				var res models.FriendshipResponse
+				require.NoError(t, json.Unmarshal(body, &res))
+				require.Equal(t, c.expected.status, res.Status)
+				require.Equal(t, c.targetID, res.UserID)
+			}
+		})
+	}
+}
+
+func Test_FriendRequestFlow(t *testing.T) {
+	me := models.NewDBUsersFixture().WithUsername("me").WithEmail("me@example.com")
+	sender := models.NewDBUsersFixture().WithUsername("sender").WithEmail("sender@example.com")
+	receiver := models.NewDBUsersFixture().WithUsername("receiver").WithEmail("receiver@example.com")
+
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() { _ = cleanup() }()
+	s.loadFixtures(DBFixtures{
+		Users: []models.DBUsers{me, sender, receiver},
+		Friendships: []models.DBFriendship{
+			models.NewDBFriendshipFixture().WithRequesterId(sender.Id).WithAddresseeId(me.Id),
+			models.NewDBFriendshipFixture().WithRequesterId(me.Id).WithAddresseeId(receiver.Id),
+		},
+	})
+	auth := models.AuthInfo{IsConnected: true, UserID: me.Id}
+
+	call := func(handler func(http.ResponseWriter, *http.Request, models.AuthInfo) error, userID string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/friends", nil)
+		routeCtx := chi.NewRouteContext()
+		routeCtx.URLParams.Add("userId", userID)
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeCtx))
+		w := httptest.NewRecorder()
+		require.NoError(t, handler(w, r, auth))
+		return w
+	}
+
+	w := call(s.GetFriendRequests, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var requests models.FriendRequestsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &requests))
+	require.Len(t, requests.Incoming, 1)
+	require.Equal(t, sender.Id, requests.Incoming[0].UserID)
+	require.Equal(t, "sender", requests.Incoming[0].Username)
+	require.Len(t, requests.Outgoing, 1)
+	require.Equal(t, receiver.Id, requests.Outgoing[0].UserID)
+
+	// A request sent by me cannot be accepted by me
+	w = call(s.AcceptFriendRequest, receiver.Id)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w = call(s.AcceptFriendRequest, sender.Id)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = call(s.GetFriends, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var friends []models.FriendResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &friends))
+	require.Len(t, friends, 1)
+	require.Equal(t, sender.Id, friends[0].UserID)
+	require.NotNil(t, friends[0].ProfilePicture)
+
+	w = call(s.BlockUser, sender.Id)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = call(s.GetFriends, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &friends))
+	require.Empty(t, friends)
+}
@@ -3,6 +3,7 @@ package main
 import (
 	googlehandler "PLIC/google-handler"
 	"PLIC/httpx"
+	"PLIC/i18n"
 	"PLIC/models"
 	"context"
 	"fmt"
@@ -73,7 +74,7 @@ func (s *Service) HandleSyncGooglePlaces(w http.ResponseWriter, r *http.Request,
 
 	if err := s.SyncGooglePlaces(ctx, lat, lng, apiKey); err != nil {
 		logger.Error().Err(err).Msg("Google Places sync failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "erreur lors de la synchro des terrains")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 
 	logger.Info().Msg("Google Places sync succeeded")
@@ -1,9 +1,10 @@
 package main
 
 import (
-	"PLIC/httpx"
 	"PLIC/models"
 	"net/http"
+
+	"github.com/rs/zerolog/log"
 )
 
 type httpHandler func(http.ResponseWriter, *http.Request, models.AuthInfo) error
@@ -11,7 +12,7 @@ type httpHandler func(http.ResponseWriter, *http.Request, models.AuthInfo) error
 func (s *Service) GET(path string, handlerFunc httpHandler) {
 	s.server.Get(path, func(w http.ResponseWriter, r *http.Request) {
 		if err := handlerFunc(w, r, models.AuthInfo{}); err != nil {
-			_ = httpx.WriteError(w, http.StatusInternalServerError, err.Error())
+			_ = writeError(w, r, log.With().Str("path", path).Logger(), err, "handler failed")
 		}
 	})
 }
@@ -19,7 +20,7 @@ func (s *Service) GET(path string, handlerFunc httpHandler) {
 func (s *Service) POST(path string, handlerFunc httpHandler) {
 	s.server.Post(path, func(w http.ResponseWriter, r *http.Request) {
 		if err := handlerFunc(w, r, models.AuthInfo{}); err != nil {
-			_ = httpx.WriteError(w, http.StatusInternalServerError, err.Error())
+			_ = writeError(w, r, log.With().Str("path", path).Logger(), err, "handler failed")
 		}
 	})
 }
@@ -27,7 +28,7 @@ func (s *Service) POST(path string, handlerFunc httpHandler) {
 func (s *Service) PATCH(path string, handlerFunc httpHandler) {
 	s.server.Patch(path, func(w http.ResponseWriter, r *http.Request) {
 		if err := handlerFunc(w, r, models.AuthInfo{}); err != nil {
-			_ = httpx.WriteError(w, http.StatusInternalServerError, err.Error())
+			_ = writeError(w, r, log.With().Str("path", path).Logger(), err, "handler failed")
 		}
 	})
 }
@@ -35,7 +36,7 @@ func (s *Service) PATCH(path string, handlerFunc httpHandler) {
 func (s *Service) DELETE(path string, handlerFunc httpHandler) {
 	s.server.Delete(path, func(w http.ResponseWriter, r *http.Request) {
 		if err := handlerFunc(w, r, models.AuthInfo{}); err != nil {
-			_ = httpx.WriteError(w, http.StatusInternalServerError, err.Error())
+			_ = writeError(w, r, log.With().Str("path", path).Logger(), err, "handler failed")
 		}
 	})
 }
@@ -1,8 +1,12 @@
 package main
 
 import (
+	"PLIC/clock"
 	"PLIC/database"
+	"PLIC/events"
 	"PLIC/models"
+	"PLIC/notifier"
+	"PLIC/rating"
 	"PLIC/s3_management"
 	"context"
 	"database/sql"
@@ -20,11 +24,21 @@ import (
 )
 
 type DBFixtures struct {
-	Users       []models.DBUsers
-	Matches     []models.DBMatches
-	UserMatches []models.DBUserMatch
-	Courts      []models.DBCourt
-	Rankings    []models.DBRanking
+	Users        []models.DBUsers
+	Matches      []models.DBMatches
+	UserMatches  []models.DBUserMatch
+	Courts       []models.DBCourt
+	Rankings     []models.DBRanking
+	History      []models.DBRankingHistory
+	Sessions     []models.DBSession
+	Friendships  []models.DBFriendship
+	QueueEntries []models.DBQueueEntry
+	OutboxEvents []models.DBOutboxEvent
+	Devices      []models.DBDevice
+
+	NotificationPreferences []models.DBNotificationPreferences
+
+	PasswordResetTokens []models.DBPasswordResetToken
 }
 
 func findLatestMigrationFile(dir string) (string, error) {
@@ -100,7 +114,27 @@ func (s *Service) InitServiceTest() func() error {
 		panic(err)
 	}
 
-	s.clock = Clock{location: parisLocation}
+	s.clock = clock.New(parisLocation)
+	s.ratings = rating.DefaultEngines()
+	s.events = events.NewMemoryBus()
+	push := notifier.NewFake()
+	s.notifier = &notifier.Notifier{Store: s.db, Providers: map[models.DevicePlatform]notifier.Provider{
+		models.PlatformIOS:     push,
+		models.PlatformAndroid: push,
+	}}
+
+	s.configuration = &models.Configuration{
+		App: models.AppConfig{
+			BaseURL: "http://localhost:8080",
+		},
+		Outbox: models.OutboxConfig{
+			MaxAttempts: 3,
+			BaseBackoff: time.Second,
+			MaxBackoff:  time.Minute,
+			Lease:       time.Minute,
+			BatchSize:   50,
+		},
+	}
 
 	return cleanup
 }
@@ -196,4 +230,52 @@ func (s *Service) loadFixtures(fixtures DBFixtures) {
 			panic(fmt.Sprintf("failed to insert ranking: %v", err))
 		}
 	}
+
+	for _, h := range fixtures.History {
+		if err := s.db.InsertRankingHistory(ctx, h); err != nil {
+			panic(fmt.Sprintf("failed to insert ranking history: %v", err))
+		}
+	}
+
+	for _, f := range fixtures.Friendships {
+		if err := s.db.InsertFriendship(ctx, f); err != nil {
+			panic(fmt.Sprintf("failed to insert friendship: %v", err))
+		}
+	}
+
+	for _, e := range fixtures.QueueEntries {
+		if err := s.db.JoinQueue(ctx, e); err != nil {
+			panic(fmt.Sprintf("failed to insert queue entry: %v", err))
+		}
+	}
+
+	for _, e := range fixtures.OutboxEvents {
+		if err := s.db.InsertOutboxEvent(ctx, e); err != nil {
+			panic(fmt.Sprintf("failed to insert outbox event: %v", err))
+		}
+	}
+
+	for _, d := range fixtures.Devices {
+		if err := s.db.RegisterDevice(ctx, d); err != nil {
+			panic(fmt.Sprintf("failed to insert device: %v", err))
+		}
+	}
+
+	for _, p := range fixtures.NotificationPreferences {
+		if err := s.db.UpsertNotificationPreferences(ctx, p); err != nil {
+			panic(fmt.Sprintf("failed to insert notification preferences: %v", err))
+		}
+	}
+
+	for _, session := range fixtures.Sessions {
+		if err := s.db.CreateSession(ctx, session); err != nil {
+			panic(fmt.Sprintf("failed to insert session: %v", err))
+		}
+	}
+
+	for _, token := range fixtures.PasswordResetTokens {
+		if err := s.db.CreatePasswordResetToken(ctx, token); err != nil {
+			panic(fmt.Sprintf("failed to insert password reset token: %v", err))
+		}
+	}
 }
@@ -1,18 +1,12 @@
 package main
 
 import (
-	"PLIC/database"
 	"PLIC/httpx"
+	"PLIC/i18n"
 	"PLIC/models"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/base64"
-	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"net/http"
-	"net/mail"
 	"strings"
 	"time"
 
@@ -23,9 +17,18 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-func GenerateJWT(userID string) (string, error) {
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+	resetTokenTTL   = 15 * time.Minute
+)
+
+func GenerateJWT(userID, sessionID string, now time.Time) (string, error) {
 	claims := jwt.MapClaims{
 		"user_id": userID,
+		"sid":     sessionID,
+		"iat":     now.Unix(),
+		"exp":     now.Add(accessTokenTTL).Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -54,9 +57,8 @@ func (s *Service) Login(w http.ResponseWriter, r *http.Request, _ models.AuthInf
 	}(r.Body)
 
 	var req models.LoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		baseLogger.Error().Err(err).Msg("invalid JSON body")
-		return httpx.WriteError(w, http.StatusBadRequest, httpx.BadRequestError)
+	if err := httpx.DecodeJSON(w, r, &req, s.clock.Now()); err != nil {
+		return writeError(w, r, baseLogger, err, "invalid JSON body")
 	}
 
 	logger := baseLogger.With().
@@ -69,43 +71,36 @@ func (s *Service) Login(w http.ResponseWriter, r *http.Request, _ models.AuthInf
 	user, err := s.db.GetUserByUsername(ctx, req.Username)
 	if err != nil {
 		logger.Error().Err(err).Msg("db get user by username failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, httpx.InternalServerError)
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 	if user == nil {
 		logger.Warn().Msg("user not found")
-		return httpx.WriteError(w, http.StatusUnauthorized, httpx.UnauthorizedError)
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
 		logger.Warn().Err(err).Msg("password comparison failed")
-		return httpx.WriteError(w, http.StatusUnauthorized, httpx.UnauthorizedError)
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
 	}
 
-	token, err := GenerateJWT(user.Id)
+	res, err := s.openSession(ctx, user.Id)
 	if err != nil {
-		logger.Error().Err(err).Msg("JWT generation failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, httpx.InternalServerError)
+		logger.Error().Err(err).Msg("session creation failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 
 	logger.Info().Str("user_id", user.Id).Msg("login succeeded")
-	return httpx.Write(w, http.StatusOK, models.LoginResponse{
-		Token:  token,
-		UserId: user.Id,
-	})
-}
-
-func isValidEmail(email string) bool {
-	_, err := mail.ParseAddress(email)
-	return err == nil
+	return httpx.Write(w, http.StatusOK, res)
 }
 
 // Register godoc
 // @Summary      Register a new user
-// @Description  Register a user with username and password
+// @Description  Register a user with username and password. A verification link is sent to the email, which must be confirmed before creating or joining a match. The language of the emails is negotiated from Accept-Language and can be changed later with PATCH /users/{id}.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Param        request body models.RegisterRequest true "User credentials"
+// @Param        Accept-Language header string false "Language of the messages and emails (fr, en)"
 // @Success      201 {object} models.LoginResponse
 // @Failure      400 {object} models.Error "Bad request"
 // @Failure      401 {object} models.Error "User already exists"
@@ -120,9 +115,8 @@ func (s *Service) Register(w http.ResponseWriter, r *http.Request, _ models.Auth
 	}(r.Body)
 
 	var req models.RegisterRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		baseLogger.Error().Err(err).Msg("invalid JSON body")
-		return httpx.WriteError(w, http.StatusBadRequest, httpx.BadRequestError)
+	if err := httpx.DecodeJSON(w, r, &req, s.clock.Now()); err != nil {
+		return writeError(w, r, baseLogger, err, "invalid JSON body")
 	}
 
 	logger := baseLogger.With().
@@ -133,23 +127,14 @@ func (s *Service) Register(w http.ResponseWriter, r *http.Request, _ models.Auth
 
 	logger.Info().Msg("entering Register")
 
-	if !isValidEmail(req.Email) {
-		logger.Warn().Msg("invalid email")
-		return httpx.WriteError(w, http.StatusBadRequest, "Invalid Email")
-	}
-	if req.Password == "" || req.Username == "" {
-		logger.Warn().Msg("missing username or password")
-		return httpx.WriteError(w, http.StatusBadRequest, "Password and username shouldn't be empty")
-	}
-
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		if errors.Is(err, bcrypt.ErrPasswordTooLong) {
 			logger.Warn().Err(err).Msg("password too long")
-			return httpx.WriteError(w, http.StatusBadRequest, "The password is too long")
+			return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrPasswordTooLong)
 		}
 		logger.Error().Err(err).Msg("password hashing failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, httpx.InternalServerError)
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 
 	newUser := models.DBUsers{
@@ -158,103 +143,48 @@ func (s *Service) Register(w http.ResponseWriter, r *http.Request, _ models.Auth
 		Email:     req.Email,
 		Bio:       req.Bio,
 		Password:  string(hashedPassword),
+		Locale:    i18n.FromRequest(r),
 		CreatedAt: s.clock.Now(),
 		UpdatedAt: s.clock.Now(),
 	}
 
 	if err := s.db.CreateUser(ctx, newUser); err != nil {
-		switch {
-		case errors.Is(err, database.ErrEmailTaken):
-			logger.Warn().Err(err).Msg("email already taken")
-			return httpx.WriteError(w, http.StatusConflict, "email_taken")
-		case errors.Is(err, database.ErrUsernameTaken):
-			logger.Warn().Err(err).Msg("username already taken")
-			return httpx.WriteError(w, http.StatusConflict, "username_taken")
-		default:
-			logger.Error().Err(err).Msg("db create user failed")
-			return httpx.WriteError(w, http.StatusInternalServerError, httpx.InternalServerError)
-		}
+		return writeError(w, r, logger, err, "db create user failed")
 	}
 
-	token, err := GenerateJWT(newUser.Id)
+	res, err := s.openSession(ctx, newUser.Id)
 	if err != nil {
-		logger.Error().Err(err).Msg("JWT generation failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, httpx.InternalServerError)
+		logger.Error().Err(err).Msg("session creation failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 
 	logger.Info().Str("user_id", newUser.Id).Msg("user registered successfully")
 
-	if err := s.mailer.SendWelcomeEmail(newUser.Id, newUser.Email, newUser.Username); err != nil {
+	if err := s.mailer.SendWelcomeEmail(newUser.Id, newUser.Email, newUser.Username, newUser.Locale); err != nil {
 		log.Error().Err(err).Msg("async welcome email failed")
 	}
 
-	return httpx.Write(w, http.StatusCreated, models.LoginResponse{
-		Token:  token,
-		UserId: newUser.Id,
-	})
-}
-
-func encodeAndTrim(data []byte, length int) string {
-	encoded := base64.StdEncoding.EncodeToString(data)
-
-	encoded = strings.Map(func(r rune) rune {
-		if strings.ContainsRune("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789!@#$", r) {
-			return r
-		}
-		return -1
-	}, encoded)
-
-	if len(encoded) < length {
-		return encoded
+	if err := s.sendVerificationEmail(newUser.Id, newUser.Email, newUser.Username, newUser.Locale); err != nil {
+		log.Error().Err(err).Msg("verification email failed")
 	}
-	return encoded[:length]
-}
-
-func passwordFromToken(token string, secret string) (string, error) {
-	mac := hmac.New(sha256.New, []byte(secret))
-	mac.Write([]byte(token))
-	sum := mac.Sum(nil)
-	return encodeAndTrim(sum, 12), nil
-}
 
-func generateResetToken(email string) (string, error) {
-	claims := jwt.MapClaims{
-		"email": email,
-		"exp":   time.Now().Add(15 * time.Minute).Unix(),
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(jwtSecret))
+	return httpx.Write(w, http.StatusCreated, res)
 }
 
-func parseResetToken(tokenStr string) (string, error) {
-	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-		return []byte(jwtSecret), nil
-	})
-
-	if err != nil {
-		return "", err
-	}
-
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		email, ok := claims["email"].(string)
-		if !ok {
-			return "", jwt.ErrTokenMalformed
-		}
-		return email, nil
-	}
-
-	return "", fmt.Errorf("invalid token claims: %v", token.Claims)
+func (s *Service) appLink(path string) string {
+	return strings.TrimRight(s.configuration.App.BaseURL, "/") + path
 }
 
 // ForgetPassword godoc
 // @Summary      Request password reset
-// @Description  Generate a new password and send it via email to the param if the account exists
+// @Description  Send a single-use link to choose a new password if the account exists. Previous links of the user stop working.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Param        request body models.MailerRequest true "Email of the param"
 // @Success      200 {object} nil "Success even if param does not exist (for security)"
 // @Failure      400 {object} models.Error "Bad request (invalid JSON or email format)"
+// @Failure      429 {object} models.Error "A reset link was sent to this email a few seconds ago"
 // @Failure      500 {object} models.Error "Internal server error"
 // @Router       /forgot-password [post]
 func (s *Service) ForgetPassword(w http.ResponseWriter, r *http.Request, _ models.AuthInfo) error {
@@ -266,9 +196,8 @@ func (s *Service) ForgetPassword(w http.ResponseWriter, r *http.Request, _ model
 	}(r.Body)
 
 	var req models.MailerRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		baseLogger.Error().Err(err).Msg("invalid JSON body")
-		return httpx.WriteError(w, http.StatusBadRequest, httpx.BadRequestError)
+	if err := httpx.DecodeJSON(w, r, &req, s.clock.Now()); err != nil {
+		return writeError(w, r, baseLogger, err, "invalid JSON body")
 	}
 
 	logger := baseLogger.With().
@@ -277,96 +206,139 @@ func (s *Service) ForgetPassword(w http.ResponseWriter, r *http.Request, _ model
 
 	logger.Info().Msg("entering ForgetPassword")
 
-	if _, err := mail.ParseAddress(req.Email); err != nil {
-		logger.Warn().Err(err).Msg("invalid email address")
-		return httpx.WriteError(w, http.StatusBadRequest, "Invalid email address")
-	}
-
 	user, err := s.db.GetUserByEmail(ctx, req.Email)
 	if err != nil {
 		logger.Error().Err(err).Msg("db get user by email failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, httpx.InternalServerError)
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 	if user == nil {
 		logger.Warn().Msg("user not found (masked success)")
 		return httpx.Write(w, http.StatusOK, nil)
 	}
 
-	token, err := generateResetToken(req.Email)
+	token, err := generateSecureToken()
 	if err != nil {
 		logger.Error().Err(err).Msg("reset token generation failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, httpx.InternalServerError)
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 
-	logger.Debug().Int("token_len", len(token)).Msg("reset token generated")
+	now := s.clock.Now()
+	if err := s.db.CreatePasswordResetToken(ctx, models.DBPasswordResetToken{
+		Id:        uuid.NewString(),
+		UserID:    user.Id,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(resetTokenTTL),
+		CreatedAt: now,
+	}); err != nil {
+		logger.Error().Err(err).Msg("db create reset token failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
 
-	if err := s.mailer.SendLinkResetPassword(req.Email, "https://gfosd9euua.execute-api.eu-west-3.amazonaws.com/reset-password/"+token); err != nil {
-		logger.Error().Err(err).Msg("sending reset link failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, httpx.InternalServerError)
+	if err := s.mailer.SendLinkResetPassword(req.Email, s.appLink("/reset-password/"+token), user.Locale); err != nil {
+		return writeError(w, r, logger, err, "sending reset link failed")
 	}
 
 	logger.Info().Msg("reset link sent")
 	return httpx.Write(w, http.StatusOK, nil)
 }
 
+// ResetPasswordForm godoc
+// @Summary      Affiche le formulaire de réinitialisation du mot de passe
+// @Description  Vérifie que le lien de réinitialisation est valide (non expiré et non utilisé) et affiche un formulaire permettant de choisir un nouveau mot de passe
+// @Tags         auth
+// @Produce      html
+// @Param        token path string true "Token de réinitialisation"
+// @Success      200 {string} string "Formulaire HTML de réinitialisation"
+// @Failure      400 {string} string "HTML indiquant que le lien est invalide ou expiré"
+// @Failure      500 {object} models.Error "Erreur interne du serveur"
+// @Router       /reset-password/{token} [get]
+func (s *Service) ResetPasswordForm(w http.ResponseWriter, r *http.Request, _ models.AuthInfo) error {
+	logger := log.With().Str("method", "ResetPasswordForm").Logger()
+
+	token := chi.URLParam(r, "token")
+	if token == "" {
+		logger.Warn().Msg("missing reset token")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrBadRequest)
+	}
+
+	resetToken, err := s.db.GetValidPasswordResetToken(r.Context(), hashToken(token), s.clock.Now())
+	if err != nil {
+		logger.Error().Err(err).Msg("db get reset token failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+	if resetToken == nil {
+		logger.Warn().Msg("reset token invalid, expired or already used")
+		return httpx.WriteHTMLMessage(w, r, http.StatusBadRequest, i18n.PageInvalidResetLinkTitle, i18n.PageInvalidResetLinkMessage)
+	}
+
+	return httpx.WriteHTMLPasswordResetForm(w, r, http.StatusOK, "")
+}
+
 // ResetPassword godoc
 // @Summary      Réinitialise le mot de passe d’un utilisateur via un lien sécurisé
-// @Description  Vérifie le token JWT fourni, génère un nouveau mot de passe, l'envoie par email à l'utilisateur et met à jour le mot de passe dans la base de données
+// @Description  Consomme le token de réinitialisation (utilisable une seule fois), enregistre le mot de passe choisi par l'utilisateur et déconnecte toutes ses sessions
 // @Tags         auth
-// @Accept       json
+// @Accept       x-www-form-urlencoded
 // @Produce      html
-// @Param        token path string true "Token JWT de réinitialisation"
-// @Success      200 {string} string "HTML indiquant que le mot de passe a été envoyé (même si l'utilisateur n'existe pas pour des raisons de sécurité)"
-// @Failure      400 {object} models.Error "Requête invalide (ex: token manquant)"
-// @Failure      500 {object} models.Error "Erreur interne du serveur (génération du mot de passe, envoi de mail, ou mise à jour en base)"
-// @Router       /reset-password/{token} [get]
+// @Param        token path string true "Token de réinitialisation"
+// @Param        password formData string true "Nouveau mot de passe"
+// @Param        confirmation formData string true "Confirmation du nouveau mot de passe"
+// @Success      200 {string} string "HTML indiquant que le mot de passe a été modifié"
+// @Failure      400 {string} string "HTML indiquant un formulaire invalide ou un lien invalide, expiré ou déjà utilisé"
+// @Failure      429 {object} models.Error "Trop de tentatives depuis cette adresse IP"
+// @Failure      500 {object} models.Error "Erreur interne du serveur"
+// @Router       /reset-password/{token} [post]
 func (s *Service) ResetPassword(w http.ResponseWriter, r *http.Request, _ models.AuthInfo) error {
-	logger := log.With().Logger()
+	logger := log.With().Str("method", "ResetPassword").Logger()
 
 	token := chi.URLParam(r, "token")
 	if token == "" {
 		logger.Warn().Msg("missing reset token")
-		return httpx.WriteError(w, http.StatusBadRequest, httpx.BadRequestError)
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrBadRequest)
 	}
 
-	logger.Info().Msg("entering ResetPassword")
-
-	email, err := parseResetToken(token)
-	if err != nil {
-		logger.Warn().Err(err).Msg("reset token invalid")
-		return httpx.WriteError(w, http.StatusInternalServerError, httpx.InternalServerError)
+	if err := r.ParseForm(); err != nil {
+		logger.Warn().Err(err).Msg("invalid form body")
+		return httpx.WriteHTMLPasswordResetForm(w, r, http.StatusBadRequest, i18n.PageResetInvalidForm)
 	}
 
-	newPassword, err := passwordFromToken(token, jwtSecret)
-	if err != nil {
-		logger.Error().Err(err).Msg("password generation failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "Could not generate password")
+	password := r.PostForm.Get("password")
+	if password == "" {
+		logger.Warn().Msg("missing password")
+		return httpx.WriteHTMLPasswordResetForm(w, r, http.StatusBadRequest, i18n.PageResetEmptyPassword)
+	}
+	if password != r.PostForm.Get("confirmation") {
+		logger.Warn().Msg("password confirmation mismatch")
+		return httpx.WriteHTMLPasswordResetForm(w, r, http.StatusBadRequest, i18n.PageResetPasswordMismatch)
 	}
 
-	passwordHash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
+		if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+			logger.Warn().Err(err).Msg("password too long")
+			return httpx.WriteHTMLPasswordResetForm(w, r, http.StatusBadRequest, i18n.PageResetPasswordTooLong)
+		}
 		logger.Error().Err(err).Msg("password hashing failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, httpx.InternalServerError)
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 
-	if err := s.db.ChangePassword(r.Context(), email, string(passwordHash)); err != nil {
-		logger.Error().Err(err).Str("email", email).Msg("db change password failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, httpx.InternalServerError)
+	ok, err := s.db.ResetPasswordWithToken(r.Context(), hashToken(token), string(passwordHash), s.clock.Now())
+	if err != nil {
+		logger.Error().Err(err).Msg("db reset password failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+	if !ok {
+		logger.Warn().Msg("reset token invalid, expired or already used")
+		return httpx.WriteHTMLMessage(w, r, http.StatusBadRequest, i18n.PageInvalidResetLinkTitle, i18n.PageInvalidResetLinkMessage)
 	}
 
-	logger.Info().Str("email", email).Msg("password reset succeeded")
-	return httpx.WriteHTMLResponseForPasswordReset(
-		w,
-		http.StatusOK,
-		"Réinitialisation du mot de passe",
-		"Votre nouveau mot de passe est :",
-		newPassword,
-	)
+	logger.Info().Msg("password reset succeeded")
+	return httpx.WriteHTMLMessage(w, r, http.StatusOK, i18n.PagePasswordChangedTitle, i18n.PagePasswordChangedMessage)
 }
 
 // ChangePassword godoc
 // @Summary      Change password for authenticated param
-// @Description  Allows a connected param to change their password
+// @Description  Allows a connected param to change their password. Every other session of the user is revoked.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
@@ -382,7 +354,7 @@ func (s *Service) ChangePassword(w http.ResponseWriter, r *http.Request, ai mode
 
 	if !ai.IsConnected {
 		baseLogger.Warn().Msg("unauthorized: user not connected")
-		return httpx.WriteError(w, http.StatusUnauthorized, httpx.UnauthorizedError)
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
 	}
 
 	ctx := r.Context()
@@ -393,17 +365,16 @@ func (s *Service) ChangePassword(w http.ResponseWriter, r *http.Request, ai mode
 	user, err := s.db.GetUserById(ctx, ai.UserID)
 	if err != nil {
 		baseLogger.Error().Err(err).Str("user_id", ai.UserID).Msg("db get user by id failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, httpx.InternalServerError)
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 	if user == nil {
 		baseLogger.Warn().Str("user_id", ai.UserID).Msg("user not found")
-		return httpx.WriteError(w, http.StatusUnauthorized, httpx.UnauthorizedError)
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
 	}
 
 	var req models.ChangePasswordRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		baseLogger.Error().Err(err).Str("user_id", ai.UserID).Msg("invalid JSON body")
-		return httpx.WriteError(w, http.StatusBadRequest, httpx.BadRequestError)
+	if err := httpx.DecodeJSON(w, r, &req, s.clock.Now()); err != nil {
+		return writeError(w, r, baseLogger.With().Str("user_id", ai.UserID).Logger(), err, "invalid JSON body")
 	}
 
 	logger := baseLogger.With().
@@ -416,12 +387,17 @@ func (s *Service) ChangePassword(w http.ResponseWriter, r *http.Request, ai mode
 	passwordHash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		logger.Error().Err(err).Msg("password hashing failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, httpx.InternalServerError)
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 
 	if err := s.db.ChangePassword(ctx, user.Email, string(passwordHash)); err != nil {
 		logger.Error().Err(err).Msg("db change password failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, httpx.InternalServerError)
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+
+	if err := s.db.RevokeUserSessions(ctx, ai.UserID, ai.SessionID, s.clock.Now()); err != nil {
+		logger.Error().Err(err).Msg("db revoke other sessions failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 
 	logger.Info().Msg("password changed successfully")
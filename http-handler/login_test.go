@@ -1,6 +1,7 @@
 package main
 
 import (
+	"PLIC/i18n"
 	"PLIC/mailer"
 	"PLIC/models"
 	"bytes"
@@ -9,9 +10,10 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
-	"regexp"
+	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/golang-jwt/jwt/v5"
@@ -162,14 +164,16 @@ func TestService_Register(t *testing.T) {
 	type expected struct {
 		statusCode int
 		persist    bool
+		locale     i18n.Locale
 		body       *string
 	}
 
 	type testCase struct {
-		name     string
-		fixtures DBFixtures
-		param    models.RegisterRequest
-		expected expected
+		name           string
+		fixtures       DBFixtures
+		param          models.RegisterRequest
+		acceptLanguage string
+		expected       expected
 	}
 
 	email := "new@gmail.com"
@@ -189,6 +193,24 @@ func TestService_Register(t *testing.T) {
 			expected: expected{
 				statusCode: http.StatusCreated,
 				persist:    true,
+				locale:     i18n.FR,
+			},
+		},
+		{
+			name: "Accept-Language en -> emails in English",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{},
+			},
+			param: models.RegisterRequest{
+				Email:    "english@example.com",
+				Password: password,
+				Username: "english",
+			},
+			acceptLanguage: "en-US,en;q=0.9,fr;q=0.8",
+			expected: expected{
+				statusCode: http.StatusCreated,
+				persist:    true,
+				locale:     i18n.EN,
 			},
 		},
 		{
@@ -205,6 +227,7 @@ func TestService_Register(t *testing.T) {
 			expected: expected{
 				statusCode: http.StatusCreated,
 				persist:    true,
+				locale:     i18n.FR,
 			},
 		},
 		{
@@ -233,6 +256,21 @@ func TestService_Register(t *testing.T) {
 				statusCode: http.StatusBadRequest,
 			},
 		},
+		{
+			name: "Invalid email and blank username => 400 with the invalid fields",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{},
+			},
+			param: models.RegisterRequest{
+				Email:    "not-an-email",
+				Password: password,
+				Username: "  ",
+			},
+			expected: expected{
+				statusCode: http.StatusBadRequest,
+				body:       ptr(`{"type":"urn:playthestreet:error:validation_failed","title":"Bad Request","status":400,"detail":"Certains champs de la requête sont invalides.","instance":"/register","code":"validation_failed","details":{"email":{"code":"validation.email","message":"Adresse e-mail invalide."},"username":{"code":"validation.not_blank","message":"Ce champ ne peut pas être vide."}}}`),
+			},
+		},
 		{
 			name: "Email already exists => 409 email_taken",
 			fixtures: DBFixtures{
@@ -250,7 +288,7 @@ func TestService_Register(t *testing.T) {
 			},
 			expected: expected{
 				statusCode: http.StatusConflict,
-				body:       ptr(`{"message":"email_taken"}`),
+				body:       ptr(`{"type":"urn:playthestreet:error:email_taken","title":"Conflict","status":409,"detail":"Cette adresse e-mail est déjà utilisée.","instance":"/register","code":"email_taken"}`),
 			},
 		},
 		{
@@ -268,9 +306,10 @@ func TestService_Register(t *testing.T) {
 				Password: "pwd",
 				Username: "takenName",
 			},
+			acceptLanguage: "en",
 			expected: expected{
 				statusCode: http.StatusConflict,
-				body:       ptr(`{"message":"username_taken"}`),
+				body:       ptr(`{"type":"urn:playthestreet:error:username_taken","title":"Conflict","status":409,"detail":"This username is already taken.","instance":"/register","code":"username_taken"}`),
 			},
 		},
 	}
@@ -293,6 +332,7 @@ func TestService_Register(t *testing.T) {
 
 			req := httptest.NewRequest("POST", "/register", bytes.NewBuffer(body))
 			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept-Language", c.acceptLanguage)
 			w := httptest.NewRecorder()
 
 			err := s.Register(w, req, models.AuthInfo{})
@@ -341,6 +381,8 @@ func TestService_Register(t *testing.T) {
 
 				require.Equal(t, c.param.Username, u.Username)
 				require.Equal(t, c.param.Bio, u.Bio)
+				require.Equal(t, c.expected.locale, u.Locale)
+				require.Equal(t, c.expected.locale, s.mailer.(*mailer.MockMailer).LastLocale)
 
 				require.NotEmpty(t, u.Password)
 				require.NotEqual(t, c.param.Password, u.Password)
@@ -353,6 +395,9 @@ func TestService_Register(t *testing.T) {
 
 				mock := s.mailer.(*mailer.MockMailer)
 				require.Equal(t, 1, mock.GetSentCounts("welcome"), "welcome email should be sent exactly once")
+				require.Equal(t, 1, mock.GetSentCounts("verify_email"), "verification email should be sent exactly once")
+				require.Equal(t, c.param.Email, mock.LastVerificationTo)
+				require.Nil(t, u.EmailVerifiedAt, "email should not be verified before the link is opened")
 			} else {
 				mock := s.mailer.(*mailer.MockMailer)
 				require.Equal(t, 0, mock.GetSentCounts("welcome"), "welcome email should not be sent on failure")
@@ -465,56 +510,200 @@ func TestService_ForgetPassword(t *testing.T) {
 	}
 }
 
+func TestService_ResetPasswordForm(t *testing.T) {
+	type testCase struct {
+		name         string
+		fixtures     DBFixtures
+		token        string
+		expectedCode int
+	}
+
+	userId := uuid.NewString()
+	token := "reset-token"
+
+	testCases := []testCase{
+		{
+			name: "Valid token -> form displayed",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{models.NewDBUsersFixture().WithId(userId)},
+				PasswordResetTokens: []models.DBPasswordResetToken{
+					models.NewDBPasswordResetTokenFixture().
+						WithUserId(userId).
+						WithTokenHash(hashToken(token)),
+				},
+			},
+			token:        token,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "Unknown token -> 400",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{models.NewDBUsersFixture().WithId(userId)},
+			},
+			token:        token,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Expired token -> 400",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{models.NewDBUsersFixture().WithId(userId)},
+				PasswordResetTokens: []models.DBPasswordResetToken{
+					models.NewDBPasswordResetTokenFixture().
+						WithUserId(userId).
+						WithTokenHash(hashToken(token)).
+						WithExpiresAt(time.Now().Add(-time.Minute)),
+				},
+			},
+			token:        token,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Missing token -> 400",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{models.NewDBUsersFixture().WithId(userId)},
+			},
+			token:        "",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() {
+				if err := cleanup(); err != nil {
+					t.Logf("cleanup error: %v", err)
+				}
+			}()
+			s.loadFixtures(c.fixtures)
+
+			req := httptest.NewRequest("GET", "/reset-password/"+c.token, nil)
+			routeCtx := chi.NewRouteContext()
+			routeCtx.URLParams.Add("token", c.token)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+			w := httptest.NewRecorder()
+
+			err := s.ResetPasswordForm(w, req, models.AuthInfo{})
+			require.NoError(t, err)
+
+			resp := w.Result()
+			defer func(Body io.ReadCloser) {
+				_ = Body.Close()
+			}(resp.Body)
+			require.Equal(t, c.expectedCode, resp.StatusCode)
+			if c.expectedCode != http.StatusOK {
+				return
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.Contains(t, string(body), `name="password"`)
+		})
+	}
+}
+
 func TestService_ResetPassword(t *testing.T) {
 	type expected struct {
-		statusCode int
+		statusCode      int
+		passwordChanged bool
 	}
 
 	type testCase struct {
 		name     string
-		param    models.DBUsers
+		fixtures DBFixtures
+		token    string
+		form     url.Values
 		expected expected
 	}
 
-	userEmail := "reset@example.com"
 	userId := uuid.NewString()
+	sessionId := uuid.NewString()
+	token := "reset-token"
+	newPassword := "myNewPassword"
 
-	testCases := []testCase{
-		{
-			name: "Valid token, password updated, mail sent",
-			param: models.NewDBUsersFixture().
+	validFixtures := DBFixtures{
+		Users: []models.DBUsers{
+			models.NewDBUsersFixture().
 				WithId(userId).
-				WithEmail(userEmail).
 				WithPassword("old-password-hash"),
+		},
+		Sessions: []models.DBSession{
+			models.NewDBSessionFixture().WithId(sessionId).WithUserId(userId),
+		},
+		PasswordResetTokens: []models.DBPasswordResetToken{
+			models.NewDBPasswordResetTokenFixture().
+				WithUserId(userId).
+				WithTokenHash(hashToken(token)),
+		},
+	}
+
+	testCases := []testCase{
+		{
+			name:     "Valid token -> password updated and sessions revoked",
+			fixtures: validFixtures,
+			token:    token,
+			form:     url.Values{"password": {newPassword}, "confirmation": {newPassword}},
 			expected: expected{
-				statusCode: http.StatusOK,
+				statusCode:      http.StatusOK,
+				passwordChanged: true,
 			},
 		},
 		{
-			name: "Missing token → 400",
-			param: models.NewDBUsersFixture().
-				WithId(userId).
-				WithEmail(userEmail),
+			name:     "Confirmation mismatch -> 400",
+			fixtures: validFixtures,
+			token:    token,
+			form:     url.Values{"password": {newPassword}, "confirmation": {"anotherPassword"}},
 			expected: expected{
 				statusCode: http.StatusBadRequest,
 			},
 		},
 		{
-			name: "Invalid token → 500",
-			param: models.NewDBUsersFixture().
-				WithId(userId).
-				WithEmail(userEmail),
+			name:     "Empty password -> 400",
+			fixtures: validFixtures,
+			token:    token,
+			form:     url.Values{"password": {""}, "confirmation": {""}},
+			expected: expected{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "Already used token -> 400",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{
+					models.NewDBUsersFixture().
+						WithId(userId).
+						WithPassword("old-password-hash"),
+				},
+				PasswordResetTokens: []models.DBPasswordResetToken{
+					models.NewDBPasswordResetTokenFixture().
+						WithUserId(userId).
+						WithTokenHash(hashToken(token)).
+						WithUsedAt(time.Now().Add(-time.Minute)),
+				},
+			},
+			token: token,
+			form:  url.Values{"password": {newPassword}, "confirmation": {newPassword}},
 			expected: expected{
-				statusCode: http.StatusInternalServerError,
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:     "Unknown token -> 400",
+			fixtures: validFixtures,
+			token:    "another-token",
+			form:     url.Values{"password": {newPassword}, "confirmation": {newPassword}},
+			expected: expected{
+				statusCode: http.StatusBadRequest,
 			},
 		},
 	}
 
 	for _, c := range testCases {
-		c := c
 		t.Run(c.name, func(t *testing.T) {
 			t.Parallel()
-
+			ctx := context.Background()
 			s := &Service{}
 			cleanup := s.InitServiceTest()
 			defer func() {
@@ -522,65 +711,52 @@ func TestService_ResetPassword(t *testing.T) {
 					t.Logf("cleanup error: %v", err)
 				}
 			}()
+			s.loadFixtures(c.fixtures)
 
-			mockMailer := mailer.NewMockMailer()
-			s.mailer = mockMailer
-
-			s.loadFixtures(DBFixtures{
-				Users: []models.DBUsers{c.param},
-			})
-
-			var token string
-			var err error
-			switch c.name {
-			case "Valid token, password updated, mail sent":
-				token, err = generateResetToken(userEmail)
-				require.NoError(t, err)
-			case "Invalid token → 500":
-				token = "invalid.token.value"
-			case "Missing token → 400":
-				token = ""
-			}
-
-			req := httptest.NewRequest("GET", "/reset-password/"+token, nil)
+			req := httptest.NewRequest("POST", "/reset-password/"+c.token, strings.NewReader(c.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 			routeCtx := chi.NewRouteContext()
-			routeCtx.URLParams.Add("token", token)
+			routeCtx.URLParams.Add("token", c.token)
 			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
 			w := httptest.NewRecorder()
 
-			err = s.ResetPassword(w, req, models.AuthInfo{})
+			err := s.ResetPassword(w, req, models.AuthInfo{})
 			require.NoError(t, err)
 
 			resp := w.Result()
 			defer func(Body io.ReadCloser) {
 				_ = Body.Close()
 			}(resp.Body)
-
 			require.Equal(t, c.expected.statusCode, resp.StatusCode)
 
-			if c.expected.statusCode != http.StatusOK {
+			user, err := s.db.GetUserById(ctx, userId)
+			require.NoError(t, err)
+			if !c.expected.passwordChanged {
+				require.Equal(t, "old-password-hash", user.Password)
 				return
 			}
+			require.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(newPassword)))
 
-			updatedUser, err := s.db.GetUserByEmail(req.Context(), userEmail)
+			active, err := s.db.IsSessionActive(ctx, sessionId, userId, time.Now())
 			require.NoError(t, err)
-			require.NotEqual(t, c.param.Password, updatedUser.Password, "le mot de passe doit être modifié")
+			require.False(t, active, "sessions opened before the reset must be revoked")
+
+			// The link can only be used once.
+			req = httptest.NewRequest("POST", "/reset-password/"+c.token, strings.NewReader(c.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+			w = httptest.NewRecorder()
+			require.NoError(t, s.ResetPassword(w, req, models.AuthInfo{}))
+			require.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
 		})
 	}
 }
 
-func extractPasswordFromHTML(t *testing.T, body string) string {
-	t.Helper()
-
-	re := regexp.MustCompile(`<div id="password" class="password-box">([^<]+)</div>`)
-	matches := re.FindStringSubmatch(body)
-	require.Len(t, matches, 2, "impossible de trouver le mot de passe dans le HTML")
-	return matches[1]
-}
-
-func TestService_ResetPassword_SamePasswordForSameToken(t *testing.T) {
-	userEmail := "reset-idempotent@example.com"
+func TestService_ForgetPasswordThenResetPassword(t *testing.T) {
+	ctx := context.Background()
+	userEmail := "reset@example.com"
 	userId := uuid.NewString()
+	newPassword := "myNewPassword"
 
 	s := &Service{}
 	cleanup := s.InitServiceTest()
@@ -589,7 +765,6 @@ func TestService_ResetPassword_SamePasswordForSameToken(t *testing.T) {
 			t.Logf("cleanup error: %v", err)
 		}
 	}()
-
 	mockMailer := mailer.NewMockMailer()
 	s.mailer = mockMailer
 
@@ -597,53 +772,43 @@ func TestService_ResetPassword_SamePasswordForSameToken(t *testing.T) {
 		Users: []models.DBUsers{
 			models.NewDBUsersFixture().
 				WithId(userId).
-				WithEmail(userEmail).
-				WithPassword("old-password-hash"),
+				WithEmail(userEmail),
 		},
 	})
 
-	token, err := generateResetToken(userEmail)
-	require.NoError(t, err)
+	requestReset := func() string {
+		body, _ := json.Marshal(models.MailerRequest{Email: userEmail})
+		req := httptest.NewRequest("POST", "/forgot-password", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		require.NoError(t, s.ForgetPassword(w, req, models.AuthInfo{}))
+		require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+		prefix := s.configuration.App.BaseURL + "/reset-password/"
+		require.True(t, strings.HasPrefix(mockMailer.LastResetLink, prefix), "reset link should use the configured base URL")
+		return strings.TrimPrefix(mockMailer.LastResetLink, prefix)
+	}
 
-	var firstPassword string
+	firstToken := requestReset()
+	secondToken := requestReset()
 
-	for i := 0; i < 2; i++ {
-		req := httptest.NewRequest("GET", "/reset-password/"+token, nil)
+	reset := func(token string) int {
+		form := url.Values{"password": {newPassword}, "confirmation": {newPassword}}
+		req := httptest.NewRequest("POST", "/reset-password/"+token, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 		routeCtx := chi.NewRouteContext()
 		routeCtx.URLParams.Add("token", token)
 		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
 		w := httptest.NewRecorder()
+		require.NoError(t, s.ResetPassword(w, req, models.AuthInfo{}))
+		return w.Result().StatusCode
+	}
 
-		err = s.ResetPassword(w, req, models.AuthInfo{})
-		require.NoError(t, err)
-
-		resp := w.Result()
-		bodyBytes, err := io.ReadAll(resp.Body)
-		_ = resp.Body.Close()
-		require.NoError(t, err)
-
-		require.Equal(t, http.StatusOK, resp.StatusCode)
-
-		body := string(bodyBytes)
-		pw := extractPasswordFromHTML(t, body)
-
-		require.Len(t, pw, 12, "le mot de passe doit faire 12 caractères")
-		allowed := "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789!@#$"
-		for _, r := range pw {
-			require.Containsf(t, allowed, string(r), "caractère interdit dans le mot de passe: %q", r)
-		}
-
-		updatedUser, err := s.db.GetUserByEmail(req.Context(), userEmail)
-		require.NoError(t, err)
-		err = bcrypt.CompareHashAndPassword([]byte(updatedUser.Password), []byte(pw))
-		require.NoError(t, err, "le hash en base doit correspondre au mot de passe affiché")
+	require.Equal(t, http.StatusBadRequest, reset(firstToken), "a new request invalidates the previous link")
+	require.Equal(t, http.StatusOK, reset(secondToken))
 
-		if i == 0 {
-			firstPassword = pw
-		} else {
-			require.Equal(t, firstPassword, pw, "pour un même token, le mot de passe doit rester identique")
-		}
-	}
+	user, err := s.db.GetUserById(ctx, userId)
+	require.NoError(t, err)
+	require.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(newPassword)))
 }
 
 func TestService_ChangePassword(t *testing.T) {
@@ -767,3 +932,29 @@ func TestService_ChangePassword(t *testing.T) {
 		})
 	}
 }
+
+func Test_checkBaseURL(t *testing.T) {
+	testCases := []struct {
+		name    string
+		raw     string
+		wantErr bool
+	}{
+		{name: "Https URL", raw: "https://api.playthestreet.com"},
+		{name: "Http URL with a path", raw: "http://localhost:8080/api/"},
+		{name: "Empty", raw: "", wantErr: true},
+		{name: "Relative path", raw: "/api", wantErr: true},
+		{name: "Missing scheme", raw: "api.playthestreet.com", wantErr: true},
+		{name: "Other scheme", raw: "ftp://api.playthestreet.com", wantErr: true},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			err := checkBaseURL(c.raw)
+			if c.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
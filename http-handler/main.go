@@ -1,12 +1,18 @@
 package main
 
 import (
+	"PLIC/clock"
 	"PLIC/database"
+	"PLIC/events"
 	"PLIC/mailer"
 	"PLIC/models"
+	"PLIC/notifier"
+	"PLIC/rating"
 	"PLIC/s3_management"
 	"context"
+	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"time"
 
@@ -33,14 +39,14 @@ import (
 
 const Port string = "8080"
 
-const DefaultElo = 1000
-const KFactor = 32
-
 type Service struct {
 	db            database.Database
 	server        *chi.Mux
-	clock         Clock
+	clock         clock.Clock
 	mailer        mailer.MailSender
+	ratings       rating.Engines
+	events        events.Bus
+	notifier      *notifier.Notifier
 	s3Service     s3_management.S3Service
 	configuration *models.Configuration
 	isLambda      bool
@@ -55,9 +61,22 @@ func loadConfig() (*models.Configuration, error) {
 	if err := env.Parse(&cfg); err != nil {
 		return nil, err
 	}
+	if err := checkBaseURL(cfg.App.BaseURL); err != nil {
+		return nil, err
+	}
 	return &cfg, nil
 }
 
+// checkBaseURL makes sure the links sent by email are absolute: a relative link
+// cannot be opened from a mailbox.
+func checkBaseURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("APP_BASE_URL must be an absolute http(s) URL, got %q", raw)
+	}
+	return nil
+}
+
 func (s *Service) initService() {
 	_ = godotenv.Load()
 
@@ -130,12 +149,19 @@ func (s *Service) initService() {
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to load Paris timezone")
 	}
-	s.clock = Clock{location: parisLocation}
+	s.clock = clock.New(parisLocation)
+
+	s.ratings, err = rating.NewEngines(appConfig.Rating)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid rating configuration")
+	}
+
+	s.events = events.NewMemoryBus()
+	s.notifier = notifier.New(appConfig.Notifier, s.db)
 
-	s.mailer = &mailer.Mailer{
-		LastSentAt:  make(map[string]time.Time),
-		AlreadySent: make(map[string]bool),
-		Config:      &appConfig.Mailer,
+	s.mailer, err = mailer.New(&appConfig.Mailer, s.db, s.clock)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid mailer configuration")
 	}
 
 	cfg, err := config.LoadDefaultConfig(context.TODO())
@@ -225,44 +251,81 @@ func main() {
 	s.POST("/register", s.Register)
 	s.POST("/login", s.Login)
 	s.POST("/forgot-password", s.ForgetPassword)
-	s.GET("/reset-password/{token}", s.ResetPassword)
-	s.POST("/change-password", withAuthentication(s.ChangePassword))
-
-	s.GET("/", withAuthentication(s.GetTime))
+	s.GET("/reset-password/{token}", s.ResetPasswordForm)
+	s.POST("/reset-password/{token}", withRateLimit(s.ResetPassword))
+	s.POST("/change-password", s.withAuthentication(s.ChangePassword))
+	s.POST("/token/refresh", withRateLimit(s.RefreshToken))
+	s.POST("/logout", s.withAuthentication(s.Logout))
+	s.POST("/logout/all", s.withAuthentication(s.LogoutAll))
+	s.GET("/verify-email/{token}", s.VerifyEmail)
+	s.POST("/verify-email/resend", s.withAuthentication(s.ResendVerificationEmail))
+
+	s.GET("/", s.withAuthentication(s.GetTime))
 	s.GET("/hello_world", s.GetHelloWorld)
 
-	s.POST("/profile_picture", withAuthentication(s.UploadProfilePictureToS3))
+	s.POST("/profile_picture", s.withAuthentication(s.UploadProfilePictureToS3))
 
 	s.POST("/place", s.HandleSyncGooglePlaces)
 
-	s.GET("/court/all", withAuthentication(s.GetAllCourts))
-	s.GET("/court/{id}", withAuthentication(s.GetCourtByID))
-
-	s.GET("/match/all", withAuthentication(s.GetAllMatches))
-	s.GET("/match/{id}", withAuthentication(s.GetMatchByID))
-	s.GET("/user/matches", withAuthentication(s.GetMatchesByUserID))
-	s.GET("/matches/court/{courtId}", withAuthentication(s.GetMatchesByCourtId))
-	s.GET("/match/{id}/vote-status", withAuthentication(s.GetMatchVoteStatus))
-	s.GET("/match/{id}/teams", withAuthentication(s.GetTeamsByMatchId))
-	s.POST("/match", withAuthentication(s.CreateMatch))
-	s.POST("/join/match/{id}", withAuthentication(s.JoinMatch))
-	s.PATCH("/score/match/{id}", withAuthentication(s.UpdateMatchScore))
-	s.DELETE("/match/{id}", withAuthentication(s.DeleteMatch))
-	s.PATCH("/match/{id}/start", withAuthentication(s.StartMatch))
-	s.PATCH("/match/{id}/finish", withAuthentication(s.FinishMatch))
-
-	s.GET("/users/{id}", withAuthentication(s.GetUserById))
-	s.PATCH("/users/{id}", withAuthentication(s.PatchUser))
-	s.DELETE("/users/{id}", withAuthentication(s.DeleteUser))
-
-	s.GET("/ranking/court/{id}/sport/{sport}", withAuthentication(s.GetRankingByCourtId))
-	s.GET("/ranking/user/{userId}", withAuthentication(s.GetRankedFieldsByUserID))
+	s.GET("/court/all", s.withAuthentication(s.GetAllCourts))
+	s.GET("/court/nearby", s.withAuthentication(s.GetNearbyCourts))
+	s.GET("/court/{id}", s.withAuthentication(s.GetCourtByID))
+
+	s.GET("/match/all", s.withAuthentication(s.GetAllMatches))
+	s.GET("/match/{id}", s.withAuthentication(s.GetMatchByID))
+	s.GET("/user/matches", s.withAuthentication(s.GetMatchesByUserID))
+	s.GET("/matches/court/{courtId}", s.withAuthentication(s.GetMatchesByCourtId))
+	s.GET("/matches/search", s.withAuthentication(s.SearchMatches))
+	s.GET("/match/{id}/vote-status", s.withAuthentication(s.GetMatchVoteStatus))
+	s.GET("/match/{id}/teams", s.withAuthentication(s.GetTeamsByMatchId))
+	s.POST("/match", s.withAuthentication(s.CreateMatch))
+	s.POST("/join/match/{id}", s.withAuthentication(s.JoinMatch))
+	s.POST("/match/{id}/leave", s.withAuthentication(s.LeaveMatch))
+	s.DELETE("/match/{id}/players/{userId}", s.withAuthentication(s.KickMatchPlayer))
+	s.PATCH("/score/match/{id}", s.withAuthentication(s.UpdateMatchScore))
+	s.DELETE("/match/{id}", s.withAuthentication(s.DeleteMatch))
+	s.PATCH("/match/{id}/start", s.withAuthentication(s.StartMatch))
+	s.PATCH("/match/{id}/finish", s.withAuthentication(s.FinishMatch))
+	s.POST("/match/{id}/invite", s.withAuthentication(s.InviteToMatch))
+	s.POST("/match/{id}/rebalance", s.withAuthentication(s.RebalanceMatch))
+	if !s.isLambda {
+		// API Gateway buffers Lambda responses, streams only work on the local server
+		s.GET("/match/{id}/events", s.withAuthentication(s.MatchEvents))
+	}
+
+	s.GET("/friends", s.withAuthentication(s.GetFriends))
+	s.GET("/friends/requests", s.withAuthentication(s.GetFriendRequests))
+	s.POST("/friends/{userId}/request", s.withAuthentication(s.RequestFriend))
+	s.POST("/friends/{userId}/accept", s.withAuthentication(s.AcceptFriendRequest))
+	s.POST("/friends/{userId}/decline", s.withAuthentication(s.DeclineFriendRequest))
+	s.POST("/friends/{userId}/block", s.withAuthentication(s.BlockUser))
+
+	s.GET("/matchmaking/queue", s.withAuthentication(s.GetMatchmakingQueue))
+	s.POST("/matchmaking/queue", s.withAuthentication(s.JoinMatchmakingQueue))
+	s.DELETE("/matchmaking/queue/{id}", s.withAuthentication(s.LeaveMatchmakingQueue))
+
+	s.POST("/devices", s.withAuthentication(s.RegisterDevice))
+	s.DELETE("/devices/{token}", s.withAuthentication(s.DeleteDevice))
+	s.GET("/notifications/preferences", s.withAuthentication(s.GetNotificationPreferences))
+	s.PATCH("/notifications/preferences", s.withAuthentication(s.PatchNotificationPreferences))
+
+	s.GET("/users/{id}", s.withAuthentication(s.GetUserById))
+	s.PATCH("/users/{id}", s.withAuthentication(s.PatchUser))
+	s.DELETE("/users/{id}", s.withAuthentication(s.DeleteUser))
+
+	s.GET("/ranking/court/{id}/sport/{sport}", s.withAuthentication(s.GetRankingByCourtId))
+	s.GET("/ranking/user/{userId}", s.withAuthentication(s.GetRankedFieldsByUserID))
+	s.GET("/ranking/user/{userId}/history", s.withAuthentication(s.GetUserRankingHistory))
+	s.GET("/ranking/global/{sport}", s.withAuthentication(s.GetGlobalLeaderboard))
+	s.GET("/ranking/city/{city}/{sport}", s.withAuthentication(s.GetCityLeaderboard))
 
 	if s.isLambda {
 		log.Info().Msg("🚀 Running in AWS Lambda mode...")
 		s.Start()
 	} else {
 
+		go s.runOutboxWorker(context.Background(), s.configuration.Outbox.PollInterval)
+
 		log.Info().Str("port", Port).Msg("🌍 Running locally...")
 		if err := http.ListenAndServe(":"+Port, s.server); err != nil {
 			log.Fatal().Err(err).Msg("server failed")
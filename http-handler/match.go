@@ -1,18 +1,22 @@
 package main
 
 import (
+	"PLIC/domain"
 	"PLIC/httpx"
+	"PLIC/i18n"
 	"PLIC/models"
+	"PLIC/rating"
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"math"
 	"net/http"
-	"sync"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
 )
 
 func (s *Service) buildMatchesResponse(ctx context.Context, matches []models.DBMatches) []models.MatchResponse {
@@ -75,31 +79,12 @@ func (s *Service) buildMatchesResponse(ctx context.Context, matches []models.DBM
 		logger.Error().Err(err).Msg("prefetching user stats failed")
 	}
 
-	profilePics := make(map[string]string, len(userIDs))
-	var mu sync.Mutex
-
-	var wg sync.WaitGroup
-	sem := make(chan struct{}, 10)
-
-	for _, uid := range userIDs {
-		wg.Add(1)
-		go func(userID string) {
-			defer wg.Done()
-			sem <- struct{}{}
-			defer func() { <-sem }()
-
-			pic, err := s.s3Service.GetProfilePicture(ctx, userID)
-			if err != nil {
-				logger.Warn().Err(err).Str("user_id", userID).Msg("failed to get profile picture")
-				return
-			}
-
-			mu.Lock()
-			profilePics[userID] = pic.URL
-			mu.Unlock()
-		}(uid)
+	historyByMatch, err := s.db.GetRankingHistoryByMatchIDs(ctx, matchIDs)
+	if err != nil {
+		logger.Error().Err(err).Msg("prefetching ranking history failed")
 	}
-	wg.Wait()
+
+	profilePics := s.prefetchProfilePictures(ctx, logger, userIDs)
 
 	responses := make([]models.MatchResponse, 0, len(matches))
 	for _, match := range matches {
@@ -131,7 +116,17 @@ func (s *Service) buildMatchesResponse(ctx context.Context, matches []models.DBM
 			Score1:          match.Score1,
 			Score2:          match.Score2,
 			Users:           userResponses,
-			CreatedAt:       match.CreatedAt,
+			Visibility:      match.Visibility,
+			AutoBalance:     match.AutoBalance,
+			EloChanges: lo.Map(historyByMatch[match.Id], func(h models.DBRankingHistory, _ int) models.MatchEloChange {
+				return models.MatchEloChange{
+					UserID:    h.UserID,
+					EloBefore: h.EloBefore,
+					EloAfter:  h.EloAfter,
+					Delta:     h.Delta,
+				}
+			}),
+			CreatedAt: match.CreatedAt,
 		})
 	}
 
@@ -141,13 +136,14 @@ func (s *Service) buildMatchesResponse(ctx context.Context, matches []models.DBM
 
 // GetMatchByID godoc
 // @Summary      Récupère un match par son ID
-// @Description  Retourne les informations d’un match en fonction de son identifiant passé en paramètre de requête
+// @Description  Retourne les informations d’un match en fonction de son identifiant passé en paramètre de requête, s'il est visible par l'utilisateur
 // @Tags         match
 // @Produce      json
 // @Param        id   path      string  true  "Identifiant du match"
 // @Success      200  {object}  models.MatchResponse "Match trouvé"
 // @Failure      400  {object}  models.Error         "ID manquant ou invalide"
 // @Failure      401   {object}  models.Error       "Utilisateur non autorisé"
+// @Failure      403  {object}  models.Error         "Match réservé aux amis ou sur invitation"
 // @Failure      404  {object}  models.Error         "Match non trouvé"
 // @Failure      500  {object}  models.Error         "Erreur serveur ou base de données"
 // @Router       /match/{id} [get]
@@ -159,7 +155,7 @@ func (s *Service) GetMatchByID(w http.ResponseWriter, r *http.Request, ai models
 
 	if !ai.IsConnected {
 		baseLogger.Warn().Msg("unauthorized")
-		return httpx.WriteError(w, http.StatusUnauthorized, "not authorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
 	}
 
 	id := chi.URLParam(r, "id")
@@ -167,24 +163,34 @@ func (s *Service) GetMatchByID(w http.ResponseWriter, r *http.Request, ai models
 
 	if id == "" {
 		logger.Warn().Msg("missing id in url params")
-		return httpx.WriteError(w, http.StatusBadRequest, "missing id in url params")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMissingParameter, "id")
 	}
 
 	ctx := r.Context()
 	match, err := s.db.GetMatchById(ctx, id)
 	if err != nil {
 		logger.Error().Err(err).Msg("db error fetching match")
-		return httpx.WriteError(w, http.StatusInternalServerError, "database error")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 	if match == nil {
 		logger.Warn().Msg("match not found")
-		return httpx.WriteError(w, http.StatusNotFound, "match not found")
+		return httpx.WriteError(w, r, http.StatusNotFound, i18n.ErrMatchNotFound)
+	}
+
+	visible, err := s.db.IsMatchVisibleTo(ctx, id, ai.UserID)
+	if err != nil {
+		logger.Error().Err(err).Msg("db check match visibility failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+	if !visible {
+		logger.Warn().Msg("match not visible")
+		return httpx.WriteError(w, r, http.StatusForbidden, i18n.ErrMatchNotVisible)
 	}
 
 	responses := s.buildMatchesResponse(ctx, []models.DBMatches{*match})
 	if len(responses) == 0 {
 		logger.Error().Msg("failed to build match response")
-		return httpx.WriteError(w, http.StatusInternalServerError, "failed to build match response")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 
 	logger.Info().Msg("match fetched successfully")
@@ -211,12 +217,12 @@ func (s *Service) GetMatchesByUserID(w http.ResponseWriter, r *http.Request, ai
 
 	if !ai.IsConnected {
 		logger.Warn().Msg("unauthorized")
-		return httpx.WriteError(w, http.StatusUnauthorized, "not authorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
 	}
 
 	if ai.UserID == "" {
 		logger.Warn().Msg("missing userId token")
-		return httpx.WriteError(w, http.StatusBadRequest, "missing userId in token")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrUnauthorized)
 	}
 
 	ctx := r.Context()
@@ -224,7 +230,7 @@ func (s *Service) GetMatchesByUserID(w http.ResponseWriter, r *http.Request, ai
 	dbMatches, err := s.db.GetMatchesByUserID(ctx, ai.UserID)
 	if err != nil {
 		logger.Error().Err(err).Msg("db get matches by user failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "database error")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 
 	res := s.buildMatchesResponse(ctx, dbMatches)
@@ -234,7 +240,7 @@ func (s *Service) GetMatchesByUserID(w http.ResponseWriter, r *http.Request, ai
 
 // GetMatchesByCourtId godoc
 // @Summary      Liste des matchs pour un court
-// @Description  Retourne les matchs associés à un terrain (court) via son ID
+// @Description  Retourne les matchs associés à un terrain (court) via son ID et visibles par l'utilisateur
 // @Tags         match
 // @Produce      json
 // @Param        courtId   path      string  true  "Identifiant du terrain"
@@ -252,7 +258,7 @@ func (s *Service) GetMatchesByCourtId(w http.ResponseWriter, r *http.Request, ai
 
 	if !ai.IsConnected {
 		baseLogger.Warn().Msg("unauthorized")
-		return httpx.WriteError(w, http.StatusUnauthorized, "not authorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
 	}
 
 	courtID := chi.URLParam(r, "courtId")
@@ -260,15 +266,15 @@ func (s *Service) GetMatchesByCourtId(w http.ResponseWriter, r *http.Request, ai
 
 	if courtID == "" {
 		logger.Warn().Msg("missing courtId in url params")
-		return httpx.WriteError(w, http.StatusBadRequest, "missing courtId in url params")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMissingParameter, "courtId")
 	}
 
 	ctx := r.Context()
 
-	matches, err := s.db.GetMatchesByCourtId(ctx, courtID)
+	matches, err := s.db.GetMatchesByCourtId(ctx, courtID, ai.UserID)
 	if err != nil {
 		logger.Error().Err(err).Msg("db get matches by court failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "database error")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 
 	res := s.buildMatchesResponse(ctx, matches)
@@ -277,8 +283,8 @@ func (s *Service) GetMatchesByCourtId(w http.ResponseWriter, r *http.Request, ai
 }
 
 // GetAllMatches godoc
-// @Summary      Liste tous les matchs
-// @Description  Retourne la liste complète de tous les matchs stockés en base
+// @Summary      Liste tous les matchs visibles
+// @Description  Retourne les matchs visibles par l'utilisateur : matchs publics, matchs qu'il a créés, rejoints ou auxquels il est invité, et matchs réservés aux amis de leur créateur
 // @Tags         match
 // @Produce      json
 // @Success      200  {array}   models.MatchResponse "Liste des matchs"
@@ -293,15 +299,15 @@ func (s *Service) GetAllMatches(w http.ResponseWriter, r *http.Request, ai model
 
 	if !ai.IsConnected {
 		baseLogger.Warn().Msg("unauthorized")
-		return httpx.WriteError(w, http.StatusUnauthorized, "not authorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
 	}
 
 	ctx := r.Context()
 
-	matches, err := s.db.GetAllMatches(ctx)
+	matches, err := s.db.GetAllMatches(ctx, ai.UserID)
 	if err != nil {
 		baseLogger.Error().Err(err).Msg("db get all matches failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "failed to fetch matches")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 
 	res := s.buildMatchesResponse(ctx, matches)
@@ -309,6 +315,202 @@ func (s *Service) GetAllMatches(w http.ResponseWriter, r *http.Request, ai model
 	return httpx.Write(w, http.StatusOK, res)
 }
 
+const (
+	defaultMatchSearchLimit = 20
+	maxMatchSearchLimit     = 100
+)
+
+// SearchMatches godoc
+// @Summary      Recherche de matchs
+// @Description  Fil de découverte des matchs visibles par l'utilisateur, paginé par curseur. Par défaut, seuls les matchs en attente de joueurs sont retournés, du plus proche dans le temps au plus lointain. Le tri par distance nécessite lat et lng.
+// @Tags         match
+// @Produce      json
+// @Param        sport           query     string  false  "Sport (basket, foot, ping-pong)"
+// @Param        state           query     string  false  "États séparés par des virgules (défaut : Manque joueur)"
+// @Param        from            query     string  false  "Date de début incluse (RFC 3339)"
+// @Param        to              query     string  false  "Date de fin exclue (RFC 3339)"
+// @Param        lat             query     number  false  "Latitude du point de recherche, avec lng"
+// @Param        lng             query     number  false  "Longitude du point de recherche, avec lat"
+// @Param        radius_m        query     number  false  "Rayon de recherche en mètres (défaut 5000, max 50000)"
+// @Param        court           query     string  false  "Identifiant du terrain"
+// @Param        players_needed  query     int     false  "Nombre minimum de places libres"
+// @Param        elo_range       query     int     false  "Écart maximum entre l'ELO moyen des inscrits et celui de l'utilisateur sur le terrain"
+// @Param        sort            query     string  false  "date (défaut) ou distance"
+// @Param        cursor          query     string  false  "Curseur next_cursor de la page précédente"
+// @Param        limit           query     int     false  "Nombre de matchs par page (défaut 20, max 100)"
+// @Success      200  {object}  models.MatchSearchResponse
+// @Failure      400  {object}  models.Error  "Paramètres invalides"
+// @Failure      401  {object}  models.Error  "Utilisateur non autorisé"
+// @Failure      500  {object}  models.Error  "Erreur lors de la recherche"
+// @Router       /matches/search [get]
+// @Security     BearerAuth
+func (s *Service) SearchMatches(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+	logger := log.With().
+		Str("method", "SearchMatches").
+		Str("user_id", ai.UserID).
+		Logger()
+
+	if !ai.IsConnected {
+		logger.Warn().Msg("unauthorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+
+	q, err := parseMatchSearchQuery(r.URL.Query())
+	if err != nil {
+		return writeError(w, r, logger.With().Str("query", r.URL.RawQuery).Logger(), err, "invalid search parameters")
+	}
+	q.ViewerID = ai.UserID
+	q.DefaultElo = rating.DefaultElo
+
+	results, next, err := s.db.SearchMatches(r.Context(), q)
+	if err != nil {
+		logger.Error().Err(err).Msg("db search matches failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+
+	res := models.MatchSearchResponse{
+		Matches: make([]models.MatchSummaryResponse, 0, len(results)),
+	}
+	for _, m := range results {
+		res.Matches = append(res.Matches, models.MatchSummaryResponse{
+			Id:              m.Id,
+			CreatorId:       m.CreatorID,
+			Sport:           m.Sport,
+			CourtId:         m.CourtID,
+			Place:           m.CourtName,
+			City:            m.CourtCity,
+			Date:            m.Date,
+			NbreParticipant: m.ParticipantNber,
+			PlayersCount:    m.PlayersCount,
+			PlayersNeeded:   max(m.ParticipantNber-m.PlayersCount, 0),
+			CurrentState:    m.CurrentState,
+			Visibility:      m.Visibility,
+			AverageElo:      m.AverageElo,
+			DistanceMeters:  m.DistanceMeters,
+		})
+	}
+	if next != nil {
+		res.NextCursor = ptr(next.Encode())
+	}
+
+	logger.Info().Int("count", len(res.Matches)).Bool("has_next", next != nil).Msg("matches searched")
+	return httpx.Write(w, http.StatusOK, res)
+}
+
+// parseMatchSearchQuery reads the query parameters of SearchMatches. Its errors are
+// i18n.Message, written by writeError.
+func parseMatchSearchQuery(query url.Values) (models.MatchSearchQuery, error) {
+	q := models.MatchSearchQuery{
+		States: []models.MatchState{models.ManqueJoueur},
+		Sort:   models.MatchSortDate,
+	}
+
+	var err error
+	q.Limit, _, err = parseLimitOffset(query, defaultMatchSearchLimit, maxMatchSearchLimit)
+	if err != nil {
+		return q, err
+	}
+
+	if raw := query.Get("sport"); raw != "" {
+		sp := models.Sport(raw)
+		switch sp {
+		case models.Basket, models.Foot, models.PingPong:
+		default:
+			return q, i18n.M(i18n.ErrInvalidSport)
+		}
+		q.Sport = &sp
+	}
+
+	if raw := query.Get("state"); raw != "" {
+		q.States = nil
+		for _, part := range strings.Split(raw, ",") {
+			st := models.MatchState(strings.TrimSpace(part))
+			switch st {
+			case models.Termine, models.ManqueScore, models.EnCours, models.Valide, models.ManqueJoueur, models.Annule:
+			default:
+				return q, i18n.M(i18n.ErrInvalidParameter, "state")
+			}
+			q.States = append(q.States, st)
+		}
+	}
+
+	for name, dst := range map[string]**time.Time{"from": &q.From, "to": &q.To} {
+		if raw := query.Get(name); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return q, i18n.M(i18n.ErrInvalidParameter, name)
+			}
+			*dst = &t
+		}
+	}
+	if q.From != nil && q.To != nil && !q.From.Before(*q.To) {
+		return q, i18n.M(i18n.ErrInvalidDateRange)
+	}
+
+	rawLat, rawLng := query.Get("lat"), query.Get("lng")
+	if rawLat != "" || rawLng != "" {
+		lat, err := parseFiniteFloat(rawLat)
+		if err != nil || lat < -90 || lat > 90 {
+			return q, i18n.M(i18n.ErrInvalidParameter, "lat")
+		}
+		lng, err := parseFiniteFloat(rawLng)
+		if err != nil || lng < -180 || lng > 180 {
+			return q, i18n.M(i18n.ErrInvalidParameter, "lng")
+		}
+		radius := defaultNearbyRadiusMeters
+		if raw := query.Get("radius_m"); raw != "" {
+			radius, err = parseFiniteFloat(raw)
+			if err != nil || radius <= 0 || radius > maxNearbyRadiusMeters {
+				return q, i18n.M(i18n.ErrInvalidParameter, "radius_m")
+			}
+		}
+		q.Near = &models.GeoFilter{Latitude: lat, Longitude: lng, RadiusMeters: radius}
+	}
+
+	if raw := query.Get("court"); raw != "" {
+		q.CourtID = &raw
+	}
+
+	if raw := query.Get("players_needed"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			return q, i18n.M(i18n.ErrInvalidParameter, "players_needed")
+		}
+		q.MinPlayersNeeded = &n
+	}
+
+	if raw := query.Get("elo_range"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return q, i18n.M(i18n.ErrInvalidParameter, "elo_range")
+		}
+		q.EloRange = &n
+	}
+
+	if raw := query.Get("sort"); raw != "" {
+		q.Sort = models.MatchSort(raw)
+		switch q.Sort {
+		case models.MatchSortDate:
+		case models.MatchSortDistance:
+			if q.Near == nil {
+				return q, i18n.M(i18n.ErrDistanceSortNeedsPosition)
+			}
+		default:
+			return q, i18n.M(i18n.ErrInvalidParameter, "sort")
+		}
+	}
+
+	if raw := query.Get("cursor"); raw != "" {
+		c, err := models.DecodeMatchSearchCursor(raw)
+		if err != nil || c.Sort != q.Sort {
+			return q, i18n.M(i18n.ErrInvalidParameter, "cursor")
+		}
+		q.Cursor = c
+	}
+
+	return q, nil
+}
+
 // CreateMatch godoc
 // @Summary      Crée un nouveau match
 // @Description  Enregistre un nouveau match en base de données à partir des données fournies en JSON
@@ -317,8 +519,9 @@ func (s *Service) GetAllMatches(w http.ResponseWriter, r *http.Request, ai model
 // @Produce      json
 // @Param        match  body      models.MatchRequest  true  "Objet match à créer"
 // @Success      201    {object}  map[string]string    "Match créé avec succès"
-// @Failure      400    {object}  models.Error         "Données invalides ou champ ID manquant"
+// @Failure      400    {object}  models.Error         "Données invalides, champ ID manquant ou visibilité inconnue"
 // @Failure      401   {object}  models.Error       "Utilisateur non autorisé"
+// @Failure      403    {object}  models.Error         "Adresse e-mail non vérifiée"
 // @Failure      500    {object}  models.Error         "Erreur lors de la création du match"
 // @Router       /match [post]
 func (s *Service) CreateMatch(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
@@ -329,15 +532,24 @@ func (s *Service) CreateMatch(w http.ResponseWriter, r *http.Request, ai models.
 
 	if !ai.IsConnected {
 		baseLogger.Warn().Msg("unauthorized")
-		return httpx.WriteError(w, http.StatusUnauthorized, "not authorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+
+	ctx := r.Context()
+
+	verified, err := s.db.IsEmailVerified(ctx, ai.UserID)
+	if err != nil {
+		baseLogger.Error().Err(err).Msg("db check email verification failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+	if !verified {
+		baseLogger.Warn().Msg("email not verified")
+		return httpx.WriteError(w, r, http.StatusForbidden, i18n.ErrEmailNotVerified)
 	}
 
 	var match models.MatchRequest
-	decoder := json.NewDecoder(r.Body)
-	defer func(Body io.ReadCloser) { _ = Body.Close() }(r.Body)
-	if err := decoder.Decode(&match); err != nil {
-		baseLogger.Warn().Err(err).Msg("invalid JSON body")
-		return httpx.WriteError(w, http.StatusBadRequest, "invalid JSON")
+	if err := httpx.DecodeJSON(w, r, &match, s.clock.Now()); err != nil {
+		return writeError(w, r, baseLogger, err, "invalid JSON body")
 	}
 
 	logger := baseLogger.With().
@@ -346,57 +558,47 @@ func (s *Service) CreateMatch(w http.ResponseWriter, r *http.Request, ai models.
 		Str("sport", string(match.Sport)).
 		Logger()
 
-	if match.NbreParticipant < 2 || match.NbreParticipant%2 != 0 {
-		logger.Warn().Msg("invalid number of participant")
-		return httpx.WriteError(w, http.StatusBadRequest, "invalid number of participant")
-	}
-
-	ctx := r.Context()
-
 	court, err := s.db.GetCourtByID(ctx, match.CourtID)
 	if err != nil {
 		logger.Error().Err(err).Msg("db get court failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "failed to fetch court")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 	if court == nil {
 		logger.Warn().Msg("court not found")
-		return httpx.WriteError(w, http.StatusBadRequest, "court not found")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrCourtNotFound)
 	}
 
 	matchDb := match.ToDBMatches(s.clock.Now(), ai.UserID)
 
 	if err := s.db.CreateMatch(ctx, matchDb); err != nil {
 		logger.Error().Err(err).Msg("db create match failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "failed to create match")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 
 	existing, err := s.db.GetRankingByUserCourtSport(ctx, ai.UserID, match.CourtID, match.Sport)
 	if err != nil {
 		logger.Error().Err(err).Msg("db get ranking failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "failed to check ranking")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 	if existing == nil {
-		if err := s.db.InsertRanking(ctx, models.DBRanking{
-			UserID:    ai.UserID,
-			CourtID:   match.CourtID,
-			Sport:     match.Sport,
-			Elo:       DefaultElo,
-			CreatedAt: s.clock.Now(),
-			UpdatedAt: s.clock.Now(),
-		}); err != nil {
+		if err := s.db.InsertRanking(ctx, s.ratings.NewRanking(ai.UserID, match.CourtID, match.Sport, s.clock.Now())); err != nil {
 			logger.Error().Err(err).Msg("db insert default ranking failed")
-			return httpx.WriteError(w, http.StatusInternalServerError, "failed to create default ranking")
+			return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 		}
 	}
 
+	creatorTeam := 1 // creator joins team 1
+	if matchDb.AutoBalance {
+		creatorTeam = 0 // teams are assigned once the match is full
+	}
 	if err := s.db.CreateUserMatch(ctx, models.DBUserMatch{
 		UserID:    ai.UserID,
 		MatchID:   matchDb.Id,
-		Team:      1, // creator joins team 1
+		Team:      creatorTeam,
 		CreatedAt: s.clock.Now(),
 	}); err != nil {
 		logger.Error().Err(err).Msg("db create user_match failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "failed to associate user to match")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 
 	logger.Info().Str("match_id", matchDb.Id).Msg("match created")
@@ -405,14 +607,15 @@ func (s *Service) CreateMatch(w http.ResponseWriter, r *http.Request, ai models.
 
 // JoinMatch godoc
 // @Summary      Un utilisateur rejoint un match
-// @Description  Permet à un utilisateur authentifié de rejoindre un match existant, si ce n’est pas déjà fait
+// @Description  Permet à un utilisateur authentifié de rejoindre un match existant, si ce n’est pas déjà fait. L'inscription, la vérification des places et le passage à l'état Valide sont faits dans une seule transaction. Pour un match auto_balance, l'équipe demandée est ignorée : les équipes sont réparties selon l'ELO des joueurs quand le match est complet.
 // @Tags         match
 // @Produce      json
 // @Param        id    path      string             true  "Identifiant du match"
-// @Param        body  body      models.JoinMatchRequest  true   "Informations pour rejoindre un match (team)"
+// @Param        body  body      models.JoinMatchRequest  true   "Informations pour rejoindre un match (team, facultatif pour un match auto_balance)"
 // @Success      200
-// @Failure      400   {object}  models.Error       "Identifiant manquant"
+// @Failure      400   {object}  models.Error       "Identifiant manquant, équipe invalide ou complète, ou match dans un mauvais état"
 // @Failure      401   {object}  models.Error       "Utilisateur non autorisé"
+// @Failure      403   {object}  models.Error       "Adresse e-mail non vérifiée, ou match réservé aux amis ou sur invitation"
 // @Failure      404   {object}  models.Error       "Match non trouvé"
 // @Failure      409   {object}  models.Error       "Utilisateur déjà inscrit au match"
 // @Failure      500   {object}  models.Error       "Erreur lors de l'inscription de l'utilisateur au match"
@@ -425,7 +628,7 @@ func (s *Service) JoinMatch(w http.ResponseWriter, r *http.Request, ai models.Au
 
 	if !ai.IsConnected {
 		baseLogger.Warn().Msg("unauthorized")
-		return httpx.WriteError(w, http.StatusUnauthorized, "not authorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
 	}
 
 	ctx := r.Context()
@@ -435,111 +638,173 @@ func (s *Service) JoinMatch(w http.ResponseWriter, r *http.Request, ai models.Au
 
 	if matchID == "" {
 		logger.Warn().Msg("missing match ID")
-		return httpx.WriteError(w, http.StatusBadRequest, "missing match ID")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMissingParameter, "id")
 	}
 
-	var matchRequest models.JoinMatchRequest
-	decoder := json.NewDecoder(r.Body)
-	defer func(Body io.ReadCloser) { _ = Body.Close() }(r.Body)
-	if err := decoder.Decode(&matchRequest); err != nil {
-		logger.Warn().Err(err).Msg("invalid JSON body")
-		return httpx.WriteError(w, http.StatusBadRequest, "invalid JSON")
-	}
-
-	match, err := s.db.GetMatchById(ctx, matchID)
+	verified, err := s.db.IsEmailVerified(ctx, ai.UserID)
 	if err != nil {
-		logger.Error().Err(err).Msg("db get match failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "failed to fetch match")
+		logger.Error().Err(err).Msg("db check email verification failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
-	if match == nil {
-		logger.Warn().Msg("match not found")
-		return httpx.WriteError(w, http.StatusNotFound, "match not found")
+	if !verified {
+		logger.Warn().Msg("email not verified")
+		return httpx.WriteError(w, r, http.StatusForbidden, i18n.ErrEmailNotVerified)
 	}
 
-	if match.CurrentState != models.ManqueJoueur {
-		logger.Warn().Str("state", string(match.CurrentState)).Msg("match not in ManqueJoueur")
-		return httpx.WriteError(w, http.StatusBadRequest, "match is not in the right state")
+	var matchRequest models.JoinMatchRequest
+	if err := httpx.DecodeJSON(w, r, &matchRequest, s.clock.Now()); err != nil {
+		return writeError(w, r, logger, err, "invalid JSON body")
 	}
 
-	exists, err := s.db.IsUserInMatch(ctx, ai.UserID, matchID)
+	match, err := s.db.JoinMatch(ctx, models.DBUserMatch{
+		UserID:    ai.UserID,
+		MatchID:   matchID,
+		Team:      matchRequest.Team,
+		CreatedAt: s.clock.Now(),
+	}, s.clock.Now(), s.ratings.NewRanking)
 	if err != nil {
-		logger.Error().Err(err).Msg("db check user in match failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "failed to check user in match")
-	}
-	if exists {
-		logger.Warn().Msg("user already in match")
-		return httpx.WriteError(w, http.StatusConflict, "user already joined the match")
+		return writeError(w, r, logger, err, "db join match failed")
 	}
 
-	count, err := s.db.CountUsersByMatchAndTeam(ctx, matchID, matchRequest.Team)
-	if err != nil {
-		logger.Error().Err(err).Msg("db count users by match/team failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "failed to count users by match and team")
+	logger.Info().Msg("user joined match successfully")
+	s.publishMatchEvent(ctx, logger, models.MatchEvent{
+		Type:    models.MatchEventPlayerJoined,
+		MatchID: matchID,
+		UserID:  ai.UserID,
+	})
+	if match.CurrentState == models.Valide {
+		s.publishMatchEvent(ctx, logger, models.MatchEvent{
+			Type:    models.MatchEventStateChanged,
+			MatchID: matchID,
+			State:   match.CurrentState,
+		})
 	}
-	if count >= match.ParticipantNber/2 {
-		logger.Warn().Int("team", matchRequest.Team).Msg("team full")
-		return httpx.WriteError(w, http.StatusBadRequest, "this team is full")
+	return httpx.Write(w, http.StatusOK, nil)
+}
+
+// LeaveMatch godoc
+// @Summary      Un joueur quitte un match
+// @Description  Libère la place du joueur connecté. Un match Valide repasse à "Manque joueur". Si le créateur quitte le match, le joueur inscrit le plus ancien devient créateur, ou le match passe à "Annule" s'il était seul. Refusé une fois le match "En cours".
+// @Tags         match
+// @Produce      json
+// @Param        id    path      string  true  "ID du match"
+// @Success      200
+// @Failure      400   {object}  models.Error  "ID manquant, mauvais état, ou utilisateur non inscrit au match"
+// @Failure      401   {object}  models.Error  "Utilisateur non autorisé"
+// @Failure      404   {object}  models.Error  "Match non trouvé"
+// @Failure      500   {object}  models.Error  "Erreur serveur ou base de données"
+// @Router       /match/{id}/leave [post]
+// @Security     BearerAuth
+func (s *Service) LeaveMatch(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+	baseLogger := log.With().
+		Str("method", "LeaveMatch").
+		Str("user_id", ai.UserID).
+		Logger()
+
+	if !ai.IsConnected {
+		baseLogger.Warn().Msg("unauthorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
 	}
 
-	existing, err := s.db.GetRankingByUserCourtSport(ctx, ai.UserID, match.CourtID, match.Sport)
-	if err != nil {
-		logger.Error().Err(err).Msg("db get ranking failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "failed to check ranking")
+	matchID := chi.URLParam(r, "id")
+	logger := baseLogger.With().Str("match_id", matchID).Logger()
+
+	if matchID == "" {
+		logger.Warn().Msg("missing match ID")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMissingParameter, "id")
 	}
-	if existing == nil {
-		if err := s.db.InsertRanking(ctx, models.DBRanking{
-			UserID:    ai.UserID,
-			CourtID:   match.CourtID,
-			Elo:       DefaultElo,
-			Sport:     match.Sport,
-			CreatedAt: s.clock.Now(),
-			UpdatedAt: s.clock.Now(),
-		}); err != nil {
-			logger.Error().Err(err).Msg("db insert default ranking failed")
-			return httpx.WriteError(w, http.StatusInternalServerError, "failed to create default ranking")
-		}
+
+	return s.removeMatchPlayer(w, r, logger, matchID, ai.UserID, ai.UserID)
+}
+
+// KickMatchPlayer godoc
+// @Summary      Le créateur retire un joueur d'un match
+// @Description  Libère la place d'un joueur. Réservé au créateur du match. Un match Valide repasse à "Manque joueur". Refusé une fois le match "En cours".
+// @Tags         match
+// @Produce      json
+// @Param        id      path      string  true  "ID du match"
+// @Param        userId  path      string  true  "ID du joueur à retirer"
+// @Success      200
+// @Failure      400   {object}  models.Error  "ID manquant, mauvais état, ou joueur non inscrit au match"
+// @Failure      401   {object}  models.Error  "Utilisateur non autorisé"
+// @Failure      403   {object}  models.Error  "L'utilisateur n'est pas le créateur du match"
+// @Failure      404   {object}  models.Error  "Match non trouvé"
+// @Failure      500   {object}  models.Error  "Erreur serveur ou base de données"
+// @Router       /match/{id}/players/{userId} [delete]
+// @Security     BearerAuth
+func (s *Service) KickMatchPlayer(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+	baseLogger := log.With().
+		Str("method", "KickMatchPlayer").
+		Str("user_id", ai.UserID).
+		Logger()
+
+	if !ai.IsConnected {
+		baseLogger.Warn().Msg("unauthorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
 	}
 
-	if err := s.db.CreateUserMatch(ctx, models.DBUserMatch{
-		UserID:    ai.UserID,
-		MatchID:   matchID,
-		Team:      matchRequest.Team,
-		CreatedAt: s.clock.Now(),
-	}); err != nil {
-		logger.Error().Err(err).Msg("db create user_match failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "failed to join match")
+	matchID := chi.URLParam(r, "id")
+	playerID := chi.URLParam(r, "userId")
+	logger := baseLogger.With().
+		Str("match_id", matchID).
+		Str("player_id", playerID).
+		Logger()
+
+	if matchID == "" || playerID == "" {
+		logger.Warn().Msg("missing match ID or player ID")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMissingParameter, "id, playerId")
 	}
 
-	newCount, err := s.db.CountUsersByMatch(ctx, matchID)
+	return s.removeMatchPlayer(w, r, logger, matchID, playerID, ai.UserID)
+}
+
+func (s *Service) removeMatchPlayer(w http.ResponseWriter, r *http.Request, logger zerolog.Logger, matchID, playerID, requestedBy string) error {
+	ctx := r.Context()
+	before, err := s.db.GetMatchById(ctx, matchID)
 	if err != nil {
-		logger.Error().Err(err).Msg("db count users by match failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "failed to count users by match")
-	}
-	if newCount == match.ParticipantNber {
-		match.CurrentState = models.Valide
+		logger.Error().Err(err).Msg("db get match failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 
-	match.UpdatedAt = s.clock.Now()
-	if err := s.db.UpsertMatch(ctx, *match, s.clock.Now()); err != nil {
-		logger.Error().Err(err).Msg("db upsert match failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "failed to update match")
+	match, cancelled, err := s.db.RemoveMatchPlayer(ctx, matchID, playerID, requestedBy, s.clock.Now())
+	if err != nil {
+		return writeError(w, r, logger, err, "db remove match player failed")
 	}
 
-	logger.Info().Msg("user joined match successfully")
+	if cancelled {
+		logger.Info().Msg("last player left, match cancelled")
+	} else {
+		logger.Info().Str("creator_id", match.CreatorID).Msg("player removed from match")
+	}
+	s.publishMatchEvent(ctx, logger, models.MatchEvent{
+		Type:    models.MatchEventPlayerLeft,
+		MatchID: matchID,
+		UserID:  playerID,
+	})
+	if before != nil && before.CurrentState != match.CurrentState {
+		s.publishMatchEvent(ctx, logger, models.MatchEvent{
+			Type:    models.MatchEventStateChanged,
+			MatchID: matchID,
+			State:   match.CurrentState,
+		})
+	}
 	return httpx.Write(w, http.StatusOK, nil)
 }
 
 // DeleteMatch godoc
-// @Summary      Supprime un match
-// @Description  Supprime un match via son ID
+// @Summary      Annule un match
+// @Description  Passe le match à l'état "Annule" au lieu de le supprimer, pour qu'il reste visible dans l'historique. Réservé au créateur, tant que le match n'a pas commencé. Les autres participants sont prévenus par e-mail.
 // @Tags         match
 // @Produce      json
-// @Param        id   path      string  true  "Identifiant du match à supprimer"
+// @Param        id   path      string  true  "Identifiant du match à annuler"
 // @Success      200
-// @Failure      400  {object}  models.Error      "ID manquant"
+// @Failure      400  {object}  models.Error      "ID manquant ou match déjà commencé"
 // @Failure      401  {object}  models.Error      "Utilisateur non autorisé"
-// @Failure      500  {object}  models.Error      "Erreur lors de la suppression du match"
+// @Failure      403  {object}  models.Error      "L'utilisateur n'est pas le créateur du match"
+// @Failure      404  {object}  models.Error      "Match non trouvé"
+// @Failure      500  {object}  models.Error      "Erreur lors de l'annulation du match"
 // @Router       /match/{id} [delete]
+// @Security     BearerAuth
 func (s *Service) DeleteMatch(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
 	baseLogger := log.With().
 		Str("method", "DeleteMatch").
@@ -548,7 +813,7 @@ func (s *Service) DeleteMatch(w http.ResponseWriter, r *http.Request, ai models.
 
 	if !ai.IsConnected {
 		baseLogger.Warn().Msg("unauthorized")
-		return httpx.WriteError(w, http.StatusUnauthorized, "not authorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
 	}
 
 	matchID := chi.URLParam(r, "id")
@@ -556,122 +821,89 @@ func (s *Service) DeleteMatch(w http.ResponseWriter, r *http.Request, ai models.
 
 	if matchID == "" {
 		logger.Warn().Msg("missing match ID")
-		return httpx.WriteError(w, http.StatusBadRequest, "missing match ID")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMissingParameter, "id")
 	}
 
 	ctx := r.Context()
-	if err := s.db.DeleteMatch(ctx, matchID); err != nil {
-		logger.Error().Err(err).Msg("db delete match failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "failed to delete match")
+	match, err := s.db.CancelMatch(ctx, matchID, ai.UserID, s.clock.Now())
+	if err != nil {
+		return writeError(w, r, logger, err, "db cancel match failed")
 	}
 
-	logger.Info().Msg("match deleted")
+	logger.Info().Msg("match cancelled")
+	s.publishMatchEvent(ctx, logger, models.MatchEvent{
+		Type:    models.MatchEventStateChanged,
+		MatchID: matchID,
+		State:   match.CurrentState,
+	})
 	return httpx.Write(w, http.StatusOK, nil)
 }
 
-func (s *Service) applyEloForMatch(ctx context.Context, match models.DBMatches, score1, score2 int) error {
-	userMatches, err := s.db.GetUserMatchesByMatchID(ctx, match.Id)
-	if err != nil {
-		return err
-	}
-	if len(userMatches) == 0 {
-		return nil
-	}
+// InviteToMatch godoc
+// @Summary      Invite des amis à un match
+// @Description  Invite des amis du créateur à un match en attente de joueurs et les prévient par e-mail. Les invités peuvent voir et rejoindre le match quelle que soit sa visibilité. Les utilisateurs déjà invités ne reçoivent pas de nouvel e-mail.
+// @Tags         match
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string                       true  "Identifiant du match"
+// @Param        body  body      models.InviteToMatchRequest  true  "Identifiants des amis à inviter"
+// @Success      200   {object}  models.InviteToMatchResponse
+// @Failure      400   {object}  models.Error  "ID manquant, liste vide, utilisateur qui n'est pas un ami, ou match qui n'attend plus de joueurs"
+// @Failure      401   {object}  models.Error  "Utilisateur non autorisé"
+// @Failure      403   {object}  models.Error  "L'utilisateur n'est pas le créateur du match"
+// @Failure      404   {object}  models.Error  "Match non trouvé"
+// @Failure      500   {object}  models.Error  "Erreur serveur"
+// @Router       /match/{id}/invite [post]
+// @Security     BearerAuth
+func (s *Service) InviteToMatch(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+	baseLogger := log.With().
+		Str("method", "InviteToMatch").
+		Str("user_id", ai.UserID).
+		Logger()
 
-	var team1Users, team2Users []string
-	for _, um := range userMatches {
-		switch um.Team {
-		case 1:
-			team1Users = append(team1Users, um.UserID)
-		case 2:
-			team2Users = append(team2Users, um.UserID)
-		}
-	}
-	if len(team1Users) == 0 || len(team2Users) == 0 {
-		return nil
+	if !ai.IsConnected {
+		baseLogger.Warn().Msg("unauthorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
 	}
 
-	now := s.clock.Now()
-
-	getRanks := func(userIDs []string) ([]models.DBRanking, error) {
-		out := make([]models.DBRanking, 0, len(userIDs))
-		for _, uid := range userIDs {
-			rk, err := s.db.GetRankingByUserCourtSport(ctx, uid, match.CourtID, match.Sport)
-			if err != nil {
-				return nil, err
-			}
-			if rk == nil {
-				return nil, fmt.Errorf("ranking missing for user=%s court=%s sport=%s", uid, match.CourtID, match.Sport)
-			}
-			out = append(out, *rk)
-		}
-		return out, nil
-	}
+	matchID := chi.URLParam(r, "id")
+	logger := baseLogger.With().Str("match_id", matchID).Logger()
 
-	r1, err := getRanks(team1Users)
-	if err != nil {
-		return err
-	}
-	r2, err := getRanks(team2Users)
-	if err != nil {
-		return err
+	if matchID == "" {
+		logger.Warn().Msg("missing match ID")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMissingParameter, "id")
 	}
 
-	avg := func(rs []models.DBRanking) float64 {
-		if len(rs) == 0 {
-			return float64(DefaultElo)
-		}
-		sum := 0
-		for _, r := range rs {
-			sum += r.Elo
-		}
-		return float64(sum) / float64(len(rs))
+	var req models.InviteToMatchRequest
+	if err := httpx.DecodeJSON(w, r, &req, s.clock.Now()); err != nil {
+		return writeError(w, r, logger, err, "invalid JSON body")
 	}
 
-	rTeam1 := avg(r1)
-	rTeam2 := avg(r2)
+	userIDs := lo.Uniq(req.UserIDs)
 
-	var s1, s2 float64
-	switch {
-	case score1 > score2:
-		s1, s2 = 1.0, 0.0
-	case score1 < score2:
-		s1, s2 = 0.0, 1.0
-	default:
-		s1, s2 = 0.5, 0.5
+	ctx := r.Context()
+	friends, err := s.db.GetFriendsByIDs(ctx, ai.UserID, userIDs)
+	if err != nil {
+		logger.Error().Err(err).Msg("db get friends failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
-
-	exp := func(rA, rB float64) float64 {
-		return 1.0 / (1.0 + math.Pow(10, (rB-rA)/400.0))
+	if len(friends) != len(userIDs) {
+		logger.Warn().Int("requested", len(userIDs)).Int("friends", len(friends)).Msg("invitees are not all friends")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrInviteFriendsOnly)
 	}
-	e1 := exp(rTeam1, rTeam2)
-	e2 := exp(rTeam2, rTeam1)
 
-	applyDelta := func(rs []models.DBRanking, S, E float64) []models.DBRanking {
-		out := make([]models.DBRanking, len(rs))
-		for i, rk := range rs {
-			delta := int(math.Round(float64(KFactor) * (S - E)))
-			rk.Elo = rk.Elo + delta
-			rk.UpdatedAt = now
-			out[i] = rk
-		}
-		return out
+	_, invited, err := s.db.InviteToMatch(ctx, matchID, ai.UserID, userIDs, s.clock.Now())
+	if err != nil {
+		return writeError(w, r, logger, err, "db invite to match failed")
 	}
 
-	r1New := applyDelta(r1, s1, e1)
-	r2New := applyDelta(r2, s2, e2)
-
-	for _, rk := range append(r1New, r2New...) {
-		if err := s.db.InsertRanking(ctx, rk); err != nil {
-			return err
-		}
-	}
-	return nil
+	logger.Info().Int("invited", len(invited)).Msg("friends invited")
+	return httpx.Write(w, http.StatusOK, models.InviteToMatchResponse{Invited: lo.Ternary(invited == nil, []string{}, invited)})
 }
 
 // UpdateMatchScore godoc
 // @Summary      Met à jour le score d’un match
-// @Description  Met à jour les scores (score1 et score2) d’un match via son ID
+// @Description  Enregistre le vote de score de l'équipe du joueur. Si les deux équipes votent le même score, le match passe à "Termine". Si elles votent des scores différents, le match est marqué en litige et un nouveau tour de vote s'ouvre jusqu'à sa date limite.
 // @Tags         match
 // @Accept       json
 // @Produce      json
@@ -691,7 +923,7 @@ func (s *Service) UpdateMatchScore(w http.ResponseWriter, r *http.Request, ai mo
 
 	if !ai.IsConnected {
 		baseLogger.Warn().Msg("unauthorized")
-		return httpx.WriteError(w, http.StatusUnauthorized, "not authorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
 	}
 
 	ctx := r.Context()
@@ -700,124 +932,46 @@ func (s *Service) UpdateMatchScore(w http.ResponseWriter, r *http.Request, ai mo
 
 	if id == "" {
 		logger.Warn().Msg("missing match ID")
-		return httpx.WriteError(w, http.StatusBadRequest, "missing match ID")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMissingParameter, "id")
 	}
 
 	var req models.UpdateScoreRequest
-	decoder := json.NewDecoder(r.Body)
-	defer func(Body io.ReadCloser) { _ = Body.Close() }(r.Body)
-	if err := decoder.Decode(&req); err != nil {
-		logger.Warn().Err(err).Msg("invalid JSON body")
-		return httpx.WriteError(w, http.StatusBadRequest, "invalid JSON")
-	}
-
-	match, err := s.db.GetMatchById(ctx, id)
-	if err != nil {
-		logger.Error().Err(err).Msg("db get match failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "database error")
-	}
-	if match == nil {
-		logger.Warn().Msg("match not found")
-		return httpx.WriteError(w, http.StatusNotFound, "match not found")
+	if err := httpx.DecodeJSON(w, r, &req, s.clock.Now()); err != nil {
+		return writeError(w, r, logger, err, "invalid JSON body")
 	}
 
-	if match.CurrentState != models.ManqueScore {
-		logger.Warn().Str("state", string(match.CurrentState)).Msg("match not in ManqueScore")
-		return httpx.WriteError(w, http.StatusBadRequest, "match is not in the right state")
-	}
-
-	userMatch, err := s.db.GetUserInMatch(ctx, ai.UserID, id)
+	vote, consensus, disputeOpened, err := s.db.SubmitScoreVote(ctx, id, ai.UserID, req.Score1, req.Score2, s.clock.Now())
 	if err != nil {
-		logger.Error().Err(err).Msg("db get user in match failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "database error")
-	}
-	if userMatch == nil {
-		logger.Warn().Msg("user not in match")
-		return httpx.WriteError(w, http.StatusNotFound, "user in this match not found")
-	}
-
-	hasSameTeamOtherVote, err := s.db.HasOtherTeamVote(ctx, id, userMatch.Team, ai.UserID)
-	if err != nil {
-		logger.Error().Err(err).Msg("db check other team vote failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "failed to check team vote")
-	}
-	if hasSameTeamOtherVote {
-		logger.Warn().Msg("team already has a vote")
-		return httpx.WriteError(w, http.StatusBadRequest, "this team already has a vote")
-	}
-
-	if err := s.db.UpsertMatchScoreVote(ctx, models.DBMatchScoreVote{
-		MatchId: id,
-		UserId:  ai.UserID,
-		Team:    userMatch.Team,
-		Score1:  req.Score1,
-		Score2:  req.Score2,
-	}); err != nil {
-		logger.Error().Err(err).Msg("db upsert score vote failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "failed to upsert score vote")
-	}
-
-	hasConsensus, err := s.db.HasConsensusScore(ctx, id, userMatch.Team, req.Score1, req.Score2)
-	if err != nil {
-		logger.Error().Err(err).Msg("db check consensus failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "failed to check consensus")
+		return writeError(w, r, logger, err, "db submit score vote failed")
+	}
+	s.publishMatchEvent(ctx, logger, models.MatchEvent{
+		Type:    models.MatchEventVoteSubmitted,
+		MatchID: id,
+		UserID:  ai.UserID,
+		Team:    vote.Team,
+		Score1:  &req.Score1,
+		Score2:  &req.Score2,
+	})
+	if disputeOpened {
+		logger.Info().Msg("teams disagree, score dispute opened")
 	}
 
-	if hasConsensus {
-		match.CurrentState = models.Termine
-		if err := s.applyEloForMatch(ctx, *match, req.Score1, req.Score2); err != nil {
-			logger.Error().Err(err).Msg("apply ELO failed")
-			return httpx.WriteError(w, http.StatusInternalServerError, "failed to update rankings")
+	if consensus {
+		finalized, applied, err := domain.FinalizeMatch(ctx, s.db, s.ratings, id, req.Score1, req.Score2, s.clock.Now())
+		if err != nil {
+			return writeError(w, r, logger, err, "finalize match failed")
 		}
-
-		court, err := s.db.GetCourtByID(ctx, match.CourtID)
-		if err != nil || court == nil {
-			logger.Error().Err(err).Msg("db get court by id failed (email for result mail)")
-		} else {
-			userMatches, err := s.db.GetUserMatchesByMatchID(ctx, id)
-			if err != nil {
-				logger.Error().Err(err).Msg("db get user_matches failed (email for result mail)")
-			} else {
-				for _, um := range userMatches {
-					u, err := s.db.GetUserById(ctx, um.UserID)
-					if err != nil || u == nil {
-						logger.Error().Err(err).Str("user_id", um.UserID).Msg("db get user by id failed (email for result mail)")
-						continue
-					}
-
-					teamScore, oppScore := req.Score1, req.Score2
-					if um.Team == 2 {
-						teamScore, oppScore = req.Score2, req.Score1
-					}
-
-					if err := s.mailer.SendMatchResultEmail(id, u.Email, u.Username, match.Sport, court.Name, teamScore, oppScore); err != nil {
-						logger.Error().
-							Err(err).
-							Str("email", u.Email).
-							Int("team", um.Team).
-							Int("team_score", teamScore).
-							Int("opp_score", oppScore).
-							Msg("sending match result email failed")
-					} else {
-						logger.Info().
-							Str("email", u.Email).
-							Int("team", um.Team).
-							Int("team_score", teamScore).
-							Int("opp_score", oppScore).
-							Msg("match result email sent")
-					}
-				}
-			}
+		logger.Info().Bool("applied", applied).Msg("score consensus, match finalized")
+		if applied {
+			s.publishMatchEvent(ctx, logger, models.MatchEvent{
+				Type:    models.MatchEventFinalized,
+				MatchID: id,
+				State:   finalized.CurrentState,
+				Score1:  finalized.Score1,
+				Score2:  finalized.Score2,
+			})
 		}
-	}
-
-	match.Score1 = &req.Score1
-	match.Score2 = &req.Score2
-	match.UpdatedAt = s.clock.Now()
-
-	if err := s.db.UpsertMatch(ctx, *match, s.clock.Now()); err != nil {
-		logger.Error().Err(err).Msg("db upsert match failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "failed to update match")
+		return httpx.Write(w, http.StatusOK, nil)
 	}
 
 	logger.Info().Msg("match score updated")
@@ -826,7 +980,7 @@ func (s *Service) UpdateMatchScore(w http.ResponseWriter, r *http.Request, ai mo
 
 // StartMatch godoc
 // @Summary      Démarre un match
-// @Description  Passe un match de l’état "Valide" à "En cours" et met à jour la date de début à maintenant.
+// @Description  Passe un match de l’état "Valide" à "En cours" sans attendre le démarrage automatique. La date prévue du match est conservée.
 // @Tags         match
 // @Produce      json
 // @Param        id    path      string  true  "ID du match"
@@ -844,7 +998,7 @@ func (s *Service) StartMatch(w http.ResponseWriter, r *http.Request, ai models.A
 
 	if !ai.IsConnected {
 		baseLogger.Warn().Msg("unauthorized")
-		return httpx.WriteError(w, http.StatusUnauthorized, "not authorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
 	}
 
 	ctx := r.Context()
@@ -853,53 +1007,56 @@ func (s *Service) StartMatch(w http.ResponseWriter, r *http.Request, ai models.A
 
 	if id == "" {
 		logger.Warn().Msg("missing match ID")
-		return httpx.WriteError(w, http.StatusBadRequest, "missing match ID")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMissingParameter, "id")
 	}
 
 	match, err := s.db.GetMatchById(ctx, id)
 	if err != nil {
 		logger.Error().Err(err).Msg("db get match failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "database error")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 	if match == nil {
 		logger.Warn().Msg("match not found")
-		return httpx.WriteError(w, http.StatusNotFound, "match not found")
+		return httpx.WriteError(w, r, http.StatusNotFound, i18n.ErrMatchNotFound)
 	}
 	if match.CurrentState != models.Valide {
 		logger.Warn().Str("state", string(match.CurrentState)).Msg("match not in Valide")
-		return httpx.WriteError(w, http.StatusBadRequest, "match is not in the right state")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMatchWrongState)
 	}
 	if match.CreatorID != ai.UserID {
-		msg := "user is not the match creator"
-		logger.Warn().Str("creator_id", match.CreatorID).Msg(msg)
-		return httpx.WriteError(w, http.StatusForbidden, msg)
+		logger.Warn().Str("creator_id", match.CreatorID).Msg("user is not the match creator")
+		return httpx.WriteError(w, r, http.StatusForbidden, i18n.ErrNotMatchCreator)
 	}
 
 	userInMatch, err := s.db.IsUserInMatch(ctx, ai.UserID, id)
 	if err != nil {
 		logger.Error().Err(err).Msg("db check user in match failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "database error")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 	if !userInMatch {
 		logger.Warn().Msg("user not in match")
-		return httpx.WriteError(w, http.StatusBadRequest, "user is not in the match")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrNotInMatch)
 	}
 
-	match.Date = s.clock.Now()
 	match.CurrentState = models.EnCours
 	match.UpdatedAt = s.clock.Now()
 	if err := s.db.UpsertMatch(ctx, *match, s.clock.Now()); err != nil {
 		logger.Error().Err(err).Msg("db upsert match failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "failed to update match")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 
 	logger.Info().Msg("match started")
+	s.publishMatchEvent(ctx, logger, models.MatchEvent{
+		Type:    models.MatchEventStateChanged,
+		MatchID: id,
+		State:   match.CurrentState,
+	})
 	return httpx.Write(w, http.StatusOK, nil)
 }
 
 // FinishMatch godoc
 // @Summary      Termine un match (passage à la saisie des scores)
-// @Description  Passe un match de l’état "En cours" à "Manque Score" afin de permettre la saisie/validation des scores.
+// @Description  Passe un match de l’état "En cours" à "Manque Score" afin de permettre la saisie/validation des scores, ouverte pendant 48 heures.
 // @Tags         match
 // @Produce      json
 // @Param        id    path      string  true  "ID du match"
@@ -917,7 +1074,7 @@ func (s *Service) FinishMatch(w http.ResponseWriter, r *http.Request, ai models.
 
 	if !ai.IsConnected {
 		baseLogger.Warn().Msg("unauthorized")
-		return httpx.WriteError(w, http.StatusUnauthorized, "not authorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
 	}
 
 	ctx := r.Context()
@@ -926,52 +1083,56 @@ func (s *Service) FinishMatch(w http.ResponseWriter, r *http.Request, ai models.
 
 	if id == "" {
 		logger.Warn().Msg("missing match ID")
-		return httpx.WriteError(w, http.StatusBadRequest, "missing match ID")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMissingParameter, "id")
 	}
 
 	match, err := s.db.GetMatchById(ctx, id)
 	if err != nil {
 		logger.Error().Err(err).Msg("db get match failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "database error")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 	if match == nil {
 		logger.Warn().Msg("match not found")
-		return httpx.WriteError(w, http.StatusNotFound, "match not found")
+		return httpx.WriteError(w, r, http.StatusNotFound, i18n.ErrMatchNotFound)
 	}
 	if match.CurrentState != models.EnCours {
 		logger.Warn().Str("state", string(match.CurrentState)).Msg("match not in EnCours")
-		return httpx.WriteError(w, http.StatusBadRequest, "match is not in the right state")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMatchWrongState)
 	}
 	if match.CreatorID != ai.UserID {
-		msg := "user is not the match creator"
-		logger.Warn().Str("creator_id", match.CreatorID).Msg(msg)
-		return httpx.WriteError(w, http.StatusForbidden, msg)
+		logger.Warn().Str("creator_id", match.CreatorID).Msg("user is not the match creator")
+		return httpx.WriteError(w, r, http.StatusForbidden, i18n.ErrNotMatchCreator)
 	}
 
 	userInMatch, err := s.db.IsUserInMatch(ctx, ai.UserID, id)
 	if err != nil {
 		logger.Error().Err(err).Msg("db check user in match failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "database error")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 	if !userInMatch {
 		logger.Warn().Msg("user not in match")
-		return httpx.WriteError(w, http.StatusBadRequest, "user is not in the match")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrNotInMatch)
 	}
 
-	match.CurrentState = models.ManqueScore
-	match.UpdatedAt = s.clock.Now()
-	if err := s.db.UpsertMatch(ctx, *match, s.clock.Now()); err != nil {
-		logger.Error().Err(err).Msg("db upsert match failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "failed to update match")
+	now := s.clock.Now()
+	updated, err := s.db.StartScoreVote(ctx, id, now.Add(models.ScoreVoteWindow), now)
+	if err != nil {
+		return writeError(w, r, logger, err, "db start score vote failed")
 	}
 
 	logger.Info().Msg("match finished (waiting scores)")
+	s.publishMatchEvent(ctx, logger, models.MatchEvent{
+		Type:    models.MatchEventStateChanged,
+		MatchID: id,
+		State:   updated.CurrentState,
+	})
 	return httpx.Write(w, http.StatusOK, nil)
 }
 
 // GetMatchVoteStatus godoc
 // @Summary      Statut de vote des scores (par équipe) pour un match terminé
-// @Description  Renvoie l'équipe du joueur, si son équipe a voté et le score voté (nullable), et la même info pour l'équipe adverse. Ne fonctionne que si le match est en statut "Termine".
+// @Description  Renvoie l'équipe du joueur, si son équipe a voté et le score voté (nullable), et la même info pour l'équipe adverse, pour le tour de vote en cours.
+// @Description  En cas de litige, indique le tour de vote (1), sa date limite et les scores contestés du premier tour. À la date limite, le vote d'une seule équipe est retenu ; sinon le match est annulé.
 // @Tags         match
 // @Produce      json
 // @Param        id   path      string  true  "ID du match"
@@ -989,7 +1150,7 @@ func (s *Service) GetMatchVoteStatus(w http.ResponseWriter, r *http.Request, ai
 
 	if !ai.IsConnected {
 		baseLogger.Warn().Msg("unauthorized")
-		return httpx.WriteError(w, http.StatusUnauthorized, "not authorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
 	}
 
 	ctx := r.Context()
@@ -998,46 +1159,30 @@ func (s *Service) GetMatchVoteStatus(w http.ResponseWriter, r *http.Request, ai
 
 	if matchID == "" {
 		logger.Warn().Msg("missing match ID")
-		return httpx.WriteError(w, http.StatusBadRequest, "missing match ID")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMissingParameter, "id")
 	}
 
 	match, err := s.db.GetMatchById(ctx, matchID)
 	if err != nil {
 		logger.Error().Err(err).Msg("db get match failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "database error")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 	if match == nil {
 		logger.Warn().Msg("match not found")
-		return httpx.WriteError(w, http.StatusNotFound, "match not found")
+		return httpx.WriteError(w, r, http.StatusNotFound, i18n.ErrMatchNotFound)
 	}
 
 	um, err := s.db.GetUserInMatch(ctx, ai.UserID, matchID)
 	if err != nil {
 		logger.Error().Err(err).Msg("db get user in match failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "database error")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 	if um == nil {
 		logger.Warn().Msg("user not in match")
-		return httpx.WriteError(w, http.StatusNotFound, "user in this match not found")
+		return httpx.WriteError(w, r, http.StatusNotFound, i18n.ErrPlayerNotFound)
 	}
 	myTeam := um.Team
-	opTeam := 1
-	if myTeam == 1 {
-		opTeam = 2
-	} else {
-		opTeam = 1
-	}
-
-	myVote, err := s.db.GetScoreVoteByMatchAndTeam(ctx, matchID, myTeam)
-	if err != nil {
-		logger.Error().Err(err).Int("team", myTeam).Msg("db get vote for my team failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "failed to fetch team vote")
-	}
-	opVote, err := s.db.GetScoreVoteByMatchAndTeam(ctx, matchID, opTeam)
-	if err != nil {
-		logger.Error().Err(err).Int("team", opTeam).Msg("db get vote for opponent team failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "failed to fetch opponent team vote")
-	}
+	opTeam := otherTeam(myTeam)
 
 	toStatus := func(v *models.DBMatchScoreVote) models.TeamVoteStatus {
 		if v == nil {
@@ -1047,11 +1192,42 @@ func (s *Service) GetMatchVoteStatus(w http.ResponseWriter, r *http.Request, ai
 		return models.TeamVoteStatus{HasVoted: true, Score: sp}
 	}
 
+	roundVotes := func(round int) (models.TeamVoteStatus, models.TeamVoteStatus, error) {
+		myVote, err := s.db.GetScoreVoteByMatchAndTeam(ctx, matchID, round, myTeam)
+		if err != nil {
+			return models.TeamVoteStatus{}, models.TeamVoteStatus{}, err
+		}
+		opVote, err := s.db.GetScoreVoteByMatchAndTeam(ctx, matchID, round, opTeam)
+		if err != nil {
+			return models.TeamVoteStatus{}, models.TeamVoteStatus{}, err
+		}
+		return toStatus(myVote), toStatus(opVote), nil
+	}
+
+	round := match.VoteRound()
+	myStatus, opStatus, err := roundVotes(round)
+	if err != nil {
+		logger.Error().Err(err).Int("round", round).Msg("db get team votes failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+
 	resp := models.MatchVoteStatusResponse{
 		MatchID:    matchID,
 		PlayerTeam: myTeam,
-		MyTeam:     toStatus(myVote),
-		Opponent:   toStatus(opVote),
+		MyTeam:     myStatus,
+		Opponent:   opStatus,
+		Disputed:   match.Disputed,
+		Round:      round,
+		Deadline:   match.ScoreDeadline,
+	}
+
+	if match.Disputed {
+		myDisputed, opDisputed, err := roundVotes(0)
+		if err != nil {
+			logger.Error().Err(err).Msg("db get disputed votes failed")
+			return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+		}
+		resp.DisputedVotes = &models.DisputedVotes{MyTeam: myDisputed, Opponent: opDisputed}
 	}
 
 	logger.Info().Msg("vote status fetched")
@@ -1060,7 +1236,7 @@ func (s *Service) GetMatchVoteStatus(w http.ResponseWriter, r *http.Request, ai
 
 // GetTeamsByMatchId godoc
 // @Summary      Récupérer les équipes d’un match
-// @Description  Renvoie la liste des joueurs répartis par équipe (team 1 et team 2) pour un match donné.
+// @Description  Renvoie la liste des joueurs répartis par équipe (team 1 et team 2) pour un match donné, ainsi que les joueurs d'un match auto_balance pas encore répartis.
 // @Description  Chaque joueur est enrichi avec ses informations publiques (profil, stats, sports, terrains, etc.).
 // @Tags         match
 // @Produce      json
@@ -1068,6 +1244,8 @@ func (s *Service) GetMatchVoteStatus(w http.ResponseWriter, r *http.Request, ai
 // @Success      200  {object}  models.TeamsByMatchIdResponse
 // @Failure      400  {object}  models.Error  "ID du match manquant"
 // @Failure      401  {object}  models.Error  "Utilisateur non autorisé"
+// @Failure      403  {object}  models.Error  "Match réservé aux amis ou sur invitation"
+// @Failure      404  {object}  models.Error  "Match non trouvé"
 // @Failure      500  {object}  models.Error  "Erreur serveur"
 // @Router       /match/{id}/teams [get]
 func (s *Service) GetTeamsByMatchId(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
@@ -1078,7 +1256,7 @@ func (s *Service) GetTeamsByMatchId(w http.ResponseWriter, r *http.Request, ai m
 
 	if !ai.IsConnected {
 		baseLogger.Warn().Msg("unauthorized")
-		return httpx.WriteError(w, http.StatusUnauthorized, "not authorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
 	}
 
 	ctx := r.Context()
@@ -1087,19 +1265,52 @@ func (s *Service) GetTeamsByMatchId(w http.ResponseWriter, r *http.Request, ai m
 
 	if matchID == "" {
 		logger.Warn().Msg("missing match ID")
-		return httpx.WriteError(w, http.StatusBadRequest, "missing match ID")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMissingParameter, "id")
 	}
 
-	rows, err := s.db.GetUsersWithTeamByMatchID(ctx, matchID)
+	match, err := s.db.GetMatchById(ctx, matchID)
+	if err != nil {
+		logger.Error().Err(err).Msg("db get match failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+	if match == nil {
+		logger.Warn().Msg("match not found")
+		return httpx.WriteError(w, r, http.StatusNotFound, i18n.ErrMatchNotFound)
+	}
+
+	visible, err := s.db.IsMatchVisibleTo(ctx, matchID, ai.UserID)
+	if err != nil {
+		logger.Error().Err(err).Msg("db check match visibility failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+	if !visible {
+		logger.Warn().Msg("match not visible")
+		return httpx.WriteError(w, r, http.StatusForbidden, i18n.ErrMatchNotVisible)
+	}
+
+	teams, err := s.buildTeamsResponse(ctx, logger, matchID)
 	if err != nil {
 		logger.Error().Err(err).Msg("fetching users with team failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "internal error")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+	return httpx.Write(w, http.StatusOK, teams)
+}
+
+// buildTeamsResponse returns the players of the match grouped by team, players of an
+// auto_balance match not assigned yet apart.
+func (s *Service) buildTeamsResponse(ctx context.Context, logger zerolog.Logger, matchID string) (models.TeamsByMatchIdResponse, error) {
+	teams := models.TeamsByMatchIdResponse{
+		Team1:      []models.UserResponse{},
+		Team2:      []models.UserResponse{},
+		Unassigned: []models.UserResponse{},
+	}
+
+	rows, err := s.db.GetUsersWithTeamByMatchID(ctx, matchID)
+	if err != nil {
+		return teams, err
 	}
 	if len(rows) == 0 {
-		return httpx.Write(w, http.StatusOK, models.TeamsByMatchIdResponse{
-			Team1: []models.UserResponse{},
-			Team2: []models.UserResponse{},
-		})
+		return teams, nil
 	}
 
 	userIDs := make([]string, 0, len(rows))
@@ -1112,33 +1323,7 @@ func (s *Service) GetTeamsByMatchId(w http.ResponseWriter, r *http.Request, ai m
 		logger.Error().Err(err).Msg("prefetching user stats failed")
 	}
 
-	profilePics := make(map[string]string, len(userIDs))
-	var mu sync.Mutex
-
-	var wg sync.WaitGroup
-	sem := make(chan struct{}, 10)
-
-	for _, uid := range userIDs {
-		wg.Add(1)
-		go func(userID string) {
-			defer wg.Done()
-			sem <- struct{}{}
-			defer func() { <-sem }()
-
-			pic, err := s.s3Service.GetProfilePicture(ctx, userID)
-			if err != nil {
-				logger.Warn().Err(err).Str("user_id", userID).Msg("failed to get profile picture")
-				return
-			}
-			mu.Lock()
-			profilePics[userID] = pic.URL
-			mu.Unlock()
-		}(uid)
-	}
-	wg.Wait()
-
-	team1 := make([]models.UserResponse, 0)
-	team2 := make([]models.UserResponse, 0)
+	profilePics := s.prefetchProfilePictures(ctx, logger, userIDs)
 
 	for _, row := range rows {
 		picURL := profilePics[row.Id]
@@ -1155,16 +1340,62 @@ func (s *Service) GetTeamsByMatchId(w http.ResponseWriter, r *http.Request, ai m
 
 		switch row.Team {
 		case 1:
-			team1 = append(team1, resp)
+			teams.Team1 = append(teams.Team1, resp)
 		case 2:
-			team2 = append(team2, resp)
+			teams.Team2 = append(teams.Team2, resp)
+		case 0:
+			teams.Unassigned = append(teams.Unassigned, resp)
 		default:
 			logger.Warn().Str("user_id", row.Id).Int("team", row.Team).Msg("unexpected team value")
 		}
 	}
+	return teams, nil
+}
 
-	return httpx.Write(w, http.StatusOK, models.TeamsByMatchIdResponse{
-		Team1: team1,
-		Team2: team2,
-	})
+// RebalanceMatch godoc
+// @Summary      Rééquilibre les équipes d'un match
+// @Description  Répartit à nouveau les joueurs d'un match complet (Valide) en deux équipes dont les moyennes d'ELO sur le terrain sont les plus proches possible. Réservé au créateur, avant le début du match.
+// @Tags         match
+// @Produce      json
+// @Param        id   path      string  true  "ID du match"
+// @Success      200  {object}  models.TeamsByMatchIdResponse  "Nouvelles équipes"
+// @Failure      400  {object}  models.Error  "ID manquant, ou match pas complet ou déjà commencé"
+// @Failure      401  {object}  models.Error  "Utilisateur non autorisé"
+// @Failure      403  {object}  models.Error  "L'utilisateur n'est pas le créateur du match"
+// @Failure      404  {object}  models.Error  "Match non trouvé"
+// @Failure      500  {object}  models.Error  "Erreur serveur"
+// @Router       /match/{id}/rebalance [post]
+// @Security     BearerAuth
+func (s *Service) RebalanceMatch(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+	baseLogger := log.With().
+		Str("method", "RebalanceMatch").
+		Str("user_id", ai.UserID).
+		Logger()
+
+	if !ai.IsConnected {
+		baseLogger.Warn().Msg("unauthorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+
+	ctx := r.Context()
+	matchID := chi.URLParam(r, "id")
+	logger := baseLogger.With().Str("match_id", matchID).Logger()
+
+	if matchID == "" {
+		logger.Warn().Msg("missing match ID")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMissingParameter, "id")
+	}
+
+	if err := s.db.RebalanceMatch(ctx, matchID, ai.UserID, s.ratings.NewRanking, s.clock.Now()); err != nil {
+		return writeError(w, r, logger, err, "db rebalance match failed")
+	}
+
+	teams, err := s.buildTeamsResponse(ctx, logger, matchID)
+	if err != nil {
+		logger.Error().Err(err).Msg("fetching users with team failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+
+	logger.Info().Msg("match teams rebalanced")
+	return httpx.Write(w, http.StatusOK, teams)
 }
@@ -6,9 +6,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
@@ -187,6 +189,8 @@ func Test_MatchLifecycle(t *testing.T) {
 	updateScore(u3, 2, 2, http.StatusOK)      // team2 propose 2-2
 
 	// === MAIL ASSERT: aucun mail "result" pendant le désaccord
+	_, err = s.outboxDispatcher().Drain(context.Background())
+	require.NoError(t, err)
 	require.Equal(t, 0, mockMailer.GetSentCounts("result"),
 		"no result email should be sent before consensus")
 
@@ -206,6 +210,8 @@ func Test_MatchLifecycle(t *testing.T) {
 	ums, err := s.db.GetUserMatchesByMatchID(ctx, matchID)
 	require.NoError(t, err)
 	expectedEmails := len(ums) // ici: 4 joueurs
+	_, err = s.outboxDispatcher().Drain(ctx)
+	require.NoError(t, err)
 	require.Equal(t, expectedEmails, mockMailer.GetSentCounts("result"),
 		"result emails should be sent to all participants when consensus is reached")
 
@@ -318,7 +324,237 @@ func Test_MatchLifecycle(t *testing.T) {
 		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
 
 		// MAIL ASSERT: compteur inchangé
+		_, err = s.outboxDispatcher().Drain(ctx)
+		require.NoError(t, err)
 		require.Equal(t, expectedEmails, mockMailer.GetSentCounts("result"),
 			"no extra result email after consensus")
 	}
 }
+
+func Test_JoinMatch_ConcurrentJoins(t *testing.T) {
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() { _ = cleanup() }()
+
+	court := models.NewDBCourtFixture()
+	creator := models.NewDBUsersFixture().
+		WithUsername("u_creator").
+		WithEmail("creator@example.com")
+
+	users := make([]models.DBUsers, 8)
+	for i := range users {
+		users[i] = models.NewDBUsersFixture().
+			WithUsername(fmt.Sprintf("u_%d", i)).
+			WithEmail(fmt.Sprintf("u%d@example.com", i))
+	}
+
+	// Match de 4 joueurs, tous les joueurs visent l'équipe 1
+	teamRace := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithParticipantNber(4).
+		WithCurrentState(models.ManqueJoueur).
+		WithCreatorId(creator.Id)
+	// Match de 4 joueurs rempli d'un coup par 4 joueurs répartis sur les deux équipes
+	fillRace := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithParticipantNber(4).
+		WithCurrentState(models.ManqueJoueur).
+		WithCreatorId(creator.Id)
+
+	s.loadFixtures(DBFixtures{
+		Courts:  []models.DBCourt{court},
+		Users:   append([]models.DBUsers{creator}, users...),
+		Matches: []models.DBMatches{teamRace, fillRace},
+	})
+
+	join := func(matchID string, userID string, team int) int {
+		b, _ := json.Marshal(models.JoinMatchRequest{Team: team})
+		r := httptest.NewRequest("POST", "/match/join/"+matchID, bytes.NewReader(b))
+		routeCtx := chi.NewRouteContext()
+		routeCtx.URLParams.Add("id", matchID)
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeCtx))
+		w := httptest.NewRecorder()
+		if err := s.JoinMatch(w, r, models.AuthInfo{IsConnected: true, UserID: userID}); err != nil {
+			return http.StatusInternalServerError
+		}
+		return w.Result().StatusCode
+	}
+
+	countCodes := func(codes []int) map[int]int {
+		res := map[int]int{}
+		for _, c := range codes {
+			res[c]++
+		}
+		return res
+	}
+
+	// === 1) 8 joueurs rejoignent la même équipe en même temps ===
+	{
+		codes := make([]int, len(users))
+		var wg sync.WaitGroup
+		for i, u := range users {
+			wg.Add(1)
+			go func(i int, userID string) {
+				defer wg.Done()
+				codes[i] = join(teamRace.Id, userID, 1)
+			}(i, u.Id)
+		}
+		wg.Wait()
+
+		got := countCodes(codes)
+		require.Equal(t, 2, got[http.StatusOK], "only half of the seats belong to team 1")
+		require.Equal(t, len(users)-2, got[http.StatusBadRequest])
+
+		count, err := s.db.CountUsersByMatchAndTeam(context.Background(), teamRace.Id, 1)
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+
+		m, err := s.db.GetMatchById(context.Background(), teamRace.Id)
+		require.NoError(t, err)
+		require.Equal(t, models.ManqueJoueur, m.CurrentState)
+	}
+
+	// === 2) 4 joueurs remplissent le match en même temps ===
+	{
+		codes := make([]int, 4)
+		var wg sync.WaitGroup
+		for i, u := range users[:4] {
+			wg.Add(1)
+			go func(i int, userID string) {
+				defer wg.Done()
+				codes[i] = join(fillRace.Id, userID, i%2+1)
+			}(i, u.Id)
+		}
+		wg.Wait()
+
+		require.Equal(t, map[int]int{http.StatusOK: 4}, countCodes(codes))
+
+		count, err := s.db.CountUsersByMatch(context.Background(), fillRace.Id)
+		require.NoError(t, err)
+		require.Equal(t, 4, count)
+
+		m, err := s.db.GetMatchById(context.Background(), fillRace.Id)
+		require.NoError(t, err)
+		require.Equal(t, models.Valide, m.CurrentState, "the last join must move the match to Valide")
+	}
+
+	// === 3) Le même joueur rejoint deux fois en même temps ===
+	{
+		solo := models.NewDBMatchesFixture().
+			WithCourtId(court.Id).
+			WithParticipantNber(4).
+			WithCurrentState(models.ManqueJoueur).
+			WithCreatorId(creator.Id)
+		s.loadFixtures(DBFixtures{Matches: []models.DBMatches{solo}})
+
+		codes := make([]int, 2)
+		var wg sync.WaitGroup
+		for i := range codes {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				codes[i] = join(solo.Id, users[7].Id, i+1)
+			}(i)
+		}
+		wg.Wait()
+
+		require.Equal(t, map[int]int{http.StatusOK: 1, http.StatusConflict: 1}, countCodes(codes))
+
+		count, err := s.db.CountUsersByMatch(context.Background(), solo.Id)
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+	}
+}
+
+func Test_UpdateMatchScore_ConcurrentVotes(t *testing.T) {
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() { _ = cleanup() }()
+
+	court := models.NewDBCourtFixture()
+	users := make([]models.DBUsers, 4)
+	for i := range users {
+		users[i] = models.NewDBUsersFixture().
+			WithUsername(fmt.Sprintf("u_%d", i)).
+			WithEmail(fmt.Sprintf("u%d@example.com", i))
+	}
+
+	// Match de 4 joueurs : users[0] et users[1] en équipe 1, users[2] et users[3] en équipe 2
+	newMatch := func() models.DBMatches {
+		return models.NewDBMatchesFixture().
+			WithCourtId(court.Id).
+			WithParticipantNber(4).
+			WithCurrentState(models.ManqueScore).
+			WithCreatorId(users[0].Id)
+	}
+	teammates := newMatch()
+	opponents := newMatch()
+
+	var userMatches []models.DBUserMatch
+	for _, m := range []models.DBMatches{teammates, opponents} {
+		for i, u := range users {
+			userMatches = append(userMatches, models.NewDBUserMatchFixture().WithUserId(u.Id).WithMatchId(m.Id).WithTeam(i/2+1))
+		}
+	}
+	s.loadFixtures(DBFixtures{
+		Courts:      []models.DBCourt{court},
+		Users:       users,
+		Matches:     []models.DBMatches{teammates, opponents},
+		UserMatches: userMatches,
+	})
+
+	vote := func(matchID string, userID string, score1, score2 int) int {
+		b, _ := json.Marshal(models.UpdateScoreRequest{Score1: score1, Score2: score2})
+		r := httptest.NewRequest("PATCH", "/score/match/"+matchID, bytes.NewReader(b))
+		routeCtx := chi.NewRouteContext()
+		routeCtx.URLParams.Add("id", matchID)
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeCtx))
+		w := httptest.NewRecorder()
+		if err := s.UpdateMatchScore(w, r, models.AuthInfo{IsConnected: true, UserID: userID}); err != nil {
+			return http.StatusInternalServerError
+		}
+		return w.Result().StatusCode
+	}
+
+	// === 1) Les deux joueurs de l'équipe 1 votent en même temps ===
+	{
+		codes := make([]int, 2)
+		var wg sync.WaitGroup
+		for i, u := range users[:2] {
+			wg.Add(1)
+			go func(i int, userID string) {
+				defer wg.Done()
+				codes[i] = vote(teammates.Id, userID, 3, i)
+			}(i, u.Id)
+		}
+		wg.Wait()
+
+		require.ElementsMatch(t, []int{http.StatusOK, http.StatusBadRequest}, codes, "only one vote per team and round")
+	}
+
+	// === 2) Les deux équipes votent des scores différents en même temps ===
+	{
+		codes := make([]int, 2)
+		var wg sync.WaitGroup
+		for i, u := range []models.DBUsers{users[0], users[2]} {
+			wg.Add(1)
+			go func(i int, userID string) {
+				defer wg.Done()
+				codes[i] = vote(opponents.Id, userID, 3, i)
+			}(i, u.Id)
+		}
+		wg.Wait()
+
+		require.Equal(t, []int{http.StatusOK, http.StatusOK}, codes)
+
+		m, err := s.db.GetMatchById(context.Background(), opponents.Id)
+		require.NoError(t, err)
+		require.True(t, m.Disputed, "the second vote must see the first one and open the dispute")
+		for _, u := range []models.DBUsers{users[0], users[2]} {
+			v, err := s.db.GetMatchScoreVote(context.Background(), opponents.Id, u.Id)
+			require.NoError(t, err)
+			require.NotNil(t, v)
+			require.Equal(t, 0, v.Round, "both votes belong to the first round")
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"PLIC/httpx"
+	"PLIC/i18n"
+	"PLIC/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// sseHeartbeat keeps idle streams open through proxies.
+const sseHeartbeat = 25 * time.Second
+
+// MatchEvents godoc
+// @Summary      Suit un match en temps réel
+// @Description  Flux Server-Sent Events des évènements du match : joueur arrivé (player_joined) ou parti (player_left), changement d'état (state_changed), vote de score (vote_submitted) et résultat validé (finalized). Chaque évènement est envoyé avec son type comme nom et un models.MatchEvent en JSON comme données.
+// @Description  Le flux est fermé si le client prend trop de retard : il doit alors recharger le match et se réabonner. Disponible uniquement sur le serveur HTTP local, pas en mode Lambda. Les transitions faites par le planificateur ne sont pas diffusées.
+// @Tags         match
+// @Produce      text/event-stream
+// @Param        id   path      string  true  "ID du match"
+// @Success      200  {object}  models.MatchEvent  "Flux d'évènements"
+// @Failure      400  {object}  models.Error  "ID manquant"
+// @Failure      401  {object}  models.Error  "Utilisateur non autorisé"
+// @Failure      403  {object}  models.Error  "Match réservé aux amis ou sur invitation"
+// @Failure      404  {object}  models.Error  "Match non trouvé"
+// @Failure      500  {object}  models.Error  "Erreur serveur"
+// @Router       /match/{id}/events [get]
+// @Security     BearerAuth
+func (s *Service) MatchEvents(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+	baseLogger := log.With().
+		Str("method", "MatchEvents").
+		Str("user_id", ai.UserID).
+		Logger()
+
+	if !ai.IsConnected {
+		baseLogger.Warn().Msg("unauthorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+
+	ctx := r.Context()
+	matchID := chi.URLParam(r, "id")
+	logger := baseLogger.With().Str("match_id", matchID).Logger()
+
+	if matchID == "" {
+		logger.Warn().Msg("missing match ID")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMissingParameter, "id")
+	}
+
+	match, err := s.db.GetMatchById(ctx, matchID)
+	if err != nil {
+		logger.Error().Err(err).Msg("db get match failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+	if match == nil {
+		logger.Warn().Msg("match not found")
+		return httpx.WriteError(w, r, http.StatusNotFound, i18n.ErrMatchNotFound)
+	}
+
+	visible, err := s.db.IsMatchVisibleTo(ctx, matchID, ai.UserID)
+	if err != nil {
+		logger.Error().Err(err).Msg("db check match visibility failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+	if !visible {
+		logger.Warn().Msg("match not visible")
+		return httpx.WriteError(w, r, http.StatusForbidden, i18n.ErrMatchNotVisible)
+	}
+
+	stream, err := s.events.Subscribe(ctx, matchID)
+	if err != nil {
+		logger.Error().Err(err).Msg("subscribe to match events failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprint(w, ": connected\n\n"); err != nil {
+		return nil
+	}
+	if err := rc.Flush(); err != nil {
+		logger.Error().Err(err).Msg("streaming not supported")
+		return nil
+	}
+	logger.Info().Msg("following match events")
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info().Msg("client left match events")
+			return nil
+		case event, ok := <-stream:
+			if !ok {
+				logger.Warn().Msg("match events stream closed")
+				return nil
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				logger.Error().Err(err).Msg("marshal match event failed")
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return nil
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return nil
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return nil
+		}
+	}
+}
+
+// publishMatchEvent pushes event to the clients following its match. Failures are
+// only logged: the change is already saved and clients can still refetch the match.
+func (s *Service) publishMatchEvent(ctx context.Context, logger zerolog.Logger, event models.MatchEvent) {
+	if event.At.IsZero() {
+		event.At = s.clock.Now()
+	}
+	if err := s.events.Publish(ctx, event); err != nil {
+		logger.Error().Err(err).Str("event", string(event.Type)).Msg("publish match event failed")
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"PLIC/models"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_MatchEvents(t *testing.T) {
+	type testCase struct {
+		name         string
+		param        string
+		auth         models.AuthInfo
+		expectedCode int
+		errorMsg     string
+	}
+
+	court := models.NewDBCourtFixture()
+	creator := models.NewDBUsersFixture()
+	stranger := models.NewDBUsersFixture().WithUsername("stranger").WithEmail("stranger@gmail.com")
+	match := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(creator.Id).
+		WithVisibility(models.VisibilityInviteOnly)
+
+	fixtures := DBFixtures{
+		Courts:  []models.DBCourt{court},
+		Users:   []models.DBUsers{creator, stranger},
+		Matches: []models.DBMatches{match},
+	}
+
+	testCases := []testCase{
+		{
+			name:         "Not connected -> 401",
+			param:        match.Id,
+			auth:         models.AuthInfo{},
+			expectedCode: http.StatusUnauthorized,
+			errorMsg:     "unauthorized",
+		},
+		{
+			name:         "Unknown match -> 404",
+			param:        "unknown",
+			auth:         models.AuthInfo{IsConnected: true, UserID: creator.Id},
+			expectedCode: http.StatusNotFound,
+			errorMsg:     "match_not_found",
+		},
+		{
+			name:         "Invite-only match of someone else -> 403",
+			param:        match.Id,
+			auth:         models.AuthInfo{IsConnected: true, UserID: stranger.Id},
+			expectedCode: http.StatusForbidden,
+			errorMsg:     "match_not_visible",
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() { _ = cleanup() }()
+			s.loadFixtures(fixtures)
+
+			r := httptest.NewRequest("GET", "/match/"+c.param+"/events", nil)
+			routeCtx := chi.NewRouteContext()
+			routeCtx.URLParams.Add("id", c.param)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeCtx))
+			w := httptest.NewRecorder()
+
+			err := s.MatchEvents(w, r, c.auth)
+			require.NoError(t, err)
+
+			resp := w.Result()
+			defer func(Body io.ReadCloser) { _ = Body.Close() }(resp.Body)
+			require.Equal(t, c.expectedCode, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.Contains(t, string(body), c.errorMsg)
+		})
+	}
+}
+
+func Test_MatchEvents_Stream(t *testing.T) {
+	court := models.NewDBCourtFixture()
+	creator := models.NewDBUsersFixture()
+	joiner := models.NewDBUsersFixture().WithUsername("joiner").WithEmail("joiner@gmail.com")
+	match := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(creator.Id).
+		WithParticipantNber(2).
+		WithCurrentState(models.ManqueJoueur)
+
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() { _ = cleanup() }()
+	s.loadFixtures(DBFixtures{
+		Courts:  []models.DBCourt{court},
+		Users:   []models.DBUsers{creator, joiner},
+		Matches: []models.DBMatches{match},
+		UserMatches: []models.DBUserMatch{
+			models.NewDBUserMatchFixture().WithUserId(creator.Id).WithMatchId(match.Id).WithTeam(1),
+		},
+	})
+
+	withMatchID := func(r *http.Request) *http.Request {
+		routeCtx := chi.NewRouteContext()
+		routeCtx.URLParams.Add("id", match.Id)
+		return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeCtx))
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = s.MatchEvents(w, withMatchID(r), models.AuthInfo{IsConnected: true, UserID: creator.Id})
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func(Body io.ReadCloser) { _ = Body.Close() }(resp.Body)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	// The comment sent on connection means the subscription is in place.
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, ": connected\n", line)
+
+	r := httptest.NewRequest("POST", "/join/match/"+match.Id, bytes.NewBufferString(`{"team":2}`))
+	w := httptest.NewRecorder()
+	require.NoError(t, s.JoinMatch(w, withMatchID(r), models.AuthInfo{IsConnected: true, UserID: joiner.Id}))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	readEvent := func() (string, models.MatchEvent) {
+		var name string
+		var event models.MatchEvent
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+			case line == "" && name != "":
+				return name, event
+			}
+		}
+	}
+
+	name, event := readEvent()
+	require.Equal(t, string(models.MatchEventPlayerJoined), name)
+	require.Equal(t, match.Id, event.MatchID)
+	require.Equal(t, joiner.Id, event.UserID)
+
+	name, event = readEvent()
+	require.Equal(t, string(models.MatchEventStateChanged), name)
+	require.Equal(t, models.Valide, event.State)
+}
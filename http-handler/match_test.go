@@ -1,6 +1,7 @@
 package main
 
 import (
+	"PLIC/i18n"
 	"PLIC/mailer"
 	"PLIC/models"
 	"bytes"
@@ -9,35 +10,50 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/require"
 )
 
 func Test_GetMatchByID(t *testing.T) {
 	type expected struct {
-		code  int
-		found bool
+		code       int
+		found      bool
+		eloChanges []models.MatchEloChange
 	}
 
 	type testCase struct {
 		name     string
 		fixtures DBFixtures
 		param    string
+		viewer   string
 		expected expected
 	}
 	court := models.NewDBCourtFixture()
 
 	user := models.NewDBUsersFixture()
+	stranger := models.NewDBUsersFixture().WithUsername("stranger").WithEmail("stranger@test.com")
 
 	match := models.NewDBMatchesFixture().
 		WithCourtId(court.Id).
 		WithCreatorId(user.Id)
 
+	friendsOnly := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(user.Id).
+		WithVisibility(models.VisibilityFriends)
+
+	finished := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(user.Id).
+		WithCurrentState(models.Termine)
+
 	testCases := []testCase{
 		{
 			name: "Match found",
@@ -57,6 +73,74 @@ func Test_GetMatchByID(t *testing.T) {
 				found: true,
 			},
 		},
+		{
+			name: "Finished match shows each player's Elo delta",
+			fixtures: DBFixtures{
+				Courts:  []models.DBCourt{court},
+				Matches: []models.DBMatches{finished},
+				Users:   []models.DBUsers{user},
+				UserMatches: []models.DBUserMatch{
+					models.NewDBUserMatchFixture().
+						WithUserId(user.Id).
+						WithMatchId(finished.Id),
+				},
+				History: []models.DBRankingHistory{
+					models.NewDBRankingHistoryFixture().
+						WithUserId(user.Id).
+						WithCourtId(court.Id).
+						WithMatchId(finished.Id).
+						WithElo(1000, 1016),
+				},
+			},
+			param: finished.Id,
+			expected: expected{
+				code:  200,
+				found: true,
+				eloChanges: []models.MatchEloChange{
+					{UserID: user.Id, EloBefore: 1000, EloAfter: 1016, Delta: 16},
+				},
+			},
+		},
+		{
+			name: "Friends-only match, viewer is not a friend -> 403",
+			fixtures: DBFixtures{
+				Courts:  []models.DBCourt{court},
+				Matches: []models.DBMatches{friendsOnly},
+				Users:   []models.DBUsers{user, stranger},
+			},
+			param:    friendsOnly.Id,
+			viewer:   stranger.Id,
+			expected: expected{code: http.StatusForbidden},
+		},
+		{
+			name: "Friends-only match, viewer is a friend of the creator",
+			fixtures: DBFixtures{
+				Courts:  []models.DBCourt{court},
+				Matches: []models.DBMatches{friendsOnly},
+				Users:   []models.DBUsers{user, stranger},
+				Friendships: []models.DBFriendship{
+					models.NewDBFriendshipFixture().
+						WithRequesterId(stranger.Id).
+						WithAddresseeId(user.Id).
+						WithStatus(models.FriendshipAccepted),
+				},
+			},
+			param:  friendsOnly.Id,
+			viewer: stranger.Id,
+			expected: expected{
+				code:  200,
+				found: true,
+			},
+		},
+		{
+			name: "Unknown match -> 404",
+			fixtures: DBFixtures{
+				Courts: []models.DBCourt{court},
+				Users:  []models.DBUsers{user},
+			},
+			param:    uuid.NewString(),
+			expected: expected{code: http.StatusNotFound},
+		},
 	}
 
 	for _, c := range testCases {
@@ -78,7 +162,7 @@ func Test_GetMatchByID(t *testing.T) {
 			w := httptest.NewRecorder()
 			err := s.GetMatchByID(w, r, models.AuthInfo{
 				IsConnected: true,
-				UserID:      user.Id,
+				UserID:      lo.Ternary(c.viewer == "", user.Id, c.viewer),
 			})
 			require.NoError(t, err)
 
@@ -94,7 +178,8 @@ func Test_GetMatchByID(t *testing.T) {
 				var res models.MatchResponse
 				err = json.Unmarshal(body, &res)
 				require.NoError(t, err)
-				require.Equal(t, match.Id, res.Id)
+				require.Equal(t, c.param, res.Id)
+				require.Equal(t, c.expected.eloChanges, res.EloChanges)
 			}
 		})
 	}
@@ -198,6 +283,7 @@ func Test_GetMatchesByCourtId(t *testing.T) {
 		code     int
 		response bool
 		errorMsg string
+		ids      []string
 	}
 
 	type testCase struct {
@@ -212,6 +298,7 @@ func Test_GetMatchesByCourtId(t *testing.T) {
 	court2 := models.NewDBCourtFixture()
 
 	user := models.NewDBUsersFixture()
+	stranger := models.NewDBUsersFixture().WithUsername("stranger").WithEmail("stranger@test.com")
 
 	match1 := models.NewDBMatchesFixture().
 		WithCourtId(court1.Id).
@@ -223,6 +310,11 @@ func Test_GetMatchesByCourtId(t *testing.T) {
 		WithSport(models.Basket).
 		WithCurrentState(models.Termine).
 		WithCreatorId(user.Id)
+	friendsOnly := models.NewDBMatchesFixture().
+		WithCourtId(court1.Id).
+		WithSport(models.Foot).
+		WithCreatorId(user.Id).
+		WithVisibility(models.VisibilityFriends)
 
 	testCases := []testCase{
 		{
@@ -231,7 +323,7 @@ func Test_GetMatchesByCourtId(t *testing.T) {
 			auth:  models.AuthInfo{IsConnected: true},
 			expected: expected{
 				code:     http.StatusBadRequest,
-				errorMsg: "missing courtId in url params",
+				errorMsg: "Paramètre manquant : courtId",
 			},
 		},
 		{
@@ -240,7 +332,7 @@ func Test_GetMatchesByCourtId(t *testing.T) {
 			auth:  models.AuthInfo{IsConnected: false},
 			expected: expected{
 				code:     http.StatusUnauthorized,
-				errorMsg: "not authorized",
+				errorMsg: "unauthorized",
 			},
 		},
 		{
@@ -271,6 +363,36 @@ func Test_GetMatchesByCourtId(t *testing.T) {
 				response: true,
 			},
 		},
+		{
+			name: "Friends-only match hidden from a non-friend",
+			fixtures: DBFixtures{
+				Users:   []models.DBUsers{user, stranger},
+				Courts:  []models.DBCourt{court1},
+				Matches: []models.DBMatches{match1, friendsOnly},
+			},
+			param: court1.Id,
+			auth:  models.AuthInfo{IsConnected: true, UserID: stranger.Id},
+			expected: expected{
+				code:     http.StatusOK,
+				response: true,
+				ids:      []string{match1.Id},
+			},
+		},
+		{
+			name: "Friends-only match shown to its creator",
+			fixtures: DBFixtures{
+				Users:   []models.DBUsers{user, stranger},
+				Courts:  []models.DBCourt{court1},
+				Matches: []models.DBMatches{match1, friendsOnly},
+			},
+			param: court1.Id,
+			auth:  models.AuthInfo{IsConnected: true, UserID: user.Id},
+			expected: expected{
+				code:     http.StatusOK,
+				response: true,
+				ids:      []string{match1.Id, friendsOnly.Id},
+			},
+		},
 	}
 
 	for _, c := range testCases {
@@ -312,6 +434,9 @@ func Test_GetMatchesByCourtId(t *testing.T) {
 				var matches []models.MatchResponse
 				err = json.Unmarshal(body, &matches)
 				require.NoError(t, err)
+				if c.expected.ids != nil {
+					require.ElementsMatch(t, c.expected.ids, lo.Map(matches, func(m models.MatchResponse, _ int) string { return m.Id }))
+				}
 			} else {
 				require.Contains(t, string(body), c.expected.errorMsg)
 			}
@@ -473,6 +598,20 @@ func Test_CreateMatch(t *testing.T) {
 				statusCode: http.StatusUnauthorized,
 			},
 		},
+		{
+			name: "Unverified email",
+			auth: models.AuthInfo{IsConnected: true, UserID: user.Id},
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{user.WithUnverifiedEmail()},
+			},
+			insertCourt: true,
+			param: models.NewMatchRequestFixture().
+				WithCourtId(court.Id).
+				WithSport(sport),
+			expected: expected{
+				statusCode: http.StatusForbidden,
+			},
+		},
 		{
 			name: "Court does not exist",
 			auth: models.AuthInfo{IsConnected: true, UserID: user.Id},
@@ -487,6 +626,36 @@ func Test_CreateMatch(t *testing.T) {
 				statusCode: http.StatusBadRequest,
 			},
 		},
+		{
+			name: "Date in the past",
+			auth: models.AuthInfo{IsConnected: true, UserID: user.Id},
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{user},
+			},
+			insertCourt: true,
+			param: models.NewMatchRequestFixture().
+				WithCourtId(court.Id).
+				WithSport(sport).
+				WithDate(time.Now().Add(-time.Hour)),
+			expected: expected{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "Odd number of participants",
+			auth: models.AuthInfo{IsConnected: true, UserID: user.Id},
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{user},
+			},
+			insertCourt: true,
+			param: models.NewMatchRequestFixture().
+				WithCourtId(court.Id).
+				WithSport(sport).
+				WithNbreParticipant(3),
+			expected: expected{
+				statusCode: http.StatusBadRequest,
+			},
+		},
 		{
 			name: "Successful match creation (keeps existing ranking as-is)",
 			auth: models.AuthInfo{IsConnected: true, UserID: user.Id},
@@ -755,6 +924,8 @@ func Test_UpdateMatchScore_ConsensusAndTeamRules(t *testing.T) {
 						sentResultSoFar += len(ums)
 					}
 				}
+				_, err = s.outboxDispatcher().Drain(context.Background())
+				require.NoError(t, err)
 				require.Equal(t, sentResultSoFar, mockMailer.GetSentCounts("result"),
 					"unexpected number of result emails after step %d of %q", stepIdx, tc.name)
 
@@ -868,7 +1039,7 @@ func Test_JoinMatch(t *testing.T) {
 			expected: expected{
 				bodyJSON: `{"team": 1}`,
 				code:     http.StatusBadRequest,
-				errorMsg: "missing match ID",
+				errorMsg: "Paramètre manquant : id",
 			},
 		},
 		{
@@ -878,7 +1049,7 @@ func Test_JoinMatch(t *testing.T) {
 			expected: expected{
 				bodyJSON: `{"team": 1}`,
 				code:     http.StatusUnauthorized,
-				errorMsg: "not authorized",
+				errorMsg: "unauthorized",
 			},
 		},
 		{
@@ -898,7 +1069,22 @@ func Test_JoinMatch(t *testing.T) {
 			expected: expected{
 				bodyJSON: `{"team": 1}`,
 				code:     http.StatusConflict,
-				errorMsg: "user already joined",
+				errorMsg: "already_in_match",
+			},
+		},
+		{
+			name: "Unverified email",
+			fixtures: DBFixtures{
+				Courts:  []models.DBCourt{court},
+				Matches: []models.DBMatches{match},
+				Users:   []models.DBUsers{user.WithUnverifiedEmail()},
+			},
+			param: match.Id,
+			auth:  models.AuthInfo{IsConnected: true, UserID: user.Id},
+			expected: expected{
+				bodyJSON: `{"team": 1}`,
+				code:     http.StatusForbidden,
+				errorMsg: "email_not_verified",
 			},
 		},
 		{
@@ -919,7 +1105,7 @@ func Test_JoinMatch(t *testing.T) {
 			expected: expected{
 				bodyJSON: `{"team": 1}`,
 				code:     http.StatusBadRequest,
-				errorMsg: "this team is full",
+				errorMsg: "team_full",
 			},
 		},
 		{
@@ -954,6 +1140,45 @@ func Test_JoinMatch(t *testing.T) {
 				wantElo:      &defaultElo,
 			},
 		},
+		{
+			name: "No team on a manual match -> 400",
+			fixtures: DBFixtures{
+				Courts:  []models.DBCourt{court},
+				Matches: []models.DBMatches{match},
+				Users:   []models.DBUsers{user},
+			},
+			param: match.Id,
+			auth:  models.AuthInfo{IsConnected: true, UserID: user.Id},
+			expected: expected{
+				bodyJSON: `{}`,
+				code:     http.StatusBadRequest,
+				errorMsg: "invalid_team",
+			},
+		},
+		{
+			name: "Auto balance match, no team -> fills the match, teams assigned",
+			fixtures: DBFixtures{
+				Courts:  []models.DBCourt{court},
+				Matches: []models.DBMatches{match.WithCreatorId(teammate.Id).WithAutoBalance(true)},
+				Users:   []models.DBUsers{user, teammate},
+				UserMatches: []models.DBUserMatch{
+					models.NewDBUserMatchFixture().
+						WithUserId(teammate.Id).
+						WithMatchId(match.Id).
+						WithTeam(0),
+				},
+			},
+			param: match.Id,
+			auth:  models.AuthInfo{IsConnected: true, UserID: user.Id},
+			expected: expected{
+				bodyJSON:     `{}`,
+				code:         http.StatusOK,
+				checkJoined:  true,
+				wantState:    ptr(models.Valide),
+				checkRanking: true,
+				wantElo:      &defaultElo,
+			},
+		},
 		{
 			name: "Ranking already exists -> not overwritten",
 			fixtures: DBFixtures{
@@ -1064,10 +1289,12 @@ func Test_StartMatch(t *testing.T) {
 
 	user := models.NewDBUsersFixture()
 	court := models.NewDBCourtFixture()
+	plannedDate := time.Now().Add(2 * time.Hour)
 	matchValide := models.NewDBMatchesFixture().
 		WithCourtId(court.Id).
 		WithCurrentState(models.Valide).
-		WithCreatorId(user.Id)
+		WithCreatorId(user.Id).
+		WithDate(plannedDate)
 	matchWrongState := models.NewDBMatchesFixture().
 		WithCourtId(court.Id).
 		WithCurrentState(models.ManqueJoueur).
@@ -1173,7 +1400,7 @@ func Test_StartMatch(t *testing.T) {
 				require.NoError(t, err)
 				require.NotNil(t, m)
 				require.Equal(t, c.expected.finalState, m.CurrentState)
-				require.WithinDuration(t, time.Now(), m.Date, time.Minute)
+				require.WithinDuration(t, plannedDate, m.Date, time.Millisecond)
 			}
 		})
 	}
@@ -1539,6 +1766,10 @@ func Test_GetTeamsByMatchId(t *testing.T) {
 
 	court := models.NewDBCourtFixture()
 	match := models.NewDBMatchesFixture().WithCourtId(court.Id).WithCreatorId(user1.Id)
+	inviteOnly := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(user1.Id).
+		WithVisibility(models.VisibilityInviteOnly)
 
 	tests := []testCase{
 		{
@@ -1592,6 +1823,36 @@ func Test_GetTeamsByMatchId(t *testing.T) {
 				check: true,
 			},
 		},
+		{
+			name:    "Invite-only match, viewer not invited -> 403",
+			auth:    models.AuthInfo{IsConnected: true, UserID: user3.Id},
+			paramID: inviteOnly.Id,
+			fixtures: DBFixtures{
+				Courts:  []models.DBCourt{court},
+				Matches: []models.DBMatches{inviteOnly},
+				Users:   []models.DBUsers{user1, user2, user3},
+				UserMatches: []models.DBUserMatch{
+					models.NewDBUserMatchFixture().WithUserId(user1.Id).WithMatchId(inviteOnly.Id).WithTeam(1),
+					models.NewDBUserMatchFixture().WithUserId(user2.Id).WithMatchId(inviteOnly.Id).WithTeam(2),
+				},
+				Friendships: []models.DBFriendship{
+					models.NewDBFriendshipFixture().
+						WithRequesterId(user1.Id).
+						WithAddresseeId(user3.Id).
+						WithStatus(models.FriendshipAccepted),
+				},
+			},
+			expected: expected{code: http.StatusForbidden},
+		},
+		{
+			name:    "Unknown match -> 404",
+			auth:    models.AuthInfo{IsConnected: true, UserID: user1.Id},
+			paramID: uuid.NewString(),
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{user1},
+			},
+			expected: expected{code: http.StatusNotFound},
+		},
 	}
 
 	for _, tc := range tests {
@@ -1644,3 +1905,920 @@ func Test_GetTeamsByMatchId(t *testing.T) {
 		})
 	}
 }
+
+func Test_LeaveMatch(t *testing.T) {
+	type expected struct {
+		code      int
+		errorMsg  string
+		cancelled bool
+		state     models.MatchState
+		creatorID string
+	}
+
+	type testCase struct {
+		name     string
+		fixtures DBFixtures
+		auth     models.AuthInfo
+		expected expected
+	}
+
+	court := models.NewDBCourtFixture()
+	creator := models.NewDBUsersFixture().WithUsername("creator").WithEmail("creator@example.com")
+	player := models.NewDBUsersFixture().WithUsername("player").WithEmail("player@example.com")
+	other := models.NewDBUsersFixture().WithUsername("other").WithEmail("other@example.com")
+
+	match := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithParticipantNber(2).
+		WithCreatorId(creator.Id)
+
+	now := time.Now()
+	seat := func(user models.DBUsers, team int, joinedAt time.Time) models.DBUserMatch {
+		return models.NewDBUserMatchFixture().
+			WithUserId(user.Id).
+			WithMatchId(match.Id).
+			WithTeam(team).
+			WithCreatedAt(joinedAt)
+	}
+
+	testCases := []testCase{
+		{
+			name: "Player leaves a Valide match -> back to Manque joueur",
+			fixtures: DBFixtures{
+				Courts:      []models.DBCourt{court},
+				Users:       []models.DBUsers{creator, player},
+				Matches:     []models.DBMatches{match.WithCurrentState(models.Valide)},
+				UserMatches: []models.DBUserMatch{seat(creator, 1, now), seat(player, 2, now.Add(time.Minute))},
+			},
+			auth: models.AuthInfo{IsConnected: true, UserID: player.Id},
+			expected: expected{
+				code:      http.StatusOK,
+				state:     models.ManqueJoueur,
+				creatorID: creator.Id,
+			},
+		},
+		{
+			name: "Creator leaves -> oldest remaining player becomes creator",
+			fixtures: DBFixtures{
+				Courts:  []models.DBCourt{court},
+				Users:   []models.DBUsers{creator, player, other},
+				Matches: []models.DBMatches{match.WithParticipantNber(4).WithCurrentState(models.ManqueJoueur)},
+				UserMatches: []models.DBUserMatch{
+					seat(creator, 1, now),
+					seat(other, 2, now.Add(2*time.Minute)),
+					seat(player, 2, now.Add(time.Minute)),
+				},
+			},
+			auth: models.AuthInfo{IsConnected: true, UserID: creator.Id},
+			expected: expected{
+				code:      http.StatusOK,
+				state:     models.ManqueJoueur,
+				creatorID: player.Id,
+			},
+		},
+		{
+			name: "Creator alone leaves -> match cancelled",
+			fixtures: DBFixtures{
+				Courts:      []models.DBCourt{court},
+				Users:       []models.DBUsers{creator},
+				Matches:     []models.DBMatches{match.WithCurrentState(models.ManqueJoueur)},
+				UserMatches: []models.DBUserMatch{seat(creator, 1, now)},
+			},
+			auth: models.AuthInfo{IsConnected: true, UserID: creator.Id},
+			expected: expected{
+				code:      http.StatusOK,
+				cancelled: true,
+				state:     models.Annule,
+				creatorID: creator.Id,
+			},
+		},
+		{
+			name: "Match already started -> 400",
+			fixtures: DBFixtures{
+				Courts:      []models.DBCourt{court},
+				Users:       []models.DBUsers{creator, player},
+				Matches:     []models.DBMatches{match.WithCurrentState(models.EnCours)},
+				UserMatches: []models.DBUserMatch{seat(creator, 1, now), seat(player, 2, now.Add(time.Minute))},
+			},
+			auth: models.AuthInfo{IsConnected: true, UserID: player.Id},
+			expected: expected{
+				code:      http.StatusBadRequest,
+				errorMsg:  "match_wrong_state",
+				state:     models.EnCours,
+				creatorID: creator.Id,
+			},
+		},
+		{
+			name: "User not in match -> 400",
+			fixtures: DBFixtures{
+				Courts:      []models.DBCourt{court},
+				Users:       []models.DBUsers{creator, player},
+				Matches:     []models.DBMatches{match.WithCurrentState(models.ManqueJoueur)},
+				UserMatches: []models.DBUserMatch{seat(creator, 1, now)},
+			},
+			auth: models.AuthInfo{IsConnected: true, UserID: player.Id},
+			expected: expected{
+				code:      http.StatusBadRequest,
+				errorMsg:  "not_in_match",
+				state:     models.ManqueJoueur,
+				creatorID: creator.Id,
+			},
+		},
+		{
+			name: "Unauthorized user",
+			fixtures: DBFixtures{
+				Courts:  []models.DBCourt{court},
+				Users:   []models.DBUsers{creator},
+				Matches: []models.DBMatches{match.WithCurrentState(models.ManqueJoueur)},
+			},
+			auth: models.AuthInfo{IsConnected: false},
+			expected: expected{
+				code:      http.StatusUnauthorized,
+				state:     models.ManqueJoueur,
+				creatorID: creator.Id,
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() { _ = cleanup() }()
+			s.loadFixtures(c.fixtures)
+
+			r := httptest.NewRequest("POST", "/match/"+match.Id+"/leave", nil)
+			routeCtx := chi.NewRouteContext()
+			routeCtx.URLParams.Add("id", match.Id)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeCtx))
+			w := httptest.NewRecorder()
+
+			err := s.LeaveMatch(w, r, c.auth)
+			require.NoError(t, err)
+
+			resp := w.Result()
+			defer func(Body io.ReadCloser) { _ = Body.Close() }(resp.Body)
+			require.Equal(t, c.expected.code, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			if c.expected.errorMsg != "" {
+				require.Contains(t, string(body), c.expected.errorMsg)
+			}
+
+			ctx := context.Background()
+			updated, err := s.db.GetMatchById(ctx, match.Id)
+			require.NoError(t, err)
+			require.NotNil(t, updated)
+			require.Equal(t, c.expected.state, updated.CurrentState)
+			require.Equal(t, c.expected.creatorID, updated.CreatorID)
+
+			if c.expected.code == http.StatusOK {
+				stillIn, err := s.db.IsUserInMatch(ctx, c.auth.UserID, match.Id)
+				require.NoError(t, err)
+				require.Equal(t, c.expected.cancelled, stillIn)
+			}
+		})
+	}
+}
+
+func Test_KickMatchPlayer(t *testing.T) {
+	type expected struct {
+		code     int
+		errorMsg string
+		state    models.MatchState
+		kicked   bool
+	}
+
+	type testCase struct {
+		name     string
+		fixtures DBFixtures
+		auth     models.AuthInfo
+		playerID string
+		expected expected
+	}
+
+	court := models.NewDBCourtFixture()
+	creator := models.NewDBUsersFixture().WithUsername("creator").WithEmail("creator@example.com")
+	player := models.NewDBUsersFixture().WithUsername("player").WithEmail("player@example.com")
+
+	match := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithParticipantNber(2).
+		WithCreatorId(creator.Id)
+
+	seats := []models.DBUserMatch{
+		models.NewDBUserMatchFixture().WithUserId(creator.Id).WithMatchId(match.Id).WithTeam(1),
+		models.NewDBUserMatchFixture().WithUserId(player.Id).WithMatchId(match.Id).WithTeam(2),
+	}
+
+	testCases := []testCase{
+		{
+			name: "Creator kicks a player from a Valide match -> back to Manque joueur",
+			fixtures: DBFixtures{
+				Courts:      []models.DBCourt{court},
+				Users:       []models.DBUsers{creator, player},
+				Matches:     []models.DBMatches{match.WithCurrentState(models.Valide)},
+				UserMatches: seats,
+			},
+			auth:     models.AuthInfo{IsConnected: true, UserID: creator.Id},
+			playerID: player.Id,
+			expected: expected{
+				code:   http.StatusOK,
+				state:  models.ManqueJoueur,
+				kicked: true,
+			},
+		},
+		{
+			name: "Not the creator -> 403",
+			fixtures: DBFixtures{
+				Courts:      []models.DBCourt{court},
+				Users:       []models.DBUsers{creator, player},
+				Matches:     []models.DBMatches{match.WithCurrentState(models.Valide)},
+				UserMatches: seats,
+			},
+			auth:     models.AuthInfo{IsConnected: true, UserID: player.Id},
+			playerID: creator.Id,
+			expected: expected{
+				code:     http.StatusForbidden,
+				errorMsg: "not_match_creator",
+				state:    models.Valide,
+			},
+		},
+		{
+			name: "Match in Manque Score -> 400",
+			fixtures: DBFixtures{
+				Courts:      []models.DBCourt{court},
+				Users:       []models.DBUsers{creator, player},
+				Matches:     []models.DBMatches{match.WithCurrentState(models.ManqueScore)},
+				UserMatches: seats,
+			},
+			auth:     models.AuthInfo{IsConnected: true, UserID: creator.Id},
+			playerID: player.Id,
+			expected: expected{
+				code:     http.StatusBadRequest,
+				errorMsg: "match_wrong_state",
+				state:    models.ManqueScore,
+			},
+		},
+		{
+			name: "Match not found -> 404",
+			fixtures: DBFixtures{
+				Courts: []models.DBCourt{court},
+				Users:  []models.DBUsers{creator, player},
+			},
+			auth:     models.AuthInfo{IsConnected: true, UserID: creator.Id},
+			playerID: player.Id,
+			expected: expected{
+				code:     http.StatusNotFound,
+				errorMsg: "match_not_found",
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() { _ = cleanup() }()
+			s.loadFixtures(c.fixtures)
+
+			r := httptest.NewRequest("DELETE", "/match/"+match.Id+"/players/"+c.playerID, nil)
+			routeCtx := chi.NewRouteContext()
+			routeCtx.URLParams.Add("id", match.Id)
+			routeCtx.URLParams.Add("userId", c.playerID)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeCtx))
+			w := httptest.NewRecorder()
+
+			err := s.KickMatchPlayer(w, r, c.auth)
+			require.NoError(t, err)
+
+			resp := w.Result()
+			defer func(Body io.ReadCloser) { _ = Body.Close() }(resp.Body)
+			require.Equal(t, c.expected.code, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			if c.expected.errorMsg != "" {
+				require.Contains(t, string(body), c.expected.errorMsg)
+			}
+			if c.expected.state == "" {
+				return
+			}
+
+			ctx := context.Background()
+			updated, err := s.db.GetMatchById(ctx, match.Id)
+			require.NoError(t, err)
+			require.Equal(t, c.expected.state, updated.CurrentState)
+
+			stillIn, err := s.db.IsUserInMatch(ctx, c.playerID, match.Id)
+			require.NoError(t, err)
+			require.Equal(t, !c.expected.kicked, stillIn)
+		})
+	}
+}
+
+func Test_DeleteMatch(t *testing.T) {
+	type expected struct {
+		code      int
+		errorMsg  string
+		state     models.MatchState
+		mailsSent int
+	}
+
+	type testCase struct {
+		name     string
+		matchID  string
+		state    models.MatchState
+		auth     models.AuthInfo
+		expected expected
+	}
+
+	court := models.NewDBCourtFixture()
+	creator := models.NewDBUsersFixture().WithUsername("creator").WithEmail("creator@example.com")
+	player := models.NewDBUsersFixture().WithUsername("player").WithEmail("player@example.com")
+	other := models.NewDBUsersFixture().WithUsername("other").WithEmail("other@example.com")
+
+	match := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithParticipantNber(4).
+		WithCreatorId(creator.Id)
+
+	testCases := []testCase{
+		{
+			name:    "Creator cancels -> Annule and other participants are emailed",
+			matchID: match.Id,
+			state:   models.ManqueJoueur,
+			auth:    models.AuthInfo{IsConnected: true, UserID: creator.Id},
+			expected: expected{
+				code:      http.StatusOK,
+				state:     models.Annule,
+				mailsSent: 2,
+			},
+		},
+		{
+			name:    "Creator cancels a Valide match",
+			matchID: match.Id,
+			state:   models.Valide,
+			auth:    models.AuthInfo{IsConnected: true, UserID: creator.Id},
+			expected: expected{
+				code:      http.StatusOK,
+				state:     models.Annule,
+				mailsSent: 2,
+			},
+		},
+		{
+			name:    "Not the creator -> 403",
+			matchID: match.Id,
+			state:   models.ManqueJoueur,
+			auth:    models.AuthInfo{IsConnected: true, UserID: player.Id},
+			expected: expected{
+				code:     http.StatusForbidden,
+				errorMsg: "not_match_creator",
+				state:    models.ManqueJoueur,
+			},
+		},
+		{
+			name:    "Match already started -> 400",
+			matchID: match.Id,
+			state:   models.EnCours,
+			auth:    models.AuthInfo{IsConnected: true, UserID: creator.Id},
+			expected: expected{
+				code:     http.StatusBadRequest,
+				errorMsg: "match_wrong_state",
+				state:    models.EnCours,
+			},
+		},
+		{
+			name:    "Match not found -> 404",
+			matchID: uuid.NewString(),
+			state:   models.ManqueJoueur,
+			auth:    models.AuthInfo{IsConnected: true, UserID: creator.Id},
+			expected: expected{
+				code:     http.StatusNotFound,
+				errorMsg: "match_not_found",
+				state:    models.ManqueJoueur,
+			},
+		},
+		{
+			name:    "Unauthorized -> 401",
+			matchID: match.Id,
+			state:   models.ManqueJoueur,
+			auth:    models.AuthInfo{IsConnected: false},
+			expected: expected{
+				code:  http.StatusUnauthorized,
+				state: models.ManqueJoueur,
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() { _ = cleanup() }()
+			mockMailer := mailer.NewMockMailer()
+			s.mailer = mockMailer
+			s.loadFixtures(DBFixtures{
+				Courts:  []models.DBCourt{court},
+				Users:   []models.DBUsers{creator, player, other},
+				Matches: []models.DBMatches{match.WithCurrentState(c.state)},
+				UserMatches: []models.DBUserMatch{
+					models.NewDBUserMatchFixture().WithUserId(creator.Id).WithMatchId(match.Id).WithTeam(1),
+					models.NewDBUserMatchFixture().WithUserId(player.Id).WithMatchId(match.Id).WithTeam(2),
+					models.NewDBUserMatchFixture().WithUserId(other.Id).WithMatchId(match.Id).WithTeam(2),
+				},
+			})
+
+			r := httptest.NewRequest("DELETE", "/match/"+c.matchID, nil)
+			routeCtx := chi.NewRouteContext()
+			routeCtx.URLParams.Add("id", c.matchID)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeCtx))
+			w := httptest.NewRecorder()
+
+			err := s.DeleteMatch(w, r, c.auth)
+			require.NoError(t, err)
+
+			resp := w.Result()
+			defer func(Body io.ReadCloser) { _ = Body.Close() }(resp.Body)
+			require.Equal(t, c.expected.code, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			if c.expected.errorMsg != "" {
+				require.Contains(t, string(body), c.expected.errorMsg)
+			}
+
+			ctx := context.Background()
+			stored, err := s.db.GetMatchById(ctx, match.Id)
+			require.NoError(t, err)
+			require.NotNil(t, stored)
+			require.Equal(t, c.expected.state, stored.CurrentState)
+			_, err = s.outboxDispatcher().Drain(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, c.expected.mailsSent, mockMailer.GetSentCounts("cancelled"))
+
+			history, err := s.db.GetMatchesByUserID(ctx, player.Id)
+			require.NoError(t, err)
+			require.Len(t, history, 1)
+		})
+	}
+}
+
+func Test_UpdateMatchScore_Dispute(t *testing.T) {
+	court := models.NewDBCourtFixture()
+	const sport = models.Foot
+
+	userA := models.NewDBUsersFixture().WithUsername("userA").WithEmail("a@example.com")
+	userB := models.NewDBUsersFixture().WithUsername("userB").WithEmail("b@example.com")
+
+	match := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithSport(sport).
+		WithCurrentState(models.ManqueScore).
+		WithCreatorId(userA.Id)
+
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() { _ = cleanup() }()
+	s.mailer = mailer.NewMockMailer()
+	s.loadFixtures(DBFixtures{
+		Courts:  []models.DBCourt{court},
+		Matches: []models.DBMatches{match},
+		Users:   []models.DBUsers{userA, userB},
+		UserMatches: []models.DBUserMatch{
+			models.NewDBUserMatchFixture().WithUserId(userA.Id).WithMatchId(match.Id).WithTeam(1),
+			models.NewDBUserMatchFixture().WithUserId(userB.Id).WithMatchId(match.Id).WithTeam(2),
+		},
+		Rankings: []models.DBRanking{
+			models.NewDBRankingFixture().WithUserId(userA.Id).WithCourtId(court.Id).WithSport(sport).WithElo(1000),
+			models.NewDBRankingFixture().WithUserId(userB.Id).WithCourtId(court.Id).WithSport(sport).WithElo(1000),
+		},
+	})
+
+	newRequest := func(method, url string, body io.Reader) *http.Request {
+		r := httptest.NewRequest(method, url, body)
+		routeCtx := chi.NewRouteContext()
+		routeCtx.URLParams.Add("id", match.Id)
+		return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeCtx))
+	}
+	vote := func(user models.DBUsers, score1, score2 int) {
+		body, err := json.Marshal(models.NewUpdateScoreRequestFixture().WithScore1(score1).WithScore2(score2))
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		require.NoError(t, s.UpdateMatchScore(w, newRequest("PATCH", "/score/match/"+match.Id, bytes.NewReader(body)), models.AuthInfo{IsConnected: true, UserID: user.Id}))
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+	voteStatus := func(user models.DBUsers) models.MatchVoteStatusResponse {
+		w := httptest.NewRecorder()
+		require.NoError(t, s.GetMatchVoteStatus(w, newRequest("GET", "/match/"+match.Id+"/vote-status", nil), models.AuthInfo{IsConnected: true, UserID: user.Id}))
+		require.Equal(t, http.StatusOK, w.Code)
+		var res models.MatchVoteStatusResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		return res
+	}
+	ctx := context.Background()
+
+	vote(userA, 3, 1)
+	vote(userB, 1, 3)
+
+	m, err := s.db.GetMatchById(ctx, match.Id)
+	require.NoError(t, err)
+	require.Equal(t, models.ManqueScore, m.CurrentState)
+	require.True(t, m.Disputed)
+	require.NotNil(t, m.ScoreDeadline)
+
+	status := voteStatus(userA)
+	require.True(t, status.Disputed)
+	require.Equal(t, 1, status.Round)
+	require.NotNil(t, status.Deadline)
+	require.False(t, status.MyTeam.HasVoted)
+	require.False(t, status.Opponent.HasVoted)
+	require.NotNil(t, status.DisputedVotes)
+	require.Equal(t, &models.ScorePair{Score1: 3, Score2: 1}, status.DisputedVotes.MyTeam.Score)
+	require.Equal(t, &models.ScorePair{Score1: 1, Score2: 3}, status.DisputedVotes.Opponent.Score)
+
+	vote(userA, 2, 2)
+	status = voteStatus(userB)
+	require.False(t, status.MyTeam.HasVoted)
+	require.True(t, status.Opponent.HasVoted)
+
+	vote(userB, 2, 2)
+
+	m, err = s.db.GetMatchById(ctx, match.Id)
+	require.NoError(t, err)
+	require.Equal(t, models.Termine, m.CurrentState)
+	require.Equal(t, 2, *m.Score1)
+	require.Equal(t, 2, *m.Score2)
+	require.Nil(t, m.ScoreDeadline)
+}
+
+func Test_InviteToMatch(t *testing.T) {
+	type expected struct {
+		code      int
+		errorMsg  string
+		invited   []string
+		mailsSent int
+	}
+
+	type testCase struct {
+		name     string
+		body     string
+		auth     models.AuthInfo
+		expected expected
+	}
+
+	court := models.NewDBCourtFixture()
+	creator := models.NewDBUsersFixture().WithUsername("creator").WithEmail("creator@example.com")
+	friend := models.NewDBUsersFixture().WithUsername("friend").WithEmail("friend@example.com")
+	alreadyInvited := models.NewDBUsersFixture().WithUsername("invited").WithEmail("invited@example.com")
+	stranger := models.NewDBUsersFixture().WithUsername("stranger").WithEmail("stranger@example.com")
+
+	match := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(creator.Id).
+		WithParticipantNber(4).
+		WithVisibility(models.VisibilityInviteOnly)
+
+	accepted := func(a, b string) models.DBFriendship {
+		return models.NewDBFriendshipFixture().
+			WithRequesterId(a).
+			WithAddresseeId(b).
+			WithStatus(models.FriendshipAccepted)
+	}
+
+	testCases := []testCase{
+		{
+			name: "Creator invites friends -> only new invitees are emailed",
+			body: `{"user_ids": ["` + friend.Id + `", "` + alreadyInvited.Id + `", "` + friend.Id + `"]}`,
+			auth: models.AuthInfo{IsConnected: true, UserID: creator.Id},
+			expected: expected{
+				code:      http.StatusOK,
+				invited:   []string{friend.Id},
+				mailsSent: 1,
+			},
+		},
+		{
+			name: "Not a friend -> 400",
+			body: `{"user_ids": ["` + friend.Id + `", "` + stranger.Id + `"]}`,
+			auth: models.AuthInfo{IsConnected: true, UserID: creator.Id},
+			expected: expected{
+				code:     http.StatusBadRequest,
+				errorMsg: "invite_friends_only",
+			},
+		},
+		{
+			name: "Empty list -> 400",
+			body: `{"user_ids": []}`,
+			auth: models.AuthInfo{IsConnected: true, UserID: creator.Id},
+			expected: expected{
+				code:     http.StatusBadRequest,
+				errorMsg: `"user_ids":{"code":"validation.min_items"`,
+			},
+		},
+		{
+			name: "Not the creator -> 403",
+			body: `{"user_ids": ["` + creator.Id + `"]}`,
+			auth: models.AuthInfo{IsConnected: true, UserID: friend.Id},
+			expected: expected{
+				code:     http.StatusForbidden,
+				errorMsg: "not_match_creator",
+			},
+		},
+		{
+			name: "Unauthorized -> 401",
+			body: `{"user_ids": ["` + friend.Id + `"]}`,
+			auth: models.AuthInfo{IsConnected: false},
+			expected: expected{
+				code: http.StatusUnauthorized,
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() { _ = cleanup() }()
+			mockMailer := mailer.NewMockMailer()
+			s.mailer = mockMailer
+			s.loadFixtures(DBFixtures{
+				Courts:  []models.DBCourt{court},
+				Users:   []models.DBUsers{creator, friend, alreadyInvited, stranger},
+				Matches: []models.DBMatches{match},
+				UserMatches: []models.DBUserMatch{
+					models.NewDBUserMatchFixture().WithUserId(creator.Id).WithMatchId(match.Id).WithTeam(1),
+				},
+				Friendships: []models.DBFriendship{
+					accepted(creator.Id, friend.Id),
+					accepted(alreadyInvited.Id, creator.Id),
+				},
+			})
+			_, _, err := s.db.InviteToMatch(context.Background(), match.Id, creator.Id, []string{alreadyInvited.Id}, time.Now())
+			require.NoError(t, err)
+
+			r := httptest.NewRequest("POST", "/match/"+match.Id+"/invite", strings.NewReader(c.body))
+			routeCtx := chi.NewRouteContext()
+			routeCtx.URLParams.Add("id", match.Id)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeCtx))
+			w := httptest.NewRecorder()
+
+			err = s.InviteToMatch(w, r, c.auth)
+			require.NoError(t, err)
+
+			resp := w.Result()
+			defer func(Body io.ReadCloser) { _ = Body.Close() }(resp.Body)
+			require.Equal(t, c.expected.code, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			if c.expected.errorMsg != "" {
+				require.Contains(t, string(body), c.expected.errorMsg)
+			}
+			if c.expected.code == http.StatusOK {
+				var res models.InviteToMatchResponse
+				require.NoError(t, json.Unmarshal(body, &res))
+				require.ElementsMatch(t, c.expected.invited, res.Invited)
+			}
+			_, err = s.outboxDispatcher().Drain(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, c.expected.mailsSent, mockMailer.GetSentCounts("invitation"))
+		})
+	}
+}
+
+func Test_RebalanceMatch(t *testing.T) {
+	type testCase struct {
+		name         string
+		caller       int
+		expectedCode int
+		errorMsg     string
+	}
+
+	court := models.NewDBCourtFixture()
+	users := []models.DBUsers{
+		models.NewDBUsersFixture().WithUsername("p1").WithEmail("p1@example.com"),
+		models.NewDBUsersFixture().WithUsername("p2").WithEmail("p2@example.com"),
+		models.NewDBUsersFixture().WithUsername("p3").WithEmail("p3@example.com"),
+		models.NewDBUsersFixture().WithUsername("p4").WithEmail("p4@example.com"),
+	}
+	// The two best players start in the same team.
+	elos := []int{1500, 1400, 1000, 1100}
+	teams := []int{1, 1, 2, 2}
+
+	match := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(users[0].Id).
+		WithParticipantNber(4).
+		WithCurrentState(models.Valide)
+
+	testCases := []testCase{
+		{name: "Creator -> 200, balanced teams", caller: 0, expectedCode: http.StatusOK},
+		{name: "Not the creator -> 403", caller: 1, expectedCode: http.StatusForbidden, errorMsg: "not_match_creator"},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			fixtures := DBFixtures{
+				Courts:  []models.DBCourt{court},
+				Users:   users,
+				Matches: []models.DBMatches{match},
+			}
+			for i, u := range users {
+				fixtures.Rankings = append(fixtures.Rankings,
+					models.NewDBRankingFixture().WithUserId(u.Id).WithCourtId(court.Id).WithSport(match.Sport).WithElo(elos[i]))
+				fixtures.UserMatches = append(fixtures.UserMatches,
+					models.NewDBUserMatchFixture().WithUserId(u.Id).WithMatchId(match.Id).WithTeam(teams[i]))
+			}
+
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() { _ = cleanup() }()
+			s.loadFixtures(fixtures)
+
+			r := httptest.NewRequest("POST", "/match/"+match.Id+"/rebalance", nil)
+			routeCtx := chi.NewRouteContext()
+			routeCtx.URLParams.Add("id", match.Id)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeCtx))
+			w := httptest.NewRecorder()
+
+			err := s.RebalanceMatch(w, r, models.AuthInfo{IsConnected: true, UserID: users[c.caller].Id})
+			require.NoError(t, err)
+
+			resp := w.Result()
+			defer func(Body io.ReadCloser) { _ = Body.Close() }(resp.Body)
+			require.Equal(t, c.expectedCode, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			if c.errorMsg != "" {
+				require.Contains(t, string(body), c.errorMsg)
+				return
+			}
+
+			var res models.TeamsByMatchIdResponse
+			require.NoError(t, json.Unmarshal(body, &res))
+			require.Len(t, res.Team1, 2)
+			require.Len(t, res.Team2, 2)
+			require.Empty(t, res.Unassigned)
+
+			// 1500 + 1000 against 1400 + 1100
+			teamOf := map[string]int{}
+			for _, u := range res.Team1 {
+				teamOf[u.Username] = 1
+			}
+			for _, u := range res.Team2 {
+				teamOf[u.Username] = 2
+			}
+			require.Equal(t, teamOf["p1"], teamOf["p3"])
+			require.Equal(t, teamOf["p2"], teamOf["p4"])
+		})
+	}
+}
+
+func Test_parseMatchSearchQuery(t *testing.T) {
+	type testCase struct {
+		name    string
+		query   string
+		wantErr error
+		check   func(t *testing.T, q models.MatchSearchQuery)
+	}
+
+	testCases := []testCase{
+		{
+			name:  "Defaults",
+			query: "",
+			check: func(t *testing.T, q models.MatchSearchQuery) {
+				require.Equal(t, []models.MatchState{models.ManqueJoueur}, q.States)
+				require.Equal(t, models.MatchSortDate, q.Sort)
+				require.Equal(t, defaultMatchSearchLimit, q.Limit)
+				require.Nil(t, q.Near)
+			},
+		},
+		{
+			name:  "Every filter",
+			query: "sport=foot&state=Valide,Manque%20joueur&from=2025-06-01T00:00:00Z&to=2025-06-08T00:00:00Z&lat=48.85&lng=2.35&radius_m=2000&court=c1&players_needed=2&elo_range=100&sort=distance&limit=5",
+			check: func(t *testing.T, q models.MatchSearchQuery) {
+				require.Equal(t, models.Foot, *q.Sport)
+				require.Equal(t, []models.MatchState{models.Valide, models.ManqueJoueur}, q.States)
+				require.Equal(t, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), *q.From)
+				require.Equal(t, time.Date(2025, 6, 8, 0, 0, 0, 0, time.UTC), *q.To)
+				require.Equal(t, models.GeoFilter{Latitude: 48.85, Longitude: 2.35, RadiusMeters: 2000}, *q.Near)
+				require.Equal(t, "c1", *q.CourtID)
+				require.Equal(t, 2, *q.MinPlayersNeeded)
+				require.Equal(t, 100, *q.EloRange)
+				require.Equal(t, models.MatchSortDistance, q.Sort)
+				require.Equal(t, 5, q.Limit)
+			},
+		},
+		{
+			name:  "Cursor of the same sort",
+			query: "cursor=" + models.MatchSearchCursor{Sort: models.MatchSortDate, Date: time.Now(), Id: "m1"}.Encode(),
+			check: func(t *testing.T, q models.MatchSearchQuery) {
+				require.Equal(t, "m1", q.Cursor.Id)
+			},
+		},
+		{name: "Wrong sport", query: "sport=tennis", wantErr: i18n.M(i18n.ErrInvalidSport)},
+		{name: "Wrong state", query: "state=Fini", wantErr: i18n.M(i18n.ErrInvalidParameter, "state")},
+		{name: "Wrong date", query: "from=yesterday", wantErr: i18n.M(i18n.ErrInvalidParameter, "from")},
+		{name: "Empty date range", query: "from=2025-06-08T00:00:00Z&to=2025-06-01T00:00:00Z", wantErr: i18n.M(i18n.ErrInvalidDateRange)},
+		{name: "Latitude without longitude", query: "lat=48.85", wantErr: i18n.M(i18n.ErrInvalidParameter, "lng")},
+		{name: "NaN longitude", query: "lat=48.85&lng=NaN", wantErr: i18n.M(i18n.ErrInvalidParameter, "lng")},
+		{name: "NaN radius", query: "lat=48.85&lng=2.35&radius_m=NaN", wantErr: i18n.M(i18n.ErrInvalidParameter, "radius_m")},
+		{name: "Radius too large", query: "lat=48.85&lng=2.35&radius_m=100000", wantErr: i18n.M(i18n.ErrInvalidParameter, "radius_m")},
+		{name: "Distance sort without position", query: "sort=distance", wantErr: i18n.M(i18n.ErrDistanceSortNeedsPosition)},
+		{name: "Unknown sort", query: "sort=elo", wantErr: i18n.M(i18n.ErrInvalidParameter, "sort")},
+		{name: "No player needed", query: "players_needed=0", wantErr: i18n.M(i18n.ErrInvalidParameter, "players_needed")},
+		{name: "Negative elo range", query: "elo_range=-1", wantErr: i18n.M(i18n.ErrInvalidParameter, "elo_range")},
+		{name: "Garbage cursor", query: "cursor=abc", wantErr: i18n.M(i18n.ErrInvalidParameter, "cursor")},
+		{
+			name:    "Cursor of another sort",
+			query:   "lat=48.85&lng=2.35&sort=distance&cursor=" + models.MatchSearchCursor{Sort: models.MatchSortDate, Id: "m1"}.Encode(),
+			wantErr: i18n.M(i18n.ErrInvalidParameter, "cursor"),
+		},
+		{name: "Limit too large", query: "limit=1000", wantErr: i18n.M(i18n.ErrInvalidParameter, "limit")},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			values, err := url.ParseQuery(c.query)
+			require.NoError(t, err)
+
+			q, err := parseMatchSearchQuery(values)
+			if c.wantErr != nil {
+				require.Equal(t, c.wantErr, err)
+				return
+			}
+			require.NoError(t, err)
+			c.check(t, q)
+		})
+	}
+}
+
+func Test_SearchMatches(t *testing.T) {
+	user := models.NewDBUsersFixture().WithUsername("searcher").WithEmail("searcher@example.com")
+	court := models.NewDBCourtFixture().WithAddress("1 rue de la Paix, 75002 Paris")
+
+	start := time.Now().Add(24 * time.Hour)
+	first := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(user.Id).
+		WithParticipantNber(4).
+		WithDate(start)
+	second := first.WithId(uuid.NewString()).WithDate(start.Add(time.Hour))
+	other := models.NewDBUsersFixture().WithUsername("other").WithEmail("other@example.com")
+	hidden := first.WithId(uuid.NewString()).
+		WithCreatorId(other.Id).
+		WithVisibility(models.VisibilityInviteOnly)
+
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() { _ = cleanup() }()
+	s.loadFixtures(DBFixtures{
+		Users:   []models.DBUsers{user, other},
+		Courts:  []models.DBCourt{court},
+		Matches: []models.DBMatches{first, second, hidden},
+		UserMatches: []models.DBUserMatch{
+			models.NewDBUserMatchFixture().WithUserId(user.Id).WithMatchId(first.Id).WithTeam(1),
+		},
+	})
+
+	search := func(query string, auth models.AuthInfo) (*httptest.ResponseRecorder, models.MatchSearchResponse) {
+		r := httptest.NewRequest("GET", "/matches/search?"+query, nil)
+		w := httptest.NewRecorder()
+		require.NoError(t, s.SearchMatches(w, r, auth))
+		var res models.MatchSearchResponse
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		}
+		return w, res
+	}
+	auth := models.AuthInfo{IsConnected: true, UserID: user.Id}
+
+	w, res := search("limit=1", auth)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, res.Matches, 1)
+	require.Equal(t, first.Id, res.Matches[0].Id)
+	require.Equal(t, 1, res.Matches[0].PlayersCount)
+	require.Equal(t, 3, res.Matches[0].PlayersNeeded)
+	require.Equal(t, "Paris", *res.Matches[0].City)
+	require.NotNil(t, res.NextCursor)
+
+	w, res = search("limit=1&cursor="+*res.NextCursor, auth)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, res.Matches, 1)
+	require.Equal(t, second.Id, res.Matches[0].Id)
+	require.Nil(t, res.NextCursor)
+
+	w, _ = search("sort=distance", auth)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), `"code":"distance_sort_needs_position"`)
+
+	w, _ = search("", models.AuthInfo{IsConnected: false})
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"PLIC/httpx"
+	"PLIC/i18n"
+	"PLIC/models"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+)
+
+// maxQueueWindow is how far ahead a player can look for a match.
+const maxQueueWindow = 7 * 24 * time.Hour
+
+// validateQueueRequest checks the rules of a matchmaking queue request spanning several
+// fields, the others being in the validate tags of models.QueueRequest. Its errors are
+// i18n.Message, written by writeError.
+func validateQueueRequest(req models.QueueRequest, now time.Time) error {
+	if req.WindowEnd.Before(req.WindowStart) {
+		return i18n.M(i18n.ErrInvalidParameter, "window")
+	}
+	if req.WindowEnd.Sub(now) > maxQueueWindow {
+		return i18n.M(i18n.ErrQueueWindowTooFar)
+	}
+
+	hasArea := req.Latitude != nil || req.Longitude != nil || req.RadiusMeters != nil
+	if hasArea && (req.Latitude == nil || req.Longitude == nil || req.RadiusMeters == nil) {
+		return i18n.M(i18n.ErrQueueAreaIncomplete)
+	}
+	if !hasArea && len(req.CourtIDs) == 0 {
+		return i18n.M(i18n.ErrQueueAreaRequired)
+	}
+	return nil
+}
+
+func toQueueEntryResponse(e models.DBQueueEntry) models.QueueEntryResponse {
+	courtIDs := e.CourtIDs
+	if courtIDs == nil {
+		courtIDs = []string{}
+	}
+	return models.QueueEntryResponse{
+		Id:           e.Id,
+		Sport:        e.Sport,
+		CourtIDs:     courtIDs,
+		Latitude:     e.Latitude,
+		Longitude:    e.Longitude,
+		RadiusMeters: e.RadiusMeters,
+		WindowStart:  e.WindowStart,
+		WindowEnd:    e.WindowEnd,
+		TeamSize:     e.TeamSize,
+		Status:       e.Status,
+		MatchID:      e.MatchID,
+		CreatedAt:    e.CreatedAt,
+	}
+}
+
+// JoinMatchmakingQueue godoc
+// @Summary      Entre dans la file du matchmaking
+// @Description  Inscrit l'utilisateur dans la file d'attente d'un sport. Le matchmaking le regroupe avec des joueurs d'ELO proche qui acceptent un même terrain (terrains préférés ou zone de recherche) et une même heure, crée le match avec des équipes équilibrées et prévient les joueurs par e-mail. Un joueur n'attend qu'une fois par sport.
+// @Tags         matchmaking
+// @Accept       json
+// @Produce      json
+// @Param        body  body      models.QueueRequest  true  "Critères de recherche"
+// @Success      201   {object}  models.QueueEntryResponse
+// @Failure      400   {object}  models.Error  "Sport, taille d'équipe, fenêtre horaire, zone ou terrain invalide"
+// @Failure      401   {object}  models.Error  "Utilisateur non autorisé"
+// @Failure      403   {object}  models.Error  "Adresse e-mail non vérifiée"
+// @Failure      409   {object}  models.Error  "Déjà dans la file pour ce sport"
+// @Failure      500   {object}  models.Error  "Erreur serveur"
+// @Router       /matchmaking/queue [post]
+// @Security     BearerAuth
+func (s *Service) JoinMatchmakingQueue(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+	baseLogger := log.With().
+		Str("method", "JoinMatchmakingQueue").
+		Str("user_id", ai.UserID).
+		Logger()
+
+	if !ai.IsConnected {
+		baseLogger.Warn().Msg("unauthorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+
+	ctx := r.Context()
+
+	verified, err := s.db.IsEmailVerified(ctx, ai.UserID)
+	if err != nil {
+		baseLogger.Error().Err(err).Msg("db check email verification failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+	if !verified {
+		baseLogger.Warn().Msg("email not verified")
+		return httpx.WriteError(w, r, http.StatusForbidden, i18n.ErrEmailNotVerified)
+	}
+
+	var req models.QueueRequest
+	if err := httpx.DecodeJSON(w, r, &req, s.clock.Now()); err != nil {
+		return writeError(w, r, baseLogger, err, "invalid JSON body")
+	}
+
+	logger := baseLogger.With().
+		Str("sport", string(req.Sport)).
+		Int("team_size", req.TeamSize).
+		Logger()
+
+	now := s.clock.Now()
+	if err := validateQueueRequest(req, now); err != nil {
+		return writeError(w, r, logger, err, "invalid queue request")
+	}
+
+	courtIDs := lo.Uniq(req.CourtIDs)
+	for _, courtID := range courtIDs {
+		court, err := s.db.GetCourtByID(ctx, courtID)
+		if err != nil {
+			logger.Error().Err(err).Str("court_id", courtID).Msg("db get court failed")
+			return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+		}
+		if court == nil {
+			logger.Warn().Str("court_id", courtID).Msg("court not found")
+			return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrCourtNotFound)
+		}
+	}
+
+	entry := models.DBQueueEntry{
+		Id:           uuid.NewString(),
+		UserID:       ai.UserID,
+		Sport:        req.Sport,
+		TeamSize:     req.TeamSize,
+		Latitude:     req.Latitude,
+		Longitude:    req.Longitude,
+		RadiusMeters: req.RadiusMeters,
+		WindowStart:  req.WindowStart,
+		WindowEnd:    req.WindowEnd,
+		Status:       models.QueueWaiting,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		CourtIDs:     courtIDs,
+	}
+	if err := s.db.JoinQueue(ctx, entry); err != nil {
+		return writeError(w, r, logger, err, "db join queue failed")
+	}
+
+	logger.Info().Str("entry_id", entry.Id).Msg("joined matchmaking queue")
+	return httpx.Write(w, http.StatusCreated, toQueueEntryResponse(entry))
+}
+
+// GetMatchmakingQueue godoc
+// @Summary      Liste les demandes de matchmaking
+// @Description  Renvoie les demandes de matchmaking de l'utilisateur, de la plus récente à la plus ancienne, avec le match créé pour celles qui ont abouti
+// @Tags         matchmaking
+// @Produce      json
+// @Success      200  {array}   models.QueueEntryResponse
+// @Failure      401  {object}  models.Error  "Utilisateur non autorisé"
+// @Failure      500  {object}  models.Error  "Erreur serveur"
+// @Router       /matchmaking/queue [get]
+// @Security     BearerAuth
+func (s *Service) GetMatchmakingQueue(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+	logger := log.With().
+		Str("method", "GetMatchmakingQueue").
+		Str("user_id", ai.UserID).
+		Logger()
+
+	if !ai.IsConnected {
+		logger.Warn().Msg("unauthorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+
+	entries, err := s.db.GetUserQueueEntries(r.Context(), ai.UserID)
+	if err != nil {
+		logger.Error().Err(err).Msg("db get queue entries failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+
+	return httpx.Write(w, http.StatusOK, lo.Map(entries, func(e models.DBQueueEntry, _ int) models.QueueEntryResponse {
+		return toQueueEntryResponse(e)
+	}))
+}
+
+// LeaveMatchmakingQueue godoc
+// @Summary      Quitte la file du matchmaking
+// @Description  Retire de la file une demande de matchmaking en attente de l'utilisateur
+// @Tags         matchmaking
+// @Produce      json
+// @Param        id   path      string  true  "Identifiant de la demande"
+// @Success      200
+// @Failure      400  {object}  models.Error  "Identifiant manquant"
+// @Failure      401  {object}  models.Error  "Utilisateur non autorisé"
+// @Failure      404  {object}  models.Error  "Demande non trouvée"
+// @Failure      409  {object}  models.Error  "Demande plus en attente (match trouvé, annulée ou expirée)"
+// @Failure      500  {object}  models.Error  "Erreur serveur"
+// @Router       /matchmaking/queue/{id} [delete]
+// @Security     BearerAuth
+func (s *Service) LeaveMatchmakingQueue(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+	baseLogger := log.With().
+		Str("method", "LeaveMatchmakingQueue").
+		Str("user_id", ai.UserID).
+		Logger()
+
+	if !ai.IsConnected {
+		baseLogger.Warn().Msg("unauthorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+
+	entryID := chi.URLParam(r, "id")
+	logger := baseLogger.With().Str("entry_id", entryID).Logger()
+
+	if entryID == "" {
+		logger.Warn().Msg("missing entry ID")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMissingParameter, "id")
+	}
+
+	if err := s.db.CancelQueueEntry(r.Context(), ai.UserID, entryID, s.clock.Now()); err != nil {
+		return writeError(w, r, logger, err, "db cancel queue entry failed")
+	}
+
+	logger.Info().Msg("left matchmaking queue")
+	return httpx.Write(w, http.StatusOK, nil)
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"PLIC/i18n"
+	"PLIC/models"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_validateQueueRequest(t *testing.T) {
+	now := time.Date(2026, 5, 2, 10, 0, 0, 0, time.UTC)
+	valid := models.QueueRequest{
+		Sport:       models.PingPong,
+		CourtIDs:    []string{"court"},
+		WindowStart: now.Add(time.Hour),
+		WindowEnd:   now.Add(3 * time.Hour),
+		TeamSize:    1,
+	}
+
+	type testCase struct {
+		name string
+		edit func(r *models.QueueRequest)
+		err  error
+	}
+
+	testCases := []testCase{
+		{name: "Valid", edit: func(r *models.QueueRequest) {}},
+		{
+			name: "Area only",
+			edit: func(r *models.QueueRequest) {
+				r.CourtIDs = nil
+				r.Latitude, r.Longitude, r.RadiusMeters = ptr(48.85), ptr(2.35), ptr(2000.0)
+			},
+		},
+		{
+			name: "Window end before start",
+			edit: func(r *models.QueueRequest) { r.WindowEnd = r.WindowStart.Add(-time.Minute) },
+			err:  i18n.M(i18n.ErrInvalidParameter, "window"),
+		},
+		{
+			name: "Window too far ahead",
+			edit: func(r *models.QueueRequest) { r.WindowEnd = now.Add(8 * 24 * time.Hour) },
+			err:  i18n.M(i18n.ErrQueueWindowTooFar),
+		},
+		{
+			name: "Partial area",
+			edit: func(r *models.QueueRequest) { r.Latitude = ptr(48.85) },
+			err:  i18n.M(i18n.ErrQueueAreaIncomplete),
+		},
+		{
+			name: "Neither courts nor area",
+			edit: func(r *models.QueueRequest) { r.CourtIDs = nil },
+			err:  i18n.M(i18n.ErrQueueAreaRequired),
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			req := valid
+			c.edit(&req)
+			err := validateQueueRequest(req, now)
+			require.Equal(t, c.err, err)
+		})
+	}
+}
+
+func Test_JoinMatchmakingQueue(t *testing.T) {
+	type expected struct {
+		code     int
+		errorMsg string
+	}
+
+	type testCase struct {
+		name     string
+		body     func(courtID string) string
+		auth     func(userID string) models.AuthInfo
+		queued   bool
+		expected expected
+	}
+
+	window := `"window_start": "` + time.Now().Add(time.Hour).Format(time.RFC3339) +
+		`", "window_end": "` + time.Now().Add(3*time.Hour).Format(time.RFC3339) + `"`
+	connected := func(userID string) models.AuthInfo {
+		return models.AuthInfo{IsConnected: true, UserID: userID}
+	}
+
+	testCases := []testCase{
+		{
+			name: "Preferred court -> 201",
+			body: func(courtID string) string {
+				return `{"sport": "ping-pong", "team_size": 1, "court_ids": ["` + courtID + `"], ` + window + `}`
+			},
+			auth:     connected,
+			expected: expected{code: http.StatusCreated},
+		},
+		{
+			name: "Already waiting for the sport -> 409",
+			body: func(courtID string) string {
+				return `{"sport": "ping-pong", "team_size": 1, "court_ids": ["` + courtID + `"], ` + window + `}`
+			},
+			auth:     connected,
+			queued:   true,
+			expected: expected{code: http.StatusConflict, errorMsg: "already_queued"},
+		},
+		{
+			name: "Unknown court -> 400",
+			body: func(string) string {
+				return `{"sport": "ping-pong", "team_size": 1, "court_ids": ["unknown"], ` + window + `}`
+			},
+			auth:     connected,
+			expected: expected{code: http.StatusBadRequest, errorMsg: "court_not_found"},
+		},
+		{
+			name: "Invalid request -> 400",
+			body: func(courtID string) string {
+				return `{"sport": "ping-pong", "team_size": 0, "court_ids": ["` + courtID + `"], ` + window + `}`
+			},
+			auth:     connected,
+			expected: expected{code: http.StatusBadRequest, errorMsg: `"team_size":{"code":"validation.min"`},
+		},
+		{
+			name: "Unauthorized -> 401",
+			body: func(string) string { return `{}` },
+			auth: func(string) models.AuthInfo {
+				return models.AuthInfo{IsConnected: false}
+			},
+			expected: expected{code: http.StatusUnauthorized},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			court := models.NewDBCourtFixture()
+			user := models.NewDBUsersFixture()
+			fixtures := DBFixtures{
+				Courts: []models.DBCourt{court},
+				Users:  []models.DBUsers{user},
+			}
+			if c.queued {
+				fixtures.QueueEntries = []models.DBQueueEntry{
+					models.NewDBQueueEntryFixture().WithUserId(user.Id).WithCourtIds(court.Id),
+				}
+			}
+
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() { _ = cleanup() }()
+			s.loadFixtures(fixtures)
+
+			r := httptest.NewRequest("POST", "/matchmaking/queue", strings.NewReader(c.body(court.Id)))
+			w := httptest.NewRecorder()
+
+			err := s.JoinMatchmakingQueue(w, r, c.auth(user.Id))
+			require.NoError(t, err)
+
+			resp := w.Result()
+			defer func(Body io.ReadCloser) { _ = Body.Close() }(resp.Body)
+			require.Equal(t, c.expected.code, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			if c.expected.errorMsg != "" {
+				require.Contains(t, string(body), c.expected.errorMsg)
+			}
+			if c.expected.code == http.StatusCreated {
+				var res models.QueueEntryResponse
+				require.NoError(t, json.Unmarshal(body, &res))
+				require.Equal(t, models.QueueWaiting, res.Status)
+				require.Equal(t, []string{court.Id}, res.CourtIDs)
+
+				entries, err := s.db.GetUserQueueEntries(context.Background(), user.Id)
+				require.NoError(t, err)
+				require.Len(t, entries, 1)
+			}
+		})
+	}
+}
+
+func Test_LeaveMatchmakingQueue(t *testing.T) {
+	type testCase struct {
+		name         string
+		status       models.QueueStatus
+		callerIsUser bool
+		expectedCode int
+	}
+
+	testCases := []testCase{
+		{name: "Waiting entry -> 200", status: models.QueueWaiting, callerIsUser: true, expectedCode: http.StatusOK},
+		{name: "Matched entry -> 409", status: models.QueueMatched, callerIsUser: true, expectedCode: http.StatusConflict},
+		{name: "Entry of another user -> 404", status: models.QueueWaiting, expectedCode: http.StatusNotFound},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			court := models.NewDBCourtFixture()
+			user := models.NewDBUsersFixture().WithUsername("user").WithEmail("user@example.com")
+			other := models.NewDBUsersFixture().WithUsername("other").WithEmail("other@example.com")
+			entry := models.NewDBQueueEntryFixture().WithUserId(user.Id).WithCourtIds(court.Id).WithStatus(c.status)
+
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() { _ = cleanup() }()
+			s.loadFixtures(DBFixtures{
+				Courts:       []models.DBCourt{court},
+				Users:        []models.DBUsers{user, other},
+				QueueEntries: []models.DBQueueEntry{entry},
+			})
+
+			caller := other.Id
+			if c.callerIsUser {
+				caller = user.Id
+			}
+
+			r := httptest.NewRequest("DELETE", "/matchmaking/queue/"+entry.Id, nil)
+			routeCtx := chi.NewRouteContext()
+			routeCtx.URLParams.Add("id", entry.Id)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeCtx))
+			w := httptest.NewRecorder()
+
+			err := s.LeaveMatchmakingQueue(w, r, models.AuthInfo{IsConnected: true, UserID: caller})
+			require.NoError(t, err)
+
+			resp := w.Result()
+			defer func(Body io.ReadCloser) { _ = Body.Close() }(resp.Body)
+			require.Equal(t, c.expectedCode, resp.StatusCode)
+		})
+	}
+}
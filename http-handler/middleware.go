@@ -1,6 +1,8 @@
 package main
 
 import (
+	"PLIC/httpx"
+	"PLIC/i18n"
 	"PLIC/models"
 	"fmt"
 	"net"
@@ -58,15 +60,14 @@ func withRateLimit(handler httpHandler) httpHandler {
 				Str("ip", ip).
 				Str("path", r.URL.Path).
 				Msg("rate limit exceeded")
-			http.Error(w, "429 - Too Many Requests", http.StatusTooManyRequests)
-			return nil
+			return httpx.WriteError(w, r, http.StatusTooManyRequests, i18n.ErrTooManyRequests)
 		}
 
 		return handler(w, r, info)
 	}
 }
 
-func withAuthentication(handler httpHandler) httpHandler {
+func (s *Service) withAuthentication(handler httpHandler) httpHandler {
 	return withRateLimit(func(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
 		ip := getRealIP(r)
 		logger := log.With().
@@ -87,15 +88,26 @@ func withAuthentication(handler httpHandler) httpHandler {
 					return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 				}
 				return []byte(jwtSecret), nil
-			})
+			}, jwt.WithExpirationRequired(), jwt.WithTimeFunc(s.clock.Now))
 
 			if err != nil {
 				logger.Warn().Err(err).Msg("invalid JWT token")
 			} else if token.Valid {
 				if claims, ok := token.Claims.(jwt.MapClaims); ok {
-					if userID, ok := claims["user_id"].(string); ok {
-						auth.IsConnected = true
-						auth.UserID = userID
+					userID, _ := claims["user_id"].(string)
+					sessionID, _ := claims["sid"].(string)
+					if userID != "" && sessionID != "" {
+						active, err := s.db.IsSessionActive(r.Context(), sessionID, userID, s.clock.Now())
+						switch {
+						case err != nil:
+							logger.Error().Err(err).Msg("session lookup failed")
+						case !active:
+							logger.Warn().Str("session_id", sessionID).Msg("session revoked or expired")
+						default:
+							auth.IsConnected = true
+							auth.UserID = userID
+							auth.SessionID = sessionID
+						}
 					}
 				}
 			}
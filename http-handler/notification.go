@@ -0,0 +1,197 @@
+package main
+
+import (
+	"PLIC/httpx"
+	"PLIC/i18n"
+	"PLIC/models"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+func toNotificationPreferencesResponse(p models.DBNotificationPreferences) models.NotificationPreferencesResponse {
+	return models.NotificationPreferencesResponse{
+		MatchFilled:   p.MatchFilled,
+		MatchStarting: p.MatchStarting,
+		ScoreVote:     p.ScoreVote,
+		MatchResult:   p.MatchResult,
+	}
+}
+
+// RegisterDevice godoc
+// @Summary      Enregistre un appareil pour les notifications push
+// @Description  Enregistre le jeton push de l'appareil (APNs pour ios, FCM pour android). Un jeton déjà enregistré est rattaché à l'utilisateur courant. Les jetons refusés par le fournisseur sont oubliés automatiquement.
+// @Tags         notification
+// @Accept       json
+// @Produce      json
+// @Param        body  body      models.RegisterDeviceRequest  true  "Jeton et plateforme de l'appareil"
+// @Success      201   {object}  models.DeviceResponse
+// @Failure      400   {object}  models.Error  "Jeton manquant ou plateforme invalide"
+// @Failure      401   {object}  models.Error  "Utilisateur non autorisé"
+// @Failure      500   {object}  models.Error  "Erreur serveur"
+// @Router       /devices [post]
+// @Security     BearerAuth
+func (s *Service) RegisterDevice(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+	logger := log.With().
+		Str("method", "RegisterDevice").
+		Str("user_id", ai.UserID).
+		Logger()
+
+	if !ai.IsConnected {
+		logger.Warn().Msg("unauthorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+
+	var req models.RegisterDeviceRequest
+	if err := httpx.DecodeJSON(w, r, &req, s.clock.Now()); err != nil {
+		return writeError(w, r, logger, err, "invalid JSON body")
+	}
+
+	now := s.clock.Now()
+	device := models.DBDevice{
+		Token:     strings.TrimSpace(req.Token),
+		UserID:    ai.UserID,
+		Platform:  req.Platform,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.db.RegisterDevice(r.Context(), device); err != nil {
+		logger.Error().Err(err).Msg("db register device failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+
+	logger.Info().Str("platform", string(device.Platform)).Msg("device registered")
+	return httpx.Write(w, http.StatusCreated, models.DeviceResponse{
+		Token:     device.Token,
+		Platform:  device.Platform,
+		CreatedAt: device.CreatedAt,
+	})
+}
+
+// DeleteDevice godoc
+// @Summary      Désenregistre un appareil
+// @Description  Arrête l'envoi des notifications push à l'appareil, par exemple à la déconnexion
+// @Tags         notification
+// @Produce      json
+// @Param        token  path      string  true  "Jeton push de l'appareil"
+// @Success      200
+// @Failure      400    {object}  models.Error  "Jeton manquant"
+// @Failure      401    {object}  models.Error  "Utilisateur non autorisé"
+// @Failure      404    {object}  models.Error  "Appareil non trouvé"
+// @Failure      500    {object}  models.Error  "Erreur serveur"
+// @Router       /devices/{token} [delete]
+// @Security     BearerAuth
+func (s *Service) DeleteDevice(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+	logger := log.With().
+		Str("method", "DeleteDevice").
+		Str("user_id", ai.UserID).
+		Logger()
+
+	if !ai.IsConnected {
+		logger.Warn().Msg("unauthorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+
+	token := chi.URLParam(r, "token")
+	if token == "" {
+		logger.Warn().Msg("missing token")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMissingParameter, "token")
+	}
+
+	if err := s.db.DeleteDevice(r.Context(), ai.UserID, token); err != nil {
+		return writeError(w, r, logger, err, "db delete device failed")
+	}
+
+	logger.Info().Msg("device deleted")
+	return httpx.Write(w, http.StatusOK, nil)
+}
+
+// GetNotificationPreferences godoc
+// @Summary      Préférences de notification
+// @Description  Renvoie les notifications push que l'utilisateur reçoit ; toutes sont activées par défaut
+// @Tags         notification
+// @Produce      json
+// @Success      200  {object}  models.NotificationPreferencesResponse
+// @Failure      401  {object}  models.Error  "Utilisateur non autorisé"
+// @Failure      500  {object}  models.Error  "Erreur serveur"
+// @Router       /notifications/preferences [get]
+// @Security     BearerAuth
+func (s *Service) GetNotificationPreferences(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+	logger := log.With().
+		Str("method", "GetNotificationPreferences").
+		Str("user_id", ai.UserID).
+		Logger()
+
+	if !ai.IsConnected {
+		logger.Warn().Msg("unauthorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+
+	prefs, err := s.db.GetNotificationPreferences(r.Context(), ai.UserID)
+	if err != nil {
+		logger.Error().Err(err).Msg("db get notification preferences failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+	return httpx.Write(w, http.StatusOK, toNotificationPreferencesResponse(prefs))
+}
+
+// PatchNotificationPreferences godoc
+// @Summary      Modifie les préférences de notification
+// @Description  Active ou désactive chaque type de notification push ; les champs absents sont inchangés
+// @Tags         notification
+// @Accept       json
+// @Produce      json
+// @Param        body  body      models.NotificationPreferencesPatchRequest  true  "Préférences à modifier"
+// @Success      200   {object}  models.NotificationPreferencesResponse
+// @Failure      400   {object}  models.Error  "JSON invalide"
+// @Failure      401   {object}  models.Error  "Utilisateur non autorisé"
+// @Failure      500   {object}  models.Error  "Erreur serveur"
+// @Router       /notifications/preferences [patch]
+// @Security     BearerAuth
+func (s *Service) PatchNotificationPreferences(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+	logger := log.With().
+		Str("method", "PatchNotificationPreferences").
+		Str("user_id", ai.UserID).
+		Logger()
+
+	if !ai.IsConnected {
+		logger.Warn().Msg("unauthorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+
+	var req models.NotificationPreferencesPatchRequest
+	if err := httpx.DecodeJSON(w, r, &req, s.clock.Now()); err != nil {
+		return writeError(w, r, logger, err, "invalid JSON body")
+	}
+
+	ctx := r.Context()
+	prefs, err := s.db.GetNotificationPreferences(ctx, ai.UserID)
+	if err != nil {
+		logger.Error().Err(err).Msg("db get notification preferences failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+
+	if req.MatchFilled != nil {
+		prefs.MatchFilled = *req.MatchFilled
+	}
+	if req.MatchStarting != nil {
+		prefs.MatchStarting = *req.MatchStarting
+	}
+	if req.ScoreVote != nil {
+		prefs.ScoreVote = *req.ScoreVote
+	}
+	if req.MatchResult != nil {
+		prefs.MatchResult = *req.MatchResult
+	}
+	prefs.UpdatedAt = s.clock.Now()
+
+	if err := s.db.UpsertNotificationPreferences(ctx, prefs); err != nil {
+		logger.Error().Err(err).Msg("db upsert notification preferences failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+
+	logger.Info().Msg("notification preferences updated")
+	return httpx.Write(w, http.StatusOK, toNotificationPreferencesResponse(prefs))
+}
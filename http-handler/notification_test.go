@@ -0,0 +1,223 @@
+package main
+
+import (
+	"PLIC/models"
+	"PLIC/notifier"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RegisterDevice(t *testing.T) {
+	type testCase struct {
+		name         string
+		body         string
+		auth         models.AuthInfo
+		expectedCode int
+	}
+
+	user := models.NewDBUsersFixture()
+
+	testCases := []testCase{
+		{
+			name:         "Not connected -> 401",
+			body:         `{"token":"abc","platform":"ios"}`,
+			auth:         models.AuthInfo{IsConnected: false},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "Unknown platform -> 400",
+			body:         `{"token":"abc","platform":"windows"}`,
+			auth:         models.AuthInfo{IsConnected: true, UserID: user.Id},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Missing token -> 400",
+			body:         `{"token":"  ","platform":"android"}`,
+			auth:         models.AuthInfo{IsConnected: true, UserID: user.Id},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Device registered -> 201",
+			body:         `{"token":"abc","platform":"ios"}`,
+			auth:         models.AuthInfo{IsConnected: true, UserID: user.Id},
+			expectedCode: http.StatusCreated,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() { _ = cleanup() }()
+			s.loadFixtures(DBFixtures{Users: []models.DBUsers{user}})
+
+			r := httptest.NewRequest("POST", "/devices", strings.NewReader(c.body))
+			w := httptest.NewRecorder()
+
+			err := s.RegisterDevice(w, r, c.auth)
+			require.NoError(t, err)
+
+			resp := w.Result()
+			defer func(Body io.ReadCloser) { _ = Body.Close() }(resp.Body)
+			require.Equal(t, c.expectedCode, resp.StatusCode)
+
+			if c.expectedCode == http.StatusCreated {
+				var res models.DeviceResponse
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+				require.Equal(t, "abc", res.Token)
+				require.Equal(t, models.PlatformIOS, res.Platform)
+
+				devices, err := s.db.GetUserDevices(context.Background(), user.Id)
+				require.NoError(t, err)
+				require.Len(t, devices, 1)
+			}
+		})
+	}
+}
+
+func Test_DeleteDevice(t *testing.T) {
+	type testCase struct {
+		name         string
+		callerIsUser bool
+		expectedCode int
+	}
+
+	testCases := []testCase{
+		{name: "Own device -> 200", callerIsUser: true, expectedCode: http.StatusOK},
+		{name: "Device of another user -> 404", expectedCode: http.StatusNotFound},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			user := models.NewDBUsersFixture().WithUsername("user").WithEmail("user@example.com")
+			other := models.NewDBUsersFixture().WithUsername("other").WithEmail("other@example.com")
+			device := models.NewDBDeviceFixture().WithUserId(user.Id)
+
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() { _ = cleanup() }()
+			s.loadFixtures(DBFixtures{
+				Users:   []models.DBUsers{user, other},
+				Devices: []models.DBDevice{device},
+			})
+
+			caller := other.Id
+			if c.callerIsUser {
+				caller = user.Id
+			}
+
+			r := httptest.NewRequest("DELETE", "/devices/"+device.Token, nil)
+			routeCtx := chi.NewRouteContext()
+			routeCtx.URLParams.Add("token", device.Token)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeCtx))
+			w := httptest.NewRecorder()
+
+			err := s.DeleteDevice(w, r, models.AuthInfo{IsConnected: true, UserID: caller})
+			require.NoError(t, err)
+
+			resp := w.Result()
+			defer func(Body io.ReadCloser) { _ = Body.Close() }(resp.Body)
+			require.Equal(t, c.expectedCode, resp.StatusCode)
+		})
+	}
+}
+
+func Test_PatchNotificationPreferences(t *testing.T) {
+	user := models.NewDBUsersFixture()
+
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() { _ = cleanup() }()
+	s.loadFixtures(DBFixtures{
+		Users: []models.DBUsers{user},
+		NotificationPreferences: []models.DBNotificationPreferences{
+			models.DefaultNotificationPreferences(user.Id).WithMatchResult(false),
+		},
+	})
+	auth := models.AuthInfo{IsConnected: true, UserID: user.Id}
+
+	r := httptest.NewRequest("PATCH", "/notifications/preferences", strings.NewReader(`{"score_vote":false}`))
+	w := httptest.NewRecorder()
+	require.NoError(t, s.PatchNotificationPreferences(w, r, auth))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	r = httptest.NewRequest("GET", "/notifications/preferences", nil)
+	w = httptest.NewRecorder()
+	require.NoError(t, s.GetNotificationPreferences(w, r, auth))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var res models.NotificationPreferencesResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+	require.Equal(t, models.NotificationPreferencesResponse{
+		MatchFilled:   true,
+		MatchStarting: true,
+		ScoreVote:     false,
+		MatchResult:   false,
+	}, res)
+}
+
+func Test_JoinMatch_NotifiesMatchFilled(t *testing.T) {
+	court := models.NewDBCourtFixture()
+	creator := models.NewDBUsersFixture().WithUsername("creator").WithEmail("creator@example.com")
+	muted := models.NewDBUsersFixture().WithUsername("muted").WithEmail("muted@example.com")
+	joiner := models.NewDBUsersFixture().WithUsername("joiner").WithEmail("joiner@example.com")
+	last := models.NewDBUsersFixture().WithUsername("last").WithEmail("last@example.com")
+	match := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(creator.Id).
+		WithCurrentState(models.ManqueJoueur).
+		WithParticipantNber(4).
+		WithDate(time.Now().Add(24 * time.Hour))
+
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() { _ = cleanup() }()
+	s.loadFixtures(DBFixtures{
+		Courts:  []models.DBCourt{court},
+		Users:   []models.DBUsers{creator, muted, joiner, last},
+		Matches: []models.DBMatches{match},
+		UserMatches: []models.DBUserMatch{
+			models.NewDBUserMatchFixture().WithUserId(creator.Id).WithMatchId(match.Id).WithTeam(1),
+			models.NewDBUserMatchFixture().WithUserId(muted.Id).WithMatchId(match.Id).WithTeam(1),
+			models.NewDBUserMatchFixture().WithUserId(joiner.Id).WithMatchId(match.Id).WithTeam(2),
+		},
+		Devices: []models.DBDevice{
+			models.NewDBDeviceFixture().WithToken("creator-phone").WithUserId(creator.Id).WithPlatform(models.PlatformIOS),
+			models.NewDBDeviceFixture().WithToken("muted-phone").WithUserId(muted.Id),
+			models.NewDBDeviceFixture().WithToken("joiner-phone").WithUserId(joiner.Id),
+			models.NewDBDeviceFixture().WithToken("last-phone").WithUserId(last.Id),
+		},
+		NotificationPreferences: []models.DBNotificationPreferences{
+			models.DefaultNotificationPreferences(muted.Id).WithMatchFilled(false),
+		},
+	})
+
+	r := httptest.NewRequest("POST", "/match/"+match.Id+"/join", strings.NewReader(`{"team":2}`))
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("id", match.Id)
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeCtx))
+	w := httptest.NewRecorder()
+	require.NoError(t, s.JoinMatch(w, r, models.AuthInfo{IsConnected: true, UserID: last.Id}))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	_, err := s.outboxDispatcher().Drain(context.Background())
+	require.NoError(t, err)
+
+	fake := s.notifier.Providers[models.PlatformIOS].(*notifier.Fake)
+	var tokens []string
+	for _, sent := range fake.Sent() {
+		tokens = append(tokens, sent.Token)
+		require.Equal(t, match.Id, sent.Notification.Data["match_id"])
+	}
+	// Neither the player who filled the match nor the one who muted it are notified.
+	require.ElementsMatch(t, []string{"creator-phone", "joiner-phone"}, tokens)
+}
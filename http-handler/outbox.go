@@ -0,0 +1,46 @@
+package main
+
+import (
+	"PLIC/domain"
+	"PLIC/outbox"
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// outboxDispatcher delivers the outbox events with the current mailer and notifier.
+func (s *Service) outboxDispatcher() outbox.Dispatcher {
+	return outbox.Dispatcher{
+		Store:    s.db,
+		Clock:    s.clock,
+		Config:   s.configuration.Outbox,
+		Handlers: domain.OutboxHandlers(s.db, s.mailer, s.notifier),
+	}
+}
+
+// runOutboxWorker delivers the outbox events every interval until ctx is done. It
+// only runs on the local server: on Lambda the scheduler delivers them.
+func (s *Service) runOutboxWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		res, err := s.outboxDispatcher().Drain(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("outbox dispatch failed")
+		} else if res != (outbox.Result{}) {
+			log.Info().
+				Int("delivered", res.Delivered).
+				Int("retried", res.Retried).
+				Int("dead", res.Dead).
+				Msg("outbox events dispatched")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
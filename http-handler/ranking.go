@@ -2,23 +2,38 @@ package main
 
 import (
 	"PLIC/httpx"
+	"PLIC/i18n"
 	"PLIC/models"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/samber/lo"
 )
 
+const (
+	defaultCourtRankingLimit = 50
+	maxCourtRankingLimit     = 200
+)
+
 // GetRankingByCourtId godoc
 // @Summary      Classement ELO par terrain
-// @Description  Retourne la liste des utilisateurs et leur ELO pour un court donné, triée par ELO croissant
+// @Description  Retourne le classement d’un court pour un sport, trié par ELO décroissant : rang, pseudo, photo de profil, ELO, matchs terminés sur ce terrain, taux de victoire et date du dernier match.
+// @Description  Paginé par limit/offset ; le nombre total de joueurs classés est renvoyé dans l’en-tête X-Total-Count.
+// @Description  Avec around=me, la page est centrée sur l’utilisateur connecté (offset ignoré) ; s’il n’est pas classé, la première page est renvoyée.
 // @Tags         ranking
 // @Produce      json
-// @Param        id   path      string  true  "Identifiant du court"
-// @Param        sport   path      string  true  "Identifiant du sport"
+// @Param        id      path      string  true   "Identifiant du court"
+// @Param        sport   path      string  true   "Identifiant du sport"
+// @Param        limit   query     int     false  "Nombre maximum de joueurs (défaut 50, max 200)"
+// @Param        offset  query     int     false  "Nombre de joueurs à sauter (défaut 0)"
+// @Param        around  query     string  false  "me : centre la page sur l’utilisateur connecté"
 // @Success      200  {array}   models.CourtRankingResponse
-// @Failure      400  {object}  models.Error  "ID manquant"
+// @Header       200  {int}     X-Total-Count  "Nombre total de joueurs classés"
+// @Failure      400  {object}  models.Error  "ID manquant, sport ou pagination invalide"
 // @Failure      401  {object}  models.Error  "Utilisateur non autorisé"
 // @Failure      500  {object}  models.Error  "Erreur serveur / base"
 // @Router       /ranking/court/{id}/sport/{sport} [get]
@@ -30,7 +45,7 @@ func (s *Service) GetRankingByCourtId(w http.ResponseWriter, r *http.Request, ai
 
 	if !ai.IsConnected {
 		baseLogger.Warn().Msg("unauthorized")
-		return httpx.WriteError(w, http.StatusUnauthorized, "not authorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
 	}
 
 	ctx := r.Context()
@@ -42,7 +57,7 @@ func (s *Service) GetRankingByCourtId(w http.ResponseWriter, r *http.Request, ai
 
 	if id == "" {
 		l.Warn().Msg("missing court ID")
-		return httpx.WriteError(w, http.StatusBadRequest, "missing court ID")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMissingParameter, "id")
 	}
 
 	sport := models.Sport(rawSport)
@@ -50,23 +65,74 @@ func (s *Service) GetRankingByCourtId(w http.ResponseWriter, r *http.Request, ai
 	switch sport {
 	case models.Basket, models.Foot, models.PingPong:
 	default:
-		return httpx.WriteError(w, http.StatusBadRequest, "wrong sport")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrInvalidSport)
+	}
+
+	query := r.URL.Query()
+	limit, offset, err := parseLimitOffset(query, defaultCourtRankingLimit, maxCourtRankingLimit)
+	if err != nil {
+		return writeError(w, r, l, err, "invalid pagination")
+	}
+
+	switch query.Get("around") {
+	case "":
+	case "me":
+		position, err := s.db.GetCourtRankingPosition(ctx, id, sport, ai.UserID)
+		if err != nil {
+			l.Error().Err(err).Msg("db get court ranking position failed")
+			return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+		}
+		offset = 0
+		if position != nil {
+			offset = max(0, *position-limit/2)
+		}
+	default:
+		l.Warn().Str("around", query.Get("around")).Msg("invalid around")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrInvalidParameter, "around")
 	}
 
-	rows, err := s.db.GetRankingsByCourtID(ctx, id, sport)
+	rows, total, err := s.db.GetCourtRanking(ctx, models.CourtRankingQuery{
+		CourtID: id,
+		Sport:   sport,
+		Limit:   limit,
+		Offset:  offset,
+	})
 	if err != nil {
 		l.Error().Err(err).Msg("db get rankings by court failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "failed to fetch rankings")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 
-	res := lo.Map(rows, func(rnk models.DBRanking, _ int) models.CourtRankingResponse {
-		return models.CourtRankingResponse{
-			UserID: rnk.UserID,
-			Elo:    rnk.Elo,
+	userIDs := lo.Map(rows, func(rnk models.DBCourtRankingEntry, _ int) string {
+		return rnk.UserID
+	})
+
+	statsByUser, err := s.db.GetCourtPlayerStatsByIDs(ctx, id, sport, userIDs)
+	if err != nil {
+		l.Error().Err(err).Msg("prefetching court player stats failed")
+	}
+
+	profilePics := s.prefetchProfilePictures(ctx, l, userIDs)
+
+	res := lo.Map(rows, func(rnk models.DBCourtRankingEntry, _ int) models.CourtRankingResponse {
+		item := models.CourtRankingResponse{
+			Rank:     rnk.Rank,
+			UserID:   rnk.UserID,
+			Username: rnk.Username,
+			Elo:      rnk.Elo,
+		}
+		if pic, ok := profilePics[rnk.UserID]; ok {
+			item.ProfilePicture = &pic
 		}
+		if st := statsByUser[rnk.UserID]; st != nil {
+			item.MatchesPlayed = st.MatchesPlayed
+			item.Winrate = st.Winrate
+			item.LastPlayedAt = st.LastPlayedAt
+		}
+		return item
 	})
 
-	l.Info().Int("count", len(res)).Msg("rankings fetched")
+	l.Info().Int("count", len(res)).Int("total", total).Int("offset", offset).Msg("rankings fetched")
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
 	return httpx.Write(w, http.StatusOK, res)
 }
 
@@ -89,7 +155,7 @@ func (s *Service) GetRankedFieldsByUserID(w http.ResponseWriter, r *http.Request
 
 	if !ai.IsConnected {
 		baseLogger.Warn().Msg("unauthorized")
-		return httpx.WriteError(w, http.StatusUnauthorized, "not authorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
 	}
 
 	userID := chi.URLParam(r, "userId")
@@ -97,16 +163,222 @@ func (s *Service) GetRankedFieldsByUserID(w http.ResponseWriter, r *http.Request
 
 	if userID == "" {
 		logger.Warn().Msg("missing userId in url params")
-		return httpx.WriteError(w, http.StatusBadRequest, "missing userId in url params")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMissingParameter, "userId")
 	}
 
 	ctx := r.Context()
 	fields, err := s.db.GetRankedFieldsByUserID(ctx, userID)
 	if err != nil {
 		logger.Error().Err(err).Msg("db get ranked fields by user failed")
-		return httpx.WriteError(w, http.StatusInternalServerError, "failed to fetch fields")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 
 	logger.Info().Int("count", len(fields)).Msg("user fields fetched")
 	return httpx.Write(w, http.StatusOK, fields)
 }
+
+// GetUserRankingHistory godoc
+// @Summary      Historique ELO d’un utilisateur
+// @Description  Retourne l’évolution de l’ELO d’un utilisateur match après match, du plus ancien au plus récent.
+// @Description  Les paramètres court et sport permettent de restreindre l’historique à un terrain et/ou un sport.
+// @Tags         ranking
+// @Produce      json
+// @Param        userId   path      string  true   "Identifiant de l'utilisateur"
+// @Param        court    query     string  false  "Identifiant du terrain"
+// @Param        sport    query     string  false  "Sport (basket, foot, ping-pong)"
+// @Success      200  {array}   models.RankingHistoryResponse
+// @Failure      400  {object}  models.Error  "userId manquant / sport invalide"
+// @Failure      401  {object}  models.Error  "Utilisateur non autorisé"
+// @Failure      500  {object}  models.Error  "Erreur serveur / base"
+// @Router       /ranking/user/{userId}/history [get]
+// @Security     BearerAuth
+func (s *Service) GetUserRankingHistory(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+	baseLogger := log.With().
+		Str("method", "GetUserRankingHistory").
+		Str("user_id", ai.UserID).
+		Logger()
+
+	if !ai.IsConnected {
+		baseLogger.Warn().Msg("unauthorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+
+	userID := chi.URLParam(r, "userId")
+	logger := baseLogger.With().Str("target_user_id", userID).Logger()
+
+	if userID == "" {
+		logger.Warn().Msg("missing userId in url params")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMissingParameter, "userId")
+	}
+
+	var courtID *string
+	if c := r.URL.Query().Get("court"); c != "" {
+		courtID = &c
+	}
+
+	var sport *models.Sport
+	if raw := r.URL.Query().Get("sport"); raw != "" {
+		sp := models.Sport(raw)
+		switch sp {
+		case models.Basket, models.Foot, models.PingPong:
+		default:
+			logger.Warn().Str("sport", raw).Msg("wrong sport")
+			return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrInvalidSport)
+		}
+		sport = &sp
+	}
+
+	ctx := r.Context()
+	history, err := s.db.GetRankingHistory(ctx, userID, courtID, sport)
+	if err != nil {
+		logger.Error().Err(err).Msg("db get ranking history failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+
+	res := lo.Map(history, func(h models.DBRankingHistory, _ int) models.RankingHistoryResponse {
+		return models.RankingHistoryResponse{
+			MatchID:   h.MatchID,
+			CourtID:   h.CourtID,
+			Sport:     h.Sport,
+			EloBefore: h.EloBefore,
+			EloAfter:  h.EloAfter,
+			Delta:     h.Delta,
+			Date:      h.CreatedAt,
+		}
+	})
+
+	logger.Info().Int("count", len(res)).Msg("ranking history fetched")
+	return httpx.Write(w, http.StatusOK, res)
+}
+
+const (
+	defaultLeaderboardLimit = 50
+	maxLeaderboardLimit     = 200
+)
+
+// GetGlobalLeaderboard godoc
+// @Summary      Classement national par sport
+// @Description  Classe les joueurs d’un sport sur l’ensemble des terrains. La note de chaque joueur est la moyenne de ses ELO par terrain, pondérée par le nombre de matchs joués sur chacun.
+// @Description  Renvoie aussi le rang de l’utilisateur connecté (me), null s’il n’est pas classé.
+// @Tags         ranking
+// @Produce      json
+// @Param        sport   path      string  true   "Sport (basket, foot, ping-pong)"
+// @Param        limit   query     int     false  "Nombre maximum de joueurs (défaut 50, max 200)"
+// @Param        offset  query     int     false  "Nombre de joueurs à sauter (défaut 0)"
+// @Success      200  {object}  models.LeaderboardResponse
+// @Failure      400  {object}  models.Error  "Sport ou pagination invalide"
+// @Failure      401  {object}  models.Error  "Utilisateur non autorisé"
+// @Failure      500  {object}  models.Error  "Erreur serveur / base"
+// @Router       /ranking/global/{sport} [get]
+// @Security     BearerAuth
+func (s *Service) GetGlobalLeaderboard(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+	logger := log.With().
+		Str("method", "GetGlobalLeaderboard").
+		Str("user_id", ai.UserID).
+		Logger()
+
+	if !ai.IsConnected {
+		logger.Warn().Msg("unauthorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+
+	return s.writeLeaderboard(w, r, ai, logger, nil)
+}
+
+// GetCityLeaderboard godoc
+// @Summary      Classement d’une ville par sport
+// @Description  Classe les joueurs d’un sport sur les terrains d’une ville (insensible à la casse). La note de chaque joueur est la moyenne de ses ELO sur ces terrains, pondérée par le nombre de matchs joués sur chacun.
+// @Description  Renvoie aussi le rang de l’utilisateur connecté (me), null s’il n’est pas classé dans cette ville.
+// @Tags         ranking
+// @Produce      json
+// @Param        city    path      string  true   "Ville"
+// @Param        sport   path      string  true   "Sport (basket, foot, ping-pong)"
+// @Param        limit   query     int     false  "Nombre maximum de joueurs (défaut 50, max 200)"
+// @Param        offset  query     int     false  "Nombre de joueurs à sauter (défaut 0)"
+// @Success      200  {object}  models.LeaderboardResponse
+// @Failure      400  {object}  models.Error  "Ville manquante ou invalide, sport ou pagination invalide"
+// @Failure      401  {object}  models.Error  "Utilisateur non autorisé"
+// @Failure      500  {object}  models.Error  "Erreur serveur / base"
+// @Router       /ranking/city/{city}/{sport} [get]
+// @Security     BearerAuth
+func (s *Service) GetCityLeaderboard(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+	rawCity := chi.URLParam(r, "city")
+	logger := log.With().
+		Str("method", "GetCityLeaderboard").
+		Str("user_id", ai.UserID).
+		Str("city", rawCity).
+		Logger()
+
+	if !ai.IsConnected {
+		logger.Warn().Msg("unauthorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+
+	city, err := url.PathUnescape(rawCity)
+	if err != nil {
+		logger.Warn().Err(err).Msg("invalid city")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrInvalidCity)
+	}
+	if city == "" {
+		logger.Warn().Msg("missing city")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrInvalidCity)
+	}
+	return s.writeLeaderboard(w, r, ai, logger, &city)
+}
+
+// writeLeaderboard serves the global leaderboard when city is nil, the city one otherwise.
+func (s *Service) writeLeaderboard(w http.ResponseWriter, r *http.Request, ai models.AuthInfo, logger zerolog.Logger, city *string) error {
+	sport := models.Sport(chi.URLParam(r, "sport"))
+	switch sport {
+	case models.Basket, models.Foot, models.PingPong:
+	default:
+		logger.Warn().Str("sport", string(sport)).Msg("wrong sport")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrInvalidSport)
+	}
+
+	limit, offset, err := parseLimitOffset(r.URL.Query(), defaultLeaderboardLimit, maxLeaderboardLimit)
+	if err != nil {
+		return writeError(w, r, logger, err, "invalid pagination")
+	}
+
+	ctx := r.Context()
+	entries, total, err := s.db.GetLeaderboard(ctx, models.LeaderboardQuery{
+		Sport:  sport,
+		City:   city,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("db get leaderboard failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+
+	me, err := s.db.GetLeaderboardEntry(ctx, sport, city, ai.UserID)
+	if err != nil {
+		logger.Error().Err(err).Msg("db get leaderboard entry failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+
+	toResponse := func(e models.DBLeaderboardEntry, _ int) models.LeaderboardEntryResponse {
+		return models.LeaderboardEntryResponse{
+			Rank:        e.Rank,
+			UserID:      e.UserID,
+			Username:    e.Username,
+			Rating:      e.Rating,
+			GamesPlayed: e.GamesPlayed,
+		}
+	}
+
+	res := models.LeaderboardResponse{
+		Entries: lo.Map(entries, toResponse),
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+	}
+	if me != nil {
+		res.Me = ptr(toResponse(*me, 0))
+	}
+
+	logger.Info().Str("sport", string(sport)).Int("count", len(res.Entries)).Int("total", total).Msg("leaderboard fetched")
+	return httpx.Write(w, http.StatusOK, res)
+}
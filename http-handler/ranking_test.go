@@ -8,10 +8,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"sort"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/require"
 )
 
@@ -200,6 +202,162 @@ func Test_GetRankingByCourtId(t *testing.T) {
 	}
 }
 
+func Test_GetRankingByCourtId_Rich(t *testing.T) {
+	type expected struct {
+		code     int
+		users    []string
+		ranks    []int
+		total    string
+		errorMsg string
+	}
+
+	type testCase struct {
+		name     string
+		query    string
+		auth     models.AuthInfo
+		expected expected
+	}
+
+	court := models.NewDBCourtFixture()
+	users := make([]models.DBUsers, 5)
+	rankings := make([]models.DBRanking, 5)
+	for i := range users {
+		users[i] = models.NewDBUsersFixture().
+			WithUsername("player" + strconv.Itoa(i)).
+			WithEmail("player" + strconv.Itoa(i) + "@example.com")
+		rankings[i] = models.NewDBRankingFixture().
+			WithUserId(users[i].Id).
+			WithCourtId(court.Id).
+			WithSport(models.Basket).
+			WithElo(1500 - 100*i)
+	}
+
+	playedAt := time.Date(2025, 5, 1, 18, 0, 0, 0, time.UTC)
+	won := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(users[0].Id).
+		WithSport(models.Basket).
+		WithCurrentState(models.Termine).
+		WithScore1(21).
+		WithScore2(15).
+		WithDate(playedAt)
+	lost := models.NewDBMatchesFixture().
+		WithCourtId(court.Id).
+		WithCreatorId(users[0].Id).
+		WithSport(models.Basket).
+		WithCurrentState(models.Termine).
+		WithScore1(10).
+		WithScore2(21).
+		WithDate(playedAt.Add(-24 * time.Hour))
+
+	fixtures := DBFixtures{
+		Courts:   []models.DBCourt{court},
+		Users:    users,
+		Rankings: rankings,
+		Matches:  []models.DBMatches{won, lost},
+		UserMatches: []models.DBUserMatch{
+			models.NewDBUserMatchFixture().WithUserId(users[0].Id).WithMatchId(won.Id).WithTeam(1),
+			models.NewDBUserMatchFixture().WithUserId(users[1].Id).WithMatchId(won.Id).WithTeam(2),
+			models.NewDBUserMatchFixture().WithUserId(users[0].Id).WithMatchId(lost.Id).WithTeam(1),
+			models.NewDBUserMatchFixture().WithUserId(users[1].Id).WithMatchId(lost.Id).WithTeam(2),
+		},
+	}
+
+	ids := func(idx ...int) []string {
+		return lo.Map(idx, func(i int, _ int) string { return users[i].Id })
+	}
+
+	testCases := []testCase{
+		{
+			name:     "First page",
+			query:    "?limit=2",
+			auth:     models.AuthInfo{IsConnected: true, UserID: users[0].Id},
+			expected: expected{code: http.StatusOK, users: ids(0, 1), ranks: []int{1, 2}, total: "5"},
+		},
+		{
+			name:     "Offset",
+			query:    "?limit=2&offset=3",
+			auth:     models.AuthInfo{IsConnected: true, UserID: users[0].Id},
+			expected: expected{code: http.StatusOK, users: ids(3, 4), ranks: []int{4, 5}, total: "5"},
+		},
+		{
+			name:     "Centered on me",
+			query:    "?limit=3&around=me",
+			auth:     models.AuthInfo{IsConnected: true, UserID: users[3].Id},
+			expected: expected{code: http.StatusOK, users: ids(2, 3, 4), ranks: []int{3, 4, 5}, total: "5"},
+		},
+		{
+			name:     "Centered on me, not ranked -> first page",
+			query:    "?limit=2&around=me",
+			auth:     models.AuthInfo{IsConnected: true, UserID: "someone-else"},
+			expected: expected{code: http.StatusOK, users: ids(0, 1), ranks: []int{1, 2}, total: "5"},
+		},
+		{
+			name:     "Invalid around",
+			query:    "?around=you",
+			auth:     models.AuthInfo{IsConnected: true, UserID: users[0].Id},
+			expected: expected{code: http.StatusBadRequest, errorMsg: "Paramètre invalide : around"},
+		},
+		{
+			name:     "Invalid offset",
+			query:    "?offset=-1",
+			auth:     models.AuthInfo{IsConnected: true, UserID: users[0].Id},
+			expected: expected{code: http.StatusBadRequest, errorMsg: "Paramètre invalide : offset"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() { _ = cleanup() }()
+			s.loadFixtures(fixtures)
+
+			r := httptest.NewRequest("GET", "/ranking/court/"+court.Id+"/sport/basket"+tc.query, nil)
+			routeCtx := chi.NewRouteContext()
+			routeCtx.URLParams.Add("id", court.Id)
+			routeCtx.URLParams.Add("sport", string(models.Basket))
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeCtx))
+
+			w := httptest.NewRecorder()
+			err := s.GetRankingByCourtId(w, r, tc.auth)
+			require.NoError(t, err)
+
+			resp := w.Result()
+			defer func(Body io.ReadCloser) { _ = Body.Close() }(resp.Body)
+			require.Equal(t, tc.expected.code, resp.StatusCode)
+
+			body, _ := io.ReadAll(resp.Body)
+			if tc.expected.errorMsg != "" {
+				require.Contains(t, string(body), tc.expected.errorMsg)
+				return
+			}
+
+			var out []models.CourtRankingResponse
+			require.NoError(t, json.Unmarshal(body, &out))
+			require.Equal(t, tc.expected.total, resp.Header.Get("X-Total-Count"))
+			require.Equal(t, tc.expected.users, lo.Map(out, func(e models.CourtRankingResponse, _ int) string { return e.UserID }))
+			require.Equal(t, tc.expected.ranks, lo.Map(out, func(e models.CourtRankingResponse, _ int) int { return e.Rank }))
+
+			for _, e := range out {
+				require.NotEmpty(t, e.Username)
+				require.Equal(t, ptr(e.UserID+".png"), e.ProfilePicture)
+				switch e.UserID {
+				case users[0].Id, users[1].Id:
+					require.Equal(t, 2, e.MatchesPlayed)
+					require.Equal(t, ptr(50), e.Winrate)
+					require.NotNil(t, e.LastPlayedAt)
+					require.True(t, playedAt.Equal(*e.LastPlayedAt))
+				default:
+					require.Equal(t, 0, e.MatchesPlayed)
+					require.Nil(t, e.Winrate)
+					require.Nil(t, e.LastPlayedAt)
+				}
+			}
+		})
+	}
+}
+
 func Test_GetUserFields(t *testing.T) {
 	type expected struct {
 		code          int
@@ -331,3 +489,256 @@ func Test_GetUserFields(t *testing.T) {
 		})
 	}
 }
+
+func Test_GetUserRankingHistory(t *testing.T) {
+	type expected struct {
+		code          int
+		deltas        []int
+		errorContains string
+	}
+
+	type testCase struct {
+		name     string
+		fixtures DBFixtures
+		param    string
+		query    string
+		auth     models.AuthInfo
+		expected expected
+	}
+
+	user := models.NewDBUsersFixture()
+	c1 := models.NewDBCourtFixture()
+	c2 := models.NewDBCourtFixture()
+
+	m1 := models.NewDBMatchesFixture().WithCourtId(c1.Id).WithSport(models.Basket).WithCreatorId(user.Id)
+	m2 := models.NewDBMatchesFixture().WithCourtId(c1.Id).WithSport(models.Basket).WithCreatorId(user.Id)
+	m3 := models.NewDBMatchesFixture().WithCourtId(c2.Id).WithSport(models.Foot).WithCreatorId(user.Id)
+
+	now := time.Now()
+	h1 := models.NewDBRankingHistoryFixture().WithUserId(user.Id).WithCourtId(c1.Id).WithSport(models.Basket).
+		WithMatchId(m1.Id).WithElo(1000, 1016).WithCreatedAt(now.Add(-2 * time.Hour))
+	h2 := models.NewDBRankingHistoryFixture().WithUserId(user.Id).WithCourtId(c1.Id).WithSport(models.Basket).
+		WithMatchId(m2.Id).WithElo(1016, 1001).WithCreatedAt(now.Add(-time.Hour))
+	h3 := models.NewDBRankingHistoryFixture().WithUserId(user.Id).WithCourtId(c2.Id).WithSport(models.Foot).
+		WithMatchId(m3.Id).WithElo(1000, 1020).WithCreatedAt(now)
+
+	fixtures := DBFixtures{
+		Users:   []models.DBUsers{user},
+		Courts:  []models.DBCourt{c1, c2},
+		Matches: []models.DBMatches{m1, m2, m3},
+		History: []models.DBRankingHistory{h3, h1, h2},
+	}
+
+	tests := []testCase{
+		{
+			name:     "all history, oldest first",
+			fixtures: fixtures,
+			param:    user.Id,
+			auth:     models.AuthInfo{IsConnected: true, UserID: user.Id},
+			expected: expected{code: http.StatusOK, deltas: []int{16, -15, 20}},
+		},
+		{
+			name:     "filtered by court and sport",
+			fixtures: fixtures,
+			param:    user.Id,
+			query:    "?court=" + c1.Id + "&sport=basket",
+			auth:     models.AuthInfo{IsConnected: true, UserID: user.Id},
+			expected: expected{code: http.StatusOK, deltas: []int{16, -15}},
+		},
+		{
+			name:     "filtered by sport only",
+			fixtures: fixtures,
+			param:    user.Id,
+			query:    "?sport=foot",
+			auth:     models.AuthInfo{IsConnected: true, UserID: user.Id},
+			expected: expected{code: http.StatusOK, deltas: []int{20}},
+		},
+		{
+			name:     "wrong sport",
+			param:    user.Id,
+			query:    "?sport=curling",
+			auth:     models.AuthInfo{IsConnected: true, UserID: user.Id},
+			expected: expected{code: http.StatusBadRequest, errorContains: "wrong sport"},
+		},
+		{
+			name:     "missing userId",
+			param:    "",
+			auth:     models.AuthInfo{IsConnected: true, UserID: user.Id},
+			expected: expected{code: http.StatusBadRequest, errorContains: "missing userId"},
+		},
+		{
+			name:     "unauthorized",
+			param:    user.Id,
+			auth:     models.AuthInfo{IsConnected: false, UserID: user.Id},
+			expected: expected{code: http.StatusUnauthorized, errorContains: "not authorized"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() { _ = cleanup() }()
+			s.loadFixtures(tc.fixtures)
+
+			r := httptest.NewRequest("GET", "/ranking/user/"+tc.param+"/history"+tc.query, nil)
+
+			routeCtx := chi.NewRouteContext()
+			if tc.param != "" {
+				routeCtx.URLParams.Add("userId", tc.param)
+			}
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeCtx))
+
+			w := httptest.NewRecorder()
+			err := s.GetUserRankingHistory(w, r, tc.auth)
+			require.NoError(t, err)
+
+			resp := w.Result()
+			defer func(Body io.ReadCloser) { _ = Body.Close() }(resp.Body)
+			require.Equal(t, tc.expected.code, resp.StatusCode)
+
+			body, _ := io.ReadAll(resp.Body)
+
+			if tc.expected.errorContains != "" {
+				require.Contains(t, string(body), tc.expected.errorContains)
+				return
+			}
+
+			var got []models.RankingHistoryResponse
+			require.NoError(t, json.Unmarshal(body, &got))
+			require.Equal(t, tc.expected.deltas, lo.Map(got, func(h models.RankingHistoryResponse, _ int) int {
+				return h.Delta
+			}))
+		})
+	}
+}
+
+func Test_GetLeaderboard(t *testing.T) {
+	type expected struct {
+		code          int
+		users         []string
+		total         int
+		myRank        *int
+		errorContains string
+	}
+
+	type testCase struct {
+		name     string
+		city     *string
+		sport    string
+		query    string
+		auth     models.AuthInfo
+		expected expected
+	}
+
+	paris := models.NewDBCourtFixture().WithAddress("1 rue A, 75011 Paris")
+	lyon := models.NewDBCourtFixture().WithAddress("2 rue B, 69002 Lyon")
+
+	alice := models.NewDBUsersFixture().WithUsername("alice").WithEmail("alice@example.com")
+	bob := models.NewDBUsersFixture().WithUsername("bob").WithEmail("bob@example.com")
+	carol := models.NewDBUsersFixture().WithUsername("carol").WithEmail("carol@example.com")
+
+	fixtures := DBFixtures{
+		Courts: []models.DBCourt{paris, lyon},
+		Users:  []models.DBUsers{alice, bob, carol},
+		Rankings: []models.DBRanking{
+			models.NewDBRankingFixture().WithUserId(alice.Id).WithCourtId(paris.Id).WithElo(1100).WithGamesPlayed(2),
+			models.NewDBRankingFixture().WithUserId(bob.Id).WithCourtId(paris.Id).WithElo(1050).WithGamesPlayed(4),
+			models.NewDBRankingFixture().WithUserId(carol.Id).WithCourtId(lyon.Id).WithElo(1200).WithGamesPlayed(1),
+		},
+	}
+
+	testCases := []testCase{
+		{
+			name:     "Global leaderboard with my rank",
+			sport:    "basket",
+			auth:     models.AuthInfo{IsConnected: true, UserID: bob.Id},
+			expected: expected{code: http.StatusOK, users: []string{carol.Id, alice.Id, bob.Id}, total: 3, myRank: ptr(3)},
+		},
+		{
+			name:     "City leaderboard, paginated",
+			city:     ptr("Paris"),
+			sport:    "basket",
+			query:    "?limit=1",
+			auth:     models.AuthInfo{IsConnected: true, UserID: bob.Id},
+			expected: expected{code: http.StatusOK, users: []string{alice.Id}, total: 2, myRank: ptr(2)},
+		},
+		{
+			name:     "Caller not ranked in this city",
+			city:     ptr("Paris"),
+			sport:    "basket",
+			auth:     models.AuthInfo{IsConnected: true, UserID: carol.Id},
+			expected: expected{code: http.StatusOK, users: []string{alice.Id, bob.Id}, total: 2},
+		},
+		{
+			name:     "Wrong sport",
+			sport:    "curling",
+			auth:     models.AuthInfo{IsConnected: true, UserID: bob.Id},
+			expected: expected{code: http.StatusBadRequest, errorContains: "wrong sport"},
+		},
+		{
+			name:     "Invalid limit",
+			sport:    "basket",
+			query:    "?limit=0",
+			auth:     models.AuthInfo{IsConnected: true, UserID: bob.Id},
+			expected: expected{code: http.StatusBadRequest, errorContains: "invalid limit"},
+		},
+		{
+			name:     "Unauthorized",
+			sport:    "basket",
+			auth:     models.AuthInfo{IsConnected: false},
+			expected: expected{code: http.StatusUnauthorized, errorContains: "not authorized"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() { _ = cleanup() }()
+			s.loadFixtures(fixtures)
+
+			routeCtx := chi.NewRouteContext()
+			routeCtx.URLParams.Add("sport", tc.sport)
+			url := "/ranking/global/" + tc.sport + tc.query
+			if tc.city != nil {
+				routeCtx.URLParams.Add("city", *tc.city)
+				url = "/ranking/city/" + *tc.city + "/" + tc.sport + tc.query
+			}
+			r := httptest.NewRequest("GET", url, nil)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeCtx))
+
+			w := httptest.NewRecorder()
+			var err error
+			if tc.city != nil {
+				err = s.GetCityLeaderboard(w, r, tc.auth)
+			} else {
+				err = s.GetGlobalLeaderboard(w, r, tc.auth)
+			}
+			require.NoError(t, err)
+
+			resp := w.Result()
+			defer func(Body io.ReadCloser) { _ = Body.Close() }(resp.Body)
+			require.Equal(t, tc.expected.code, resp.StatusCode)
+
+			body, _ := io.ReadAll(resp.Body)
+			if tc.expected.errorContains != "" {
+				require.Contains(t, string(body), tc.expected.errorContains)
+				return
+			}
+
+			var got models.LeaderboardResponse
+			require.NoError(t, json.Unmarshal(body, &got))
+			require.Equal(t, tc.expected.total, got.Total)
+			require.Equal(t, tc.expected.users, lo.Map(got.Entries, func(e models.LeaderboardEntryResponse, _ int) string {
+				return e.UserID
+			}))
+			if tc.expected.myRank == nil {
+				require.Nil(t, got.Me)
+			} else {
+				require.NotNil(t, got.Me)
+				require.Equal(t, *tc.expected.myRank, got.Me.Rank)
+			}
+		})
+	}
+}
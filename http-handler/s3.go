@@ -2,6 +2,7 @@ package main
 
 import (
 	"PLIC/httpx"
+	"PLIC/i18n"
 	"PLIC/models"
 	"bytes"
 	"errors"
@@ -32,7 +33,7 @@ func (s *Service) UploadProfilePictureToS3(w http.ResponseWriter, r *http.Reques
 
 	if !ai.IsConnected {
 		logger.Warn().Msg("unauthorized user tried to upload profile picture")
-		return httpx.WriteError(w, http.StatusUnauthorized, "not authorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
 	}
 
 	ctx := r.Context()
@@ -43,9 +44,8 @@ func (s *Service) UploadProfilePictureToS3(w http.ResponseWriter, r *http.Reques
 
 	file, _, err := r.FormFile("image")
 	if err != nil {
-		msg := "Image file not found or incorrect format"
-		logger.Warn().Err(err).Msg(msg)
-		return httpx.WriteError(w, http.StatusBadRequest, msg)
+		logger.Warn().Err(err).Msg("image file not found or incorrect format")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrInvalidImage)
 	}
 	defer func() {
 		if err := file.Close(); err != nil {
@@ -56,7 +56,7 @@ func (s *Service) UploadProfilePictureToS3(w http.ResponseWriter, r *http.Reques
 	buf := new(bytes.Buffer)
 	if _, err := buf.ReadFrom(file); err != nil {
 		logger.Error().Err(err).Msg("failed to read uploaded file")
-		return httpx.WriteError(w, http.StatusInternalServerError, httpx.InternalServerError)
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 
 	if err := s.s3Service.PutObject(ctx, bucketName, objectKey, buf); err != nil {
@@ -70,7 +70,7 @@ func (s *Service) UploadProfilePictureToS3(w http.ResponseWriter, r *http.Reques
 		} else {
 			logger.Error().Err(err).Msg("failed to upload to S3")
 		}
-		return httpx.WriteError(w, http.StatusInternalServerError, httpx.InternalServerError)
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 
 	logger.Info().Msg("profile picture uploaded successfully")
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"PLIC/httpx"
+	"PLIC/i18n"
+	"PLIC/models"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+func generateSecureToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *Service) openSession(ctx context.Context, userID string) (models.LoginResponse, error) {
+	now := s.clock.Now()
+
+	refreshToken, err := generateSecureToken()
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	session := models.DBSession{
+		Id:               uuid.NewString(),
+		UserID:           userID,
+		RefreshTokenHash: hashToken(refreshToken),
+		ExpiresAt:        now.Add(refreshTokenTTL),
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if err := s.db.CreateSession(ctx, session); err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	token, err := GenerateJWT(userID, session.Id, now)
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	return models.LoginResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(accessTokenTTL.Seconds()),
+		UserId:       userID,
+	}, nil
+}
+
+// RefreshToken godoc
+// @Summary      Refresh an access token
+// @Description  Exchange a refresh token for a new access token. The refresh token is rotated: the one sent can not be used again.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request body models.RefreshTokenRequest true "Refresh token"
+// @Success      200 {object} models.LoginResponse
+// @Failure      400 {object} models.Error "Bad request"
+// @Failure      401 {object} models.Error "Invalid, expired or revoked refresh token"
+// @Failure      500 {object} models.Error "Internal server error"
+// @Router       /token/refresh [post]
+func (s *Service) RefreshToken(w http.ResponseWriter, r *http.Request, _ models.AuthInfo) error {
+	logger := log.With().Str("method", "RefreshToken").Logger()
+
+	ctx := r.Context()
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(r.Body)
+
+	var req models.RefreshTokenRequest
+	if err := httpx.DecodeJSON(w, r, &req, s.clock.Now()); err != nil {
+		return writeError(w, r, logger, err, "invalid JSON body")
+	}
+	if req.RefreshToken == "" {
+		logger.Warn().Msg("missing refresh token")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrBadRequest)
+	}
+
+	oldHash := hashToken(req.RefreshToken)
+	session, err := s.db.GetSessionByRefreshTokenHash(ctx, oldHash)
+	if err != nil {
+		logger.Error().Err(err).Msg("db get session failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+	now := s.clock.Now()
+	if session == nil || session.RevokedAt != nil || !session.ExpiresAt.After(now) {
+		logger.Warn().Msg("unknown, revoked or expired refresh token")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+
+	logger = logger.With().
+		Str("user_id", session.UserID).
+		Str("session_id", session.Id).
+		Logger()
+
+	refreshToken, err := generateSecureToken()
+	if err != nil {
+		logger.Error().Err(err).Msg("refresh token generation failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+
+	rotated, err := s.db.RotateSessionRefreshToken(ctx, session.Id, oldHash, hashToken(refreshToken), now.Add(refreshTokenTTL), now)
+	if err != nil {
+		logger.Error().Err(err).Msg("db rotate session failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+	if !rotated {
+		logger.Warn().Msg("refresh token already used")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+
+	token, err := GenerateJWT(session.UserID, session.Id, now)
+	if err != nil {
+		logger.Error().Err(err).Msg("JWT generation failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+
+	logger.Info().Msg("access token refreshed")
+	return httpx.Write(w, http.StatusOK, models.LoginResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(accessTokenTTL.Seconds()),
+		UserId:       session.UserID,
+	})
+}
+
+// Logout godoc
+// @Summary      Log out the current device
+// @Description  Revoke the session of the access token used for this request
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} nil "Session revoked"
+// @Failure      401 {object} models.Error "Unauthorized"
+// @Failure      500 {object} models.Error "Internal server error"
+// @Router       /logout [post]
+func (s *Service) Logout(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+	logger := log.With().
+		Str("method", "Logout").
+		Str("user_id", ai.UserID).
+		Str("session_id", ai.SessionID).
+		Logger()
+
+	if !ai.IsConnected {
+		logger.Warn().Msg("unauthorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+
+	if err := s.db.RevokeSession(r.Context(), ai.SessionID, ai.UserID, s.clock.Now()); err != nil {
+		logger.Error().Err(err).Msg("db revoke session failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+
+	logger.Info().Msg("session revoked")
+	return httpx.Write(w, http.StatusOK, nil)
+}
+
+// LogoutAll godoc
+// @Summary      Log out all devices
+// @Description  Revoke every session of the connected user, including the current one
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} nil "Sessions revoked"
+// @Failure      401 {object} models.Error "Unauthorized"
+// @Failure      500 {object} models.Error "Internal server error"
+// @Router       /logout/all [post]
+func (s *Service) LogoutAll(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+	logger := log.With().
+		Str("method", "LogoutAll").
+		Str("user_id", ai.UserID).
+		Logger()
+
+	if !ai.IsConnected {
+		logger.Warn().Msg("unauthorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
+	}
+
+	if err := s.db.RevokeUserSessions(r.Context(), ai.UserID, "", s.clock.Now()); err != nil {
+		logger.Error().Err(err).Msg("db revoke sessions failed")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+	}
+
+	logger.Info().Msg("all sessions revoked")
+	return httpx.Write(w, http.StatusOK, nil)
+}
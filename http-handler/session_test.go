@@ -0,0 +1,300 @@
+package main
+
+import (
+	"PLIC/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_RefreshToken(t *testing.T) {
+	type testCase struct {
+		name         string
+		fixtures     DBFixtures
+		refreshToken string
+		expectedCode int
+	}
+
+	userId := uuid.NewString()
+	sessionId := uuid.NewString()
+	refreshToken := "my-refresh-token"
+
+	testCases := []testCase{
+		{
+			name: "Active session -> 200 with rotated tokens",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{models.NewDBUsersFixture().WithId(userId)},
+				Sessions: []models.DBSession{
+					models.NewDBSessionFixture().
+						WithId(sessionId).
+						WithUserId(userId).
+						WithRefreshTokenHash(hashToken(refreshToken)),
+				},
+			},
+			refreshToken: refreshToken,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "Unknown refresh token -> 401",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{models.NewDBUsersFixture().WithId(userId)},
+			},
+			refreshToken: refreshToken,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name: "Revoked session -> 401",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{models.NewDBUsersFixture().WithId(userId)},
+				Sessions: []models.DBSession{
+					models.NewDBSessionFixture().
+						WithId(sessionId).
+						WithUserId(userId).
+						WithRefreshTokenHash(hashToken(refreshToken)).
+						WithRevokedAt(time.Now().Add(-time.Minute)),
+				},
+			},
+			refreshToken: refreshToken,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name: "Expired session -> 401",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{models.NewDBUsersFixture().WithId(userId)},
+				Sessions: []models.DBSession{
+					models.NewDBSessionFixture().
+						WithId(sessionId).
+						WithUserId(userId).
+						WithRefreshTokenHash(hashToken(refreshToken)).
+						WithExpiresAt(time.Now().Add(-time.Minute)),
+				},
+			},
+			refreshToken: refreshToken,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "Missing refresh token -> 400",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() {
+				if err := cleanup(); err != nil {
+					t.Logf("cleanup error: %v", err)
+				}
+			}()
+			s.loadFixtures(c.fixtures)
+
+			body, _ := json.Marshal(models.RefreshTokenRequest{RefreshToken: c.refreshToken})
+			req := httptest.NewRequest("POST", "/token/refresh", bytes.NewBuffer(body))
+			w := httptest.NewRecorder()
+
+			err := s.RefreshToken(w, req, models.AuthInfo{})
+			require.NoError(t, err)
+
+			resp := w.Result()
+			defer func(Body io.ReadCloser) {
+				_ = Body.Close()
+			}(resp.Body)
+			require.Equal(t, c.expectedCode, resp.StatusCode)
+			if c.expectedCode != http.StatusOK {
+				return
+			}
+
+			var actual models.LoginResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&actual))
+			require.Equal(t, userId, actual.UserId)
+			require.NotEmpty(t, actual.RefreshToken)
+			require.NotEqual(t, c.refreshToken, actual.RefreshToken)
+
+			parsedToken, err := jwt.Parse(actual.Token, func(token *jwt.Token) (interface{}, error) {
+				return []byte(jwtSecret), nil
+			})
+			require.NoError(t, err)
+			claims, ok := parsedToken.Claims.(jwt.MapClaims)
+			require.True(t, ok)
+			require.Equal(t, userId, claims["user_id"])
+			require.Equal(t, sessionId, claims["sid"])
+
+			// The old refresh token has been rotated and can not be used again.
+			req = httptest.NewRequest("POST", "/token/refresh", bytes.NewBuffer(body))
+			w = httptest.NewRecorder()
+			require.NoError(t, s.RefreshToken(w, req, models.AuthInfo{}))
+			require.Equal(t, http.StatusUnauthorized, w.Result().StatusCode)
+		})
+	}
+}
+
+func TestService_Logout(t *testing.T) {
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() {
+		if err := cleanup(); err != nil {
+			t.Logf("cleanup error: %v", err)
+		}
+	}()
+
+	ctx := context.Background()
+	userId := uuid.NewString()
+	currentSessionId := uuid.NewString()
+	otherSessionId := uuid.NewString()
+
+	s.loadFixtures(DBFixtures{
+		Users: []models.DBUsers{models.NewDBUsersFixture().WithId(userId)},
+		Sessions: []models.DBSession{
+			models.NewDBSessionFixture().WithId(currentSessionId).WithUserId(userId),
+			models.NewDBSessionFixture().WithId(otherSessionId).WithUserId(userId),
+		},
+	})
+
+	req := httptest.NewRequest("POST", "/logout", nil)
+	w := httptest.NewRecorder()
+	err := s.Logout(w, req, models.AuthInfo{IsConnected: true, UserID: userId, SessionID: currentSessionId})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+	active, err := s.db.IsSessionActive(ctx, currentSessionId, userId, time.Now())
+	require.NoError(t, err)
+	require.False(t, active)
+
+	active, err = s.db.IsSessionActive(ctx, otherSessionId, userId, time.Now())
+	require.NoError(t, err)
+	require.True(t, active)
+}
+
+func TestService_LogoutAll(t *testing.T) {
+	s := &Service{}
+	cleanup := s.InitServiceTest()
+	defer func() {
+		if err := cleanup(); err != nil {
+			t.Logf("cleanup error: %v", err)
+		}
+	}()
+
+	ctx := context.Background()
+	userId := uuid.NewString()
+	otherUserId := uuid.NewString()
+	sessionIds := []string{uuid.NewString(), uuid.NewString()}
+	otherUserSessionId := uuid.NewString()
+
+	s.loadFixtures(DBFixtures{
+		Users: []models.DBUsers{
+			models.NewDBUsersFixture().WithId(userId),
+			models.NewDBUsersFixture().WithId(otherUserId),
+		},
+		Sessions: []models.DBSession{
+			models.NewDBSessionFixture().WithId(sessionIds[0]).WithUserId(userId),
+			models.NewDBSessionFixture().WithId(sessionIds[1]).WithUserId(userId),
+			models.NewDBSessionFixture().WithId(otherUserSessionId).WithUserId(otherUserId),
+		},
+	})
+
+	req := httptest.NewRequest("POST", "/logout/all", nil)
+	w := httptest.NewRecorder()
+	err := s.LogoutAll(w, req, models.AuthInfo{IsConnected: true, UserID: userId, SessionID: sessionIds[0]})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+	for _, id := range sessionIds {
+		active, err := s.db.IsSessionActive(ctx, id, userId, time.Now())
+		require.NoError(t, err)
+		require.False(t, active)
+	}
+
+	active, err := s.db.IsSessionActive(ctx, otherUserSessionId, otherUserId, time.Now())
+	require.NoError(t, err)
+	require.True(t, active)
+}
+
+func TestService_WithAuthentication(t *testing.T) {
+	type testCase struct {
+		name              string
+		fixtures          DBFixtures
+		claims            jwt.MapClaims
+		expectedConnected bool
+	}
+
+	userId := uuid.NewString()
+	sessionId := uuid.NewString()
+	now := time.Now()
+
+	testCases := []testCase{
+		{
+			name: "Active session -> connected",
+			fixtures: DBFixtures{
+				Users:    []models.DBUsers{models.NewDBUsersFixture().WithId(userId)},
+				Sessions: []models.DBSession{models.NewDBSessionFixture().WithId(sessionId).WithUserId(userId)},
+			},
+			claims:            jwt.MapClaims{"user_id": userId, "sid": sessionId, "exp": now.Add(time.Minute).Unix()},
+			expectedConnected: true,
+		},
+		{
+			name: "Revoked session -> not connected",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{models.NewDBUsersFixture().WithId(userId)},
+				Sessions: []models.DBSession{
+					models.NewDBSessionFixture().WithId(sessionId).WithUserId(userId).WithRevokedAt(now),
+				},
+			},
+			claims: jwt.MapClaims{"user_id": userId, "sid": sessionId, "exp": now.Add(time.Minute).Unix()},
+		},
+		{
+			name: "Expired access token -> not connected",
+			fixtures: DBFixtures{
+				Users:    []models.DBUsers{models.NewDBUsersFixture().WithId(userId)},
+				Sessions: []models.DBSession{models.NewDBSessionFixture().WithId(sessionId).WithUserId(userId)},
+			},
+			claims: jwt.MapClaims{"user_id": userId, "sid": sessionId, "exp": now.Add(-time.Minute).Unix()},
+		},
+		{
+			name: "Token without expiry -> not connected",
+			fixtures: DBFixtures{
+				Users:    []models.DBUsers{models.NewDBUsersFixture().WithId(userId)},
+				Sessions: []models.DBSession{models.NewDBSessionFixture().WithId(sessionId).WithUserId(userId)},
+			},
+			claims: jwt.MapClaims{"user_id": userId, "sid": sessionId},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			s := &Service{}
+			cleanup := s.InitServiceTest()
+			defer func() {
+				if err := cleanup(); err != nil {
+					t.Logf("cleanup error: %v", err)
+				}
+			}()
+			s.loadFixtures(c.fixtures)
+
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, c.claims).SignedString([]byte(jwtSecret))
+			require.NoError(t, err)
+
+			var got models.AuthInfo
+			handler := s.withAuthentication(func(w http.ResponseWriter, r *http.Request, ai models.AuthInfo) error {
+				got = ai
+				return nil
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			require.NoError(t, handler(httptest.NewRecorder(), req, models.AuthInfo{}))
+			require.Equal(t, c.expectedConnected, got.IsConnected)
+		})
+	}
+}
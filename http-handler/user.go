@@ -2,13 +2,16 @@ package main
 
 import (
 	"PLIC/httpx"
+	"PLIC/i18n"
+	"PLIC/mailer"
 	"PLIC/models"
 	"context"
-	"encoding/json"
 	"net/http"
+	"sync"
 
 	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
 	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
@@ -105,24 +108,24 @@ func (s *Service) GetUserById(w http.ResponseWriter, r *http.Request, ai models.
 
 	if !ai.IsConnected {
 		logger.Warn().Msg("unauthorized")
-		return httpx.WriteError(w, http.StatusUnauthorized, "not authorized")
+		return httpx.WriteError(w, r, http.StatusUnauthorized, i18n.ErrUnauthorized)
 	}
 
 	id := chi.URLParam(r, "id")
 	if id == "" {
 		logger.Warn().Msg("missing id in url params")
-		return httpx.WriteError(w, http.StatusBadRequest, "missing id in url params")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMissingParameter, "id")
 	}
 
 	ctx := r.Context()
 	user, err := s.db.GetUserById(ctx, id)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to get user by id")
-		return httpx.WriteError(w, http.StatusInternalServerError, "database error")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 	if user == nil {
 		logger.Info().Str("target_id", id).Msg("user not found")
-		return httpx.WriteError(w, http.StatusNotFound, "user not found")
+		return httpx.WriteError(w, r, http.StatusNotFound, i18n.ErrUserNotFound)
 	}
 
 	s3Resp, err := s.s3Service.GetProfilePicture(ctx, id)
@@ -139,15 +142,17 @@ func (s *Service) GetUserById(w http.ResponseWriter, r *http.Request, ai models.
 
 // PatchUser godoc
 // @Summary      Patch a user by ID
-// @Description  Update user fields
+// @Description  Update user fields. A new email only takes effect once the link sent to it has been opened. The locale (fr or en) is the language of the emails and push notifications.
 // @Tags         users
 // @Accept       json
 // @Produce      json
 // @Param        id path string true "User ID"
 // @Param        body body models.UserPatchRequest true "User fields to update"
 // @Success      200
-// @Failure      400 {object} models.Error "Missing ID in URL params"
+// @Failure      400 {object} models.Error "Missing ID in URL params or unsupported locale"
 // @Failure      403 {object} models.Error "Incorrect rights"
+// @Failure      409 {object} models.Error "Email already taken"
+// @Failure      429 {object} models.Error "A verification email was sent less than a minute ago"
 // @Failure      500 {object} models.Error "Internal server error"
 // @Router       /users/{id} [patch]
 // @Security     BearerAuth
@@ -162,23 +167,74 @@ func (s *Service) PatchUser(w http.ResponseWriter, r *http.Request, ai models.Au
 	id := chi.URLParam(r, "id")
 	if id == "" {
 		logger.Warn().Msg("missing id in url params")
-		return httpx.WriteError(w, http.StatusBadRequest, "missing id in url params")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMissingParameter, "id")
 	}
 
 	if !ai.IsConnected || ai.UserID != id {
 		logger.Warn().Msg("unauthorized user tried to patch another account")
-		return httpx.WriteError(w, http.StatusForbidden, "not authorized")
+		return httpx.WriteError(w, r, http.StatusForbidden, i18n.ErrUnauthorized)
 	}
 
 	var req models.UserPatchRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		logger.Warn().Err(err).Msg("invalid JSON in patch request")
-		return httpx.WriteError(w, http.StatusBadRequest, httpx.BadRequestError)
+	if err := httpx.DecodeJSON(w, r, &req, s.clock.Now()); err != nil {
+		return writeError(w, r, logger, err, "invalid JSON body")
+	}
+
+	if req.Email != nil {
+		newEmail := *req.Email
+		req.Email = nil
+
+		user, err := s.db.GetUserById(ctx, id)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to fetch user from db")
+			return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+		}
+		if user == nil {
+			logger.Warn().Msg("user not found")
+			return httpx.WriteError(w, r, http.StatusNotFound, i18n.ErrUserNotFound)
+		}
+
+		if newEmail != user.Email {
+			existing, err := s.db.GetUserByEmail(ctx, newEmail)
+			if err != nil {
+				logger.Error().Err(err).Msg("failed to check email in db")
+				return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+			}
+			if existing != nil {
+				logger.Warn().Msg("email already taken")
+				return httpx.WriteError(w, r, http.StatusConflict, i18n.ErrEmailTaken)
+			}
+
+			// Refuse before saving the pending email, rather than save it and fail to send
+			// its link.
+			throttled, err := s.mailer.VerificationEmailThrottled(id)
+			if err != nil {
+				logger.Error().Err(err).Msg("failed to check the verification email throttle")
+				return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+			}
+			if throttled {
+				return writeError(w, r, logger, mailer.ErrThrottled, "verification email throttled")
+			}
+
+			if err := s.db.SetPendingEmail(ctx, id, newEmail, s.clock.Now()); err != nil {
+				logger.Error().Err(err).Msg("failed to set pending email in db")
+				return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
+			}
+
+			locale := user.Locale
+			if req.Locale != nil {
+				locale = *req.Locale
+			}
+			if err := s.sendVerificationEmail(id, newEmail, user.Username, locale); err != nil {
+				return writeError(w, r, logger, err, "sending verification email failed")
+			}
+			logger.Info().Msg("email change pending verification")
+		}
 	}
 
 	if err := s.db.UpdateUser(ctx, req, id, s.clock.Now()); err != nil {
 		logger.Error().Err(err).Msg("failed to update user in db")
-		return httpx.WriteError(w, http.StatusInternalServerError, "database error")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 
 	logger.Info().Str("target_id", id).Msg("user updated successfully")
@@ -209,19 +265,51 @@ func (s *Service) DeleteUser(w http.ResponseWriter, r *http.Request, ai models.A
 	id := chi.URLParam(r, "id")
 	if id == "" {
 		logger.Warn().Msg("missing id in url params")
-		return httpx.WriteError(w, http.StatusBadRequest, "missing id in url params")
+		return httpx.WriteError(w, r, http.StatusBadRequest, i18n.ErrMissingParameter, "id")
 	}
 
 	if !ai.IsConnected || ai.UserID != id {
 		logger.Warn().Msg("unauthorized user tried to delete another account")
-		return httpx.WriteError(w, http.StatusForbidden, "not authorized")
+		return httpx.WriteError(w, r, http.StatusForbidden, i18n.ErrUnauthorized)
 	}
 
 	if err := s.db.DeleteUser(ctx, id); err != nil {
 		logger.Error().Err(err).Msg("failed to delete user from db")
-		return httpx.WriteError(w, http.StatusInternalServerError, "database error")
+		return httpx.WriteError(w, r, http.StatusInternalServerError, i18n.ErrInternal)
 	}
 
 	logger.Info().Str("target_id", id).Msg("user deleted successfully")
 	return httpx.Write(w, http.StatusOK, nil)
 }
+
+// prefetchProfilePictures fetches the profile picture URL of every user concurrently,
+// at most 10 at a time. Users whose picture cannot be fetched are missing from the map.
+func (s *Service) prefetchProfilePictures(ctx context.Context, logger zerolog.Logger, userIDs []string) map[string]string {
+	profilePics := make(map[string]string, len(userIDs))
+	var mu sync.Mutex
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 10)
+
+	for _, uid := range userIDs {
+		wg.Add(1)
+		go func(userID string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			pic, err := s.s3Service.GetProfilePicture(ctx, userID)
+			if err != nil {
+				logger.Warn().Err(err).Str("user_id", userID).Msg("failed to get profile picture")
+				return
+			}
+
+			mu.Lock()
+			profilePics[userID] = pic.URL
+			mu.Unlock()
+		}(uid)
+	}
+	wg.Wait()
+
+	return profilePics
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"PLIC/i18n"
+	"PLIC/mailer"
 	"PLIC/models"
 	"bytes"
 	"context"
@@ -187,8 +189,9 @@ func Test_GetUserById(t *testing.T) {
 
 func Test_PatchUser(t *testing.T) {
 	type expected struct {
-		res  *models.DBUsers
-		code int
+		res               *models.DBUsers
+		code              int
+		verificationsSent int
 	}
 
 	type testCase struct {
@@ -197,6 +200,7 @@ func Test_PatchUser(t *testing.T) {
 		param     models.UserPatchRequest
 		auth      models.AuthInfo
 		urlUserId string
+		throttled bool
 		expected  expected
 	}
 
@@ -206,7 +210,7 @@ func Test_PatchUser(t *testing.T) {
 	originalBio := "A bio"
 
 	newUsername := "New username"
-	newEmail := "<EMAIL2>"
+	newEmail := "new-email@example.com"
 	newBio := "A new bio"
 	newCurrentFieldId := "a-new-current-field-id"
 
@@ -234,11 +238,70 @@ func Test_PatchUser(t *testing.T) {
 			urlUserId: userId,
 			expected: expected{
 				code: 200,
+				res: &models.DBUsers{
+					Id:           userId,
+					Username:     newUsername,
+					Email:        originalEmail,
+					PendingEmail: ptr(newEmail),
+					Bio:          ptr(newBio),
+				},
+				verificationsSent: 1,
+			},
+		},
+		{
+			name: "Email taken by another user",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{
+					models.NewDBUsersFixture().
+						WithId(userId).
+						WithUsername(originalUsername).
+						WithEmail(originalEmail),
+					models.NewDBUsersFixture().
+						WithUsername("another username").
+						WithEmail(newEmail),
+				},
+			},
+			param: models.UserPatchRequest{
+				Email: ptr(newEmail),
+			},
+			auth: models.AuthInfo{
+				IsConnected: true,
+				UserID:      userId,
+			},
+			urlUserId: userId,
+			expected: expected{
+				code: 409,
+				res:  nil,
+			},
+		},
+		{
+			name: "Email changed again too soon -> 429, nothing saved",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{
+					models.NewDBUsersFixture().
+						WithId(userId).
+						WithUsername(originalUsername).
+						WithEmail(originalEmail).
+						WithBio(originalBio),
+				},
+			},
+			param: models.UserPatchRequest{
+				Email: ptr(newEmail),
+				Bio:   ptr(newBio),
+			},
+			auth: models.AuthInfo{
+				IsConnected: true,
+				UserID:      userId,
+			},
+			urlUserId: userId,
+			throttled: true,
+			expected: expected{
+				code: 429,
 				res: &models.DBUsers{
 					Id:       userId,
-					Username: newUsername,
-					Email:    newEmail,
-					Bio:      ptr(newBio),
+					Username: originalUsername,
+					Email:    originalEmail,
+					Bio:      ptr(originalBio),
 				},
 			},
 		},
@@ -326,6 +389,89 @@ func Test_PatchUser(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Locale",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{
+					models.NewDBUsersFixture().WithId(userId),
+				},
+			},
+			param: models.UserPatchRequest{
+				Locale: ptr(i18n.EN),
+			},
+			auth: models.AuthInfo{
+				IsConnected: true,
+				UserID:      userId,
+			},
+			urlUserId: userId,
+			expected: expected{
+				code: 200,
+				res: &models.DBUsers{
+					Id:       userId,
+					Username: "username",
+					Email:    "an email",
+					Bio:      ptr("a bio"),
+					Locale:   i18n.EN,
+				},
+			},
+		},
+		{
+			name: "Unsupported locale",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{
+					models.NewDBUsersFixture().WithId(userId),
+				},
+			},
+			param: models.UserPatchRequest{
+				Locale: ptr(i18n.Locale("de")),
+			},
+			auth: models.AuthInfo{
+				IsConnected: true,
+				UserID:      userId,
+			},
+			urlUserId: userId,
+			expected: expected{
+				code: 400,
+			},
+		},
+		{
+			name: "Blank username",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{
+					models.NewDBUsersFixture().WithId(userId),
+				},
+			},
+			param: models.UserPatchRequest{
+				Username: ptr("   "),
+			},
+			auth: models.AuthInfo{
+				IsConnected: true,
+				UserID:      userId,
+			},
+			urlUserId: userId,
+			expected: expected{
+				code: 400,
+			},
+		},
+		{
+			name: "Invalid email",
+			fixtures: DBFixtures{
+				Users: []models.DBUsers{
+					models.NewDBUsersFixture().WithId(userId),
+				},
+			},
+			param: models.UserPatchRequest{
+				Email: ptr("not-an-email"),
+			},
+			auth: models.AuthInfo{
+				IsConnected: true,
+				UserID:      userId,
+			},
+			urlUserId: userId,
+			expected: expected{
+				code: 400,
+			},
+		},
 	}
 
 	for _, c := range testCases {
@@ -339,6 +485,9 @@ func Test_PatchUser(t *testing.T) {
 					t.Logf("cleanup error: %v", err)
 				}
 			}()
+			mockMailer := mailer.NewMockMailer()
+			mockMailer.VerificationThrottled = c.throttled
+			s.mailer = mockMailer
 			s.loadFixtures(c.fixtures)
 
 			body, err := json.Marshal(c.param)
@@ -362,6 +511,7 @@ func Test_PatchUser(t *testing.T) {
 			}(resp.Body)
 
 			require.Equal(t, c.expected.code, resp.StatusCode)
+			require.Equal(t, c.expected.verificationsSent, mockMailer.GetSentCounts("verify_email"))
 
 			if c.expected.res != nil {
 				updated, err := s.db.GetUserById(r.Context(), c.urlUserId)
@@ -369,10 +519,14 @@ func Test_PatchUser(t *testing.T) {
 
 				require.Equal(t, c.expected.res.Username, updated.Username)
 				require.Equal(t, c.expected.res.Email, updated.Email)
+				require.Equal(t, c.expected.res.PendingEmail, updated.PendingEmail)
 				require.Equal(t, *c.expected.res.Bio, *updated.Bio)
 				if c.expected.res.CurrentFieldId != nil {
 					require.Equal(t, *c.expected.res.CurrentFieldId, *updated.CurrentFieldId)
 				}
+				if c.expected.res.Locale != "" {
+					require.Equal(t, c.expected.res.Locale, updated.Locale)
+				}
 			}
 		})
 	}
@@ -1,5 +1,57 @@
 package main
 
+import (
+	"PLIC/i18n"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+)
+
 func ptr[T any](v T) *T {
 	return &v
 }
+
+func otherTeam(team int) int {
+	if team == 1 {
+		return 2
+	}
+	return 1
+}
+
+// parseFiniteFloat parses raw as a float, refusing NaN and infinities: every
+// comparison with NaN is false, so it would get through the range checks.
+func parseFiniteFloat(raw string) (float64, error) {
+	f, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("%q is not a finite number", raw)
+	}
+	return f, nil
+}
+
+var (
+	errInvalidLimit  = i18n.M(i18n.ErrInvalidParameter, "limit")
+	errInvalidOffset = i18n.M(i18n.ErrInvalidParameter, "offset")
+)
+
+// parseLimitOffset reads the limit and offset query parameters of a paginated
+// endpoint. Its errors are i18n.Message, written by writeError.
+func parseLimitOffset(query url.Values, defaultLimit, maxLimit int) (limit, offset int, err error) {
+	limit = defaultLimit
+	if raw := query.Get("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit <= 0 || limit > maxLimit {
+			return 0, 0, errInvalidLimit
+		}
+	}
+	if raw := query.Get("offset"); raw != "" {
+		offset, err = strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return 0, 0, errInvalidOffset
+		}
+	}
+	return limit, offset, nil
+}
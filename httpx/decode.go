@@ -0,0 +1,65 @@
+package httpx
+
+import (
+	"PLIC/i18n"
+	"PLIC/models"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxBodySize is the size of the largest JSON body DecodeJSON reads.
+const MaxBodySize = 1 << 20
+
+// DecodeJSON decodes the JSON body of r into dst, refusing unknown fields and bodies
+// over MaxBodySize, then checks dst with models.Validate, now being the reference of
+// the dates. Its errors are *AppError, the invalid fields in their details.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, dst any, now time.Time) error {
+	defer func() { _ = r.Body.Close() }()
+
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		return decodeError(err)
+	}
+	if decoder.More() {
+		return NewError(http.StatusBadRequest, i18n.ErrInvalidJSON)
+	}
+
+	var fields models.FieldErrors
+	if err := models.Validate(dst, now); errors.As(err, &fields) {
+		details := make(map[string]any, len(fields))
+		for name, msg := range fields {
+			details[name] = msg
+		}
+		return NewError(http.StatusBadRequest, i18n.ErrValidation).WithDetails(details).Wrap(err)
+	}
+	return nil
+}
+
+func decodeError(err error) *AppError {
+	var tooLarge *http.MaxBytesError
+	var wrongType *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &tooLarge):
+		return NewError(http.StatusRequestEntityTooLarge, i18n.ErrBodyTooLarge).Wrap(err)
+	case errors.As(err, &wrongType) && wrongType.Field != "":
+		return fieldError(wrongType.Field, i18n.FieldType).Wrap(err)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no error type for it, only this message.
+		name, unquoteErr := strconv.Unquote(strings.TrimPrefix(err.Error(), "json: unknown field "))
+		if unquoteErr != nil {
+			return NewError(http.StatusBadRequest, i18n.ErrInvalidJSON).Wrap(err)
+		}
+		return fieldError(name, i18n.FieldUnknown).Wrap(err)
+	default:
+		return NewError(http.StatusBadRequest, i18n.ErrInvalidJSON).Wrap(err)
+	}
+}
+
+func fieldError(name string, key i18n.Key) *AppError {
+	return NewError(http.StatusBadRequest, i18n.ErrValidation).WithDetails(map[string]any{name: i18n.M(key)})
+}
@@ -0,0 +1,89 @@
+package httpx
+
+import (
+	"PLIC/i18n"
+	"PLIC/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// ProblemContentType is the media type of the error responses (RFC 7807).
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix prefixes the code of an error to build the type URI of its problem.
+const problemTypePrefix = "urn:playthestreet:error:"
+
+// AppError is an error meant for the client: an HTTP status, a stable code whose message
+// is localized when written, optional details and the cause, which is only logged.
+type AppError struct {
+	Status  int
+	Code    i18n.Key
+	Args    []any
+	Details map[string]any
+	Err     error
+}
+
+// NewError returns the error answered with status and code, whose message takes args.
+func NewError(status int, code i18n.Key, args ...any) *AppError {
+	return &AppError{Status: status, Code: code, Args: args}
+}
+
+// Wrap records err as the cause of e.
+func (e *AppError) Wrap(err error) *AppError {
+	e.Err = err
+	return e
+}
+
+// WithDetails adds details to the problem written for e.
+func (e *AppError) WithDetails(details map[string]any) *AppError {
+	e.Details = details
+	return e
+}
+
+// Error is the English message, followed by the cause if any, for the logs.
+func (e *AppError) Error() string {
+	msg := fmt.Sprintf("%s: %s", e.Code, i18n.T(i18n.EN, e.Code, e.Args...))
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// WriteProblem writes e as an RFC 7807 problem, its detail in the language negotiated
+// from the Accept-Language header of r, as are the i18n.Message of its details. The
+// cause of e is never sent.
+func WriteProblem(w http.ResponseWriter, r *http.Request, e *AppError) error {
+	locale := i18n.FromRequest(r)
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.Header().Set("Content-Language", string(locale))
+	w.WriteHeader(e.Status)
+	return json.NewEncoder(w).Encode(models.Error{
+		Type:     problemTypePrefix + string(e.Code),
+		Title:    http.StatusText(e.Status),
+		Status:   e.Status,
+		Detail:   i18n.T(locale, e.Code, e.Args...),
+		Instance: r.URL.Path,
+		Code:     string(e.Code),
+		Details:  localizeDetails(e.Details, locale),
+	})
+}
+
+// localizeDetails writes each i18n.Message of details as a models.FieldError.
+func localizeDetails(details map[string]any, locale i18n.Locale) map[string]any {
+	if details == nil {
+		return nil
+	}
+	out := make(map[string]any, len(details))
+	for name, value := range details {
+		if msg, ok := value.(i18n.Message); ok {
+			value = models.FieldError{Code: string(msg.Key), Message: msg.In(locale)}
+		}
+		out[name] = value
+	}
+	return out
+}
@@ -1,25 +1,16 @@
 package httpx
 
 import (
-	"PLIC/models"
+	"PLIC/i18n"
 	"encoding/json"
 	"fmt"
+	"html"
 	"net/http"
 )
 
-const (
-	BadRequestError     = "Bad Request"
-	UnauthorizedError   = "Unauthorized"
-	InternalServerError = "Internal Server Error"
-)
-
-func WriteHTMLResponseForPasswordReset(w http.ResponseWriter, statusCode int, title, message, password string) error {
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.WriteHeader(statusCode)
-
-	html := fmt.Sprintf(`
+const htmlPageLayout = `
 	<!DOCTYPE html>
-	<html lang="fr">
+	<html lang="%s">
 	<head>
 		<meta charset="UTF-8">
 		<title>%s</title>
@@ -111,27 +102,23 @@ func WriteHTMLResponseForPasswordReset(w http.ResponseWriter, statusCode int, ti
 				margin: 0 0 22px 0;
 			}
 
-			.password-container {
-				display: inline-flex;
-				align-items: center;
-				justify-content: center;
-				gap: 10px;
-				margin-bottom: 8px;
+			.reset-form {
+				display: flex;
+				flex-direction: column;
+				gap: 12px;
+				margin: 0 auto;
+				max-width: 360px;
 			}
-			.password-box {
-				background: var(--bg-accent);
-				padding: 14px 22px;
-				border: 2px dashed var(--brand);
+			.reset-form input {
+				padding: 12px 14px;
+				font-size: 16px;
+				border: 2px solid var(--bg-accent-hover);
 				border-radius: 10px;
-				font-size: 22px;
-				font-weight: 700;
-				color: var(--brand);
-				user-select: all;
-				transition: background 0.3s;
-				line-height: 1;
+				outline: none;
+				transition: border-color 0.25s;
 			}
-			.password-box:hover { background: var(--bg-accent-hover); }
-			.copy-btn {
+			.reset-form input:focus { border-color: var(--brand); }
+			.submit-btn {
 				background-color: var(--brand);
 				border: none;
 				color: #fff;
@@ -141,32 +128,18 @@ func WriteHTMLResponseForPasswordReset(w http.ResponseWriter, statusCode int, ti
 				border-radius: 10px;
 				cursor: pointer;
 				transition: background 0.25s, transform 0.05s;
-				white-space: nowrap;
-			}
-			.copy-btn:hover  { background-color: #e67600; }
-			.copy-btn:active { transform: scale(0.98); }
-
-			.copy-hint {
-				font-size: 14px;
-				color: var(--muted);
-				margin-top: 6px;
 			}
-			.footer {
-				margin-top: 24px;
-				font-size: 14px;
-				color: var(--muted);
+			.submit-btn:hover  { background-color: #e67600; }
+			.submit-btn:active { transform: scale(0.98); }
+			.error {
+				color: #c0392b;
+				font-size: 16px;
 			}
 
 			@keyframes fadeIn {
 				from { opacity: 0; transform: translateY(14px); }
 				to   { opacity: 1; transform: translateY(0); }
 			}
-
-			/* Mobile */
-			@media (max-width: 420px) {
-				.password-box { font-size: 18px; }
-				.copy-btn     { font-size: 14px; padding: 10px 14px; }
-			}
 		</style>
 	</head>
 	<body>
@@ -223,61 +196,66 @@ func WriteHTMLResponseForPasswordReset(w http.ResponseWriter, statusCode int, ti
 			<div class="app-name">Play The Street</div>
 			<div class="app-underline" aria-hidden="true"></div>
 
-			<h1>%s</h1>
-			<p>%s</p>
-
-			<div class="password-container">
-				<div id="password" class="password-box">%s</div>
-				<button class="copy-btn" onclick="copyPassword()">Copier</button>
-			</div>
-			<div class="copy-hint">Clique pour copier le mot de passe.</div>
-
-			<p class="footer">Conservez ce mot de passe précieusement et changez-le dès votre prochaine connexion.</p>
+%s
 		</div>
-
-		<script>
-			function copyPassword() {
-				var el = document.getElementById('password');
-				var pwd = el.textContent;
-				if (navigator.clipboard && navigator.clipboard.writeText) {
-					navigator.clipboard.writeText(pwd).then(flashCopied).catch(function(){ fallbackCopy(pwd); });
-				} else { fallbackCopy(pwd); }
-			}
-			function fallbackCopy(text) {
-				var ta = document.createElement('textarea');
-				ta.value = text; document.body.appendChild(ta); ta.select();
-				try { document.execCommand('copy'); } catch(e) {}
-				document.body.removeChild(ta);
-				flashCopied();
-			}
-			function flashCopied() {
-				var btn = document.querySelector('.copy-btn');
-				btn.textContent = 'Copié !';
-				btn.style.backgroundColor = '#28a745';
-				setTimeout(function () {
-					btn.textContent = 'Copier';
-					btn.style.backgroundColor = getComputedStyle(document.documentElement).getPropertyValue('--brand').trim();
-				}, 1800);
-			}
-		</script>
 	</body>
 	</html>
-	`, title, title, message, password)
+	`
+
+func writeHTMLPage(w http.ResponseWriter, locale i18n.Locale, statusCode int, title, content string) error {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Language", string(locale))
+	w.WriteHeader(statusCode)
 
-	_, err := w.Write([]byte(html))
+	_, err := w.Write([]byte(fmt.Sprintf(htmlPageLayout, locale, html.EscapeString(title), content)))
 	return err
 }
 
+// WriteHTMLMessage renders a page with a title and a message, in the language of the
+// browser since the pages are opened from the email links.
+func WriteHTMLMessage(w http.ResponseWriter, r *http.Request, statusCode int, title, message i18n.Key) error {
+	locale := i18n.FromRequest(r)
+	content := fmt.Sprintf(`
+			<h1>%s</h1>
+			<p>%s</p>`, html.EscapeString(i18n.T(locale, title)), html.EscapeString(i18n.T(locale, message)))
+	return writeHTMLPage(w, locale, statusCode, i18n.T(locale, title), content)
+}
+
+// WriteHTMLPasswordResetForm renders the form posting the new password back to the reset
+// link, with errorMessage above it unless empty.
+func WriteHTMLPasswordResetForm(w http.ResponseWriter, r *http.Request, statusCode int, errorMessage i18n.Key) error {
+	locale := i18n.FromRequest(r)
+	errorBlock := ""
+	if errorMessage != "" {
+		errorBlock = fmt.Sprintf(`<p class="error">%s</p>`, html.EscapeString(i18n.T(locale, errorMessage)))
+	}
+
+	content := fmt.Sprintf(`
+			<h1>%s</h1>
+			<p>%s</p>
+			%s
+			<form class="reset-form" method="POST">
+				<input type="password" name="password" placeholder="%s" autocomplete="new-password" required>
+				<input type="password" name="confirmation" placeholder="%s" autocomplete="new-password" required>
+				<button type="submit" class="submit-btn">%s</button>
+			</form>`,
+		html.EscapeString(i18n.T(locale, i18n.PageResetTitle)),
+		html.EscapeString(i18n.T(locale, i18n.PageResetPrompt)),
+		errorBlock,
+		html.EscapeString(i18n.T(locale, i18n.PageResetPassword)),
+		html.EscapeString(i18n.T(locale, i18n.PageResetConfirmation)),
+		html.EscapeString(i18n.T(locale, i18n.PageResetSubmit)))
+	return writeHTMLPage(w, locale, statusCode, i18n.T(locale, i18n.PageResetTitle), content)
+}
+
 func Write(w http.ResponseWriter, statusCode int, data interface{}) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 	return json.NewEncoder(w).Encode(data)
 }
 
-func WriteError(w http.ResponseWriter, statusCode int, message string) error {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	return json.NewEncoder(w).Encode(models.Error{
-		Message: message,
-	})
+// WriteError writes the error key, formatted with args, as a problem. It is the
+// shorthand of WriteProblem for the errors a handler detects itself.
+func WriteError(w http.ResponseWriter, r *http.Request, statusCode int, key i18n.Key, args ...any) error {
+	return WriteProblem(w, r, NewError(statusCode, key, args...))
 }
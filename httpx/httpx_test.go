@@ -0,0 +1,293 @@
+package httpx
+
+import (
+	"PLIC/i18n"
+	"PLIC/models"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteError(t *testing.T) {
+	type testCase struct {
+		name           string
+		acceptLanguage string
+		write          func(w http.ResponseWriter, r *http.Request) error
+		wantStatus     int
+		wantLanguage   string
+		wantBody       string
+	}
+
+	testCases := []testCase{
+		{
+			name: "Default locale",
+			write: func(w http.ResponseWriter, r *http.Request) error {
+				return WriteError(w, r, http.StatusNotFound, i18n.ErrMatchNotFound)
+			},
+			wantStatus:   http.StatusNotFound,
+			wantLanguage: "fr",
+			wantBody:     `{"type":"urn:playthestreet:error:match_not_found","title":"Not Found","status":404,"detail":"Match introuvable.","instance":"/matches/1","code":"match_not_found"}`,
+		},
+		{
+			name:           "Negotiated locale with arguments",
+			acceptLanguage: "en-GB,en;q=0.9",
+			write: func(w http.ResponseWriter, r *http.Request) error {
+				return WriteError(w, r, http.StatusBadRequest, i18n.ErrMissingParameter, "id")
+			},
+			wantStatus:   http.StatusBadRequest,
+			wantLanguage: "en",
+			wantBody:     `{"type":"urn:playthestreet:error:missing_parameter","title":"Bad Request","status":400,"detail":"Missing parameter: id.","instance":"/matches/1","code":"missing_parameter"}`,
+		},
+		{
+			name: "Details, cause hidden",
+			write: func(w http.ResponseWriter, r *http.Request) error {
+				e := NewError(http.StatusConflict, i18n.ErrConflict).
+					Wrap(errors.New("pq: duplicate key")).
+					WithDetails(map[string]any{"field": "email"})
+				return WriteProblem(w, r, e)
+			},
+			wantStatus:   http.StatusConflict,
+			wantLanguage: "fr",
+			wantBody:     `{"type":"urn:playthestreet:error:conflict","title":"Conflict","status":409,"detail":"Cette action est en conflit avec l'état actuel des données.","instance":"/matches/1","code":"conflict","details":{"field":"email"}}`,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/matches/1?x=1", nil)
+			r.Header.Set("Accept-Language", c.acceptLanguage)
+			w := httptest.NewRecorder()
+
+			require.NoError(t, c.write(w, r))
+			require.Equal(t, c.wantStatus, w.Code)
+			require.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+			require.Equal(t, c.wantLanguage, w.Header().Get("Content-Language"))
+			require.Equal(t, c.wantBody, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
+
+func TestAppError(t *testing.T) {
+	cause := errors.New("pq: duplicate key")
+	e := NewError(http.StatusBadRequest, i18n.ErrInvalidParameter, "lat").Wrap(cause)
+
+	require.ErrorIs(t, e, cause)
+	require.Equal(t, "invalid_parameter: Invalid parameter: lat.: pq: duplicate key", e.Error())
+}
+
+func TestDecodeJSON(t *testing.T) {
+	now := time.Date(2026, 5, 2, 18, 30, 0, 0, time.UTC)
+
+	type testCase struct {
+		name        string
+		body        string
+		dst         any
+		wantStatus  int
+		wantCode    i18n.Key
+		wantDetails map[string]any
+	}
+
+	testCases := []testCase{
+		{
+			name: "Valid match",
+			body: `{"sport":"foot","court_id":"c1","date":"2026-05-03T18:00:00Z","nbre_participant":4}`,
+			dst:  &models.MatchRequest{},
+		},
+		{
+			name:       "Every invalid field reported",
+			body:       `{"sport":"tennis","date":"2026-05-01T18:00:00Z","nbre_participant":3,"visibility":"secret"}`,
+			dst:        &models.MatchRequest{},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   i18n.ErrValidation,
+			wantDetails: map[string]any{
+				"sport":            i18n.M(i18n.FieldInvalid),
+				"court_id":         i18n.M(i18n.FieldRequired),
+				"date":             i18n.M(i18n.FieldFuture),
+				"nbre_participant": i18n.M(i18n.FieldEven),
+				"visibility":       i18n.M(i18n.FieldInvalid),
+			},
+		},
+		{
+			name:        "Team out of range",
+			body:        `{"team":3}`,
+			dst:         &models.JoinMatchRequest{},
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    i18n.ErrValidation,
+			wantDetails: map[string]any{"team": i18n.M(i18n.FieldMax, int64(2))},
+		},
+		{
+			name:        "Negative score",
+			body:        `{"score1":-1,"score2":3}`,
+			dst:         &models.UpdateScoreRequest{},
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    i18n.ErrValidation,
+			wantDetails: map[string]any{"score1": i18n.M(i18n.FieldMin, int64(0))},
+		},
+		{
+			name: "Patch with the fields left out",
+			body: `{"bio":"Salut"}`,
+			dst:  &models.UserPatchRequest{},
+		},
+		{
+			name:       "Patch with a blank username and a wrong email",
+			body:       `{"username":" ","email":"nope","locale":"de"}`,
+			dst:        &models.UserPatchRequest{},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   i18n.ErrValidation,
+			wantDetails: map[string]any{
+				"username": i18n.M(i18n.FieldNotBlank),
+				"email":    i18n.M(i18n.FieldEmail),
+				"locale":   i18n.M(i18n.FieldInvalid),
+			},
+		},
+		{
+			name:       "Register with missing credentials",
+			body:       `{"email":"zoe@example.com","username":""}`,
+			dst:        &models.RegisterRequest{},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   i18n.ErrValidation,
+			wantDetails: map[string]any{
+				"password": i18n.M(i18n.FieldRequired),
+				"username": i18n.M(i18n.FieldNotBlank),
+			},
+		},
+		{
+			name: "Queue request with the area out of range",
+			body: `{"sport":"tennis","latitude":91,"longitude":2.35,"radius_m":60000,` +
+				`"window_start":"2026-05-02T10:00:00Z","window_end":"2026-05-02T12:00:00Z","team_size":0}`,
+			dst:        &models.QueueRequest{},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   i18n.ErrValidation,
+			wantDetails: map[string]any{
+				"sport":      i18n.M(i18n.FieldInvalid),
+				"latitude":   i18n.M(i18n.FieldMax, int64(90)),
+				"radius_m":   i18n.M(i18n.FieldMax, int64(50000)),
+				"window_end": i18n.M(i18n.FieldFuture),
+				"team_size":  i18n.M(i18n.FieldMin, int64(1)),
+			},
+		},
+		{
+			name:       "Device with a blank token and an unknown platform",
+			body:       `{"token":"  ","platform":"windows"}`,
+			dst:        &models.RegisterDeviceRequest{},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   i18n.ErrValidation,
+			wantDetails: map[string]any{
+				"token":    i18n.M(i18n.FieldNotBlank),
+				"platform": i18n.M(i18n.FieldInvalid),
+			},
+		},
+		{
+			name:        "Device token too long",
+			body:        `{"token":"` + strings.Repeat("a", 4097) + `","platform":"ios"}`,
+			dst:         &models.RegisterDeviceRequest{},
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    i18n.ErrValidation,
+			wantDetails: map[string]any{"token": i18n.M(i18n.FieldMaxLength, int64(4096))},
+		},
+		{
+			name:        "Nobody to invite",
+			body:        `{"user_ids":[]}`,
+			dst:         &models.InviteToMatchRequest{},
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    i18n.ErrValidation,
+			wantDetails: map[string]any{"user_ids": i18n.M(i18n.FieldMinItems, int64(1))},
+		},
+		{
+			name:        "Register with a display name in the email",
+			body:        `{"email":"Bob <bob@example.com>","password":"secret","username":"bob"}`,
+			dst:         &models.RegisterRequest{},
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    i18n.ErrValidation,
+			wantDetails: map[string]any{"email": i18n.M(i18n.FieldEmail)},
+		},
+		{
+			name:        "Patch with the email in angle brackets",
+			body:        `{"email":"<bob@example.com>"}`,
+			dst:         &models.UserPatchRequest{},
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    i18n.ErrValidation,
+			wantDetails: map[string]any{"email": i18n.M(i18n.FieldEmail)},
+		},
+		{
+			name:        "Unknown field",
+			body:        `{"score1":1,"score2":0,"winner":1}`,
+			dst:         &models.UpdateScoreRequest{},
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    i18n.ErrValidation,
+			wantDetails: map[string]any{"winner": i18n.M(i18n.FieldUnknown)},
+		},
+		{
+			name:        "Wrong type",
+			body:        `{"score1":"one","score2":0}`,
+			dst:         &models.UpdateScoreRequest{},
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    i18n.ErrValidation,
+			wantDetails: map[string]any{"score1": i18n.M(i18n.FieldType)},
+		},
+		{
+			name:       "Not JSON",
+			body:       `{"score1":`,
+			dst:        &models.UpdateScoreRequest{},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   i18n.ErrInvalidJSON,
+		},
+		{
+			name:       "Two documents",
+			body:       `{"score1":1,"score2":0}{}`,
+			dst:        &models.UpdateScoreRequest{},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   i18n.ErrInvalidJSON,
+		},
+		{
+			name:       "Too large",
+			body:       `{"bio":"` + strings.Repeat("a", MaxBodySize) + `"}`,
+			dst:        &models.UserPatchRequest{},
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantCode:   i18n.ErrBodyTooLarge,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/", strings.NewReader(c.body))
+			err := DecodeJSON(httptest.NewRecorder(), r, c.dst, now)
+			if c.wantCode == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			var appErr *AppError
+			require.ErrorAs(t, err, &appErr)
+			require.Equal(t, c.wantStatus, appErr.Status)
+			require.Equal(t, c.wantCode, appErr.Code)
+			require.Equal(t, c.wantDetails, appErr.Details)
+		})
+	}
+}
+
+func TestWriteProblem_FieldErrors(t *testing.T) {
+	r := httptest.NewRequest("POST", "/match", nil)
+	r.Header.Set("Accept-Language", "en")
+	w := httptest.NewRecorder()
+
+	e := NewError(http.StatusBadRequest, i18n.ErrValidation).WithDetails(map[string]any{"team": i18n.M(i18n.FieldMax, int64(2))})
+	require.NoError(t, WriteProblem(w, r, e))
+	require.Contains(t, w.Body.String(), `"details":{"team":{"code":"validation.max","message":"Must be less than or equal to 2."}}`)
+}
+
+func TestWriteHTMLMessage(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Language", "en")
+	w := httptest.NewRecorder()
+
+	require.NoError(t, WriteHTMLMessage(w, r, http.StatusOK, i18n.PageEmailVerifiedTitle, i18n.PageEmailVerifiedMessage))
+	require.Equal(t, "en", w.Header().Get("Content-Language"))
+	require.Contains(t, w.Body.String(), `<html lang="en">`)
+	require.Contains(t, w.Body.String(), "<h1>Address confirmed</h1>")
+}
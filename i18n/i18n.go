@@ -0,0 +1,136 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Locale is a language the application speaks.
+type Locale string
+
+const (
+	FR Locale = "fr"
+	EN Locale = "en"
+)
+
+// Default is the locale of the users who never chose one and of the requests that
+// accept none of ours.
+const Default = FR
+
+// Locales lists the supported locales.
+var Locales = []Locale{FR, EN}
+
+func (l Locale) IsValid() bool {
+	_, ok := catalogs[l]
+	return ok
+}
+
+// Or returns l, or fallback when l is not supported.
+func (l Locale) Or(fallback Locale) Locale {
+	if l.IsValid() {
+		return l
+	}
+	return fallback
+}
+
+//go:embed catalog/*.json
+var catalogFS embed.FS
+
+// catalogs holds the messages of each locale, loaded once at start up from
+// catalog/<locale>.json. A missing or broken catalog panics right away.
+var catalogs = mustLoadCatalogs()
+
+func mustLoadCatalogs() map[Locale]map[Key]string {
+	loaded := make(map[Locale]map[Key]string, len(Locales))
+	for _, l := range Locales {
+		raw, err := catalogFS.ReadFile("catalog/" + string(l) + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("i18n: missing catalog %s: %v", l, err))
+		}
+		var messages map[Key]string
+		if err := json.Unmarshal(raw, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog %s: %v", l, err))
+		}
+		loaded[l] = messages
+	}
+	return loaded
+}
+
+// T returns the message key in locale, formatted with args. A message missing from
+// the locale falls back to the Default locale, then to the key itself.
+func T(locale Locale, key Key, args ...any) string {
+	msg, ok := catalogs[locale][key]
+	if !ok {
+		msg, ok = catalogs[Default][key]
+	}
+	if !ok {
+		msg = string(key)
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Negotiate picks the supported locale the Accept-Language header prefers, Default if
+// it accepts none of them.
+func Negotiate(acceptLanguage string) Locale {
+	type candidate struct {
+		locale Locale
+		q      float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if l := Locale(base); l.IsValid() {
+			candidates = append(candidates, candidate{locale: l, q: q})
+		}
+	}
+	if len(candidates) == 0 {
+		return Default
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].locale
+}
+
+// FromRequest is the locale negotiated from the Accept-Language header of r.
+func FromRequest(r *http.Request) Locale {
+	return Negotiate(r.Header.Get("Accept-Language"))
+}
+
+// Message is a catalog entry with its arguments. It is also an error, so that the
+// validation helpers can return a message the handler localizes.
+type Message struct {
+	Key  Key
+	Args []any
+}
+
+func M(key Key, args ...any) Message {
+	return Message{Key: key, Args: args}
+}
+
+// Error is the English message, for the logs.
+func (m Message) Error() string {
+	return T(EN, m.Key, m.Args...)
+}
+
+func (m Message) In(locale Locale) string {
+	return T(locale, m.Key, m.Args...)
+}
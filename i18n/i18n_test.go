@@ -0,0 +1,63 @@
+package i18n
+
+import (
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var verbs = regexp.MustCompile(`%[a-z]`)
+
+func TestCatalogsMatch(t *testing.T) {
+	for _, l := range Locales {
+		require.Len(t, catalogs[l], len(catalogs[Default]), l)
+		for key, msg := range catalogs[Default] {
+			translated, ok := catalogs[l][key]
+			require.True(t, ok, "%s is missing in %s", key, l)
+			require.Equal(t, verbs.FindAllString(msg, -1), verbs.FindAllString(translated, -1), "%s in %s", key, l)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	testCases := []struct {
+		header   string
+		expected Locale
+	}{
+		{"", FR},
+		{"en", EN},
+		{"en-US,en;q=0.9", EN},
+		{"de-DE,de;q=0.9,en;q=0.8", EN},
+		{"fr-CA,en;q=0.5", FR},
+		{"en;q=0.4,fr;q=0.8", FR},
+		{"fr;q=0,en", EN},
+		{"de", FR},
+		{"en;q=abc", FR},
+		{"EN-gb", EN},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.header, func(t *testing.T) {
+			require.Equal(t, c.expected, Negotiate(c.header))
+		})
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Language", "en-GB")
+	require.Equal(t, EN, FromRequest(r))
+}
+
+func TestT(t *testing.T) {
+	require.Equal(t, "Match introuvable.", T(FR, ErrMatchNotFound))
+	require.Equal(t, "Missing parameter: id.", T(EN, ErrMissingParameter, "id"))
+	// Unknown locales fall back to the default one, unknown keys to the key.
+	require.Equal(t, "Match introuvable.", T("de", ErrMatchNotFound))
+	require.Equal(t, "nope", T(EN, "nope"))
+
+	m := M(ErrInvalidParameter, "lat")
+	require.Equal(t, "Invalid parameter: lat.", m.Error())
+	require.Equal(t, "Paramètre invalide : lat.", m.In(FR))
+	require.Equal(t, EN, Locale("xx").Or(EN))
+}
@@ -0,0 +1,178 @@
+package i18n
+
+// Key identifies a message of the catalogs. The keys of the API errors are also their
+// machine code, sent to the clients next to the localized text: they must not change.
+type Key string
+
+// API errors.
+const (
+	ErrBadRequest       Key = "bad_request"
+	ErrUnauthorized     Key = "unauthorized"
+	ErrInternal         Key = "internal_error"
+	ErrNotFound         Key = "not_found"
+	ErrConflict         Key = "conflict"
+	ErrTooManyRequests  Key = "too_many_requests"
+	ErrInvalidJSON      Key = "invalid_json"
+	ErrMissingParameter Key = "missing_parameter" // %s: the parameter
+	ErrInvalidParameter Key = "invalid_parameter" // %s: the parameter
+	ErrValidation       Key = "validation_failed"
+	ErrBodyTooLarge     Key = "body_too_large"
+
+	ErrMatchNotFound             Key = "match_not_found"
+	ErrMatchWrongState           Key = "match_wrong_state"
+	ErrMatchNotVisible           Key = "match_not_visible"
+	ErrNotMatchCreator           Key = "not_match_creator"
+	ErrNotInMatch                Key = "not_in_match"
+	ErrPlayerNotFound            Key = "player_not_found"
+	ErrAlreadyInMatch            Key = "already_in_match"
+	ErrInvalidSport              Key = "invalid_sport"
+	ErrInvalidTeam               Key = "invalid_team"
+	ErrTeamFull                  Key = "team_full"
+	ErrTeamAlreadyVoted          Key = "team_already_voted"
+	ErrInviteFriendsOnly         Key = "invite_friends_only"
+	ErrInvalidCity               Key = "invalid_city"
+	ErrCourtNotFound             Key = "court_not_found"
+	ErrInvalidDateRange          Key = "invalid_date_range"
+	ErrDistanceSortNeedsPosition Key = "distance_sort_needs_position"
+
+	ErrUserNotFound         Key = "user_not_found"
+	ErrEmailNotVerified     Key = "email_not_verified"
+	ErrEmailAlreadyVerified Key = "email_already_verified"
+	ErrEmailTaken           Key = "email_taken"
+	ErrUsernameTaken        Key = "username_taken"
+	ErrPasswordTooLong      Key = "password_too_long"
+	ErrInvalidImage         Key = "invalid_image"
+
+	ErrAlreadyFriends           Key = "already_friends"
+	ErrFriendshipBlocked        Key = "friendship_blocked"
+	ErrFriendRequestNotFound    Key = "friend_request_not_found"
+	ErrFriendRequestAlreadySent Key = "friend_request_already_sent"
+	ErrCannotTargetSelf         Key = "cannot_target_self"
+
+	ErrQueueEntryNotFound   Key = "queue_entry_not_found"
+	ErrQueueEntryNotWaiting Key = "queue_entry_not_waiting"
+	ErrAlreadyQueued        Key = "already_queued"
+	ErrQueueWindowTooFar    Key = "queue_window_too_far"
+	ErrQueueAreaIncomplete  Key = "queue_area_incomplete"
+	ErrQueueAreaRequired    Key = "queue_area_required"
+
+	ErrDeviceNotFound Key = "device_not_found"
+)
+
+// ErrorCodes are the keys of the API errors, the codes a client can receive.
+var ErrorCodes = []Key{
+	ErrBadRequest,
+	ErrUnauthorized,
+	ErrInternal,
+	ErrNotFound,
+	ErrConflict,
+	ErrTooManyRequests,
+	ErrInvalidJSON,
+	ErrMissingParameter,
+	ErrInvalidParameter,
+	ErrValidation,
+	ErrBodyTooLarge,
+	ErrMatchNotFound,
+	ErrMatchWrongState,
+	ErrMatchNotVisible,
+	ErrNotMatchCreator,
+	ErrNotInMatch,
+	ErrPlayerNotFound,
+	ErrAlreadyInMatch,
+	ErrInvalidSport,
+	ErrInvalidTeam,
+	ErrTeamFull,
+	ErrTeamAlreadyVoted,
+	ErrInviteFriendsOnly,
+	ErrInvalidCity,
+	ErrCourtNotFound,
+	ErrInvalidDateRange,
+	ErrDistanceSortNeedsPosition,
+	ErrUserNotFound,
+	ErrEmailNotVerified,
+	ErrEmailAlreadyVerified,
+	ErrEmailTaken,
+	ErrUsernameTaken,
+	ErrPasswordTooLong,
+	ErrInvalidImage,
+	ErrAlreadyFriends,
+	ErrFriendshipBlocked,
+	ErrFriendRequestNotFound,
+	ErrFriendRequestAlreadySent,
+	ErrCannotTargetSelf,
+	ErrQueueEntryNotFound,
+	ErrQueueEntryNotWaiting,
+	ErrAlreadyQueued,
+	ErrQueueWindowTooFar,
+	ErrQueueAreaIncomplete,
+	ErrQueueAreaRequired,
+	ErrDeviceNotFound,
+}
+
+// Field errors of the request validation, written in the details of a validation_failed
+// problem.
+const (
+	FieldRequired  Key = "validation.required"
+	FieldNotBlank  Key = "validation.not_blank"
+	FieldMin       Key = "validation.min"        // %d: the minimum
+	FieldMax       Key = "validation.max"        // %d: the maximum
+	FieldMinItems  Key = "validation.min_items"  // %d: the minimum
+	FieldMaxLength Key = "validation.max_length" // %d: the maximum
+	FieldEven      Key = "validation.even"
+	FieldFuture    Key = "validation.future"
+	FieldEmail     Key = "validation.email"
+	FieldInvalid   Key = "validation.invalid"
+	FieldType      Key = "validation.type"
+	FieldUnknown   Key = "validation.unknown_field"
+)
+
+// HTML pages opened from the email links.
+const (
+	PageInvalidVerificationLinkTitle   Key = "page.invalid_verification_link.title"
+	PageInvalidVerificationLinkMessage Key = "page.invalid_verification_link.message"
+	PageEmailInUseTitle                Key = "page.email_in_use.title"
+	PageEmailInUseMessage              Key = "page.email_in_use.message"
+	PageEmailVerifiedTitle             Key = "page.email_verified.title"
+	PageEmailVerifiedMessage           Key = "page.email_verified.message"
+	PageInvalidResetLinkTitle          Key = "page.invalid_reset_link.title"
+	PageInvalidResetLinkMessage        Key = "page.invalid_reset_link.message"
+	PageResetTitle                     Key = "page.reset.title"
+	PageResetPrompt                    Key = "page.reset.prompt"
+	PageResetPassword                  Key = "page.reset.password"
+	PageResetConfirmation              Key = "page.reset.confirmation"
+	PageResetSubmit                    Key = "page.reset.submit"
+	PageResetInvalidForm               Key = "page.reset.invalid_form"
+	PageResetEmptyPassword             Key = "page.reset.empty_password"
+	PageResetPasswordMismatch          Key = "page.reset.password_mismatch"
+	PageResetPasswordTooLong           Key = "page.reset.password_too_long"
+	PagePasswordChangedTitle           Key = "page.password_changed.title"
+	PagePasswordChangedMessage         Key = "page.password_changed.message"
+)
+
+// Emails and push notifications.
+const (
+	SportBasket   Key = "sport.basket"
+	SportFoot     Key = "sport.foot"
+	SportPingPong Key = "sport.pingpong"
+
+	// DateTimeLayout and TimeLayout are time.Format layouts.
+	DateTimeLayout      Key = "format.date_time"
+	ShortDateTimeLayout Key = "format.short_date_time"
+	TimeLayout          Key = "format.time"
+
+	MailResultVictory Key = "mail.result.victory"
+	MailResultDefeat  Key = "mail.result.defeat"
+	MailResultDraw    Key = "mail.result.draw"
+	MailCheerVictory  Key = "mail.cheer.victory"
+	MailCheerDefeat   Key = "mail.cheer.defeat"
+	MailCheerDraw     Key = "mail.cheer.draw"
+
+	PushMatchFilledTitle   Key = "push.match_filled.title"
+	PushMatchFilledBody    Key = "push.match_filled.body" // sport, court, date
+	PushMatchStartingTitle Key = "push.match_starting.title"
+	PushMatchStartingBody  Key = "push.match_starting.body" // sport, court, time
+	PushScoreVoteTitle     Key = "push.score_vote.title"
+	PushScoreVoteBody      Key = "push.score_vote.body" // sport, court
+	PushMatchResultTitle   Key = "push.match_result.title"
+	PushMatchResultBody    Key = "push.match_result.body" // sport, court, team score, opponent score
+)
@@ -1,422 +1,205 @@
 package mailer
 
 import (
+	"PLIC/clock"
+	"PLIC/i18n"
 	"PLIC/models"
-	"crypto/tls"
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
-	"gopkg.in/gomail.v2"
 )
 
+// MailSender sends the emails of the application, each in the locale of its recipient.
 type MailSender interface {
-	SendLinkResetPassword(to string, url string) error
-	SendWelcomeEmail(userId string, to string, username string) error
-	SendMatchResultEmail(matchId string, to string, username string, sport models.Sport, fieldName string, teamScore, oppScore int) error
+	SendLinkResetPassword(to string, url string, locale i18n.Locale) error
+	SendWelcomeEmail(userId string, to string, username string, locale i18n.Locale) error
+	SendVerificationEmail(userId string, to string, username string, url string, locale i18n.Locale) error
+	// VerificationEmailThrottled tells whether SendVerificationEmail would be throttled for
+	// userId, so that a caller can refuse a request before changing anything.
+	VerificationEmailThrottled(userId string) (bool, error)
+	SendMatchResultEmail(matchId string, to string, username string, sport models.Sport, fieldName string, teamScore, oppScore int, locale i18n.Locale) error
+	SendMatchCancelledEmail(matchId string, to string, username string, sport models.Sport, fieldName string, date time.Time, locale i18n.Locale) error
+	SendMatchInvitationEmail(matchId string, to string, username string, inviterName string, sport models.Sport, fieldName string, date time.Time, locale i18n.Locale) error
+	SendMatchFoundEmail(matchId string, to string, username string, sport models.Sport, fieldName string, date time.Time, team int, locale i18n.Locale) error
 }
 
-type Mailer struct {
-	AlreadySent map[string]bool
-	LastSentAt  map[string]time.Time
-	Config      *models.MailerConfig
+// ErrThrottled is returned when the same email was sent too recently.
+var ErrThrottled = errors.New("email recently sent, throttled")
+
+// SendLog records the emails sent and throttles them, implemented by
+// database.Database so that every instance shares it.
+type SendLog interface {
+	ReserveEmail(ctx context.Context, e models.DBSentEmail, since time.Time) (bool, error)
+	CompleteEmail(ctx context.Context, id string, now time.Time, sendErr error) error
+	IsEmailThrottled(ctx context.Context, throttleKey string, since time.Time) (bool, error)
 }
 
-func (mailer *Mailer) dialer() *gomail.Dialer {
-	d := gomail.NewDialer(mailer.Config.Host, mailer.Config.Port, mailer.Config.Username, mailer.Config.Password)
-	d.TLSConfig = &tls.Config{
-		ServerName: mailer.Config.Host,
-		MinVersion: tls.VersionTLS12,
-	}
-	return d
+// sendTimeout bounds a send, the MailSender methods are called without a context.
+const sendTimeout = 30 * time.Second
+
+// Mailer renders the emails from their templates, throttles them through the send log
+// and hands them to the transport.
+type Mailer struct {
+	Transport Transport
+	Log       SendLog
+	Clock     clock.Clock
+	From      string
 }
 
-func (mailer *Mailer) setCommonHeaders(m *gomail.Message, subject, to string) {
-	from := mailer.Config.From
-	m.SetHeader("From", from)
-	m.SetHeader("To", to)
-	m.SetHeader("Subject", subject)
-	m.SetHeader("Reply-To", "support@tondomaine.tld")
-	msgID := fmt.Sprintf("<%s@%s>", uuid.NewString(), "tondomaine.tld")
-	m.SetHeader("Message-ID", msgID)
+// New builds the Mailer of the configuration: MAIL_TRANSPORT picks SMTP (the default),
+// files in MAIL_OUTPUT_DIR ("file") or the standard output ("stdout").
+func New(cfg *models.MailerConfig, sendLog SendLog, clk clock.Clock) (*Mailer, error) {
+	var transport Transport
+	switch cfg.Transport {
+	case "", "smtp":
+		transport = SMTPTransport{Config: cfg}
+	case "file":
+		if cfg.OutputDir == "" {
+			return nil, errors.New("MAIL_OUTPUT_DIR is required by the file transport")
+		}
+		transport = &FileTransport{Dir: cfg.OutputDir}
+	case "stdout":
+		transport = &FileTransport{Out: os.Stdout}
+	default:
+		return nil, fmt.Errorf("unknown mail transport %q", cfg.Transport)
+	}
+	return &Mailer{Transport: transport, Log: sendLog, Clock: clk, From: cfg.From}, nil
 }
 
-func (mailer *Mailer) SendLinkResetPassword(to string, url string) error {
-	baseLogger := log.With().
-		Str("mail_kind", "reset_password").
+// send renders kind in locale for data and sends it to to, unless an email with throttleKey was
+// sent within window. The reservation in the send log and the send are not atomic: an
+// email whose send fails stops throttling its key so it can be retried.
+func (mailer *Mailer) send(kind Kind, locale i18n.Locale, throttleKey string, window time.Duration, to string, data templateData) error {
+	logger := log.With().
+		Str("mail_kind", string(kind)).
 		Str("to", to).
+		Str("locale", string(locale)).
 		Logger()
 
-	if mailer.AlreadySent[to] && time.Since(mailer.LastSentAt[to]) < 10*time.Second {
-		baseLogger.Warn().
-			Dur("since_last", time.Since(mailer.LastSentAt[to])).
-			Msg("email recently sent → throttled")
-		return fmt.Errorf("email recently sent to %s → throttled", to)
-	}
-
-	baseLogger.Info().Msg("sending reset password email")
-
-	m := gomail.NewMessage()
-	m.SetHeader("From", mailer.Config.From)
-	m.SetHeader("To", to)
-	m.SetHeader("Subject", "Réinitialisation de votre mot de passe")
-
-	textBody := fmt.Sprintf(`Bonjour,
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
 
-Vous avez demandé à réinitialiser votre mot de passe.
-
-Veuillez cliquer sur le lien suivant pour définir un nouveau mot de passe (valable 15 minutes) :
-%s
-
-Si vous n'êtes pas à l'origine de cette demande, vous pouvez ignorer cet e-mail.
-
-Cordialement,
-L'équipe Support`, url)
-
-	htmlBody := fmt.Sprintf(`
-	<html>
-		<body style="font-family: Arial, sans-serif; background-color: #f4f4f4; padding: 20px;">
-			<div style="max-width: 600px; margin: auto; background: white; padding: 20px; border-radius: 8px;">
-				<h2 style="color: #333;">Demande de réinitialisation de mot de passe</h2>
-				<p style="font-size: 16px;">Vous avez demandé à réinitialiser votre mot de passe.</p>
-				<p style="font-size: 16px;">Cliquez sur le lien ci-dessous pour définir un nouveau mot de passe. <strong>Ce lien est valide pendant 15 minutes.</strong></p>
-				<p style="text-align: center; margin: 20px 0;">
-					<a href="%s" style="display: inline-block; background-color: #007BFF; color: white; padding: 12px 20px; text-decoration: none; border-radius: 5px;">
-						Réinitialiser mon mot de passe
-					</a>
-				</p>
-				<p style="font-size: 14px; color: #555;">Si vous n'êtes pas à l'origine de cette demande, vous pouvez ignorer cet e-mail.</p>
-				<hr style="margin: 20px 0;">
-				<small style="color: #888;">Cet e-mail a été envoyé automatiquement par notre application Go.</small>
-			</div>
-		</body>
-	</html>
-	`, url)
-
-	m.SetBody("text/plain", textBody)
-	m.AddAlternative("text/html", htmlBody)
-
-	start := time.Now()
-	d := mailer.dialer()
-
-	if err := d.DialAndSend(m); err != nil {
-		baseLogger.Error().Err(err).Dur("latency", time.Since(start)).Msg("mail send failed")
+	now := mailer.Clock.Now()
+	data.Year = now.Year()
+	msg, err := render(locale, kind, data)
+	if err != nil {
 		return err
 	}
-
-	if mailer.AlreadySent == nil {
-		mailer.AlreadySent = map[string]bool{}
+	msg.From = mailer.From
+	msg.To = to
+
+	entry := models.DBSentEmail{
+		Id:          uuid.NewString(),
+		Kind:        string(kind),
+		ThrottleKey: throttleKey,
+		Recipient:   to,
+		Subject:     msg.Subject,
+		Status:      models.SentEmailSending,
+		CreatedAt:   now,
 	}
-	if mailer.LastSentAt == nil {
-		mailer.LastSentAt = map[string]time.Time{}
+	reserved, err := mailer.Log.ReserveEmail(ctx, entry, now.Add(-window))
+	if err != nil {
+		return err
+	}
+	if !reserved {
+		logger.Warn().Str("throttle_key", throttleKey).Msg("email recently sent → throttled")
+		return fmt.Errorf("%s email to %s: %w", kind, to, ErrThrottled)
 	}
 
-	mailer.AlreadySent[to] = true
-	mailer.LastSentAt[to] = time.Now()
+	logger.Info().Msg("sending email")
+	start := time.Now()
+	sendErr := mailer.Transport.Send(ctx, msg)
+	if err := mailer.Log.CompleteEmail(ctx, entry.Id, mailer.Clock.Now(), sendErr); err != nil {
+		logger.Error().Err(err).Msg("send log update failed")
+	}
+	if sendErr != nil {
+		logger.Error().Err(sendErr).Dur("latency", time.Since(start)).Msg("mail send failed")
+		return sendErr
+	}
 
-	baseLogger.Info().Dur("latency", time.Since(start)).Msg("mail sent successfully")
+	logger.Info().Dur("latency", time.Since(start)).Msg("mail sent successfully")
 	return nil
 }
 
-func (mailer *Mailer) SendWelcomeEmail(userId string, to string, username string) error {
-	key := userId + ":welcome"
-
-	baseLogger := log.With().
-		Str("mail_kind", "welcome").
-		Str("to", to).
-		Str("username", username).
-		Logger()
-
-	if mailer.AlreadySent[key] && time.Since(mailer.LastSentAt[key]) < time.Minute {
-		baseLogger.Warn().
-			Dur("since_last", time.Since(mailer.LastSentAt[key])).
-			Msg("welcome email recently sent → throttled")
-		return fmt.Errorf("welcome email recently sent to %s → throttled", to)
-	}
-
-	baseLogger.Info().Msg("sending welcome email")
-
-	m := gomail.NewMessage()
-	m.SetHeader("From", mailer.Config.From)
-	m.SetHeader("To", to)
-	m.SetHeader("Subject", fmt.Sprintf("Bienvenue sur Play The Street, %s 🏀", username))
-
-	textBody := fmt.Sprintf(`Salut %s,
-
-Bienvenue sur Play The Street ! 🙌
-Tu peux maintenant :
-• Créer ou rejoindre des matchs
-• Suivre tes stats & victoires
-• Découvrir les terrains près de chez toi
-
-À très vite sur le terrain !
-L’équipe Play The Street`, username)
-
-	htmlBody := fmt.Sprintf(`
-<html>
-	<body style="margin:0;padding:0;background:#0E0E0E;font-family: Inter, Arial, sans-serif;">
-		<table role="presentation" width="100%%" cellpadding="0" cellspacing="0" style="padding:24px 0;">
-			<tr>
-				<td align="center">
-					<table role="presentation" width="600" cellpadding="0" cellspacing="0" style="width:600px;max-width:94%%;">
-						
-						<!-- LOGO / HEADER -->
-						<tr>
-							<td align="center" style="padding:8px 0 20px 0;">
-								<div style="font-size:22px;color:#FF6A00;font-weight:700;letter-spacing:.3px;">
-									PLAY THE STREET
-								</div>
-							</td>
-						</tr>
-
-						<!-- CARD -->
-						<tr>
-							<td style="background:#1A1A1A;border-radius:16px;padding:28px 22px;border:1px solid #2B2B2B;">
-								
-								<!-- TITRE CENTRÉ -->
-								<h1 style="
-									margin:0 0 16px 0;
-									font-size:24px;
-									line-height:30px;
-									color:#EDEDED;
-									text-align:center;
-									font-weight:600;
-								">
-									Bienvenue, %s 👋
-								</h1>
-
-								<!-- Texte intro -->
-								<p style="margin:0 0 22px 0;font-size:15px;line-height:22px;color:#CFCFCF;text-align:center;">
-									Ravi de te compter parmi nous. À partir d’aujourd’hui, tu peux créer ou rejoindre des matchs,
-									suivre tes stats, et découvrir les meilleurs terrains autour de toi.
-								</p>
-
-								<!-- LISTE CENTRÉE -->
-								<table role="presentation" cellpadding="0" cellspacing="0" style="margin:0 auto 26px auto;">
-									<tr>
-										<td style="
-											background:#2B2B2B;
-											border-radius:12px;
-											padding:14px 16px;
-											border:1px solid #3A3A3A;
-											text-align:center;
-										">
-											<ul style="
-												list-style:none;
-												padding:0;
-												margin:0;
-												color:#D9D9D9;
-												font-size:14px;
-												line-height:22px;
-												text-align:center;
-											">
-												<li>Crée un match en 10&nbsp;secondes</li>
-												<li>Invite des joueurs près de chez toi</li>
-												<li>Suis tes victoires et ton classement</li>
-											</ul>
-										</td>
-									</tr>
-								</table>
-
-								<!-- TEXTE MOTIVANT -->
-								<p style="
-									margin:12px 0 0 0;
-									font-size:14px;
-									line-height:22px;
-									color:#FF6A00;
-									text-align:center;
-									font-weight:600;
-								">
-									🔥 Ne perds pas une seconde : ton prochain match t’attend déjà.
-								</p>
-
-							</td>
-						</tr>
-
-						<!-- FOOTER -->
-						<tr>
-							<td style="padding:18px 6px 0 6px;text-align:center;color:#7A7A7A;font-size:12px;">
-								© %d Play The Street • Paris, France
-							</td>
-						</tr>
-
-					</table>
-				</td>
-			</tr>
-		</table>
-	</body>
-</html>
-`, username, time.Now().Year())
-
-	m.SetBody("text/plain", textBody)
-	m.AddAlternative("text/html", htmlBody)
-
-	start := time.Now()
-	d := mailer.dialer()
+func (mailer *Mailer) SendLinkResetPassword(to string, url string, locale i18n.Locale) error {
+	return mailer.send(KindResetPassword, locale, to+":reset_password", 10*time.Second, to, templateData{URL: url})
+}
 
-	if err := d.DialAndSend(m); err != nil {
-		baseLogger.Error().Err(err).Dur("latency", time.Since(start)).Msg("mail send failed")
-		return err
-	}
+func (mailer *Mailer) SendWelcomeEmail(userId string, to string, username string, locale i18n.Locale) error {
+	return mailer.send(KindWelcome, locale, userId+":welcome", time.Minute, to, templateData{Username: username})
+}
 
-	if mailer.AlreadySent == nil {
-		mailer.AlreadySent = map[string]bool{}
-	}
-	if mailer.LastSentAt == nil {
-		mailer.LastSentAt = map[string]time.Time{}
-	}
+// verifyEmailWindow is how long a verification email throttles the next ones of the user.
+const verifyEmailWindow = time.Minute
 
-	mailer.AlreadySent[key] = true
-	mailer.LastSentAt[key] = time.Now()
+func (mailer *Mailer) SendVerificationEmail(userId string, to string, username string, url string, locale i18n.Locale) error {
+	return mailer.send(KindVerifyEmail, locale, userId+":verify_email", verifyEmailWindow, to, templateData{Username: username, URL: url})
+}
 
-	baseLogger.Info().Dur("latency", time.Since(start)).Msg("mail sent successfully")
-	return nil
+func (mailer *Mailer) VerificationEmailThrottled(userId string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+	return mailer.Log.IsEmailThrottled(ctx, userId+":verify_email", mailer.Clock.Now().Add(-verifyEmailWindow))
 }
 
-func sportMeta(s models.Sport) (label, emoji string) {
+// SportMeta is the label of the sport in locale and its emoji.
+func SportMeta(locale i18n.Locale, s models.Sport) (label, emoji string) {
 	switch s {
 	case models.Basket:
-		return "Basket", "🏀"
+		return i18n.T(locale, i18n.SportBasket), "🏀"
 	case models.Foot:
-		return "Football", "⚽️"
+		return i18n.T(locale, i18n.SportFoot), "⚽️"
 	case models.PingPong:
-		return "Ping-pong", "🏓"
+		return i18n.T(locale, i18n.SportPingPong), "🏓"
 	default:
 		return string(s), "🎯"
 	}
 }
 
-func (mailer *Mailer) SendMatchResultEmail(matchId string, to string, username string, sport models.Sport, fieldName string, teamScore, oppScore int) error {
-	baseLogger := log.With().
-		Str("mail_kind", "match_result").
-		Str("to", to).
-		Str("username", username).
-		Str("sport", string(sport)).
-		Str("field", fieldName).
-		Int("team_score", teamScore).
-		Int("opp_score", oppScore).
-		Logger()
-
-	key := matchId + ":match_result"
-	if mailer.AlreadySent[key] && time.Since(mailer.LastSentAt[key]) < 10*time.Second {
-		baseLogger.Warn().Dur("since_last", time.Since(mailer.LastSentAt[key])).Msg("match result email throttled")
-		return fmt.Errorf("match result email recently sent to %s → throttled", to)
+// matchData fills the fields shared by the match emails.
+func matchData(locale i18n.Locale, username string, sport models.Sport, fieldName string, date time.Time) templateData {
+	label, emoji := SportMeta(locale, sport)
+	return templateData{
+		Username: username,
+		Label:    label,
+		Emoji:    emoji,
+		Field:    fieldName,
+		When:     date.Format(i18n.T(locale, i18n.DateTimeLayout)),
 	}
+}
 
-	label, emoji := sportMeta(sport)
-	resultWord := "Match nul"
-	resultBadgeBg := "#3F3F46"
-	if teamScore > oppScore {
-		resultWord = "Victoire"
-		resultBadgeBg = "#22C55E"
-	} else if teamScore < oppScore {
-		resultWord = "Défaite"
-		resultBadgeBg = "#EF4444"
+func (mailer *Mailer) SendMatchResultEmail(matchId string, to string, username string, sport models.Sport, fieldName string, teamScore, oppScore int, locale i18n.Locale) error {
+	data := matchData(locale, username, sport, fieldName, time.Time{})
+	data.TeamScore = teamScore
+	data.OppScore = oppScore
+	switch {
+	case teamScore > oppScore:
+		data.ResultWord, data.ResultBadge, data.Cheer = i18n.T(locale, i18n.MailResultVictory), "#22C55E", i18n.T(locale, i18n.MailCheerVictory)
+	case teamScore < oppScore:
+		data.ResultWord, data.ResultBadge, data.Cheer = i18n.T(locale, i18n.MailResultDefeat), "#EF4444", i18n.T(locale, i18n.MailCheerDefeat)
+	default:
+		data.ResultWord, data.ResultBadge, data.Cheer = i18n.T(locale, i18n.MailResultDraw), "#3F3F46", i18n.T(locale, i18n.MailCheerDraw)
 	}
+	return mailer.send(KindMatchResult, locale, matchId+":"+to+":match_result", 10*time.Second, to, data)
+}
 
-	subject := fmt.Sprintf("%s • %s à %s — %s %d–%d", label, emoji, fieldName, resultWord, teamScore, oppScore)
-
-	baseLogger.Info().Msg("sending match result email")
-
-	m := gomail.NewMessage()
-	m.SetHeader("From", mailer.Config.From)
-	m.SetHeader("To", to)
-	m.SetHeader("Subject", subject)
-
-	textBody := fmt.Sprintf(`Salut %s,
-
-Résultat de ton match de %s à %s :
-%s — %d-%d
-
-À bientôt sur le terrain !
-Play The Street`,
-		username, label, fieldName, resultWord, teamScore, oppScore)
-
-	htmlBody := fmt.Sprintf(`
-<html>
-	<body style="margin:0;padding:0;background:#0E0E0E;font-family: Inter, Arial, sans-serif;">
-		<table role="presentation" width="100%%" cellpadding="0" cellspacing="0" style="padding:24px 0;">
-			<tr>
-				<td align="center">
-					<table role="presentation" width="600" cellpadding="0" cellspacing="0" style="width:600px;max-width:94%%;">
-						<tr>
-							<td align="center" style="padding:8px 0 20px 0;">
-								<div style="font-size:22px;color:#FF6A00;font-weight:700;letter-spacing:.3px;">
-									PLAY THE STREET
-								</div>
-							</td>
-						</tr>
-
-						<tr>
-							<td style="background:#1A1A1A;border-radius:16px;padding:28px 22px;border:1px solid #2B2B2B;">
-								
-								<h1 style="margin:0 0 14px 0;font-size:22px;line-height:28px;color:#EDEDED;text-align:center;font-weight:600;">
-									%s — %s
-								</h1>
-								<p style="margin:0 0 6px 0;font-size:14px;line-height:20px;color:#BDBDBD;text-align:center;">
-									%s • %s
-								</p>
-
-								<div style="text-align:center;margin:18px 0 8px 0;">
-									<span style="display:inline-block;padding:8px 14px;border-radius:999px;background:%s;color:#0E0E0E;font-weight:800;font-size:13px;">
-										%s
-									</span>
-								</div>
-
-								<div style="text-align:center;margin:14px 0 20px 0;">
-									<span style="display:inline-block;font-size:34px;line-height:38px;color:#FFFFFF;font-weight:800;">
-										%d&nbsp;–&nbsp;%d
-									</span>
-								</div>
-
-								<p style="margin:6px 0 0 0;font-size:14px;line-height:22px;color:#FF6A00;text-align:center;font-weight:600;">
-									%s, %s ! Continue sur ta lancée.
-								</p>
-							</td>
-						</tr>
-
-						<tr>
-							<td style="padding:18px 6px 0 6px;text-align:center;color:#7A7A7A;font-size:12px;">
-								© %d Play The Street • Paris, France
-							</td>
-						</tr>
-					</table>
-				</td>
-			</tr>
-		</table>
-	</body>
-</html>
-`, label, emoji, fieldName, username, resultBadgeBg, resultWord, teamScore, oppScore,
-		func() string {
-			if teamScore > oppScore {
-				return "Belle victoire"
-			}
-			if teamScore == oppScore {
-				return "Beau match"
-			}
-			return "Ce n’est que partie remise"
-		}(), username, time.Now().Year())
-
-	m.SetBody("text/plain", textBody)
-	m.AddAlternative("text/html", htmlBody)
-
-	start := time.Now()
-	d := mailer.dialer()
-	if err := d.DialAndSend(m); err != nil {
-		baseLogger.Error().Err(err).Dur("latency", time.Since(start)).Msg("mail send failed")
-		return err
-	}
+func (mailer *Mailer) SendMatchCancelledEmail(matchId string, to string, username string, sport models.Sport, fieldName string, date time.Time, locale i18n.Locale) error {
+	return mailer.send(KindMatchCancelled, locale, matchId+":"+to+":match_cancelled", 10*time.Second, to, matchData(locale, username, sport, fieldName, date))
+}
 
-	if mailer.AlreadySent == nil {
-		mailer.AlreadySent = map[string]bool{}
-	}
-	if mailer.LastSentAt == nil {
-		mailer.LastSentAt = map[string]time.Time{}
-	}
-	mailer.AlreadySent[key] = true
-	mailer.LastSentAt[key] = time.Now()
+func (mailer *Mailer) SendMatchInvitationEmail(matchId string, to string, username string, inviterName string, sport models.Sport, fieldName string, date time.Time, locale i18n.Locale) error {
+	data := matchData(locale, username, sport, fieldName, date)
+	data.Inviter = inviterName
+	return mailer.send(KindMatchInvitation, locale, matchId+":"+to+":match_invitation", 10*time.Second, to, data)
+}
 
-	baseLogger.Info().Dur("latency", time.Since(start)).Msg("mail sent successfully")
-	return nil
+func (mailer *Mailer) SendMatchFoundEmail(matchId string, to string, username string, sport models.Sport, fieldName string, date time.Time, team int, locale i18n.Locale) error {
+	data := matchData(locale, username, sport, fieldName, date)
+	data.Team = team
+	return mailer.send(KindMatchFound, locale, matchId+":"+to+":match_found", 10*time.Second, to, data)
 }
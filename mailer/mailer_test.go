@@ -0,0 +1,323 @@
+package mailer
+
+import (
+	"PLIC/clock"
+	"PLIC/i18n"
+	"PLIC/models"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memoryLog is a SendLog kept in memory.
+type memoryLog struct {
+	mu     sync.Mutex
+	emails []models.DBSentEmail
+}
+
+func (l *memoryLog) ReserveEmail(_ context.Context, e models.DBSentEmail, since time.Time) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, sent := range l.emails {
+		if sent.ThrottleKey == e.ThrottleKey && sent.CreatedAt.After(since) && sent.Status != models.SentEmailFailed {
+			return false, nil
+		}
+	}
+	l.emails = append(l.emails, e)
+	return true, nil
+}
+
+func (l *memoryLog) IsEmailThrottled(_ context.Context, throttleKey string, since time.Time) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, sent := range l.emails {
+		if sent.ThrottleKey == throttleKey && sent.CreatedAt.After(since) && sent.Status != models.SentEmailFailed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (l *memoryLog) CompleteEmail(_ context.Context, id string, now time.Time, sendErr error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i := range l.emails {
+		if l.emails[i].Id != id {
+			continue
+		}
+		if sendErr != nil {
+			l.emails[i].Status = models.SentEmailFailed
+		} else {
+			l.emails[i].Status = models.SentEmailSent
+			l.emails[i].SentAt = &now
+		}
+	}
+	return nil
+}
+
+// recordingTransport keeps the messages instead of sending them.
+type recordingTransport struct {
+	mu   sync.Mutex
+	sent []Message
+	err  error
+}
+
+func (t *recordingTransport) Send(_ context.Context, msg Message) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err != nil {
+		return t.err
+	}
+	t.sent = append(t.sent, msg)
+	return nil
+}
+
+func newTestMailer(now time.Time) (*Mailer, *recordingTransport, *clock.Mock) {
+	transport := &recordingTransport{}
+	clk := clock.NewMock(now)
+	return &Mailer{Transport: transport, Log: &memoryLog{}, Clock: clk, From: "noreply@plic.test"}, transport, clk
+}
+
+func TestMailer_RendersEveryKind(t *testing.T) {
+	date := time.Date(2026, 5, 2, 18, 30, 0, 0, time.UTC)
+
+	type testCase struct {
+		name        string
+		send        func(m *Mailer) error
+		wantSubject string
+		wantText    string
+		wantHTML    string
+	}
+
+	testCases := []testCase{
+		{
+			name:        "Reset password",
+			send:        func(m *Mailer) error { return m.SendLinkResetPassword("a@b.c", "https://app/reset/tok", i18n.FR) },
+			wantSubject: "Réinitialisation de votre mot de passe",
+			wantText:    "https://app/reset/tok",
+			wantHTML:    `href="https://app/reset/tok"`,
+		},
+		{
+			name:        "Welcome",
+			send:        func(m *Mailer) error { return m.SendWelcomeEmail("u1", "a@b.c", "Zoé", i18n.FR) },
+			wantSubject: "Bienvenue sur Play The Street, Zoé 🏀",
+			wantText:    "Salut Zoé,",
+			wantHTML:    "© 2026 Play The Street",
+		},
+		{
+			name: "Verification",
+			send: func(m *Mailer) error {
+				return m.SendVerificationEmail("u1", "a@b.c", "Zoé", "https://app/verify/tok", i18n.FR)
+			},
+			wantSubject: "Confirme ton adresse e-mail",
+			wantText:    "https://app/verify/tok",
+			wantHTML:    `href="https://app/verify/tok"`,
+		},
+		{
+			name: "Match result",
+			send: func(m *Mailer) error {
+				return m.SendMatchResultEmail("m1", "a@b.c", "Zoé", models.Basket, "Stalingrad", 21, 15, i18n.FR)
+			},
+			wantSubject: "Basket • 🏀 à Stalingrad — Victoire 21–15",
+			wantText:    "Victoire — 21-15",
+			wantHTML:    "background:#22C55E",
+		},
+		{
+			name: "Match cancelled",
+			send: func(m *Mailer) error {
+				return m.SendMatchCancelledEmail("m1", "a@b.c", "Zoé", models.Foot, "Stalingrad", date, i18n.FR)
+			},
+			wantSubject: "Football • ⚽️ à Stalingrad — Match annulé",
+			wantText:    "prévu le 02/05/2026 à 18h30",
+			wantHTML:    "<strong>Stalingrad</strong>",
+		},
+		{
+			name: "Match invitation",
+			send: func(m *Mailer) error {
+				return m.SendMatchInvitationEmail("m1", "a@b.c", "Zoé", "Léo", models.PingPong, "Stalingrad", date, i18n.FR)
+			},
+			wantSubject: "Ping-pong • 🏓 à Stalingrad — Léo t'invite",
+			wantText:    "Léo t'invite",
+			wantHTML:    "<strong>Léo</strong>",
+		},
+		{
+			name: "Match found",
+			send: func(m *Mailer) error {
+				return m.SendMatchFoundEmail("m1", "a@b.c", "Zoé", models.Basket, "Stalingrad", date, 2, i18n.FR)
+			},
+			wantSubject: "Basket • 🏀 à Stalingrad — Match trouvé !",
+			wantText:    "Tu joues dans l'équipe 2.",
+			wantHTML:    "équipe 2",
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			m, transport, _ := newTestMailer(date)
+			require.NoError(t, c.send(m))
+			require.Len(t, transport.sent, 1)
+
+			msg := transport.sent[0]
+			require.Equal(t, "a@b.c", msg.To)
+			require.Equal(t, "noreply@plic.test", msg.From)
+			require.Equal(t, c.wantSubject, msg.Subject)
+			require.Contains(t, msg.Text, c.wantText)
+			require.Contains(t, msg.HTML, c.wantHTML)
+		})
+	}
+}
+
+func TestMailer_Locales(t *testing.T) {
+	date := time.Date(2026, 5, 2, 18, 30, 0, 0, time.UTC)
+
+	type testCase struct {
+		name        string
+		send        func(m *Mailer) error
+		wantSubject string
+		wantText    string
+	}
+
+	testCases := []testCase{
+		{
+			name:        "Welcome in English",
+			send:        func(m *Mailer) error { return m.SendWelcomeEmail("u1", "a@b.c", "Zoé", i18n.EN) },
+			wantSubject: "Welcome to Play The Street, Zoé 🏀",
+			wantText:    "Hi Zoé,",
+		},
+		{
+			name: "Match result in English",
+			send: func(m *Mailer) error {
+				return m.SendMatchResultEmail("m1", "a@b.c", "Zoé", models.PingPong, "Stalingrad", 1, 3, i18n.EN)
+			},
+			wantSubject: "Table tennis • 🏓 at Stalingrad — Defeat 1–3",
+			wantText:    "Defeat — 1-3",
+		},
+		{
+			name: "Match cancelled in English",
+			send: func(m *Mailer) error {
+				return m.SendMatchCancelledEmail("m1", "a@b.c", "Zoé", models.Foot, "Stalingrad", date, i18n.EN)
+			},
+			wantSubject: "Football • ⚽️ at Stalingrad — Match cancelled",
+			wantText:    "planned on 05/02/2026 at 6:30 PM",
+		},
+		{
+			name:        "Unsupported locale -> French",
+			send:        func(m *Mailer) error { return m.SendWelcomeEmail("u1", "a@b.c", "Zoé", "de") },
+			wantSubject: "Bienvenue sur Play The Street, Zoé 🏀",
+			wantText:    "Salut Zoé,",
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			m, transport, _ := newTestMailer(date)
+			require.NoError(t, c.send(m))
+			require.Len(t, transport.sent, 1)
+			require.Equal(t, c.wantSubject, transport.sent[0].Subject)
+			require.Contains(t, transport.sent[0].Text, c.wantText)
+		})
+	}
+}
+
+func TestMailer_EscapesHTML(t *testing.T) {
+	m, transport, _ := newTestMailer(time.Now())
+	require.NoError(t, m.SendWelcomeEmail("u1", "a@b.c", "<script>alert(1)</script>", i18n.FR))
+
+	require.Len(t, transport.sent, 1)
+	require.NotContains(t, transport.sent[0].HTML, "<script>")
+	require.Contains(t, transport.sent[0].HTML, "&lt;script&gt;")
+}
+
+func TestMailer_Throttling(t *testing.T) {
+	now := time.Now()
+
+	t.Run("Same email twice -> throttled until the window is over", func(t *testing.T) {
+		m, transport, clk := newTestMailer(now)
+		require.NoError(t, m.SendVerificationEmail("u1", "a@b.c", "Zoé", "https://app/1", i18n.FR))
+		throttled, err := m.VerificationEmailThrottled("u1")
+		require.NoError(t, err)
+		require.True(t, throttled)
+		require.ErrorIs(t, m.SendVerificationEmail("u1", "a@b.c", "Zoé", "https://app/2", i18n.FR), ErrThrottled)
+
+		clk.Advance(time.Minute + time.Second)
+		throttled, err = m.VerificationEmailThrottled("u1")
+		require.NoError(t, err)
+		require.False(t, throttled)
+		require.NoError(t, m.SendVerificationEmail("u1", "a@b.c", "Zoé", "https://app/3", i18n.FR))
+		require.Len(t, transport.sent, 2)
+	})
+
+	t.Run("Result of one match to two players -> both sent", func(t *testing.T) {
+		m, transport, _ := newTestMailer(now)
+		require.NoError(t, m.SendMatchResultEmail("m1", "a@b.c", "A", models.Basket, "Court", 1, 0, i18n.FR))
+		require.NoError(t, m.SendMatchResultEmail("m1", "d@e.f", "D", models.Basket, "Court", 0, 1, i18n.FR))
+		require.Len(t, transport.sent, 2)
+	})
+
+	t.Run("Failed send -> retried right away", func(t *testing.T) {
+		m, transport, _ := newTestMailer(now)
+		transport.err = errors.New("smtp down")
+		require.Error(t, m.SendWelcomeEmail("u1", "a@b.c", "Zoé", i18n.FR))
+
+		transport.err = nil
+		require.NoError(t, m.SendWelcomeEmail("u1", "a@b.c", "Zoé", i18n.FR))
+		require.Len(t, transport.sent, 1)
+	})
+
+	t.Run("Concurrent sends -> only one goes out", func(t *testing.T) {
+		m, transport, _ := newTestMailer(now)
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = m.SendLinkResetPassword("a@b.c", "https://app/reset", i18n.FR)
+			}()
+		}
+		wg.Wait()
+		require.Len(t, transport.sent, 1)
+	})
+}
+
+func TestFileTransport(t *testing.T) {
+	msg := Message{Kind: KindWelcome, From: "noreply@plic.test", To: "a@b.c", Subject: "Bienvenue", Text: "Salut", HTML: "<p>Salut</p>"}
+
+	t.Run("Directory", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, (&FileTransport{Dir: dir}).Send(context.Background(), msg))
+
+		files, err := filepath.Glob(filepath.Join(dir, "*-welcome-*"))
+		require.NoError(t, err)
+		require.Len(t, files, 2)
+		for _, f := range files {
+			content, err := os.ReadFile(f)
+			require.NoError(t, err)
+			require.Contains(t, string(content), "Salut")
+		}
+	})
+
+	t.Run("Writer", func(t *testing.T) {
+		var out strings.Builder
+		require.NoError(t, (&FileTransport{Out: &out}).Send(context.Background(), msg))
+		require.Contains(t, out.String(), "To: a@b.c")
+		require.Contains(t, out.String(), "Subject: Bienvenue")
+	})
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(&models.MailerConfig{Transport: "file"}, &memoryLog{}, clock.NewMock(time.Now()))
+	require.Error(t, err)
+	_, err = New(&models.MailerConfig{Transport: "pigeon"}, &memoryLog{}, clock.NewMock(time.Now()))
+	require.Error(t, err)
+
+	m, err := New(&models.MailerConfig{Transport: "stdout", From: "noreply@plic.test"}, &memoryLog{}, clock.NewMock(time.Now()))
+	require.NoError(t, err)
+	require.IsType(t, &FileTransport{}, m.Transport)
+}
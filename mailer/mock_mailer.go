@@ -1,9 +1,19 @@
 package mailer
 
-import "PLIC/models"
+import (
+	"PLIC/i18n"
+	"PLIC/models"
+	"time"
+)
 
 type MockMailer struct {
-	SentCounts map[string]int
+	SentCounts           map[string]int
+	LastResetLink        string
+	LastVerificationLink string
+	LastVerificationTo   string
+	LastLocale           i18n.Locale
+	// VerificationThrottled makes the verification emails throttled.
+	VerificationThrottled bool
 }
 
 func NewMockMailer() *MockMailer {
@@ -12,21 +22,58 @@ func NewMockMailer() *MockMailer {
 	}
 }
 
-func (m *MockMailer) SendLinkResetPassword(_ string, _ string) error {
+func (m *MockMailer) SendLinkResetPassword(_ string, url string, locale i18n.Locale) error {
+	m.LastLocale = locale
 	m.SentCounts["link_reset"]++
+	m.LastResetLink = url
 	return nil
 }
 
-func (m *MockMailer) SendWelcomeEmail(_ string, _ string, _ string) error {
+func (m *MockMailer) SendWelcomeEmail(_ string, _ string, _ string, locale i18n.Locale) error {
+	m.LastLocale = locale
 	m.SentCounts["welcome"]++
 	return nil
 }
 
-func (m *MockMailer) SendMatchResultEmail(_ string, _ string, _ string, _ models.Sport, _ string, _, _ int) error {
+func (m *MockMailer) SendVerificationEmail(_ string, to string, _ string, url string, locale i18n.Locale) error {
+	if m.VerificationThrottled {
+		return ErrThrottled
+	}
+	m.LastLocale = locale
+	m.SentCounts["verify_email"]++
+	m.LastVerificationTo = to
+	m.LastVerificationLink = url
+	return nil
+}
+
+func (m *MockMailer) VerificationEmailThrottled(_ string) (bool, error) {
+	return m.VerificationThrottled, nil
+}
+
+func (m *MockMailer) SendMatchResultEmail(_ string, _ string, _ string, _ models.Sport, _ string, _, _ int, locale i18n.Locale) error {
+	m.LastLocale = locale
 	m.SentCounts["result"]++
 	return nil
 }
 
+func (m *MockMailer) SendMatchCancelledEmail(_ string, _ string, _ string, _ models.Sport, _ string, _ time.Time, locale i18n.Locale) error {
+	m.LastLocale = locale
+	m.SentCounts["cancelled"]++
+	return nil
+}
+
+func (m *MockMailer) SendMatchInvitationEmail(_ string, _ string, _ string, _ string, _ models.Sport, _ string, _ time.Time, locale i18n.Locale) error {
+	m.LastLocale = locale
+	m.SentCounts["invitation"]++
+	return nil
+}
+
+func (m *MockMailer) SendMatchFoundEmail(_ string, _ string, _ string, _ models.Sport, _ string, _ time.Time, _ int, locale i18n.Locale) error {
+	m.LastLocale = locale
+	m.SentCounts["match_found"]++
+	return nil
+}
+
 func (m *MockMailer) GetSentCounts(mail string) int {
 	return m.SentCounts[mail]
 }
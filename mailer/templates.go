@@ -0,0 +1,96 @@
+package mailer
+
+import (
+	"PLIC/i18n"
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// Kind is a kind of email, named after its templates: templates/<locale>/<kind>.txt
+// holds the subject (its "subject" template) and the plain text body,
+// templates/<locale>/<kind>.html the HTML body, which can use the partials of the
+// layout.html of its locale.
+type Kind string
+
+const (
+	KindResetPassword   Kind = "reset_password"
+	KindWelcome         Kind = "welcome"
+	KindVerifyEmail     Kind = "verify_email"
+	KindMatchResult     Kind = "match_result"
+	KindMatchCancelled  Kind = "match_cancelled"
+	KindMatchInvitation Kind = "match_invitation"
+	KindMatchFound      Kind = "match_found"
+)
+
+var kinds = []Kind{KindResetPassword, KindWelcome, KindVerifyEmail, KindMatchResult, KindMatchCancelled, KindMatchInvitation, KindMatchFound}
+
+type kindTemplates struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// templates are parsed once at start up, a broken or missing template of any locale
+// panics right away instead of failing its first send.
+var templates = mustParseTemplates()
+
+func mustParseTemplates() map[i18n.Locale]map[Kind]kindTemplates {
+	parsed := make(map[i18n.Locale]map[Kind]kindTemplates, len(i18n.Locales))
+	for _, l := range i18n.Locales {
+		dir := "templates/" + string(l) + "/"
+		parsed[l] = make(map[Kind]kindTemplates, len(kinds))
+		for _, k := range kinds {
+			text := texttemplate.Must(texttemplate.New(string(k)+".txt").ParseFS(templateFS, dir+string(k)+".txt"))
+			html := htmltemplate.Must(htmltemplate.New(string(k)+".html").ParseFS(templateFS, dir+string(k)+".html", dir+"layout.html"))
+			parsed[l][k] = kindTemplates{text: text, html: html}
+		}
+	}
+	return parsed
+}
+
+// templateData is what the templates can use; each kind only uses some of the fields.
+type templateData struct {
+	Username string
+	URL      string
+	Year     int
+
+	Label string
+	Emoji string
+	Field string
+	When  string
+
+	Inviter string
+	Team    int
+
+	TeamScore   int
+	OppScore    int
+	ResultWord  string
+	ResultBadge string
+	Cheer       string
+}
+
+// render builds the message of kind in locale for data, without its recipient. An
+// unsupported locale falls back to i18n.Default.
+func render(locale i18n.Locale, kind Kind, data templateData) (Message, error) {
+	t, ok := templates[locale.Or(i18n.Default)][kind]
+	if !ok {
+		return Message{}, fmt.Errorf("unknown email kind %q", kind)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := t.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, fmt.Errorf("render %s subject: %w", kind, err)
+	}
+	if err := t.text.Execute(&text, data); err != nil {
+		return Message{}, fmt.Errorf("render %s text: %w", kind, err)
+	}
+	if err := t.html.Execute(&html, data); err != nil {
+		return Message{}, fmt.Errorf("render %s html: %w", kind, err)
+	}
+	return Message{Kind: kind, Subject: subject.String(), Text: text.String(), HTML: html.String()}, nil
+}
@@ -0,0 +1,98 @@
+package mailer
+
+import (
+	"PLIC/models"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/gomail.v2"
+)
+
+// Message is a rendered email.
+type Message struct {
+	Kind    Kind
+	From    string
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Transport delivers rendered emails.
+type Transport interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// SMTPTransport sends the emails through the SMTP server of the configuration.
+type SMTPTransport struct {
+	Config *models.MailerConfig
+}
+
+func (t SMTPTransport) Send(_ context.Context, msg Message) error {
+	m := gomail.NewMessage()
+	m.SetHeader("From", msg.From)
+	m.SetHeader("To", msg.To)
+	m.SetHeader("Subject", msg.Subject)
+	m.SetHeader("Message-ID", fmt.Sprintf("<%s@%s>", uuid.NewString(), messageIDDomain(msg.From)))
+	m.SetBody("text/plain", msg.Text)
+	m.AddAlternative("text/html", msg.HTML)
+
+	d := gomail.NewDialer(t.Config.Host, t.Config.Port, t.Config.Username, t.Config.Password)
+	d.TLSConfig = &tls.Config{
+		ServerName: t.Config.Host,
+		MinVersion: tls.VersionTLS12,
+	}
+	return d.DialAndSend(m)
+}
+
+func messageIDDomain(from string) string {
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		return strings.Trim(from[i+1:], "> ")
+	}
+	return "localhost"
+}
+
+// FileTransport is for local development: it writes each email to a file of Dir, or
+// to Out when Dir is empty, instead of sending it. It is safe for concurrent use.
+type FileTransport struct {
+	Dir string
+	Out io.Writer
+
+	mu sync.Mutex
+}
+
+func (t *FileTransport) Send(_ context.Context, msg Message) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\nTo: %s\nSubject: %s\nX-Kind: %s\n\n%s\n", msg.From, msg.To, msg.Subject, msg.Kind, msg.Text)
+
+	if t.Dir == "" {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		out := t.Out
+		if out == nil {
+			out = os.Stdout
+		}
+		_, err := io.WriteString(out, b.String()+"\n")
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s-%s", time.Now().Format("20060102T150405"), msg.Kind, uuid.NewString()[:8])
+	if err := os.MkdirAll(t.Dir, 0o755); err != nil {
+		return fmt.Errorf("create mail directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(t.Dir, name+".txt"), []byte(b.String()), 0o644); err != nil {
+		return fmt.Errorf("write mail: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(t.Dir, name+".html"), []byte(msg.HTML), 0o644); err != nil {
+		return fmt.Errorf("write mail: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package matchmaking
+
+import "sort"
+
+// exactBalanceLimit is the largest number of players split by trying every team
+// composition; larger groups are split greedily.
+const exactBalanceLimit = 20
+
+// BalanceTeams splits an even number of players into two teams of the same size so
+// that the difference between the teams' total (hence average) ratings is as small as
+// possible. It returns the indexes of the players of each team, in ascending order.
+func BalanceTeams(ratings []int) (team1, team2 []int) {
+	n := len(ratings)
+	if n == 0 {
+		return nil, nil
+	}
+	if n > exactBalanceLimit {
+		return greedyBalance(ratings)
+	}
+
+	total := 0
+	for _, r := range ratings {
+		total += r
+	}
+
+	// Player 0 always plays in team 1: a composition and its mirror are the same split.
+	best, bestDiff := 0, -1
+	for mask := 0; mask < 1<<(n-1); mask++ {
+		set := mask<<1 | 1
+		if popcount(set) != n/2 {
+			continue
+		}
+		sum := 0
+		for i := 0; i < n; i++ {
+			if set&(1<<i) != 0 {
+				sum += ratings[i]
+			}
+		}
+		diff := abs(2*sum - total)
+		if bestDiff < 0 || diff < bestDiff {
+			best, bestDiff = set, diff
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		if best&(1<<i) != 0 {
+			team1 = append(team1, i)
+		} else {
+			team2 = append(team2, i)
+		}
+	}
+	return team1, team2
+}
+
+// greedyBalance gives each player, strongest first, to the weaker team that still has
+// a free seat.
+func greedyBalance(ratings []int) (team1, team2 []int) {
+	order := make([]int, len(ratings))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return ratings[order[a]] > ratings[order[b]] })
+
+	size := len(ratings) / 2
+	sum1, sum2 := 0, 0
+	for _, i := range order {
+		if len(team2) >= size || (len(team1) < size && sum1 <= sum2) {
+			team1 = append(team1, i)
+			sum1 += ratings[i]
+		} else {
+			team2 = append(team2, i)
+			sum2 += ratings[i]
+		}
+	}
+	sort.Ints(team1)
+	sort.Ints(team2)
+	return team1, team2
+}
+
+func popcount(x int) int {
+	c := 0
+	for ; x != 0; x &= x - 1 {
+		c++
+	}
+	return c
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
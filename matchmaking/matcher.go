@@ -0,0 +1,198 @@
+package matchmaking
+
+import (
+	"PLIC/clock"
+	"sort"
+	"time"
+)
+
+// Candidate is a player waiting in the matchmaking queue.
+type Candidate struct {
+	EntryID  string
+	UserID   string
+	Elo      int
+	TeamSize int
+	// WindowStart and WindowEnd bound the start times the player accepts.
+	WindowStart time.Time
+	WindowEnd   time.Time
+	QueuedAt    time.Time
+	// Courts are the courts the player accepts, with their distance in meters to the
+	// player's search area; preferred courts are at distance 0.
+	Courts map[string]float64
+}
+
+// Proposal is a match the matcher found: every player accepts the court and the date.
+type Proposal struct {
+	CourtID string
+	Date    time.Time
+	Team1   []Candidate
+	Team2   []Candidate
+}
+
+// Matcher groups queued players into matches. A player is only grouped with players
+// whose Elo is within MaxEloGap of theirs; the gap grows by EloGapPerMinute for every
+// minute the oldest player of the group has waited, so nobody waits forever. Matches
+// start at least LeadTime after now.
+type Matcher struct {
+	Clock           clock.Clock
+	MaxEloGap       int
+	EloGapPerMinute float64
+	LeadTime        time.Duration
+}
+
+// Match returns the matches that can be made out of the candidates of one sport.
+// Candidates are served in queue order: the oldest one that can be matched picks the
+// closest players in Elo. A candidate is part of at most one proposal.
+func (m Matcher) Match(candidates []Candidate) []Proposal {
+	now := m.Clock.Now()
+	earliest := now.Add(m.LeadTime)
+
+	queue := append([]Candidate(nil), candidates...)
+	sort.SliceStable(queue, func(a, b int) bool {
+		if !queue[a].QueuedAt.Equal(queue[b].QueuedAt) {
+			return queue[a].QueuedAt.Before(queue[b].QueuedAt)
+		}
+		return queue[a].EntryID < queue[b].EntryID
+	})
+
+	used := make(map[string]bool, len(queue))
+	var proposals []Proposal
+	for i, anchor := range queue {
+		if used[anchor.EntryID] {
+			continue
+		}
+		gap := m.eloGap(anchor, now)
+
+		var others []Candidate
+		for _, c := range queue[i+1:] {
+			if used[c.EntryID] || c.TeamSize != anchor.TeamSize || abs(c.Elo-anchor.Elo) > gap {
+				continue
+			}
+			others = append(others, c)
+		}
+		sort.SliceStable(others, func(a, b int) bool {
+			return abs(others[a].Elo-anchor.Elo) < abs(others[b].Elo-anchor.Elo)
+		})
+
+		g := newGroup(anchor, earliest)
+		for _, c := range others {
+			if g.full() {
+				break
+			}
+			g.tryAdd(c, earliest)
+		}
+		if !g.full() || g.date(earliest).After(g.end) {
+			continue
+		}
+
+		for _, c := range g.players {
+			used[c.EntryID] = true
+		}
+		proposals = append(proposals, g.proposal(earliest))
+	}
+	return proposals
+}
+
+func (m Matcher) eloGap(c Candidate, now time.Time) int {
+	waited := now.Sub(c.QueuedAt).Minutes()
+	if waited < 0 {
+		waited = 0
+	}
+	return m.MaxEloGap + int(m.EloGapPerMinute*waited)
+}
+
+// group is a match being filled: the courts and the start times every player accepts.
+type group struct {
+	size    int
+	players []Candidate
+	courts  map[string]float64
+	start   time.Time
+	end     time.Time
+}
+
+func newGroup(anchor Candidate, earliest time.Time) *group {
+	courts := make(map[string]float64, len(anchor.Courts))
+	for id, d := range anchor.Courts {
+		courts[id] = d
+	}
+	return &group{
+		size:    2 * anchor.TeamSize,
+		players: []Candidate{anchor},
+		courts:  courts,
+		start:   anchor.WindowStart,
+		end:     anchor.WindowEnd,
+	}
+}
+
+func (g *group) full() bool {
+	return len(g.players) == g.size
+}
+
+func (g *group) date(earliest time.Time) time.Time {
+	if g.start.Before(earliest) {
+		return earliest
+	}
+	return g.start
+}
+
+// tryAdd adds c if the group still shares a court and a start time with them.
+func (g *group) tryAdd(c Candidate, earliest time.Time) {
+	courts := make(map[string]float64)
+	for id, d := range g.courts {
+		if cd, ok := c.Courts[id]; ok {
+			courts[id] = d + cd
+		}
+	}
+	if len(courts) == 0 {
+		return
+	}
+
+	start, end := g.start, g.end
+	if c.WindowStart.After(start) {
+		start = c.WindowStart
+	}
+	if c.WindowEnd.Before(end) {
+		end = c.WindowEnd
+	}
+	date := start
+	if date.Before(earliest) {
+		date = earliest
+	}
+	if date.After(end) {
+		return
+	}
+
+	g.players = append(g.players, c)
+	g.courts = courts
+	g.start, g.end = start, end
+}
+
+// proposal picks the court the players have the least distance to travel to, and
+// splits them into balanced teams.
+func (g *group) proposal(earliest time.Time) Proposal {
+	courtIDs := make([]string, 0, len(g.courts))
+	for id := range g.courts {
+		courtIDs = append(courtIDs, id)
+	}
+	sort.Slice(courtIDs, func(a, b int) bool {
+		if g.courts[courtIDs[a]] != g.courts[courtIDs[b]] {
+			return g.courts[courtIDs[a]] < g.courts[courtIDs[b]]
+		}
+		return courtIDs[a] < courtIDs[b]
+	})
+
+	ratings := make([]int, len(g.players))
+	for i, c := range g.players {
+		ratings[i] = c.Elo
+	}
+	team1, team2 := BalanceTeams(ratings)
+
+	p := Proposal{CourtID: courtIDs[0], Date: g.date(earliest)}
+	for _, i := range team1 {
+		p.Team1 = append(p.Team1, g.players[i])
+	}
+	for _, i := range team2 {
+		p.Team2 = append(p.Team2, g.players[i])
+	}
+	return p
+}
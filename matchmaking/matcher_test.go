@@ -0,0 +1,179 @@
+package matchmaking
+
+import (
+	"PLIC/clock"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalanceTeams(t *testing.T) {
+	type testCase struct {
+		name     string
+		ratings  []int
+		expected [2][]int
+	}
+
+	testCases := []testCase{
+		{
+			name:     "Two players",
+			ratings:  []int{1200, 900},
+			expected: [2][]int{{0}, {1}},
+		},
+		{
+			name:     "Strongest with weakest",
+			ratings:  []int{1400, 1000, 1300, 1100},
+			expected: [2][]int{{0, 1}, {2, 3}},
+		},
+		{
+			name:     "Three against three",
+			ratings:  []int{1500, 1000, 1000, 1000, 1250, 1250},
+			expected: [2][]int{{0, 1, 2}, {3, 4, 5}},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			team1, team2 := BalanceTeams(c.ratings)
+			require.Equal(t, c.expected[0], team1)
+			require.Equal(t, c.expected[1], team2)
+		})
+	}
+
+	t.Run("Large group -> greedy split of equal sizes", func(t *testing.T) {
+		ratings := make([]int, 24)
+		for i := range ratings {
+			ratings[i] = 1000 + 10*i
+		}
+		team1, team2 := BalanceTeams(ratings)
+		require.Len(t, team1, 12)
+		require.Len(t, team2, 12)
+
+		sum1, sum2 := 0, 0
+		for _, i := range team1 {
+			sum1 += ratings[i]
+		}
+		for _, i := range team2 {
+			sum2 += ratings[i]
+		}
+		require.LessOrEqual(t, abs(sum1-sum2), 120)
+	})
+}
+
+func TestMatcher_Match(t *testing.T) {
+	now := time.Date(2026, 5, 2, 10, 0, 0, 0, time.UTC)
+
+	candidate := func(id string, elo int, queuedAgo time.Duration, courts map[string]float64) Candidate {
+		return Candidate{
+			EntryID:     id,
+			UserID:      "user-" + id,
+			Elo:         elo,
+			TeamSize:    1,
+			WindowStart: now.Add(time.Hour),
+			WindowEnd:   now.Add(4 * time.Hour),
+			QueuedAt:    now.Add(-queuedAgo),
+			Courts:      courts,
+		}
+	}
+	userIDs := func(cs []Candidate) []string {
+		var ids []string
+		for _, c := range cs {
+			ids = append(ids, c.UserID)
+		}
+		return ids
+	}
+	matcher := Matcher{
+		Clock:           clock.NewMock(now),
+		MaxEloGap:       100,
+		EloGapPerMinute: 10,
+		LeadTime:        30 * time.Minute,
+	}
+
+	t.Run("Closest rating is picked, on the nearest shared court", func(t *testing.T) {
+		proposals := matcher.Match([]Candidate{
+			candidate("a", 1000, 0, map[string]float64{"c1": 0, "c2": 500}),
+			candidate("b", 1090, 0, map[string]float64{"c1": 2000, "c2": 0}),
+			candidate("c", 1030, 0, map[string]float64{"c1": 3000, "c2": 1000}),
+		})
+		require.Len(t, proposals, 1)
+		require.Equal(t, "c2", proposals[0].CourtID)
+		require.Equal(t, now.Add(time.Hour), proposals[0].Date)
+		require.ElementsMatch(t, []string{"user-a", "user-c"}, append(userIDs(proposals[0].Team1), userIDs(proposals[0].Team2)...))
+	})
+
+	t.Run("Rating gap too wide -> no match", func(t *testing.T) {
+		proposals := matcher.Match([]Candidate{
+			candidate("a", 1000, 0, map[string]float64{"c1": 0}),
+			candidate("b", 1200, 0, map[string]float64{"c1": 0}),
+		})
+		require.Empty(t, proposals)
+	})
+
+	t.Run("Gap widens with waiting time", func(t *testing.T) {
+		clk := clock.NewMock(now)
+		m := matcher
+		m.Clock = clk
+		cands := []Candidate{
+			candidate("a", 1000, 0, map[string]float64{"c1": 0}),
+			candidate("b", 1200, 0, map[string]float64{"c1": 0}),
+		}
+		require.Empty(t, m.Match(cands))
+
+		clk.Advance(10 * time.Minute)
+		require.Len(t, m.Match(cands), 1)
+	})
+
+	t.Run("No shared court -> no match", func(t *testing.T) {
+		proposals := matcher.Match([]Candidate{
+			candidate("a", 1000, 0, map[string]float64{"c1": 0}),
+			candidate("b", 1000, 0, map[string]float64{"c2": 0}),
+		})
+		require.Empty(t, proposals)
+	})
+
+	t.Run("Disjoint windows -> no match", func(t *testing.T) {
+		a := candidate("a", 1000, 0, map[string]float64{"c1": 0})
+		b := candidate("b", 1000, 0, map[string]float64{"c1": 0})
+		b.WindowStart, b.WindowEnd = now.Add(5*time.Hour), now.Add(6*time.Hour)
+		require.Empty(t, matcher.Match([]Candidate{a, b}))
+	})
+
+	t.Run("Window starting now -> date pushed by the lead time", func(t *testing.T) {
+		a := candidate("a", 1000, 0, map[string]float64{"c1": 0})
+		b := candidate("b", 1000, 0, map[string]float64{"c1": 0})
+		a.WindowStart, b.WindowStart = now, now
+		proposals := matcher.Match([]Candidate{a, b})
+		require.Len(t, proposals, 1)
+		require.Equal(t, now.Add(30*time.Minute), proposals[0].Date)
+	})
+
+	t.Run("Window ending within the lead time -> no match", func(t *testing.T) {
+		a := candidate("a", 1000, 0, map[string]float64{"c1": 0})
+		b := candidate("b", 1000, 0, map[string]float64{"c1": 0})
+		a.WindowStart, a.WindowEnd = now, now.Add(15*time.Minute)
+		b.WindowStart = now
+		require.Empty(t, matcher.Match([]Candidate{a, b}))
+	})
+
+	t.Run("Different team sizes are not mixed", func(t *testing.T) {
+		a := candidate("a", 1000, 0, map[string]float64{"c1": 0})
+		b := candidate("b", 1000, 0, map[string]float64{"c1": 0})
+		b.TeamSize = 2
+		require.Empty(t, matcher.Match([]Candidate{a, b}))
+	})
+
+	t.Run("Doubles -> oldest served first, balanced teams", func(t *testing.T) {
+		var cands []Candidate
+		for i, elo := range []int{1000, 1080, 1020, 1060, 1040} {
+			c := candidate(string(rune('a'+i)), elo, time.Duration(10-i)*time.Minute, map[string]float64{"c1": 0})
+			c.TeamSize = 2
+			cands = append(cands, c)
+		}
+		proposals := matcher.Match(cands)
+		require.Len(t, proposals, 1)
+		// a (1000, oldest) plays with the three closest ratings: c, e and d.
+		require.ElementsMatch(t, []string{"user-a", "user-d"}, userIDs(proposals[0].Team1))
+		require.ElementsMatch(t, []string{"user-c", "user-e"}, userIDs(proposals[0].Team2))
+	})
+}
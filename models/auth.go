@@ -3,4 +3,9 @@ package models
 type AuthInfo struct {
 	IsConnected bool
 	UserID      string
+	SessionID   string
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken"`
 }
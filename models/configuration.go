@@ -1,11 +1,16 @@
 package models
 
+import "time"
+
 type MailerConfig struct {
 	From     string `env:"SMTP_FROM"`
 	Host     string `env:"SMTP_HOST"`
 	Port     int    `env:"SMTP_PORT" envDefault:"587"`
 	Username string `env:"SMTP_USERNAME"`
 	Password string `env:"SMTP_PASSWORD"`
+	// Transport is smtp, file (one file per email in OutputDir) or stdout.
+	Transport string `env:"MAIL_TRANSPORT" envDefault:"smtp"`
+	OutputDir string `env:"MAIL_OUTPUT_DIR"`
 }
 
 type LambdaConfig struct {
@@ -20,9 +25,70 @@ type GoogleConfig struct {
 	ApiKey string `env:"GOOGLE_APIKEY"`
 }
 
+type AppConfig struct {
+	// BaseURL is the absolute public URL of the API, prefixed to the links sent by email.
+	BaseURL string `env:"APP_BASE_URL,required"`
+}
+
+// RatingEngineConfig selects the rating engine of one sport and its parameters.
+// Engine is "elo" or "glicko2"; K, ProvisionalK and ProvisionalGames only apply to
+// Elo, Tau only to Glicko-2.
+type RatingEngineConfig struct {
+	Engine           string  `env:"ENGINE" envDefault:"elo"`
+	K                int     `env:"K" envDefault:"32"`
+	ProvisionalK     int     `env:"PROVISIONAL_K" envDefault:"32"`
+	ProvisionalGames int     `env:"PROVISIONAL_GAMES" envDefault:"0"`
+	Tau              float64 `env:"TAU" envDefault:"0.5"`
+}
+
+type RatingConfig struct {
+	Basket   RatingEngineConfig `envPrefix:"RATING_BASKET_"`
+	Foot     RatingEngineConfig `envPrefix:"RATING_FOOT_"`
+	PingPong RatingEngineConfig `envPrefix:"RATING_PINGPONG_"`
+}
+
+// MatchmakingConfig tunes the matchmaker: players are paired when their ratings are
+// within MaxEloGap, widened by EloGapPerMinute for every minute waited, and matches
+// start at least LeadTime after they are found.
+type MatchmakingConfig struct {
+	MaxEloGap       int           `env:"MATCHMAKING_MAX_ELO_GAP" envDefault:"150"`
+	EloGapPerMinute float64       `env:"MATCHMAKING_ELO_GAP_PER_MINUTE" envDefault:"5"`
+	LeadTime        time.Duration `env:"MATCHMAKING_LEAD_TIME" envDefault:"30m"`
+}
+
+// OutboxConfig tunes the outbox dispatcher: a failed delivery is retried after
+// BaseBackoff, doubled on every failure up to MaxBackoff, and the event is dead after
+// MaxAttempts. A claimed event is retried after Lease if its dispatcher crashed.
+type OutboxConfig struct {
+	MaxAttempts int           `env:"OUTBOX_MAX_ATTEMPTS" envDefault:"8"`
+	BaseBackoff time.Duration `env:"OUTBOX_BASE_BACKOFF" envDefault:"30s"`
+	MaxBackoff  time.Duration `env:"OUTBOX_MAX_BACKOFF" envDefault:"1h"`
+	Lease       time.Duration `env:"OUTBOX_LEASE" envDefault:"5m"`
+	BatchSize   int           `env:"OUTBOX_BATCH_SIZE" envDefault:"50"`
+	// PollInterval is how often the local HTTP server delivers the pending events.
+	PollInterval time.Duration `env:"OUTBOX_POLL_INTERVAL" envDefault:"5s"`
+}
+
+// NotifierConfig configures the push providers; a platform without credentials gets
+// no push. APNsToken is the provider JWT, renewed by the deployment.
+type NotifierConfig struct {
+	APNsEndpoint string `env:"APNS_ENDPOINT" envDefault:"https://api.push.apple.com"`
+	APNsTopic    string `env:"APNS_TOPIC"`
+	APNsToken    string `env:"APNS_TOKEN"`
+	FCMEndpoint  string `env:"FCM_ENDPOINT"`
+	FCMToken     string `env:"FCM_TOKEN"`
+	// StartingSoon is how long before its date the players of a match are reminded.
+	StartingSoon time.Duration `env:"NOTIFY_STARTING_SOON" envDefault:"1h"`
+}
+
 type Configuration struct {
-	Mailer   MailerConfig
-	Lambda   LambdaConfig
-	Database DatabaseConfig
-	Google   GoogleConfig
+	App         AppConfig
+	Mailer      MailerConfig
+	Lambda      LambdaConfig
+	Database    DatabaseConfig
+	Google      GoogleConfig
+	Rating      RatingConfig
+	Matchmaking MatchmakingConfig
+	Outbox      OutboxConfig
+	Notifier    NotifierConfig
 }
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+type NearbyCourtsQuery struct {
+	Latitude     float64
+	Longitude    float64
+	RadiusMeters float64
+	Sport        *Sport
+	Limit        int
+}
+
+type DBCourtWithDistance struct {
+	DBCourt
+	DistanceMeters float64 `db:"distance_m"`
+}
+
+type NearbyCourtResponse struct {
+	DBCourt
+	DistanceMeters float64 `json:"distanceMeters"`
+}
+
+var postalCodeRegexp = regexp.MustCompile(`\b\d{5}\b`)
+
+// ParseCity extracts the city from a court address as returned by Google Places
+// ("12 Rue de Rivoli, 75004 Paris"): the last comma-separated part, postal code
+// removed. It returns nil when the address has no such part.
+func ParseCity(address string) *string {
+	i := strings.LastIndex(address, ",")
+	if i < 0 {
+		return nil
+	}
+	city := strings.Join(strings.Fields(postalCodeRegexp.ReplaceAllString(address[i+1:], "")), " ")
+	if city == "" {
+		return nil
+	}
+	return &city
+}
@@ -10,6 +10,7 @@ type DBCourt struct {
 	Id        string    `db:"id"`
 	Name      string    `db:"name"`
 	Address   string    `db:"address"`
+	City      *string   `db:"city"`
 	Longitude float64   `db:"longitude"`
 	Latitude  float64   `db:"latitude"`
 	CreatedAt time.Time `db:"created_at"`
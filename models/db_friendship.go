@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+type FriendshipStatus string
+
+const (
+	FriendshipPending  FriendshipStatus = "pending"
+	FriendshipAccepted FriendshipStatus = "accepted"
+	FriendshipDeclined FriendshipStatus = "declined"
+	FriendshipBlocked  FriendshipStatus = "blocked"
+)
+
+// DBFriendship is the relation between two users. RequesterID sent the request, or
+// blocked AddresseeID when Status is blocked. There is at most one row per pair.
+type DBFriendship struct {
+	RequesterID string           `db:"requester_id"`
+	AddresseeID string           `db:"addressee_id"`
+	Status      FriendshipStatus `db:"status"`
+	CreatedAt   time.Time        `db:"created_at"`
+	UpdatedAt   time.Time        `db:"updated_at"`
+}
+
+func NewDBFriendshipFixture() DBFriendship {
+	return DBFriendship{
+		Status:    FriendshipPending,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+func (f DBFriendship) WithRequesterId(requesterId string) DBFriendship {
+	f.RequesterID = requesterId
+	return f
+}
+
+func (f DBFriendship) WithAddresseeId(addresseeId string) DBFriendship {
+	f.AddresseeID = addresseeId
+	return f
+}
+
+func (f DBFriendship) WithStatus(status FriendshipStatus) DBFriendship {
+	f.Status = status
+	return f
+}
+
+// DBFriend is the other user of a friendship, seen from the user who lists it.
+type DBFriend struct {
+	UserID    string    `db:"user_id"`
+	Username  string    `db:"username"`
+	Email     string    `db:"email"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
@@ -8,5 +8,6 @@ type DBMatchScoreVote struct {
 	Team      int       `db:"team"`
 	Score1    int       `db:"score1"`
 	Score2    int       `db:"score2"`
+	Round     int       `db:"round"`
 	CreatedAt time.Time `db:"created_at"`
 }
@@ -14,28 +14,65 @@ const (
 	PingPong Sport = "ping-pong"
 )
 
+func (s Sport) IsValid() bool {
+	switch s {
+	case Basket, Foot, PingPong:
+		return true
+	}
+	return false
+}
+
 type MatchState string
 
+// ScoreVoteWindow is how long the teams have to vote the score once a match is over.
+const ScoreVoteWindow = 48 * time.Hour
+
+// DisputeVoteWindow is how long the re-vote round lasts once the teams disagree.
+const DisputeVoteWindow = 24 * time.Hour
+
 const (
 	Termine      MatchState = "Termine"
 	ManqueScore  MatchState = "Manque Score"
 	EnCours      MatchState = "En cours"
 	Valide       MatchState = "Valide"
 	ManqueJoueur MatchState = "Manque joueur"
+	Annule       MatchState = "Annule"
+)
+
+// MatchVisibility says who can see and join a match.
+type MatchVisibility string
+
+const (
+	VisibilityPublic     MatchVisibility = "public"
+	VisibilityFriends    MatchVisibility = "friends"
+	VisibilityInviteOnly MatchVisibility = "invite_only"
 )
 
+func (v MatchVisibility) IsValid() bool {
+	switch v {
+	case VisibilityPublic, VisibilityFriends, VisibilityInviteOnly:
+		return true
+	}
+	return false
+}
+
 type DBMatches struct {
-	Id              string     `db:"id"`
-	Sport           Sport      `db:"sport"`
-	Date            time.Time  `db:"date"`
-	ParticipantNber int        `db:"participant_nber"`
-	CurrentState    MatchState `db:"current_state"`
-	Score1          *int       `db:"score1"`
-	Score2          *int       `db:"score2"`
-	CourtID         string     `db:"court_id"`
-	CreatorID       string     `db:"creator_id"`
-	CreatedAt       time.Time  `db:"created_at"`
-	UpdatedAt       time.Time  `db:"updated_at"`
+	Id              string          `db:"id"`
+	Sport           Sport           `db:"sport"`
+	Date            time.Time       `db:"date"`
+	ParticipantNber int             `db:"participant_nber"`
+	CurrentState    MatchState      `db:"current_state"`
+	Score1          *int            `db:"score1"`
+	Score2          *int            `db:"score2"`
+	CourtID         string          `db:"court_id"`
+	CreatorID       string          `db:"creator_id"`
+	Disputed        bool            `db:"disputed"`
+	ScoreDeadline   *time.Time      `db:"score_deadline"`
+	EloAppliedAt    *time.Time      `db:"elo_applied_at"`
+	Visibility      MatchVisibility `db:"visibility"`
+	AutoBalance     bool            `db:"auto_balance"`
+	CreatedAt       time.Time       `db:"created_at"`
+	UpdatedAt       time.Time       `db:"updated_at"`
 }
 
 func NewDBMatchesFixture() DBMatches {
@@ -48,6 +85,7 @@ func NewDBMatchesFixture() DBMatches {
 		CreatorID:       uuid.NewString(),
 		Score1:          nil,
 		Score2:          nil,
+		Visibility:      VisibilityPublic,
 	}
 }
 
@@ -81,6 +119,46 @@ func (m DBMatches) WithSport(sport Sport) DBMatches {
 	return m
 }
 
+func (m DBMatches) WithDisputed(deadline time.Time) DBMatches {
+	m.Disputed = true
+	m.ScoreDeadline = &deadline
+	return m
+}
+
+func (m DBMatches) WithScoreDeadline(deadline time.Time) DBMatches {
+	m.ScoreDeadline = &deadline
+	return m
+}
+
+func (m DBMatches) WithVisibility(visibility MatchVisibility) DBMatches {
+	m.Visibility = visibility
+	return m
+}
+
+func (m DBMatches) WithAutoBalance(autoBalance bool) DBMatches {
+	m.AutoBalance = autoBalance
+	return m
+}
+
+func (m DBMatches) WithEloAppliedAt(appliedAt time.Time) DBMatches {
+	m.EloAppliedAt = &appliedAt
+	return m
+}
+
+// VoteRound is the score vote round currently open: 0 for the first vote, 1 for the
+// re-vote once the teams disagreed.
+func (m DBMatches) VoteRound() int {
+	if m.Disputed {
+		return 1
+	}
+	return 0
+}
+
+func (m DBMatches) WithDate(date time.Time) DBMatches {
+	m.Date = date
+	return m
+}
+
 func (m DBMatches) WithScore1(score1 int) DBMatches {
 	m.Score1 = &score1
 	return m
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DBPasswordResetToken struct {
+	Id        string     `db:"id"`
+	UserID    string     `db:"user_id"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+func NewDBPasswordResetTokenFixture() DBPasswordResetToken {
+	return DBPasswordResetToken{
+		Id:        uuid.NewString(),
+		UserID:    uuid.NewString(),
+		TokenHash: uuid.NewString(),
+		ExpiresAt: time.Now().Add(15 * time.Minute),
+		UsedAt:    nil,
+		CreatedAt: time.Now(),
+	}
+}
+
+func (t DBPasswordResetToken) WithUserId(userId string) DBPasswordResetToken {
+	t.UserID = userId
+	return t
+}
+
+func (t DBPasswordResetToken) WithTokenHash(hash string) DBPasswordResetToken {
+	t.TokenHash = hash
+	return t
+}
+
+func (t DBPasswordResetToken) WithExpiresAt(expiresAt time.Time) DBPasswordResetToken {
+	t.ExpiresAt = expiresAt
+	return t
+}
+
+func (t DBPasswordResetToken) WithUsedAt(usedAt time.Time) DBPasswordResetToken {
+	t.UsedAt = &usedAt
+	return t
+}
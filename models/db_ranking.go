@@ -7,22 +7,27 @@ import (
 )
 
 type DBRanking struct {
-	UserID    string    `db:"user_id"`
-	CourtID   string    `db:"court_id"`
-	Elo       int       `db:"elo"`
-	Sport     Sport     `db:"sport"`
-	CreatedAt time.Time `db:"created_at"`
-	UpdatedAt time.Time `db:"updated_at"`
+	UserID      string    `db:"user_id"`
+	CourtID     string    `db:"court_id"`
+	Elo         int       `db:"elo"`
+	Deviation   float64   `db:"rating_deviation"`
+	Volatility  float64   `db:"volatility"`
+	GamesPlayed int       `db:"games_played"`
+	Sport       Sport     `db:"sport"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
 }
 
 func NewDBRankingFixture() DBRanking {
 	return DBRanking{
-		UserID:    uuid.NewString(),
-		CourtID:   uuid.NewString(),
-		Elo:       1000,
-		Sport:     Basket,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		UserID:     uuid.NewString(),
+		CourtID:    uuid.NewString(),
+		Elo:        1000,
+		Deviation:  350,
+		Volatility: 0.06,
+		Sport:      Basket,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
 	}
 }
 
@@ -45,3 +50,8 @@ func (u DBRanking) WithSport(sport Sport) DBRanking {
 	u.Sport = sport
 	return u
 }
+
+func (u DBRanking) WithGamesPlayed(gamesPlayed int) DBRanking {
+	u.GamesPlayed = gamesPlayed
+	return u
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DBRankingHistory struct {
+	Id        string    `db:"id"`
+	UserID    string    `db:"user_id"`
+	CourtID   string    `db:"court_id"`
+	Sport     Sport     `db:"sport"`
+	MatchID   string    `db:"match_id"`
+	EloBefore int       `db:"elo_before"`
+	EloAfter  int       `db:"elo_after"`
+	Delta     int       `db:"delta"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func NewDBRankingHistoryFixture() DBRankingHistory {
+	return DBRankingHistory{
+		Id:        uuid.NewString(),
+		UserID:    uuid.NewString(),
+		CourtID:   uuid.NewString(),
+		Sport:     Basket,
+		MatchID:   uuid.NewString(),
+		EloBefore: 1000,
+		EloAfter:  1016,
+		Delta:     16,
+		CreatedAt: time.Now(),
+	}
+}
+
+func (h DBRankingHistory) WithUserId(userId string) DBRankingHistory {
+	h.UserID = userId
+	return h
+}
+
+func (h DBRankingHistory) WithCourtId(courtId string) DBRankingHistory {
+	h.CourtID = courtId
+	return h
+}
+
+func (h DBRankingHistory) WithSport(sport Sport) DBRankingHistory {
+	h.Sport = sport
+	return h
+}
+
+func (h DBRankingHistory) WithMatchId(matchId string) DBRankingHistory {
+	h.MatchID = matchId
+	return h
+}
+
+func (h DBRankingHistory) WithElo(before, after int) DBRankingHistory {
+	h.EloBefore = before
+	h.EloAfter = after
+	h.Delta = after - before
+	return h
+}
+
+func (h DBRankingHistory) WithCreatedAt(createdAt time.Time) DBRankingHistory {
+	h.CreatedAt = createdAt
+	return h
+}
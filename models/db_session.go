@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DBSession struct {
+	Id               string     `db:"id"`
+	UserID           string     `db:"user_id"`
+	RefreshTokenHash string     `db:"refresh_token_hash"`
+	ExpiresAt        time.Time  `db:"expires_at"`
+	RevokedAt        *time.Time `db:"revoked_at"`
+	CreatedAt        time.Time  `db:"created_at"`
+	UpdatedAt        time.Time  `db:"updated_at"`
+}
+
+func NewDBSessionFixture() DBSession {
+	return DBSession{
+		Id:               uuid.NewString(),
+		UserID:           uuid.NewString(),
+		RefreshTokenHash: uuid.NewString(),
+		ExpiresAt:        time.Now().Add(24 * time.Hour),
+		RevokedAt:        nil,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+	}
+}
+
+func (s DBSession) WithId(id string) DBSession {
+	s.Id = id
+	return s
+}
+
+func (s DBSession) WithUserId(userId string) DBSession {
+	s.UserID = userId
+	return s
+}
+
+func (s DBSession) WithRefreshTokenHash(hash string) DBSession {
+	s.RefreshTokenHash = hash
+	return s
+}
+
+func (s DBSession) WithExpiresAt(expiresAt time.Time) DBSession {
+	s.ExpiresAt = expiresAt
+	return s
+}
+
+func (s DBSession) WithRevokedAt(revokedAt time.Time) DBSession {
+	s.RevokedAt = &revokedAt
+	return s
+}
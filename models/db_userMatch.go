@@ -36,3 +36,8 @@ func (u DBUserMatch) WithTeam(team int) DBUserMatch {
 	u.Team = team
 	return u
 }
+
+func (u DBUserMatch) WithCreatedAt(createdAt time.Time) DBUserMatch {
+	u.CreatedAt = createdAt
+	return u
+}
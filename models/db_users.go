@@ -1,31 +1,37 @@
 package models
 
 import (
+	"PLIC/i18n"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type DBUsers struct {
-	Id             string    `db:"id"`
-	Username       string    `db:"username"`
-	Email          string    `db:"email"`
-	Bio            *string   `db:"bio"`
-	CurrentFieldId *string   `db:"current_field_id"`
-	Password       string    `db:"password"`
-	CreatedAt      time.Time `db:"created_at"`
-	UpdatedAt      time.Time `db:"updated_at"`
+	Id              string      `db:"id"`
+	Username        string      `db:"username"`
+	Email           string      `db:"email"`
+	Bio             *string     `db:"bio"`
+	CurrentFieldId  *string     `db:"current_field_id"`
+	Password        string      `db:"password"`
+	EmailVerifiedAt *time.Time  `db:"email_verified_at"`
+	PendingEmail    *string     `db:"pending_email"`
+	Locale          i18n.Locale `db:"locale"`
+	CreatedAt       time.Time   `db:"created_at"`
+	UpdatedAt       time.Time   `db:"updated_at"`
 }
 
 func NewDBUsersFixture() DBUsers {
 	return DBUsers{
-		Id:        uuid.NewString(),
-		Username:  "username",
-		Email:     "an email",
-		Bio:       ptr("a bio"),
-		Password:  "password",
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		Id:              uuid.NewString(),
+		Username:        "username",
+		Email:           "an email",
+		Bio:             ptr("a bio"),
+		Password:        "password",
+		EmailVerifiedAt: ptr(time.Now()),
+		Locale:          i18n.Default,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
 	}
 }
 
@@ -58,3 +64,18 @@ func (u DBUsers) WitUpdatedAt(updatedAt time.Time) DBUsers {
 	u.UpdatedAt = updatedAt
 	return u
 }
+
+func (u DBUsers) WithUnverifiedEmail() DBUsers {
+	u.EmailVerifiedAt = nil
+	return u
+}
+
+func (u DBUsers) WithPendingEmail(email string) DBUsers {
+	u.PendingEmail = ptr(email)
+	return u
+}
+
+func (u DBUsers) WithLocale(locale i18n.Locale) DBUsers {
+	u.Locale = locale
+	return u
+}
@@ -1,5 +1,23 @@
 package models
 
+// Error est la réponse d'erreur de l'API, un problème RFC 7807 (application/problem+json).
 type Error struct {
-	Message string `json:"message"`
+	Type   string `json:"type" example:"urn:playthestreet:error:match_not_found"`
+	Title  string `json:"title" example:"Not Found"`
+	Status int    `json:"status" example:"404"`
+	// Message dans la langue négociée par Accept-Language
+	Detail   string `json:"detail" example:"Match introuvable."`
+	Instance string `json:"instance" example:"/match/42"`
+	// Code stable, à utiliser par les clients
+	Code string `json:"code" enums:"bad_request,unauthorized,internal_error,not_found,conflict,too_many_requests,invalid_json,missing_parameter,invalid_parameter,validation_failed,body_too_large,match_not_found,match_wrong_state,match_not_visible,not_match_creator,not_in_match,player_not_found,already_in_match,invalid_sport,invalid_team,team_full,team_already_voted,invite_friends_only,invalid_city,court_not_found,invalid_date_range,distance_sort_needs_position,user_not_found,email_not_verified,email_already_verified,email_taken,username_taken,password_too_long,invalid_image,already_friends,friendship_blocked,friend_request_not_found,friend_request_already_sent,cannot_target_self,queue_entry_not_found,queue_entry_not_waiting,already_queued,queue_window_too_far,queue_area_incomplete,queue_area_required,device_not_found" example:"match_not_found"`
+	// Précisions sur l'erreur ; pour validation_failed, un FieldError par champ invalide,
+	// indexé par son nom JSON
+	// @nullable
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// FieldError dit pourquoi un champ de la requête est invalide.
+type FieldError struct {
+	Code    string `json:"code" example:"validation.future"`
+	Message string `json:"message" example:"Doit être dans le futur."`
 }
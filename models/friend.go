@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+type FriendResponse struct {
+	UserID   string `json:"userId"`
+	Username string `json:"username"`
+	// @nullable
+	ProfilePicture *string `json:"profilePicture"`
+	// Date de la dernière mise à jour de la relation (envoi ou acceptation)
+	Since time.Time `json:"since"`
+}
+
+type FriendRequestsResponse struct {
+	// Demandes reçues, en attente de réponse
+	Incoming []FriendResponse `json:"incoming"`
+	// Demandes envoyées, en attente de réponse
+	Outgoing []FriendResponse `json:"outgoing"`
+}
+
+type FriendshipResponse struct {
+	UserID string           `json:"userId"`
+	Status FriendshipStatus `json:"status"`
+}
@@ -1,20 +1,22 @@
 package models
 
 type LoginRequest struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
 }
 
 type RegisterRequest struct {
-	Email    string  `json:"email"`
-	Password string  `json:"password"`
-	Username string  `json:"username"`
+	Email    string  `json:"email" validate:"required,email"`
+	Password string  `json:"password" validate:"required"`
+	Username string  `json:"username" validate:"not_blank"`
 	Bio      *string `json:"bio"`
 }
 
 type LoginResponse struct {
-	Token  string `json:"token"`
-	UserId string `json:"userId"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int    `json:"expiresIn"`
+	UserId       string `json:"userId"`
 }
 
 type ChangePasswordRequest struct {
@@ -1,5 +1,5 @@
 package models
 
 type MailerRequest struct {
-	Email string `json:"email"`
+	Email string `json:"email" validate:"required,email"`
 }
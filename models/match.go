@@ -7,17 +7,24 @@ import (
 )
 
 type MatchRequest struct {
-	Sport           Sport     `json:"sport"`
-	CourtID         string    `json:"court_id"`
-	Date            time.Time `json:"date"`
-	NbreParticipant int       `json:"nbre_participant"`
+	Sport   Sport  `json:"sport" validate:"required,valid"`
+	CourtID string `json:"court_id" validate:"required"`
+	// Dans le futur
+	Date time.Time `json:"date" validate:"required,future"`
+	// Pair, au moins 2
+	NbreParticipant int `json:"nbre_participant" validate:"min=2,even" minimum:"2"`
+	// public (par défaut), friends ou invite_only
+	Visibility MatchVisibility `json:"visibility,omitempty" validate:"omitempty,valid"`
+	// Les joueurs rejoignent sans choisir d'équipe ; les équipes sont réparties selon
+	// l'ELO des joueurs sur le terrain quand le match est complet
+	AutoBalance bool `json:"auto_balance,omitempty"`
 }
 
 func NewMatchRequestFixture() MatchRequest {
 	return MatchRequest{
 		Sport:           Foot,
 		CourtID:         uuid.NewString(),
-		Date:            time.Now(),
+		Date:            time.Now().Add(24 * time.Hour),
 		NbreParticipant: 2,
 	}
 }
@@ -32,7 +39,31 @@ func (m MatchRequest) WithSport(sport Sport) MatchRequest {
 	return m
 }
 
+func (m MatchRequest) WithDate(date time.Time) MatchRequest {
+	m.Date = date
+	return m
+}
+
+func (m MatchRequest) WithNbreParticipant(nbreParticipant int) MatchRequest {
+	m.NbreParticipant = nbreParticipant
+	return m
+}
+
+func (m MatchRequest) WithVisibility(visibility MatchVisibility) MatchRequest {
+	m.Visibility = visibility
+	return m
+}
+
+func (m MatchRequest) WithAutoBalance(autoBalance bool) MatchRequest {
+	m.AutoBalance = autoBalance
+	return m
+}
+
 func (m MatchRequest) ToDBMatches(now time.Time, creatorId string) DBMatches {
+	visibility := m.Visibility
+	if visibility == "" {
+		visibility = VisibilityPublic
+	}
 	return DBMatches{
 		Id:              uuid.NewString(),
 		Sport:           m.Sport,
@@ -43,27 +74,50 @@ func (m MatchRequest) ToDBMatches(now time.Time, creatorId string) DBMatches {
 		Score2:          nil,
 		CourtID:         m.CourtID,
 		CreatorID:       creatorId,
+		Visibility:      visibility,
+		AutoBalance:     m.AutoBalance,
 		CreatedAt:       now,
 		UpdatedAt:       now,
 	}
 }
 
 type MatchResponse struct {
-	Id              string         `json:"id"`
-	CreatorId       string         `json:"creator_id"`
-	Sport           Sport          `json:"sport"`
-	Place           string         `json:"place"`
-	Date            time.Time      `json:"date"`
-	NbreParticipant int            `json:"nbre_participant"`
-	CurrentState    MatchState     `json:"current_state"`
-	Score1          *int           `json:"score1"`
-	Score2          *int           `json:"score2"`
-	Users           []UserResponse `json:"users"`
-	CreatedAt       time.Time      `json:"created_at"`
+	Id              string          `json:"id"`
+	CreatorId       string          `json:"creator_id"`
+	Sport           Sport           `json:"sport"`
+	Place           string          `json:"place"`
+	Date            time.Time       `json:"date"`
+	NbreParticipant int             `json:"nbre_participant"`
+	CurrentState    MatchState      `json:"current_state"`
+	Score1          *int            `json:"score1"`
+	Score2          *int            `json:"score2"`
+	Users           []UserResponse  `json:"users"`
+	Visibility      MatchVisibility `json:"visibility"`
+	AutoBalance     bool            `json:"auto_balance"`
+	// Variation d'ELO de chaque joueur, renseignée une fois le match terminé
+	EloChanges []MatchEloChange `json:"elo_changes,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+type MatchEloChange struct {
+	UserID    string `json:"user_id"`
+	EloBefore int    `json:"elo_before"`
+	EloAfter  int    `json:"elo_after"`
+	Delta     int    `json:"delta"`
 }
 
 type JoinMatchRequest struct {
-	Team int `json:"team"`
+	// 1 ou 2 ; ignoré pour un match auto_balance
+	Team int `json:"team,omitempty" validate:"min=0,max=2" minimum:"0" maximum:"2"`
+}
+
+type InviteToMatchRequest struct {
+	UserIDs []string `json:"user_ids" validate:"min=1" minItems:"1"`
+}
+
+type InviteToMatchResponse struct {
+	// Amis invités par cette requête, hors invitations déjà envoyées
+	Invited []string `json:"invited"`
 }
 
 type CreateMatchResponse struct {
@@ -80,14 +134,25 @@ type TeamVoteStatus struct {
 	Score    *ScorePair `json:"score,omitempty"`
 }
 
+type DisputedVotes struct {
+	MyTeam   TeamVoteStatus `json:"myTeam"`
+	Opponent TeamVoteStatus `json:"opponent"`
+}
+
 type MatchVoteStatusResponse struct {
-	MatchID    string         `json:"matchId"`
-	PlayerTeam int            `json:"playerTeam"`
-	MyTeam     TeamVoteStatus `json:"myTeam"`
-	Opponent   TeamVoteStatus `json:"opponent"`
+	MatchID       string         `json:"matchId"`
+	PlayerTeam    int            `json:"playerTeam"`
+	MyTeam        TeamVoteStatus `json:"myTeam"`
+	Opponent      TeamVoteStatus `json:"opponent"`
+	Disputed      bool           `json:"disputed"`
+	Round         int            `json:"round"`
+	Deadline      *time.Time     `json:"deadline,omitempty"`
+	DisputedVotes *DisputedVotes `json:"disputedVotes,omitempty"`
 }
 
 type TeamsByMatchIdResponse struct {
 	Team1 []UserResponse `json:"team1"`
 	Team2 []UserResponse `json:"team2"`
+	// Joueurs d'un match auto_balance pas encore répartis, le match n'étant pas complet
+	Unassigned []UserResponse `json:"unassigned"`
 }
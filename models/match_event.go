@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+type MatchEventType string
+
+const (
+	MatchEventPlayerJoined  MatchEventType = "player_joined"
+	MatchEventPlayerLeft    MatchEventType = "player_left"
+	MatchEventStateChanged  MatchEventType = "state_changed"
+	MatchEventVoteSubmitted MatchEventType = "vote_submitted"
+	MatchEventFinalized     MatchEventType = "finalized"
+)
+
+// MatchEvent is pushed to the clients following a match. Only the fields relevant to
+// its type are set.
+type MatchEvent struct {
+	Type    MatchEventType `json:"type"`
+	MatchID string         `json:"match_id"`
+	// Joueur qui a rejoint, quitté le match ou voté
+	UserID string `json:"user_id,omitempty"`
+	// Équipe du joueur qui a voté
+	Team int `json:"team,omitempty"`
+	// Nouvel état du match (state_changed, finalized)
+	State  MatchState `json:"state,omitempty"`
+	Score1 *int       `json:"score1,omitempty"`
+	Score2 *int       `json:"score2,omitempty"`
+	At     time.Time  `json:"at"`
+}
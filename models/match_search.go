@@ -0,0 +1,113 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+type MatchSort string
+
+const (
+	MatchSortDate     MatchSort = "date"
+	MatchSortDistance MatchSort = "distance"
+)
+
+// MatchSearchQuery holds the filters of the match discovery feed. Nil filters are
+// not applied.
+type MatchSearchQuery struct {
+	ViewerID string
+	Sport    *Sport
+	States   []MatchState
+	From     *time.Time
+	To       *time.Time
+	CourtID  *string
+	// Near keeps the matches whose court is within RadiusMeters of the point
+	Near *GeoFilter
+	// MinPlayersNeeded keeps the matches with at least that many free seats
+	MinPlayersNeeded *int
+	// EloRange keeps the matches whose players' average Elo on the court is within
+	// EloRange of the viewer's. Players not ranked yet count as DefaultElo.
+	EloRange   *int
+	DefaultElo int
+	Sort       MatchSort
+	Cursor     *MatchSearchCursor
+	Limit      int
+}
+
+type GeoFilter struct {
+	Latitude     float64
+	Longitude    float64
+	RadiusMeters float64
+}
+
+// MatchSearchCursor is the sort key of the last match of a page; the next page starts
+// right after it.
+type MatchSearchCursor struct {
+	Sort           MatchSort `json:"s"`
+	Date           time.Time `json:"d,omitempty"`
+	DistanceMeters float64   `json:"m,omitempty"`
+	Id             string    `json:"i"`
+}
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Encode returns the opaque form of the cursor handed to clients.
+func (c MatchSearchCursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeMatchSearchCursor(s string) (*MatchSearchCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c MatchSearchCursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.Id == "" {
+		return nil, ErrInvalidCursor
+	}
+	if c.Sort != MatchSortDate && c.Sort != MatchSortDistance {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+type DBMatchSearchResult struct {
+	DBMatches
+	CourtName      string   `db:"court_name"`
+	CourtCity      *string  `db:"court_city"`
+	PlayersCount   int      `db:"players_count"`
+	AverageElo     *int     `db:"average_elo"`
+	DistanceMeters *float64 `db:"distance_m"`
+}
+
+type MatchSummaryResponse struct {
+	Id        string `json:"id"`
+	CreatorId string `json:"creator_id"`
+	Sport     Sport  `json:"sport"`
+	CourtId   string `json:"court_id"`
+	Place     string `json:"place"`
+	// @nullable
+	City            *string         `json:"city"`
+	Date            time.Time       `json:"date"`
+	NbreParticipant int             `json:"nbre_participant"`
+	PlayersCount    int             `json:"players_count"`
+	PlayersNeeded   int             `json:"players_needed"`
+	CurrentState    MatchState      `json:"current_state"`
+	Visibility      MatchVisibility `json:"visibility"`
+	// Moyenne des ELO des joueurs inscrits sur ce terrain, null si personne n'est inscrit
+	// @nullable
+	AverageElo *int `json:"average_elo"`
+	// Renseignée quand la recherche porte sur une position
+	// @nullable
+	DistanceMeters *float64 `json:"distance_m"`
+}
+
+type MatchSearchResponse struct {
+	Matches []MatchSummaryResponse `json:"matches"`
+	// Curseur à passer pour obtenir la page suivante, null sur la dernière page
+	// @nullable
+	NextCursor *string `json:"next_cursor"`
+}
@@ -1,8 +1,8 @@
 package models
 
 type UpdateScoreRequest struct {
-	Score1 int `json:"score1"`
-	Score2 int `json:"score2"`
+	Score1 int `json:"score1" validate:"min=0" minimum:"0"`
+	Score2 int `json:"score2" validate:"min=0" minimum:"0"`
 }
 
 func NewUpdateScoreRequestFixture() UpdateScoreRequest {
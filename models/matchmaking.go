@@ -0,0 +1,139 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type QueueStatus string
+
+const (
+	QueueWaiting   QueueStatus = "waiting"
+	QueueMatched   QueueStatus = "matched"
+	QueueCancelled QueueStatus = "cancelled"
+	QueueExpired   QueueStatus = "expired"
+)
+
+// DBQueueEntry is a player waiting for the matchmaker to find them a match. The player
+// accepts their preferred courts (CourtIDs) and the courts within RadiusMeters of the
+// point, when one is set.
+type DBQueueEntry struct {
+	Id           string      `db:"id"`
+	UserID       string      `db:"user_id"`
+	Sport        Sport       `db:"sport"`
+	TeamSize     int         `db:"team_size"`
+	Latitude     *float64    `db:"latitude"`
+	Longitude    *float64    `db:"longitude"`
+	RadiusMeters *float64    `db:"radius_m"`
+	WindowStart  time.Time   `db:"window_start"`
+	WindowEnd    time.Time   `db:"window_end"`
+	Status       QueueStatus `db:"status"`
+	MatchID      *string     `db:"match_id"`
+	CreatedAt    time.Time   `db:"created_at"`
+	UpdatedAt    time.Time   `db:"updated_at"`
+	CourtIDs     []string    `db:"-"`
+}
+
+func NewDBQueueEntryFixture() DBQueueEntry {
+	return DBQueueEntry{
+		Id:          uuid.NewString(),
+		UserID:      uuid.NewString(),
+		Sport:       PingPong,
+		TeamSize:    1,
+		WindowStart: time.Now().Add(time.Hour),
+		WindowEnd:   time.Now().Add(4 * time.Hour),
+		Status:      QueueWaiting,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+}
+
+func (e DBQueueEntry) WithUserId(userId string) DBQueueEntry {
+	e.UserID = userId
+	return e
+}
+
+func (e DBQueueEntry) WithSport(sport Sport) DBQueueEntry {
+	e.Sport = sport
+	return e
+}
+
+func (e DBQueueEntry) WithTeamSize(teamSize int) DBQueueEntry {
+	e.TeamSize = teamSize
+	return e
+}
+
+func (e DBQueueEntry) WithCourtIds(courtIds ...string) DBQueueEntry {
+	e.CourtIDs = courtIds
+	return e
+}
+
+func (e DBQueueEntry) WithArea(latitude, longitude, radiusMeters float64) DBQueueEntry {
+	e.Latitude, e.Longitude, e.RadiusMeters = &latitude, &longitude, &radiusMeters
+	return e
+}
+
+func (e DBQueueEntry) WithWindow(start, end time.Time) DBQueueEntry {
+	e.WindowStart, e.WindowEnd = start, end
+	return e
+}
+
+func (e DBQueueEntry) WithStatus(status QueueStatus) DBQueueEntry {
+	e.Status = status
+	return e
+}
+
+func (e DBQueueEntry) WithCreatedAt(createdAt time.Time) DBQueueEntry {
+	e.CreatedAt = createdAt
+	return e
+}
+
+// DBMatchmakingCandidate is a waiting entry with the player's rating in the sport and
+// one court they accept; an entry comes once per accepted court.
+type DBMatchmakingCandidate struct {
+	EntryID        string    `db:"entry_id"`
+	UserID         string    `db:"user_id"`
+	TeamSize       int       `db:"team_size"`
+	WindowStart    time.Time `db:"window_start"`
+	WindowEnd      time.Time `db:"window_end"`
+	CreatedAt      time.Time `db:"created_at"`
+	Elo            int       `db:"elo"`
+	CourtID        string    `db:"court_id"`
+	DistanceMeters float64   `db:"distance_m"`
+}
+
+type QueueRequest struct {
+	Sport Sport `json:"sport" validate:"valid"`
+	// Terrains acceptés en plus de la zone de recherche
+	CourtIDs []string `json:"court_ids"`
+	// Centre de la zone de recherche, à renseigner avec radius_m
+	Latitude     *float64 `json:"latitude,omitempty" validate:"min=-90,max=90" minimum:"-90" maximum:"90"`
+	Longitude    *float64 `json:"longitude,omitempty" validate:"min=-180,max=180" minimum:"-180" maximum:"180"`
+	RadiusMeters *float64 `json:"radius_m,omitempty" validate:"min=1,max=50000" minimum:"1" maximum:"50000"`
+	// Heures de début de match acceptées
+	WindowStart time.Time `json:"window_start" validate:"required"`
+	WindowEnd   time.Time `json:"window_end" validate:"required,future"`
+	// Nombre de joueurs par équipe
+	TeamSize int `json:"team_size" validate:"min=1,max=11" minimum:"1" maximum:"11"`
+}
+
+type QueueEntryResponse struct {
+	Id       string   `json:"id"`
+	Sport    Sport    `json:"sport"`
+	CourtIDs []string `json:"court_ids"`
+	// @nullable
+	Latitude *float64 `json:"latitude"`
+	// @nullable
+	Longitude *float64 `json:"longitude"`
+	// @nullable
+	RadiusMeters *float64    `json:"radius_m"`
+	WindowStart  time.Time   `json:"window_start"`
+	WindowEnd    time.Time   `json:"window_end"`
+	TeamSize     int         `json:"team_size"`
+	Status       QueueStatus `json:"status"`
+	// Match créé par le matchmaking, renseigné quand status vaut matched
+	// @nullable
+	MatchID   *string   `json:"match_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
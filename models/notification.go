@@ -0,0 +1,148 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DevicePlatform string
+
+const (
+	PlatformIOS     DevicePlatform = "ios"
+	PlatformAndroid DevicePlatform = "android"
+)
+
+func (p DevicePlatform) IsValid() bool {
+	switch p {
+	case PlatformIOS, PlatformAndroid:
+		return true
+	}
+	return false
+}
+
+// NotificationKind is a kind of push notification the user can turn off.
+type NotificationKind string
+
+const (
+	NotifyMatchFilled   NotificationKind = "match_filled"
+	NotifyMatchStarting NotificationKind = "match_starting"
+	NotifyScoreVote     NotificationKind = "score_vote"
+	NotifyMatchResult   NotificationKind = "match_result"
+)
+
+// DBDevice is a device registered by a user to receive push notifications.
+type DBDevice struct {
+	Token     string         `db:"token"`
+	UserID    string         `db:"user_id"`
+	Platform  DevicePlatform `db:"platform"`
+	CreatedAt time.Time      `db:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at"`
+}
+
+func NewDBDeviceFixture() DBDevice {
+	return DBDevice{
+		Token:     uuid.NewString(),
+		UserID:    uuid.NewString(),
+		Platform:  PlatformAndroid,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+func (d DBDevice) WithToken(token string) DBDevice {
+	d.Token = token
+	return d
+}
+
+func (d DBDevice) WithUserId(userId string) DBDevice {
+	d.UserID = userId
+	return d
+}
+
+func (d DBDevice) WithPlatform(platform DevicePlatform) DBDevice {
+	d.Platform = platform
+	return d
+}
+
+// DBNotificationPreferences tells which notifications a user receives. A user without
+// a row receives them all.
+type DBNotificationPreferences struct {
+	UserID        string    `db:"user_id"`
+	MatchFilled   bool      `db:"match_filled"`
+	MatchStarting bool      `db:"match_starting"`
+	ScoreVote     bool      `db:"score_vote"`
+	MatchResult   bool      `db:"match_result"`
+	UpdatedAt     time.Time `db:"updated_at"`
+}
+
+// DefaultNotificationPreferences are the preferences of a user who never changed them.
+func DefaultNotificationPreferences(userID string) DBNotificationPreferences {
+	return DBNotificationPreferences{
+		UserID:        userID,
+		MatchFilled:   true,
+		MatchStarting: true,
+		ScoreVote:     true,
+		MatchResult:   true,
+	}
+}
+
+func (p DBNotificationPreferences) Allows(kind NotificationKind) bool {
+	switch kind {
+	case NotifyMatchFilled:
+		return p.MatchFilled
+	case NotifyMatchStarting:
+		return p.MatchStarting
+	case NotifyScoreVote:
+		return p.ScoreVote
+	case NotifyMatchResult:
+		return p.MatchResult
+	}
+	return false
+}
+
+func (p DBNotificationPreferences) WithMatchFilled(enabled bool) DBNotificationPreferences {
+	p.MatchFilled = enabled
+	return p
+}
+
+func (p DBNotificationPreferences) WithMatchResult(enabled bool) DBNotificationPreferences {
+	p.MatchResult = enabled
+	return p
+}
+
+type RegisterDeviceRequest struct {
+	// Jeton push de l'appareil
+	Token string `json:"token" validate:"not_blank,max=4096" maxLength:"4096"`
+	// ios ou android
+	Platform DevicePlatform `json:"platform" validate:"valid"`
+}
+
+type DeviceResponse struct {
+	Token     string         `json:"token"`
+	Platform  DevicePlatform `json:"platform"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+type NotificationPreferencesResponse struct {
+	// Un match auquel le joueur est inscrit est complet
+	MatchFilled bool `json:"match_filled"`
+	// Un match du joueur commence bientôt
+	MatchStarting bool `json:"match_starting"`
+	// Le score d'un match du joueur doit être voté
+	ScoreVote bool `json:"score_vote"`
+	// Le résultat d'un match du joueur est validé
+	MatchResult bool `json:"match_result"`
+}
+
+// NotificationPreferencesPatchRequest only changes the fields it sets.
+type NotificationPreferencesPatchRequest struct {
+	// @nullable
+	MatchFilled *bool `json:"match_filled"`
+	// @nullable
+	MatchStarting *bool `json:"match_starting"`
+	// @nullable
+	ScoreVote *bool `json:"score_vote"`
+	// @nullable
+	MatchResult *bool `json:"match_result"`
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxEventType names a side effect and selects the handler delivering it.
+type OutboxEventType string
+
+const (
+	OutboxMatchResultEmail     OutboxEventType = "match_result_email"
+	OutboxMatchCancelledEmail  OutboxEventType = "match_cancelled_email"
+	OutboxMatchInvitationEmail OutboxEventType = "match_invitation_email"
+	OutboxMatchFoundEmail      OutboxEventType = "match_found_email"
+
+	OutboxMatchFilledPush   OutboxEventType = "match_filled_push"
+	OutboxMatchStartingPush OutboxEventType = "match_starting_push"
+	OutboxScoreVotePush     OutboxEventType = "score_vote_push"
+	OutboxMatchResultPush   OutboxEventType = "match_result_push"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxPending OutboxStatus = "pending"
+	OutboxDone    OutboxStatus = "done"
+	OutboxDead    OutboxStatus = "dead"
+)
+
+// DBOutboxEvent is a side effect recorded with the change that causes it, delivered
+// later by the outbox dispatcher. Attempts counts the deliveries started so far.
+type DBOutboxEvent struct {
+	Id            string          `db:"id"`
+	Type          OutboxEventType `db:"type"`
+	Payload       []byte          `db:"payload"`
+	Status        OutboxStatus    `db:"status"`
+	Attempts      int             `db:"attempts"`
+	NextAttemptAt time.Time       `db:"next_attempt_at"`
+	LastError     *string         `db:"last_error"`
+	CreatedAt     time.Time       `db:"created_at"`
+	ProcessedAt   *time.Time      `db:"processed_at"`
+}
+
+// NewOutboxEvent builds a pending event of the given type, due right away.
+func NewOutboxEvent(eventType OutboxEventType, payload any, now time.Time) (DBOutboxEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return DBOutboxEvent{}, fmt.Errorf("failed to marshal %s payload: %w", eventType, err)
+	}
+	return DBOutboxEvent{
+		Id:            uuid.NewString(),
+		Type:          eventType,
+		Payload:       data,
+		Status:        OutboxPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}, nil
+}
+
+// MatchRecipientPayload is the payload of the match emails and notifications: one
+// event per recipient, so that a failed delivery is retried for that recipient only.
+type MatchRecipientPayload struct {
+	MatchID string `json:"match_id"`
+	UserID  string `json:"user_id"`
+	// Créateur du match qui invite, pour match_invitation_email
+	InviterID string `json:"inviter_id,omitempty"`
+}
+
+func NewDBOutboxEventFixture() DBOutboxEvent {
+	return DBOutboxEvent{
+		Id:            uuid.NewString(),
+		Type:          OutboxMatchResultEmail,
+		Payload:       []byte(`{}`),
+		Status:        OutboxPending,
+		NextAttemptAt: time.Now(),
+		CreatedAt:     time.Now(),
+	}
+}
+
+func (e DBOutboxEvent) WithType(eventType OutboxEventType) DBOutboxEvent {
+	e.Type = eventType
+	return e
+}
+
+func (e DBOutboxEvent) WithPayload(payload any) DBOutboxEvent {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		panic(err)
+	}
+	e.Payload = data
+	return e
+}
+
+func (e DBOutboxEvent) WithStatus(status OutboxStatus) DBOutboxEvent {
+	e.Status = status
+	return e
+}
+
+func (e DBOutboxEvent) WithAttempts(attempts int) DBOutboxEvent {
+	e.Attempts = attempts
+	return e
+}
+
+func (e DBOutboxEvent) WithNextAttemptAt(next time.Time) DBOutboxEvent {
+	e.NextAttemptAt = next
+	return e
+}
@@ -1,10 +1,85 @@
 package models
 
+import "time"
+
 type CourtRankingResponse struct {
-	UserID string `json:"userId" db:"user_id"`
-	Elo    int    `json:"elo"    db:"elo"`
+	Rank     int    `json:"rank"`
+	UserID   string `json:"userId" db:"user_id"`
+	Username string `json:"username"`
+	// @nullable
+	ProfilePicture *string `json:"profilePicture"`
+	Elo            int     `json:"elo"    db:"elo"`
+	// Matchs terminés sur ce terrain dans ce sport
+	MatchesPlayed int `json:"matchesPlayed"`
+	// @nullable
+	Winrate *int `json:"winrate"`
+	// @nullable
+	LastPlayedAt *time.Time `json:"lastPlayedAt"`
+}
+
+type CourtRankingQuery struct {
+	CourtID string
+	Sport   Sport
+	Limit   int
+	Offset  int
+}
+
+type DBCourtRankingEntry struct {
+	Rank     int    `db:"rank"`
+	UserID   string `db:"user_id"`
+	Username string `db:"username"`
+	Elo      int    `db:"elo"`
+}
+
+type CourtPlayerStats struct {
+	MatchesPlayed int
+	Winrate       *int
+	LastPlayedAt  *time.Time
 }
 
 type CourtRankingRequest struct {
 	Sport Sport `json:"sport"`
 }
+
+type RankingHistoryResponse struct {
+	MatchID   string    `json:"matchId"`
+	CourtID   string    `json:"courtId"`
+	Sport     Sport     `json:"sport"`
+	EloBefore int       `json:"eloBefore"`
+	EloAfter  int       `json:"eloAfter"`
+	Delta     int       `json:"delta"`
+	Date      time.Time `json:"date"`
+}
+
+type LeaderboardQuery struct {
+	Sport Sport
+	// City restreint le classement aux terrains de cette ville, nil pour le classement national
+	City   *string
+	Limit  int
+	Offset int
+}
+
+type DBLeaderboardEntry struct {
+	Rank        int    `db:"rank"`
+	UserID      string `db:"user_id"`
+	Username    string `db:"username"`
+	Rating      int    `db:"rating"`
+	GamesPlayed int    `db:"games_played"`
+}
+
+type LeaderboardEntryResponse struct {
+	Rank        int    `json:"rank"`
+	UserID      string `json:"userId"`
+	Username    string `json:"username"`
+	Rating      int    `json:"rating"`
+	GamesPlayed int    `json:"gamesPlayed"`
+}
+
+type LeaderboardResponse struct {
+	Entries []LeaderboardEntryResponse `json:"entries"`
+	Total   int                        `json:"total"`
+	Limit   int                        `json:"limit"`
+	Offset  int                        `json:"offset"`
+	// @nullable
+	Me *LeaderboardEntryResponse `json:"me"`
+}
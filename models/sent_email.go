@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+type SentEmailStatus string
+
+const (
+	SentEmailSending SentEmailStatus = "sending"
+	SentEmailSent    SentEmailStatus = "sent"
+	SentEmailFailed  SentEmailStatus = "failed"
+)
+
+// DBSentEmail is an email in the send log. ThrottleKey groups the emails that must not
+// be sent twice in a short time, e.g. the verification emails of one user.
+type DBSentEmail struct {
+	Id          string          `db:"id"`
+	Kind        string          `db:"kind"`
+	ThrottleKey string          `db:"throttle_key"`
+	Recipient   string          `db:"recipient"`
+	Subject     string          `db:"subject"`
+	Status      SentEmailStatus `db:"status"`
+	Error       *string         `db:"error"`
+	CreatedAt   time.Time       `db:"created_at"`
+	SentAt      *time.Time      `db:"sent_at"`
+}
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"PLIC/i18n"
+	"time"
+)
 
 type UserResponse struct {
 	Username string `json:"username"`
@@ -26,13 +29,15 @@ type UserResponse struct {
 
 type UserPatchRequest struct {
 	// @nullable
-	Username *string `json:"username"`
+	Username *string `json:"username" validate:"not_blank"`
 	// @nullable
-	Email *string `json:"email"`
+	Email *string `json:"email" validate:"email"`
 	// @nullable
 	Bio *string `json:"bio"`
 	// @nullable
 	CurrentFieldId *string `json:"currentFieldId"`
+	// @nullable
+	Locale *i18n.Locale `json:"locale" swaggertype:"string" enums:"fr,en" validate:"valid"`
 }
 
 type UserStats struct {
@@ -0,0 +1,166 @@
+package models
+
+import (
+	"PLIC/i18n"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// The fields of the request models are validated from their `validate` tag, a comma
+// separated list of rules checked in order, the first failing one being reported:
+//
+//	required   the field is set: not nil, not the zero value
+//	omitempty  the other rules are skipped when the field is the zero value
+//	not_blank  a string with something else than spaces
+//	min=N      a number greater than or equal to N, or a list of at least N items
+//	max=N      a number less than or equal to N, or a string of at most N characters
+//	even       an even number
+//	future     a date after now
+//	email      a bare email address, without display name nor angle brackets
+//	valid      a value whose IsValid method returns true, e.g. a Sport
+//
+// The rules of a pointer field apply to the value it points to, and only when it is
+// set: a nil pointer is a field the client left out.
+
+// FieldErrors maps the JSON name of each invalid field to why it is invalid.
+type FieldErrors map[string]i18n.Message
+
+func (f FieldErrors) Error() string {
+	fields := make([]string, 0, len(f))
+	for name := range f {
+		fields = append(fields, name)
+	}
+	slices.Sort(fields)
+	return "invalid fields: " + strings.Join(fields, ", ")
+}
+
+type validatable interface {
+	IsValid() bool
+}
+
+// Validate checks the fields of the struct v points to against their `validate` tag,
+// now being the reference of the future rule. It returns a FieldErrors, or nil if
+// every field is valid.
+func Validate(v any, now time.Time) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	errs := FieldErrors{}
+	rt := rv.Type()
+	for i := range rt.NumField() {
+		field := rt.Field(i)
+		tag, ok := field.Tag.Lookup("validate")
+		if !ok || !field.IsExported() {
+			continue
+		}
+		if msg, ok := checkField(rv.Field(i), strings.Split(tag, ","), now); !ok {
+			errs[jsonName(field)] = msg
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func checkField(value reflect.Value, rules []string, now time.Time) (i18n.Message, bool) {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			if slices.Contains(rules, "required") {
+				return i18n.M(i18n.FieldRequired), false
+			}
+			return i18n.Message{}, true
+		}
+		value = value.Elem()
+	}
+
+	for _, rule := range rules {
+		name, arg, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			if value.IsZero() {
+				return i18n.M(i18n.FieldRequired), false
+			}
+		case "omitempty":
+			if value.IsZero() {
+				return i18n.Message{}, true
+			}
+		case "not_blank":
+			if strings.TrimSpace(value.String()) == "" {
+				return i18n.M(i18n.FieldNotBlank), false
+			}
+		case "min":
+			n := mustParseInt(arg)
+			if value.Kind() == reflect.Slice {
+				if int64(value.Len()) < n {
+					return i18n.M(i18n.FieldMinItems, n), false
+				}
+			} else if number(value) < float64(n) {
+				return i18n.M(i18n.FieldMin, n), false
+			}
+		case "max":
+			n := mustParseInt(arg)
+			if value.Kind() == reflect.String {
+				if int64(utf8.RuneCountInString(value.String())) > n {
+					return i18n.M(i18n.FieldMaxLength, n), false
+				}
+			} else if number(value) > float64(n) {
+				return i18n.M(i18n.FieldMax, n), false
+			}
+		case "even":
+			if value.Int()%2 != 0 {
+				return i18n.M(i18n.FieldEven), false
+			}
+		case "future":
+			if t, ok := value.Interface().(time.Time); !ok || !t.After(now) {
+				return i18n.M(i18n.FieldFuture), false
+			}
+		case "email":
+			// ParseAddress also accepts "Bob <bob@example.com>": only a bare address is valid.
+			if addr, err := mail.ParseAddress(value.String()); err != nil || addr.Address != value.String() {
+				return i18n.M(i18n.FieldEmail), false
+			}
+		case "valid":
+			if v, ok := value.Interface().(validatable); !ok || !v.IsValid() {
+				return i18n.M(i18n.FieldInvalid), false
+			}
+		default:
+			panic(fmt.Sprintf("unknown validation rule %q", rule))
+		}
+	}
+	return i18n.Message{}, true
+}
+
+// jsonName is the name of the field in the JSON body.
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// number is the value of a numeric field, as a float so that integers and floats
+// compare alike.
+func number(value reflect.Value) float64 {
+	if value.CanFloat() {
+		return value.Float()
+	}
+	return float64(value.Int())
+}
+
+func mustParseInt(s string) int64 {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		panic(fmt.Sprintf("invalid validation bound %q", s))
+	}
+	return n
+}
@@ -0,0 +1,75 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// APNs sends notifications through the Apple Push Notification service HTTP/2 API,
+// authenticated with a provider token.
+type APNs struct {
+	Endpoint string
+	Topic    string
+	Token    string
+	Client   *http.Client
+}
+
+type apnsAlert struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+type apnsAps struct {
+	Alert apnsAlert `json:"alert"`
+	Sound string    `json:"sound"`
+}
+
+func (a *APNs) Send(ctx context.Context, token string, n Notification) error {
+	payload := map[string]any{"aps": apnsAps{Alert: apnsAlert{Title: n.Title, Body: n.Body}, Sound: "default"}}
+	for k, v := range n.Data {
+		payload[k] = v
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("apns: encode payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(a.Endpoint, "/")+"/3/device/"+url.PathEscape(token), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("apns: build request: %w", err)
+	}
+	req.Header.Set("Authorization", "bearer "+a.Token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("apns-topic", a.Topic)
+	req.Header.Set("apns-push-type", "alert")
+
+	resp, err := client(a.Client).Do(req)
+	if err != nil {
+		return fmt.Errorf("apns: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var reply struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&reply)
+	if resp.StatusCode == http.StatusGone || reply.Reason == "BadDeviceToken" || reply.Reason == "Unregistered" {
+		return ErrInvalidToken
+	}
+	return fmt.Errorf("apns: status %d: %s", resp.StatusCode, reply.Reason)
+}
+
+func client(c *http.Client) *http.Client {
+	if c == nil {
+		return http.DefaultClient
+	}
+	return c
+}
@@ -0,0 +1,41 @@
+package notifier
+
+import (
+	"context"
+	"sync"
+)
+
+// Sent is a notification recorded by Fake.
+type Sent struct {
+	Token        string
+	Notification Notification
+}
+
+// Fake is a Provider for tests and local runs: it records what it sends instead of
+// sending it. Tokens in Invalid are rejected with ErrInvalidToken.
+type Fake struct {
+	mu      sync.Mutex
+	sent    []Sent
+	Invalid map[string]bool
+}
+
+func NewFake() *Fake {
+	return &Fake{Invalid: make(map[string]bool)}
+}
+
+func (f *Fake) Send(_ context.Context, token string, n Notification) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Invalid[token] {
+		return ErrInvalidToken
+	}
+	f.sent = append(f.sent, Sent{Token: token, Notification: n})
+	return nil
+}
+
+// Sent returns the notifications sent so far.
+func (f *Fake) Sent() []Sent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Sent(nil), f.sent...)
+}
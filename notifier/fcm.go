@@ -0,0 +1,74 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// FCM sends notifications through the Firebase Cloud Messaging HTTP v1 API. Endpoint
+// is the messages:send URL of the project, Token an OAuth2 access token.
+type FCM struct {
+	Endpoint string
+	Token    string
+	Client   *http.Client
+}
+
+type fcmNotification struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+type fcmMessage struct {
+	Token        string            `json:"token"`
+	Notification fcmNotification   `json:"notification"`
+	Data         map[string]string `json:"data,omitempty"`
+}
+
+func (f *FCM) Send(ctx context.Context, token string, n Notification) error {
+	body, err := json.Marshal(map[string]fcmMessage{"message": {
+		Token:        token,
+		Notification: fcmNotification{Title: n.Title, Body: n.Body},
+		Data:         n.Data,
+	}})
+	if err != nil {
+		return fmt.Errorf("fcm: encode payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("fcm: build request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+f.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client(f.Client).Do(req)
+	if err != nil {
+		return fmt.Errorf("fcm: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var reply struct {
+		Error struct {
+			Status  string `json:"status"`
+			Details []struct {
+				ErrorCode string `json:"errorCode"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&reply)
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrInvalidToken
+	}
+	for _, d := range reply.Error.Details {
+		if d.ErrorCode == "UNREGISTERED" {
+			return ErrInvalidToken
+		}
+	}
+	return fmt.Errorf("fcm: status %d: %s", resp.StatusCode, reply.Error.Status)
+}
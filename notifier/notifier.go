@@ -0,0 +1,104 @@
+package notifier
+
+import (
+	"PLIC/models"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrInvalidToken is returned by a Provider when the device token is no longer valid,
+// e.g. because the app was uninstalled. The token should be forgotten.
+var ErrInvalidToken = errors.New("invalid device token")
+
+// Notification is a push notification, Data is handed to the app as is.
+type Notification struct {
+	Title string
+	Body  string
+	Data  map[string]string
+}
+
+// Provider sends a notification to one device of its platform.
+type Provider interface {
+	Send(ctx context.Context, token string, n Notification) error
+}
+
+// Store is what the Notifier reads and cleans up, implemented by database.Database.
+type Store interface {
+	GetNotificationPreferences(ctx context.Context, userID string) (models.DBNotificationPreferences, error)
+	GetUserDevices(ctx context.Context, userID string) ([]models.DBDevice, error)
+	DeleteDeviceToken(ctx context.Context, token string) error
+}
+
+// Notifier sends the notifications of a user to all their devices, through the
+// provider of each device's platform.
+type Notifier struct {
+	Store     Store
+	Providers map[models.DevicePlatform]Provider
+}
+
+// New builds a Notifier with a provider for each platform whose credentials are set.
+func New(cfg models.NotifierConfig, store Store) *Notifier {
+	providers := make(map[models.DevicePlatform]Provider)
+	if cfg.APNsToken != "" && cfg.APNsTopic != "" {
+		providers[models.PlatformIOS] = &APNs{Endpoint: cfg.APNsEndpoint, Topic: cfg.APNsTopic, Token: cfg.APNsToken}
+	}
+	if cfg.FCMEndpoint != "" && cfg.FCMToken != "" {
+		providers[models.PlatformAndroid] = &FCM{Endpoint: cfg.FCMEndpoint, Token: cfg.FCMToken}
+	}
+	return &Notifier{Store: store, Providers: providers}
+}
+
+// Notify sends n to every device of the user, unless they turned off kind, and
+// returns how many devices received it. Tokens rejected as invalid are deleted. An
+// error is only returned when no device could be reached, so that retrying does not
+// notify twice the devices that already received it.
+func (nt *Notifier) Notify(ctx context.Context, userID string, kind models.NotificationKind, n Notification) (int, error) {
+	logger := log.With().
+		Str("method", "Notify").
+		Str("user_id", userID).
+		Str("kind", string(kind)).
+		Logger()
+
+	prefs, err := nt.Store.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if !prefs.Allows(kind) {
+		return 0, nil
+	}
+
+	devices, err := nt.Store.GetUserDevices(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var errs []error
+	for _, d := range devices {
+		provider, ok := nt.Providers[d.Platform]
+		if !ok {
+			continue
+		}
+		err := provider.Send(ctx, d.Token, n)
+		if errors.Is(err, ErrInvalidToken) {
+			logger.Info().Str("platform", string(d.Platform)).Msg("invalid device token, deleted")
+			if err := nt.Store.DeleteDeviceToken(ctx, d.Token); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if err != nil {
+			logger.Warn().Err(err).Str("platform", string(d.Platform)).Msg("push failed")
+			errs = append(errs, fmt.Errorf("%s: %w", d.Platform, err))
+			continue
+		}
+		sent++
+	}
+	if sent == 0 && len(errs) > 0 {
+		return 0, errors.Join(errs...)
+	}
+	return sent, nil
+}
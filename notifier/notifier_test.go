@@ -0,0 +1,244 @@
+package notifier
+
+import (
+	"PLIC/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore serves the devices and preferences of the Notifier from memory.
+type memoryStore struct {
+	prefs   map[string]models.DBNotificationPreferences
+	devices []models.DBDevice
+}
+
+func (m *memoryStore) GetNotificationPreferences(_ context.Context, userID string) (models.DBNotificationPreferences, error) {
+	if p, ok := m.prefs[userID]; ok {
+		return p, nil
+	}
+	return models.DefaultNotificationPreferences(userID), nil
+}
+
+func (m *memoryStore) GetUserDevices(_ context.Context, userID string) ([]models.DBDevice, error) {
+	var devices []models.DBDevice
+	for _, d := range m.devices {
+		if d.UserID == userID {
+			devices = append(devices, d)
+		}
+	}
+	return devices, nil
+}
+
+func (m *memoryStore) DeleteDeviceToken(_ context.Context, token string) error {
+	kept := m.devices[:0]
+	for _, d := range m.devices {
+		if d.Token != token {
+			kept = append(kept, d)
+		}
+	}
+	m.devices = kept
+	return nil
+}
+
+type failingProvider struct{}
+
+func (failingProvider) Send(context.Context, string, Notification) error {
+	return errors.New("provider down")
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	n := Notification{Title: "Match complet", Body: "Votre match est complet"}
+
+	type testCase struct {
+		name       string
+		prefs      map[string]models.DBNotificationPreferences
+		devices    []models.DBDevice
+		invalid    []string
+		android    Provider
+		wantSent   int
+		wantErr    bool
+		wantTokens []string
+	}
+
+	testCases := []testCase{
+		{
+			name: "Every device notified",
+			devices: []models.DBDevice{
+				models.NewDBDeviceFixture().WithToken("ios-1").WithUserId("u1").WithPlatform(models.PlatformIOS),
+				models.NewDBDeviceFixture().WithToken("android-1").WithUserId("u1"),
+				models.NewDBDeviceFixture().WithToken("other").WithUserId("u2"),
+			},
+			wantSent:   2,
+			wantTokens: []string{"ios-1", "android-1", "other"},
+		},
+		{
+			name:  "Kind turned off -> nothing sent",
+			prefs: map[string]models.DBNotificationPreferences{"u1": models.DefaultNotificationPreferences("u1").WithMatchFilled(false)},
+			devices: []models.DBDevice{
+				models.NewDBDeviceFixture().WithToken("ios-1").WithUserId("u1").WithPlatform(models.PlatformIOS),
+			},
+			wantSent:   0,
+			wantTokens: []string{"ios-1"},
+		},
+		{
+			name: "Invalid token -> deleted",
+			devices: []models.DBDevice{
+				models.NewDBDeviceFixture().WithToken("ios-1").WithUserId("u1").WithPlatform(models.PlatformIOS),
+				models.NewDBDeviceFixture().WithToken("android-1").WithUserId("u1"),
+			},
+			invalid:    []string{"android-1"},
+			wantSent:   1,
+			wantTokens: []string{"ios-1"},
+		},
+		{
+			name: "One provider failing, another device reached -> no error",
+			devices: []models.DBDevice{
+				models.NewDBDeviceFixture().WithToken("ios-1").WithUserId("u1").WithPlatform(models.PlatformIOS),
+				models.NewDBDeviceFixture().WithToken("android-1").WithUserId("u1"),
+			},
+			android:    failingProvider{},
+			wantSent:   1,
+			wantTokens: []string{"ios-1", "android-1"},
+		},
+		{
+			name: "No device reached -> error",
+			devices: []models.DBDevice{
+				models.NewDBDeviceFixture().WithToken("android-1").WithUserId("u1"),
+			},
+			android:    failingProvider{},
+			wantErr:    true,
+			wantTokens: []string{"android-1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &memoryStore{prefs: tc.prefs, devices: tc.devices}
+			fake := NewFake()
+			for _, token := range tc.invalid {
+				fake.Invalid[token] = true
+			}
+			var android Provider = fake
+			if tc.android != nil {
+				android = tc.android
+			}
+			nt := &Notifier{Store: store, Providers: map[models.DevicePlatform]Provider{
+				models.PlatformIOS:     fake,
+				models.PlatformAndroid: android,
+			}}
+
+			sent, err := nt.Notify(context.Background(), "u1", models.NotifyMatchFilled, n)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.wantSent, sent)
+
+			var tokens []string
+			for _, d := range store.devices {
+				tokens = append(tokens, d.Token)
+			}
+			require.Equal(t, tc.wantTokens, tokens)
+		})
+	}
+}
+
+func TestAPNs_Send(t *testing.T) {
+	type testCase struct {
+		name        string
+		status      int
+		reason      string
+		wantInvalid bool
+		wantErr     bool
+	}
+
+	testCases := []testCase{
+		{name: "Delivered", status: http.StatusOK},
+		{name: "Unregistered -> invalid token", status: http.StatusGone, reason: "Unregistered", wantInvalid: true, wantErr: true},
+		{name: "Bad device token -> invalid token", status: http.StatusBadRequest, reason: "BadDeviceToken", wantInvalid: true, wantErr: true},
+		{name: "Server error -> error", status: http.StatusServiceUnavailable, reason: "ServiceUnavailable", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "/3/device/tok", r.URL.Path)
+				require.Equal(t, "bearer jwt", r.Header.Get("Authorization"))
+				require.Equal(t, "com.plic.app", r.Header.Get("apns-topic"))
+
+				var payload struct {
+					Aps     apnsAps `json:"aps"`
+					MatchID string  `json:"match_id"`
+				}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+				require.Equal(t, "Titre", payload.Aps.Alert.Title)
+				require.Equal(t, "m1", payload.MatchID)
+
+				w.WriteHeader(tc.status)
+				if tc.reason != "" {
+					_ = json.NewEncoder(w).Encode(map[string]string{"reason": tc.reason})
+				}
+			}))
+			defer srv.Close()
+
+			a := &APNs{Endpoint: srv.URL, Topic: "com.plic.app", Token: "jwt", Client: srv.Client()}
+			err := a.Send(context.Background(), "tok", Notification{Title: "Titre", Body: "Corps", Data: map[string]string{"match_id": "m1"}})
+			if !tc.wantErr {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Equal(t, tc.wantInvalid, errors.Is(err, ErrInvalidToken))
+		})
+	}
+}
+
+func TestFCM_Send(t *testing.T) {
+	type testCase struct {
+		name        string
+		status      int
+		reply       string
+		wantInvalid bool
+		wantErr     bool
+	}
+
+	testCases := []testCase{
+		{name: "Delivered", status: http.StatusOK, reply: `{"name":"projects/p/messages/1"}`},
+		{name: "Unregistered -> invalid token", status: http.StatusNotFound, reply: `{"error":{"status":"NOT_FOUND","details":[{"errorCode":"UNREGISTERED"}]}}`, wantInvalid: true, wantErr: true},
+		{name: "Unregistered detail -> invalid token", status: http.StatusBadRequest, reply: `{"error":{"status":"INVALID_ARGUMENT","details":[{"errorCode":"UNREGISTERED"}]}}`, wantInvalid: true, wantErr: true},
+		{name: "Quota exceeded -> error", status: http.StatusTooManyRequests, reply: `{"error":{"status":"RESOURCE_EXHAUSTED"}}`, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "Bearer oauth", r.Header.Get("Authorization"))
+
+				var body map[string]fcmMessage
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				require.Equal(t, "tok", body["message"].Token)
+				require.Equal(t, "Titre", body["message"].Notification.Title)
+
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.reply))
+			}))
+			defer srv.Close()
+
+			f := &FCM{Endpoint: srv.URL, Token: "oauth", Client: srv.Client()}
+			err := f.Send(context.Background(), "tok", Notification{Title: "Titre", Body: "Corps"})
+			if !tc.wantErr {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Equal(t, tc.wantInvalid, errors.Is(err, ErrInvalidToken))
+		})
+	}
+}
@@ -0,0 +1,163 @@
+// Package outbox delivers the side effects recorded in the outbox_events table by the
+// transactions causing them.
+package outbox
+
+import (
+	"PLIC/clock"
+	"PLIC/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Handler delivers one event. Delivery is at least once: an event whose handler
+// succeeded may be handled again if the dispatcher crashes before recording it.
+type Handler interface {
+	Handle(ctx context.Context, event models.DBOutboxEvent) error
+}
+
+type HandlerFunc func(ctx context.Context, event models.DBOutboxEvent) error
+
+func (f HandlerFunc) Handle(ctx context.Context, event models.DBOutboxEvent) error {
+	return f(ctx, event)
+}
+
+// permanentError marks a failure that retrying cannot fix.
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps the error of a handler that must not be retried, e.g. an invalid
+// payload: the event goes straight to the dead state.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// IsPermanent tells whether err was wrapped by Permanent.
+func IsPermanent(err error) bool {
+	return errors.As(err, new(permanentError))
+}
+
+// Store is the storage of the events, implemented by database.Database.
+type Store interface {
+	ClaimOutboxEvents(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.DBOutboxEvent, error)
+	CompleteOutboxEvent(ctx context.Context, id string, now time.Time) error
+	RetryOutboxEvent(ctx context.Context, id string, next time.Time, lastErr string) error
+	KillOutboxEvent(ctx context.Context, id string, now time.Time, lastErr string) error
+}
+
+// Dispatcher hands the due events to the handler of their type. A failed event is
+// retried with an exponential backoff, and dead once it failed Config.MaxAttempts
+// times or with a permanent error. Several dispatchers can run at once: each event is
+// claimed by one of them.
+type Dispatcher struct {
+	Store    Store
+	Clock    clock.Clock
+	Config   models.OutboxConfig
+	Handlers map[models.OutboxEventType]Handler
+}
+
+// Result counts what a run did with the events it claimed.
+type Result struct {
+	Delivered int
+	Retried   int
+	Dead      int
+}
+
+func (r *Result) add(other Result) {
+	r.Delivered += other.Delivered
+	r.Retried += other.Retried
+	r.Dead += other.Dead
+}
+
+// Drain runs batches until no event is due.
+func (d Dispatcher) Drain(ctx context.Context) (Result, error) {
+	var total Result
+	for {
+		res, claimed, err := d.runBatch(ctx)
+		total.add(res)
+		if err != nil {
+			return total, err
+		}
+		if claimed == 0 || claimed < d.Config.BatchSize {
+			return total, nil
+		}
+	}
+}
+
+// RunOnce delivers one batch of due events.
+func (d Dispatcher) RunOnce(ctx context.Context) (Result, error) {
+	res, _, err := d.runBatch(ctx)
+	return res, err
+}
+
+func (d Dispatcher) runBatch(ctx context.Context) (Result, int, error) {
+	var res Result
+	events, err := d.Store.ClaimOutboxEvents(ctx, d.Clock.Now(), d.Config.Lease, d.Config.BatchSize)
+	if err != nil {
+		return res, 0, err
+	}
+
+	for _, e := range events {
+		logger := log.With().
+			Str("method", "outbox.Dispatcher").
+			Str("event_id", e.Id).
+			Str("type", string(e.Type)).
+			Int("attempt", e.Attempts).
+			Logger()
+
+		handleErr := d.handle(ctx, e)
+		now := d.Clock.Now()
+		switch {
+		case handleErr == nil:
+			if err := d.Store.CompleteOutboxEvent(ctx, e.Id, now); err != nil {
+				return res, len(events), err
+			}
+			res.Delivered++
+			logger.Info().Msg("outbox event delivered")
+
+		case IsPermanent(handleErr) || e.Attempts >= d.Config.MaxAttempts:
+			if err := d.Store.KillOutboxEvent(ctx, e.Id, now, handleErr.Error()); err != nil {
+				return res, len(events), err
+			}
+			res.Dead++
+			logger.Error().Err(handleErr).Msg("outbox event dead")
+
+		default:
+			next := now.Add(d.Backoff(e.Attempts))
+			if err := d.Store.RetryOutboxEvent(ctx, e.Id, next, handleErr.Error()); err != nil {
+				return res, len(events), err
+			}
+			res.Retried++
+			logger.Warn().Err(handleErr).Time("next_attempt_at", next).Msg("outbox event failed, will retry")
+		}
+	}
+	return res, len(events), nil
+}
+
+func (d Dispatcher) handle(ctx context.Context, e models.DBOutboxEvent) error {
+	h, ok := d.Handlers[e.Type]
+	if !ok {
+		return Permanent(fmt.Errorf("no handler for outbox event type %q", e.Type))
+	}
+	return h.Handle(ctx, e)
+}
+
+// Backoff is the delay before the next attempt of an event that failed attempts
+// times: BaseBackoff, doubled on every failure, capped at MaxBackoff.
+func (d Dispatcher) Backoff(attempts int) time.Duration {
+	delay := d.Config.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.Config.MaxBackoff {
+			return d.Config.MaxBackoff
+		}
+	}
+	return min(delay, d.Config.MaxBackoff)
+}
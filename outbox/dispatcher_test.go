@@ -0,0 +1,154 @@
+package outbox
+
+import (
+	"PLIC/clock"
+	"PLIC/models"
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is a Store kept in memory, claiming like the database does.
+type memoryStore struct {
+	events map[string]*models.DBOutboxEvent
+}
+
+func newMemoryStore(events ...models.DBOutboxEvent) *memoryStore {
+	s := &memoryStore{events: make(map[string]*models.DBOutboxEvent)}
+	for i := range events {
+		e := events[i]
+		s.events[e.Id] = &e
+	}
+	return s
+}
+
+func (s *memoryStore) ClaimOutboxEvents(_ context.Context, now time.Time, lease time.Duration, limit int) ([]models.DBOutboxEvent, error) {
+	var due []*models.DBOutboxEvent
+	for _, e := range s.events {
+		if e.Status == models.OutboxPending && !e.NextAttemptAt.After(now) {
+			due = append(due, e)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].CreatedAt.Before(due[j].CreatedAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	claimed := make([]models.DBOutboxEvent, 0, len(due))
+	for _, e := range due {
+		e.Attempts++
+		e.NextAttemptAt = now.Add(lease)
+		claimed = append(claimed, *e)
+	}
+	return claimed, nil
+}
+
+func (s *memoryStore) CompleteOutboxEvent(_ context.Context, id string, now time.Time) error {
+	s.events[id].Status = models.OutboxDone
+	s.events[id].ProcessedAt = &now
+	return nil
+}
+
+func (s *memoryStore) RetryOutboxEvent(_ context.Context, id string, next time.Time, lastErr string) error {
+	s.events[id].NextAttemptAt = next
+	s.events[id].LastError = &lastErr
+	return nil
+}
+
+func (s *memoryStore) KillOutboxEvent(_ context.Context, id string, now time.Time, lastErr string) error {
+	s.events[id].Status = models.OutboxDead
+	s.events[id].ProcessedAt = &now
+	s.events[id].LastError = &lastErr
+	return nil
+}
+
+func TestDispatcher_Backoff(t *testing.T) {
+	d := Dispatcher{Config: models.OutboxConfig{BaseBackoff: 30 * time.Second, MaxBackoff: 5 * time.Minute}}
+
+	require.Equal(t, 30*time.Second, d.Backoff(1))
+	require.Equal(t, time.Minute, d.Backoff(2))
+	require.Equal(t, 4*time.Minute, d.Backoff(4))
+	require.Equal(t, 5*time.Minute, d.Backoff(5))
+	require.Equal(t, 5*time.Minute, d.Backoff(50))
+}
+
+func TestDispatcher_Drain(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	config := models.OutboxConfig{
+		MaxAttempts: 3,
+		BaseBackoff: time.Minute,
+		MaxBackoff:  time.Hour,
+		Lease:       5 * time.Minute,
+		BatchSize:   2,
+	}
+
+	ok := models.NewDBOutboxEventFixture().WithType("ok").WithNextAttemptAt(start)
+	flaky := models.NewDBOutboxEventFixture().WithType("flaky").WithNextAttemptAt(start)
+	broken := models.NewDBOutboxEventFixture().WithType("broken").WithNextAttemptAt(start)
+	invalid := models.NewDBOutboxEventFixture().WithType("invalid").WithNextAttemptAt(start)
+	unknown := models.NewDBOutboxEventFixture().WithType("unknown").WithNextAttemptAt(start)
+	later := models.NewDBOutboxEventFixture().WithType("ok").WithNextAttemptAt(start.Add(time.Hour))
+
+	calls := map[models.OutboxEventType]int{}
+	handler := func(err func(attempt int) error) Handler {
+		return HandlerFunc(func(_ context.Context, e models.DBOutboxEvent) error {
+			calls[e.Type]++
+			return err(calls[e.Type])
+		})
+	}
+
+	clk := clock.NewMock(start)
+	store := newMemoryStore(ok, flaky, broken, invalid, unknown, later)
+	d := Dispatcher{
+		Store:  store,
+		Clock:  clk,
+		Config: config,
+		Handlers: map[models.OutboxEventType]Handler{
+			"ok": handler(func(int) error { return nil }),
+			"flaky": handler(func(attempt int) error {
+				if attempt == 1 {
+					return errors.New("smtp timeout")
+				}
+				return nil
+			}),
+			"broken":  handler(func(int) error { return errors.New("smtp down") }),
+			"invalid": handler(func(int) error { return Permanent(errors.New("bad payload")) }),
+		},
+	}
+
+	res, err := d.Drain(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, Result{Delivered: 1, Retried: 2, Dead: 2}, res)
+	require.Equal(t, models.OutboxDone, store.events[ok.Id].Status)
+	require.Equal(t, models.OutboxDead, store.events[invalid.Id].Status)
+	require.Equal(t, models.OutboxDead, store.events[unknown.Id].Status)
+	require.Equal(t, start.Add(time.Minute), store.events[flaky.Id].NextAttemptAt)
+	require.Equal(t, "smtp timeout", *store.events[flaky.Id].LastError)
+	require.Equal(t, models.OutboxPending, store.events[later.Id].Status)
+
+	// Not due yet: nothing happens.
+	res, err = d.Drain(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, Result{}, res)
+
+	clk.Advance(time.Minute)
+	res, err = d.Drain(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, Result{Delivered: 1, Retried: 1}, res)
+	require.Equal(t, models.OutboxDone, store.events[flaky.Id].Status)
+	require.Equal(t, start.Add(3*time.Minute), store.events[broken.Id].NextAttemptAt)
+
+	// Third failure reaches MaxAttempts; the delayed event is due too.
+	clk.Advance(time.Hour)
+	res, err = d.Drain(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, Result{Delivered: 1, Dead: 1}, res)
+	require.Equal(t, models.OutboxDead, store.events[broken.Id].Status)
+	require.Equal(t, 3, store.events[broken.Id].Attempts)
+	require.Equal(t, "smtp down", *store.events[broken.Id].LastError)
+	require.Equal(t, models.OutboxDone, store.events[later.Id].Status)
+}
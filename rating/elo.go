@@ -0,0 +1,69 @@
+package rating
+
+import (
+	"PLIC/models"
+	"math"
+	"time"
+)
+
+const DefaultElo = 1000
+const KFactor = 32
+
+// Elo is the team Elo engine: each team plays with the average Elo of its players
+// and every player of a team gets the same expected score. During their first
+// ProvisionalGames games on a court, players move by ProvisionalK instead of K so a
+// newcomer quickly reaches their level.
+type Elo struct {
+	K                int
+	ProvisionalK     int
+	ProvisionalGames int
+}
+
+func (e Elo) NewRanking(userID, courtID string, sport models.Sport, now time.Time) models.DBRanking {
+	return newRanking(userID, courtID, sport, now)
+}
+
+func (e Elo) Rate(team1, team2 []models.DBRanking, score1, score2 int, now time.Time) []models.DBRanking {
+	avg := func(rs []models.DBRanking) float64 {
+		if len(rs) == 0 {
+			return float64(DefaultElo)
+		}
+		sum := 0
+		for _, r := range rs {
+			sum += r.Elo
+		}
+		return float64(sum) / float64(len(rs))
+	}
+
+	rTeam1 := avg(team1)
+	rTeam2 := avg(team2)
+
+	s1, s2 := outcome(score1, score2)
+
+	exp := func(rA, rB float64) float64 {
+		return 1.0 / (1.0 + math.Pow(10, (rB-rA)/400.0))
+	}
+	e1 := exp(rTeam1, rTeam2)
+	e2 := exp(rTeam2, rTeam1)
+
+	applyDelta := func(rs []models.DBRanking, S, E float64) []models.DBRanking {
+		out := make([]models.DBRanking, len(rs))
+		for i, rk := range rs {
+			delta := int(math.Round(float64(e.kFactor(rk)) * (S - E)))
+			rk.Elo = rk.Elo + delta
+			rk.GamesPlayed++
+			rk.UpdatedAt = now
+			out[i] = rk
+		}
+		return out
+	}
+
+	return append(applyDelta(team1, s1, e1), applyDelta(team2, s2, e2)...)
+}
+
+func (e Elo) kFactor(rk models.DBRanking) int {
+	if rk.GamesPlayed < e.ProvisionalGames {
+		return e.ProvisionalK
+	}
+	return e.K
+}
@@ -0,0 +1,94 @@
+package rating
+
+import (
+	"PLIC/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestElo_Rate(t *testing.T) {
+	type testCase struct {
+		name           string
+		engine         Elo
+		team1Elo       []int
+		team2Elo       []int
+		gamesPlayed    int
+		score1, score2 int
+		expected       []int
+	}
+
+	testCases := []testCase{
+		{
+			name:     "Even teams, team 1 wins",
+			engine:   Elo{K: KFactor},
+			team1Elo: []int{1000},
+			team2Elo: []int{1000},
+			score1:   3,
+			score2:   1,
+			expected: []int{1016, 984},
+		},
+		{
+			name:     "Even teams, draw",
+			engine:   Elo{K: KFactor},
+			team1Elo: []int{1000, 1000},
+			team2Elo: []int{1000, 1000},
+			score1:   2,
+			score2:   2,
+			expected: []int{1000, 1000, 1000, 1000},
+		},
+		{
+			name:     "Underdog wins -> bigger gain",
+			engine:   Elo{K: KFactor},
+			team1Elo: []int{800, 1000},
+			team2Elo: []int{1100},
+			score1:   5,
+			score2:   4,
+			expected: []int{824, 1024, 1076},
+		},
+		{
+			name:        "Provisional period -> provisional K",
+			engine:      Elo{K: 16, ProvisionalK: 48, ProvisionalGames: 5},
+			team1Elo:    []int{1000},
+			team2Elo:    []int{1000},
+			gamesPlayed: 4,
+			score1:      3,
+			score2:      1,
+			expected:    []int{1024, 976},
+		},
+		{
+			name:        "Provisional period over -> regular K",
+			engine:      Elo{K: 16, ProvisionalK: 48, ProvisionalGames: 5},
+			team1Elo:    []int{1000},
+			team2Elo:    []int{1000},
+			gamesPlayed: 5,
+			score1:      3,
+			score2:      1,
+			expected:    []int{1008, 992},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			rankings := func(elos []int) []models.DBRanking {
+				out := make([]models.DBRanking, len(elos))
+				for i, elo := range elos {
+					out[i] = models.NewDBRankingFixture().WithElo(elo).WithGamesPlayed(c.gamesPlayed)
+				}
+				return out
+			}
+
+			now := time.Now()
+			updated := c.engine.Rate(rankings(c.team1Elo), rankings(c.team2Elo), c.score1, c.score2, now)
+
+			require.Len(t, updated, len(c.expected))
+			for i, rk := range updated {
+				require.Equal(t, c.expected[i], rk.Elo)
+				require.Equal(t, c.gamesPlayed+1, rk.GamesPlayed)
+				require.Equal(t, now, rk.UpdatedAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package rating
+
+import (
+	"PLIC/models"
+	"fmt"
+	"time"
+)
+
+// RatingEngine turns match results into rankings. Rankings are per player, court and
+// sport; an engine only reads and writes the fields of models.DBRanking it needs.
+type RatingEngine interface {
+	// NewRanking returns the ranking of a player who never played sport on courtID.
+	NewRanking(userID, courtID string, sport models.Sport, now time.Time) models.DBRanking
+	// Rate returns the rankings of both teams once the match ended with score1 -
+	// score2, team 1 first.
+	Rate(team1, team2 []models.DBRanking, score1, score2 int, now time.Time) []models.DBRanking
+}
+
+// Engines holds the rating engine of each sport. Sports without an engine are rated
+// with the default Elo.
+type Engines map[models.Sport]RatingEngine
+
+// DefaultEngines rates every sport with Elo, K = KFactor and no provisional period.
+func DefaultEngines() Engines {
+	return Engines{
+		models.Basket:   Elo{K: KFactor},
+		models.Foot:     Elo{K: KFactor},
+		models.PingPong: Elo{K: KFactor},
+	}
+}
+
+// NewEngines builds the engines described by the configuration.
+func NewEngines(cfg models.RatingConfig) (Engines, error) {
+	engines := Engines{}
+	for sport, c := range map[models.Sport]models.RatingEngineConfig{
+		models.Basket:   cfg.Basket,
+		models.Foot:     cfg.Foot,
+		models.PingPong: cfg.PingPong,
+	} {
+		engine, err := newEngine(c)
+		if err != nil {
+			return nil, fmt.Errorf("rating engine for %s: %w", sport, err)
+		}
+		engines[sport] = engine
+	}
+	return engines, nil
+}
+
+func newEngine(c models.RatingEngineConfig) (RatingEngine, error) {
+	switch c.Engine {
+	case "", "elo":
+		if c.K <= 0 || c.ProvisionalK < 0 || c.ProvisionalGames < 0 {
+			return nil, fmt.Errorf("invalid elo parameters: k=%d provisional_k=%d provisional_games=%d", c.K, c.ProvisionalK, c.ProvisionalGames)
+		}
+		return Elo{K: c.K, ProvisionalK: c.ProvisionalK, ProvisionalGames: c.ProvisionalGames}, nil
+	case "glicko2":
+		if c.Tau <= 0 {
+			return nil, fmt.Errorf("invalid glicko2 tau: %v", c.Tau)
+		}
+		return Glicko2{Tau: c.Tau}, nil
+	default:
+		return nil, fmt.Errorf("unknown engine %q", c.Engine)
+	}
+}
+
+func (e Engines) For(sport models.Sport) RatingEngine {
+	if engine, ok := e[sport]; ok {
+		return engine
+	}
+	return Elo{K: KFactor}
+}
+
+// Rate rates a match of sport with that sport's engine. Its signature matches
+// database.RateFunc.
+func (e Engines) Rate(sport models.Sport, team1, team2 []models.DBRanking, score1, score2 int, now time.Time) []models.DBRanking {
+	return e.For(sport).Rate(team1, team2, score1, score2, now)
+}
+
+func (e Engines) NewRanking(userID, courtID string, sport models.Sport, now time.Time) models.DBRanking {
+	return e.For(sport).NewRanking(userID, courtID, sport, now)
+}
+
+// outcome returns the result of the match for team 1 and team 2: 1 for a win, 0.5
+// for a draw and 0 for a loss.
+func outcome(score1, score2 int) (float64, float64) {
+	switch {
+	case score1 > score2:
+		return 1, 0
+	case score1 < score2:
+		return 0, 1
+	default:
+		return 0.5, 0.5
+	}
+}
+
+func newRanking(userID, courtID string, sport models.Sport, now time.Time) models.DBRanking {
+	return models.DBRanking{
+		UserID:     userID,
+		CourtID:    courtID,
+		Sport:      sport,
+		Elo:        DefaultElo,
+		Deviation:  DefaultDeviation,
+		Volatility: DefaultVolatility,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
@@ -0,0 +1,129 @@
+package rating
+
+import (
+	"PLIC/models"
+	"math"
+	"time"
+)
+
+const DefaultDeviation = 350.0
+const DefaultVolatility = 0.06
+
+// glicko2Scale converts between the Glicko rating scale and the Glicko-2 internal one.
+const glicko2Scale = 173.7178
+
+const glicko2Epsilon = 0.000001
+
+// Glicko2 is the Glicko-2 engine (Glickman, "Example of the Glicko-2 system"). Every
+// match is its own rating period. A player faces the opposing team as one composite
+// opponent whose rating is the team average and whose deviation is the quadratic
+// mean of the team's deviations. Tau constrains how fast volatility changes.
+type Glicko2 struct {
+	Tau float64
+}
+
+func (g Glicko2) NewRanking(userID, courtID string, sport models.Sport, now time.Time) models.DBRanking {
+	return newRanking(userID, courtID, sport, now)
+}
+
+func (g Glicko2) Rate(team1, team2 []models.DBRanking, score1, score2 int, now time.Time) []models.DBRanking {
+	s1, s2 := outcome(score1, score2)
+
+	opp1Mu, opp1Phi := composite(team2)
+	opp2Mu, opp2Phi := composite(team1)
+
+	out := make([]models.DBRanking, 0, len(team1)+len(team2))
+	for _, rk := range team1 {
+		out = append(out, g.update(rk, opp1Mu, opp1Phi, s1, now))
+	}
+	for _, rk := range team2 {
+		out = append(out, g.update(rk, opp2Mu, opp2Phi, s2, now))
+	}
+	return out
+}
+
+// composite returns the Glicko-2 rating and deviation of a team seen as one player.
+func composite(team []models.DBRanking) (mu, phi float64) {
+	if len(team) == 0 {
+		return 0, DefaultDeviation / glicko2Scale
+	}
+	var sumMu, sumPhi2 float64
+	for _, rk := range team {
+		m, p := toGlicko2(rk)
+		sumMu += m
+		sumPhi2 += p * p
+	}
+	n := float64(len(team))
+	return sumMu / n, math.Sqrt(sumPhi2 / n)
+}
+
+func (g Glicko2) update(rk models.DBRanking, oppMu, oppPhi, score float64, now time.Time) models.DBRanking {
+	mu, phi := toGlicko2(rk)
+	sigma := rk.Volatility
+	if sigma <= 0 {
+		sigma = DefaultVolatility
+	}
+
+	gPhi := 1 / math.Sqrt(1+3*oppPhi*oppPhi/(math.Pi*math.Pi))
+	e := 1 / (1 + math.Exp(-gPhi*(mu-oppMu)))
+	v := 1 / (gPhi * gPhi * e * (1 - e))
+	delta := v * gPhi * (score - e)
+
+	sigma = g.volatility(phi, sigma, v, delta)
+
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	newPhi := 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	newMu := mu + newPhi*newPhi*gPhi*(score-e)
+
+	rk.Elo = int(math.Round(newMu*glicko2Scale)) + DefaultElo
+	rk.Deviation = math.Min(newPhi*glicko2Scale, DefaultDeviation)
+	rk.Volatility = sigma
+	rk.GamesPlayed++
+	rk.UpdatedAt = now
+	return rk
+}
+
+// volatility is step 5 of the algorithm: it solves for the new volatility with the
+// Illinois variant of regula falsi.
+func (g Glicko2) volatility(phi, sigma, v, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	tau2 := g.Tau * g.Tau
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-phi*phi-v-ex)/(2*d*d) - (x-a)/tau2
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*g.Tau) < 0 {
+			k++
+		}
+		B = a - k*g.Tau
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > glicko2Epsilon {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+	return math.Exp(A / 2)
+}
+
+func toGlicko2(rk models.DBRanking) (mu, phi float64) {
+	deviation := rk.Deviation
+	if deviation <= 0 {
+		deviation = DefaultDeviation
+	}
+	return float64(rk.Elo-DefaultElo) / glicko2Scale, deviation / glicko2Scale
+}
@@ -0,0 +1,82 @@
+package rating
+
+import (
+	"PLIC/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlicko2_Rate(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	engine := Glicko2{Tau: 0.5}
+
+	t.Run("Win against a lower rated, settled opponent", func(t *testing.T) {
+		t.Parallel()
+		player := models.NewDBRankingFixture().WithElo(DefaultElo)
+		player.Deviation = 200
+		opponent := models.NewDBRankingFixture().WithElo(DefaultElo - 100)
+		opponent.Deviation = 30
+
+		updated := engine.Rate([]models.DBRanking{player}, []models.DBRanking{opponent}, 1, 0, now)
+		require.Len(t, updated, 2)
+
+		require.Greater(t, updated[0].Elo, player.Elo)
+		require.Less(t, updated[0].Deviation, player.Deviation)
+		require.InDelta(t, 0.06, updated[0].Volatility, 0.001)
+		require.Equal(t, 1, updated[0].GamesPlayed)
+		require.Equal(t, now, updated[0].UpdatedAt)
+
+		// The opponent is almost certain of their rating: they barely move.
+		require.Less(t, updated[1].Elo, opponent.Elo)
+		require.Less(t, opponent.Elo-updated[1].Elo, updated[0].Elo-player.Elo)
+	})
+
+	t.Run("Even teams, draw", func(t *testing.T) {
+		t.Parallel()
+		team1 := []models.DBRanking{models.NewDBRankingFixture(), models.NewDBRankingFixture()}
+		team2 := []models.DBRanking{models.NewDBRankingFixture(), models.NewDBRankingFixture()}
+
+		updated := engine.Rate(team1, team2, 2, 2, now)
+		require.Len(t, updated, 4)
+		for _, rk := range updated {
+			require.Equal(t, DefaultElo, rk.Elo)
+			require.Less(t, rk.Deviation, DefaultDeviation)
+		}
+	})
+
+	t.Run("Uncertain player moves more", func(t *testing.T) {
+		t.Parallel()
+		newcomer := models.NewDBRankingFixture()
+		veteran := models.NewDBRankingFixture()
+		veteran.Deviation = 50
+		opponent := models.NewDBRankingFixture()
+
+		fromNewcomer := engine.Rate([]models.DBRanking{newcomer}, []models.DBRanking{opponent}, 1, 0, now)
+		fromVeteran := engine.Rate([]models.DBRanking{veteran}, []models.DBRanking{opponent}, 1, 0, now)
+		require.Greater(t, fromNewcomer[0].Elo-DefaultElo, fromVeteran[0].Elo-DefaultElo)
+	})
+}
+
+func TestNewEngines(t *testing.T) {
+	t.Parallel()
+
+	engines, err := NewEngines(models.RatingConfig{
+		Basket:   models.RatingEngineConfig{Engine: "elo", K: 24, ProvisionalK: 40, ProvisionalGames: 10},
+		Foot:     models.RatingEngineConfig{Engine: "glicko2", Tau: 0.3},
+		PingPong: models.RatingEngineConfig{Engine: "elo", K: 32},
+	})
+	require.NoError(t, err)
+	require.Equal(t, Elo{K: 24, ProvisionalK: 40, ProvisionalGames: 10}, engines.For(models.Basket))
+	require.Equal(t, Glicko2{Tau: 0.3}, engines.For(models.Foot))
+	require.Equal(t, Elo{K: 32}, engines.For(models.PingPong))
+
+	_, err = NewEngines(models.RatingConfig{
+		Basket:   models.RatingEngineConfig{Engine: "trueskill"},
+		Foot:     models.RatingEngineConfig{Engine: "elo", K: 32},
+		PingPong: models.RatingEngineConfig{Engine: "elo", K: 32},
+	})
+	require.Error(t, err)
+}